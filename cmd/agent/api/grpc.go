@@ -32,11 +32,13 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/grpc"
 	"github.com/DataDog/datadog-agent/pkg/util/hostname"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
 )
 
 const (
-	taggerStreamSendTimeout = 1 * time.Minute
-	streamKeepAliveInterval = 9 * time.Minute
+	taggerStreamSendTimeout       = 1 * time.Minute
+	workloadmetaStreamSendTimeout = 1 * time.Minute
+	streamKeepAliveInterval       = 9 * time.Minute
 )
 
 type server struct {
@@ -45,6 +47,7 @@ type server struct {
 
 type serverSecure struct {
 	pb.UnimplementedAgentSecureServer
+	pb.UnimplementedAgentWorkloadmetaServer
 	configService *remoteconfig.Service
 }
 
@@ -216,6 +219,90 @@ func (s *serverSecure) TaggerFetchEntity(ctx context.Context, in *pb.FetchEntity
 	}, nil
 }
 
+// WorkloadmetaStreamEntities subscribes to set and unset events in the
+// workloadmeta store and streams them to clients as pb.WorkloadmetaStreamResponse
+// events. The store is subscribed to once per requested source, so that every
+// event carries the data reported by a single source.
+func (s *serverSecure) WorkloadmetaStreamEntities(in *pb.WorkloadmetaStreamRequest, out pb.AgentWorkloadmeta_WorkloadmetaStreamEntitiesServer) error {
+	filters, err := pbutils.Pb2WorkloadmetaFilters(in.GetFilter())
+	if err != nil {
+		return err
+	}
+
+	store := workloadmeta.GetGlobalStore()
+	eventsCh := make(chan []*pb.WorkloadmetaEvent)
+
+	for source, filter := range filters {
+		ch := store.Subscribe("grpc-stream-"+string(source), workloadmeta.NormalPriority, filter)
+		defer store.Unsubscribe(ch)
+
+		go func(source workloadmeta.Source, ch chan workloadmeta.EventBundle) {
+			for bundle := range ch {
+				// the events are converted before the bundle is
+				// acknowledged, as they are shared with other
+				// subscribers
+				events := make([]*pb.WorkloadmetaEvent, 0, len(bundle.Events))
+				for _, event := range bundle.Events {
+					e, err := pbutils.Workloadmeta2PbEvent(source, event)
+					if err != nil {
+						log.Warnf("can't convert workloadmeta event to protobuf: %s", err)
+						continue
+					}
+
+					events = append(events, e)
+				}
+
+				close(bundle.Ch)
+
+				if len(events) == 0 {
+					continue
+				}
+
+				select {
+				case eventsCh <- events:
+				case <-out.Context().Done():
+				}
+			}
+		}(source, ch)
+	}
+
+	ticker := time.NewTicker(streamKeepAliveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case events := <-eventsCh:
+			ticker.Reset(streamKeepAliveInterval)
+
+			err = grpc.DoWithTimeout(func() error {
+				return out.Send(&pb.WorkloadmetaStreamResponse{
+					Events: events,
+				})
+			}, workloadmetaStreamSendTimeout)
+
+			if err != nil {
+				log.Warnf("error sending workloadmeta event: %s", err)
+				return err
+			}
+
+		case <-out.Context().Done():
+			return nil
+
+		// keep the connection alive in the same way as TaggerStreamEntities
+		case <-ticker.C:
+			err = grpc.DoWithTimeout(func() error {
+				return out.Send(&pb.WorkloadmetaStreamResponse{
+					Events: []*pb.WorkloadmetaEvent{},
+				})
+			}, workloadmetaStreamSendTimeout)
+
+			if err != nil {
+				log.Warnf("error sending workloadmeta keep-alive: %s", err)
+				return err
+			}
+		}
+	}
+}
+
 func (s *serverSecure) ClientGetConfigs(ctx context.Context, in *pb.ClientGetConfigsRequest) (*pb.ClientGetConfigsResponse, error) {
 	if s.configService == nil {
 		log.Debug("Remote configuration service not initialized")
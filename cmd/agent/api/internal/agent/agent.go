@@ -66,13 +66,16 @@ func SetupHandlers(r *mux.Router) *mux.Router {
 	r.HandleFunc("/{component}/configs", componentConfigHandler).Methods("GET")
 	r.HandleFunc("/gui/csrf-token", getCSRFToken).Methods("GET")
 	r.HandleFunc("/config-check", getConfigCheck).Methods("GET")
+	r.HandleFunc("/config-check/explain", getConfigCheckExplain).Methods("GET")
 	r.HandleFunc("/config", settingshttp.Server.GetFull("")).Methods("GET")
 	r.HandleFunc("/config/list-runtime", settingshttp.Server.ListConfigurable).Methods("GET")
 	r.HandleFunc("/config/{setting}", settingshttp.Server.GetValue).Methods("GET")
 	r.HandleFunc("/config/{setting}", settingshttp.Server.SetValue).Methods("POST")
 	r.HandleFunc("/tagger-list", getTaggerList).Methods("GET")
+	r.HandleFunc("/tagger-inspect", getTaggerInspect).Methods("GET")
 	r.HandleFunc("/workload-list/short", getShortWorkloadList).Methods("GET")
 	r.HandleFunc("/workload-list/verbose", getVerboseWorkloadList).Methods("GET")
+	r.HandleFunc("/workload-list/snapshot", getWorkloadSnapshot).Methods("GET")
 	r.HandleFunc("/secrets", secretInfo).Methods("GET")
 	r.HandleFunc("/metadata/{payload}", metadataPayload).Methods("GET")
 
@@ -363,6 +366,34 @@ func getConfigCheck(w http.ResponseWriter, r *http.Request) {
 	w.Write(jsonConfig)
 }
 
+func getConfigCheckExplain(w http.ResponseWriter, r *http.Request) {
+	if common.AC == nil {
+		log.Errorf("Trying to use /config-check/explain before the agent has been initialized.")
+		setJSONError(w, fmt.Errorf("agent not initialized"), 503)
+		return
+	}
+
+	id := r.URL.Query().Get("service")
+	if id == "" {
+		setJSONError(w, fmt.Errorf("missing service parameter"), 400)
+		return
+	}
+
+	explanation, err := common.AC.Explain(r.Context(), id)
+	if err != nil {
+		setJSONError(w, err, 404)
+		return
+	}
+
+	jsonExplanation, err := json.Marshal(explanation)
+	if err != nil {
+		setJSONError(w, log.Errorf("Unable to marshal config check explain response: %s", err), 500)
+		return
+	}
+
+	w.Write(jsonExplanation)
+}
+
 func getTaggerList(w http.ResponseWriter, r *http.Request) {
 	// query at the highest cardinality between checks and dogstatsd cardinalities
 	cardinality := collectors.TagCardinality(max(int(tagger.ChecksCardinality), int(tagger.DogstatsdCardinality)))
@@ -376,6 +407,27 @@ func getTaggerList(w http.ResponseWriter, r *http.Request) {
 	w.Write(jsonTags)
 }
 
+func getTaggerInspect(w http.ResponseWriter, r *http.Request) {
+	entityID := r.URL.Query().Get("entity")
+	if entityID == "" {
+		setJSONError(w, fmt.Errorf("missing entity parameter"), 400)
+		return
+	}
+
+	response, err := tagger.Inspect(entityID)
+	if err != nil {
+		setJSONError(w, err, 404)
+		return
+	}
+
+	jsonInspect, err := json.Marshal(response)
+	if err != nil {
+		setJSONError(w, log.Errorf("Unable to marshal tagger inspect response: %s", err), 500)
+		return
+	}
+	w.Write(jsonInspect)
+}
+
 func getVerboseWorkloadList(w http.ResponseWriter, r *http.Request) {
 	workloadList(w, true)
 }
@@ -395,6 +447,22 @@ func workloadList(w http.ResponseWriter, verbose bool) {
 	w.Write(jsonDump)
 }
 
+func getWorkloadSnapshot(w http.ResponseWriter, r *http.Request) {
+	snapshot, err := workloadmeta.GetGlobalStore().Snapshot()
+	if err != nil {
+		setJSONError(w, log.Errorf("Unable to snapshot the workload store: %v", err), 500)
+		return
+	}
+
+	jsonSnapshot, err := json.Marshal(snapshot)
+	if err != nil {
+		setJSONError(w, log.Errorf("Unable to marshal workload snapshot: %v", err), 500)
+		return
+	}
+
+	w.Write(jsonSnapshot)
+}
+
 func secretInfo(w http.ResponseWriter, r *http.Request) {
 	info, err := secrets.GetDebugInfo()
 	if err != nil {
@@ -91,7 +91,9 @@ func StartServer(configService *remoteconfig.Service) error {
 
 	s := grpc.NewServer(opts...)
 	pb.RegisterAgentServer(s, &server{})
-	pb.RegisterAgentSecureServer(s, &serverSecure{configService: configService})
+	secure := &serverSecure{configService: configService}
+	pb.RegisterAgentSecureServer(s, secure)
+	pb.RegisterAgentWorkloadmetaServer(s, secure)
 
 	dcreds := credentials.NewTLS(&tls.Config{
 		ServerName: tlsAddr,
@@ -27,6 +27,7 @@ type cliParams struct {
 	*command.GlobalParams
 
 	verbose bool
+	explain string
 }
 
 // Commands returns a slice of subcommands for the 'agent' command.
@@ -52,6 +53,7 @@ func Commands(globalParams *command.GlobalParams) []*cobra.Command {
 		},
 	}
 	configCheckCommand.Flags().BoolVarP(&cliParams.verbose, "verbose", "v", false, "print additional debug info")
+	configCheckCommand.Flags().StringVar(&cliParams.explain, "explain", "", "explain why templates did or did not match the given service or container ID")
 
 	return []*cobra.Command{configCheckCommand}
 }
@@ -59,7 +61,12 @@ func Commands(globalParams *command.GlobalParams) []*cobra.Command {
 func run(config config.Component, cliParams *cliParams) error {
 	var b bytes.Buffer
 	color.Output = &b
-	err := flare.GetConfigCheck(color.Output, cliParams.verbose)
+	var err error
+	if cliParams.explain != "" {
+		err = flare.GetConfigCheckExplain(color.Output, cliParams.explain)
+	} else {
+		err = flare.GetConfigCheck(color.Output, cliParams.verbose)
+	}
 	if err != nil {
 		return fmt.Errorf("unable to get pkgconfig: %v", err)
 	}
@@ -25,3 +25,14 @@ func TestCommand(t *testing.T) {
 			require.Equal(t, true, coreParams.ConfigLoadSecrets)
 		})
 }
+
+func TestExplainCommand(t *testing.T) {
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"configcheck", "--explain", "docker://abcd"},
+		run,
+		func(cliParams *cliParams, coreParams core.BundleParams) {
+			require.Equal(t, "docker://abcd", cliParams.explain)
+			require.Equal(t, false, cliParams.verbose)
+		})
+}
@@ -19,6 +19,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/cmd/agent/command"
 	"github.com/DataDog/datadog-agent/cmd/agent/common"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/profiletest"
 	utilFunc "github.com/DataDog/datadog-agent/pkg/snmp/gosnmplib"
 	parse "github.com/DataDog/datadog-agent/pkg/snmp/snmpparse"
 )
@@ -313,12 +314,46 @@ func Commands(globalParams *command.GlobalParams) []*cobra.Command {
 
 	snmpWalkCmd.SetArgs([]string{})
 
+	snmpProfileTestCmd := &cobra.Command{
+		Use:   "profile-test <profile> <walk file>",
+		Short: "Test a SNMP profile against a recorded snmpwalk",
+		Long: `Run a SNMP profile against the output of snmpwalk (-On), agent snmp walk or a .snmprec file,
+and print the metrics, tags and metadata it reports, and the OIDs of the profile that didn't match any value.
+The profile is either the path of a profile definition file or the name of a profile of the snmp.d/profiles directory.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// the configuration is used to resolve the profiles directory
+			if err := common.SetupConfig(globalParams.ConfFilePath); err != nil {
+				fmt.Printf("The config file provided is invalid : %v \n", err)
+			}
+
+			f, err := os.Open(args[1])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			variables, err := profiletest.ParseWalk(f)
+			if err != nil {
+				return fmt.Errorf("couldn't parse walk file %s: %w", args[1], err)
+			}
+
+			result, err := profiletest.Run(args[0], variables)
+			if err != nil {
+				return err
+			}
+			result.Print(os.Stdout)
+			return nil
+		},
+	}
+
 	snmpCmd := &cobra.Command{
 		Use:   "snmp",
 		Short: "Snmp tools",
 		Long:  ``,
 	}
 	snmpCmd.AddCommand(snmpWalkCmd)
+	snmpCmd.AddCommand(snmpProfileTestCmd)
 
 	return []*cobra.Command{snmpCmd}
 }
@@ -8,6 +8,8 @@ package taggerlist
 
 import (
 	"fmt"
+	"net/url"
+	"time"
 
 	"go.uber.org/fx"
 
@@ -27,6 +29,10 @@ import (
 // cliParams are the command-line arguments for this subcommand
 type cliParams struct {
 	*command.GlobalParams
+
+	entity   string
+	diffFrom string
+	diffTo   string
 }
 
 // Commands returns a slice of subcommands for the 'agent' command.
@@ -49,6 +55,9 @@ func Commands(globalParams *command.GlobalParams) []*cobra.Command {
 			)
 		},
 	}
+	taggerListCommand.Flags().StringVar(&cliParams.entity, "entity", "", "print the tags of this entity by source and cardinality, along with their history")
+	taggerListCommand.Flags().StringVar(&cliParams.diffFrom, "diff-from", "", "with --entity, print the tag changes since this time (RFC3339 timestamp or duration ago, e.g. 10m)")
+	taggerListCommand.Flags().StringVar(&cliParams.diffTo, "diff-to", "", "with --diff-from, print the tag changes until this time (RFC3339 timestamp or duration ago, defaults to now)")
 
 	return []*cobra.Command{taggerListCommand}
 }
@@ -59,6 +68,10 @@ func taggerList(log log.Component, config config.Component, cliParams *cliParams
 		return err
 	}
 
+	if cliParams.entity != "" {
+		return taggerInspect(config, cliParams)
+	}
+
 	url, err := getTaggerURL(config)
 	if err != nil {
 		return err
@@ -67,6 +80,47 @@ func taggerList(log log.Component, config config.Component, cliParams *cliParams
 	return tagger_api.GetTaggerList(color.Output, url)
 }
 
+func taggerInspect(config config.Component, cliParams *cliParams) error {
+	now := time.Now()
+
+	var from, to time.Time
+	var err error
+	if cliParams.diffFrom != "" {
+		if from, err = parseTime(cliParams.diffFrom, now); err != nil {
+			return err
+		}
+		to = now
+		if cliParams.diffTo != "" {
+			if to, err = parseTime(cliParams.diffTo, now); err != nil {
+				return err
+			}
+		}
+	}
+
+	ipcAddress, err := pkgconfig.GetIPCAddress()
+	if err != nil {
+		return err
+	}
+	inspectURL := fmt.Sprintf("https://%v:%v/agent/tagger-inspect?entity=%s", ipcAddress, config.GetInt("cmd_port"), url.QueryEscape(cliParams.entity))
+
+	return tagger_api.GetTaggerInspect(color.Output, inspectURL, from, to)
+}
+
+// parseTime parses either an RFC3339 timestamp or a duration, interpreted
+// as a time in the past relative to now.
+func parseTime(value string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q, expected an RFC3339 timestamp or a duration", value)
+	}
+
+	return now.Add(-d), nil
+}
+
 func getTaggerURL(config config.Component) (string, error) {
 	ipcAddress, err := pkgconfig.GetIPCAddress()
 	if err != nil {
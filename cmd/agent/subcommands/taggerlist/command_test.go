@@ -7,6 +7,7 @@ package taggerlist
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -24,3 +25,30 @@ func TestCommand(t *testing.T) {
 			require.Equal(t, false, coreParams.ConfigLoadSecrets)
 		})
 }
+
+func TestInspectCommand(t *testing.T) {
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"tagger-list", "--entity", "container_id://abcd", "--diff-from", "10m"},
+		taggerList,
+		func(cliParams *cliParams, coreParams core.BundleParams) {
+			require.Equal(t, "container_id://abcd", cliParams.entity)
+			require.Equal(t, "10m", cliParams.diffFrom)
+			require.Equal(t, "", cliParams.diffTo)
+		})
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	parsed, err := parseTime("10m", now)
+	require.NoError(t, err)
+	require.Equal(t, now.Add(-10*time.Minute), parsed)
+
+	parsed, err = parseTime("2022-10-01T11:00:00Z", now)
+	require.NoError(t, err)
+	require.Equal(t, now.Add(-time.Hour), parsed)
+
+	_, err = parseTime("yesterday", now)
+	require.Error(t, err)
+}
@@ -7,6 +7,7 @@
 package workloadlist
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 
@@ -30,6 +31,7 @@ type cliParams struct {
 	*command.GlobalParams
 
 	verboseList bool
+	snapshot    bool
 }
 
 // Commands returns a slice of subcommands for the 'agent' command.
@@ -53,6 +55,7 @@ func Commands(globalParams *command.GlobalParams) []*cobra.Command {
 		},
 	}
 	workloadListCommand.Flags().BoolVarP(&cliParams.verboseList, "verbose", "v", false, "print out a full dump of the workload store")
+	workloadListCommand.Flags().BoolVarP(&cliParams.snapshot, "snapshot", "", false, "print out a JSON snapshot of the workload store, that can be loaded in workloadmeta.MockStore")
 
 	return []*cobra.Command{workloadListCommand}
 }
@@ -70,7 +73,12 @@ func workloadList(log log.Component, config config.Component, cliParams *cliPara
 		return err
 	}
 
-	r, err := util.DoGet(c, workloadURL(cliParams.verboseList, ipcAddress, config.GetInt("cmd_port")), util.LeaveConnectionOpen)
+	url := workloadURL(cliParams.verboseList, ipcAddress, config.GetInt("cmd_port"))
+	if cliParams.snapshot {
+		url = fmt.Sprintf("https://%v:%v/agent/workload-list/snapshot", ipcAddress, config.GetInt("cmd_port"))
+	}
+
+	r, err := util.DoGet(c, url, util.LeaveConnectionOpen)
 	if err != nil {
 		if r != nil && string(r) != "" {
 			fmt.Fprintf(color.Output, "The agent ran into an error while getting the workload store information: %s\n", string(r))
@@ -79,6 +87,16 @@ func workloadList(log log.Component, config config.Component, cliParams *cliPara
 		}
 	}
 
+	if cliParams.snapshot {
+		var snapshot bytes.Buffer
+		if err := json.Indent(&snapshot, r, "", "  "); err != nil {
+			return err
+		}
+
+		fmt.Fprintln(color.Output, snapshot.String())
+		return nil
+	}
+
 	workload := workloadmeta.WorkloadDumpResponse{}
 	err = json.Unmarshal(r, &workload)
 	if err != nil {
@@ -25,3 +25,13 @@ func TestCommand(t *testing.T) {
 			require.Equal(t, false, coreParams.ConfigLoadSecrets)
 		})
 }
+
+func TestSnapshotCommand(t *testing.T) {
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"workload-list", "--snapshot"},
+		workloadList,
+		func(cliParams *cliParams, coreParams core.BundleParams) {
+			require.Equal(t, true, cliParams.snapshot)
+		})
+}
@@ -93,7 +93,8 @@ func getCheckConfigs(sc clusteragent.ServerContext) func(w http.ResponseWriter,
 	}
 }
 
-// postRebalanceChecks requests that the cluster checks be rebalanced
+// postRebalanceChecks requests that the cluster checks be rebalanced,
+// the moves are only simulated with the `dry_run=true` query parameter
 func postRebalanceChecks(sc clusteragent.ServerContext) func(w http.ResponseWriter, r *http.Request) {
 	if sc.ClusterCheckHandler == nil {
 		return clusterChecksDisabledHandler
@@ -104,7 +105,8 @@ func postRebalanceChecks(sc clusteragent.ServerContext) func(w http.ResponseWrit
 			return
 		}
 
-		response, err := sc.ClusterCheckHandler.RebalanceClusterChecks()
+		dryRun := r.URL.Query().Get("dry_run") == "true"
+		response, err := sc.ClusterCheckHandler.RebalanceClusterChecks(dryRun)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
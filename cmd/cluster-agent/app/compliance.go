@@ -86,6 +86,11 @@ func startCompliance(stopper startstop.Stopper, apiCl *apiserver.APIClient, isLe
 		return err
 	}
 
+	reporter, err = event.WithConfiguredFileReporter(stopper, reporter)
+	if err != nil {
+		return err
+	}
+
 	runner := runner.NewRunner()
 	stopper.Add(runner)
 
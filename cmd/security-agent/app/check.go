@@ -29,6 +29,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 	"github.com/DataDog/datadog-agent/pkg/util/startstop"
+	"github.com/DataDog/datadog-agent/pkg/version"
 )
 
 var (
@@ -41,6 +42,8 @@ var (
 		dumpRegoInput     string
 		dumpReports       string
 		skipRegoEval      bool
+		reportFile        string
+		reportFormat      string
 	}{}
 )
 
@@ -53,6 +56,8 @@ func setupCheckCmd(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&checkArgs.dumpRegoInput, "dump-rego-input", "", "", "Path to file where to dump the Rego input JSON")
 	cmd.Flags().StringVarP(&checkArgs.dumpReports, "dump-reports", "", "", "Path to file where to dump reports")
 	cmd.Flags().BoolVarP(&checkArgs.skipRegoEval, "skip-rego-eval", "", false, "Skip rego evaluation")
+	cmd.Flags().StringVarP(&checkArgs.reportFile, "report-file", "", "", "Path to file where to write a benchmark report, with the findings and their counts by framework")
+	cmd.Flags().StringVarP(&checkArgs.reportFormat, "report-format", "", event.FormatJSON, fmt.Sprintf("Format of the findings of the benchmark report, %s or %s", event.FormatJSON, event.FormatOCSF))
 }
 
 // CheckCmd returns a cobra command to run security agent checks
@@ -75,10 +80,16 @@ func runCheck(cmd *cobra.Command, confPathArray []string, args []string) error {
 		return err
 	}
 
-	if checkArgs.skipRegoEval && checkArgs.dumpReports != "" {
+	if checkArgs.skipRegoEval && (checkArgs.dumpReports != "" || checkArgs.reportFile != "") {
 		return errors.New("skipping the rego evaluation does not allow the generation of reports")
 	}
 
+	if checkArgs.reportFile != "" {
+		if err := event.ValidateFormat(checkArgs.reportFormat); err != nil {
+			return err
+		}
+	}
+
 	// We need to set before calling `SetupConfig`
 	configName := "datadog"
 	if flavor.GetFlavor() == flavor.ClusterAgent {
@@ -177,6 +188,22 @@ func runCheck(cmd *cobra.Command, confPathArray []string, args []string) error {
 		return err
 	}
 
+	if checkArgs.reportFile != "" {
+		report, err := event.NewBenchmarkReport(reporter.allEvents, checkArgs.reportFormat, hname, version.AgentVersion, time.Now())
+		if err != nil {
+			return err
+		}
+
+		if err := report.WriteFile(checkArgs.reportFile); err != nil {
+			log.Errorf("Failed to write benchmark report %v", err)
+			return err
+		}
+
+		for framework, summary := range report.Frameworks {
+			log.Infof("Framework %s: %d passed, %d failed, %d errors", framework, summary.Passed, summary.Failed, summary.Errors)
+		}
+	}
+
 	return nil
 }
 
@@ -203,6 +230,7 @@ func configureLogger() error {
 type RunCheckReporter struct {
 	reporter        event.Reporter
 	events          map[string][]*event.Event
+	allEvents       []*event.Event
 	dumpReportsPath string
 }
 
@@ -234,6 +262,7 @@ func NewCheckReporter(stopper startstop.Stopper, report bool, dumpReportsPath st
 // Report reports the event
 func (r *RunCheckReporter) Report(event *event.Event) {
 	r.events[event.AgentRuleID] = append(r.events[event.AgentRuleID], event)
+	r.allEvents = append(r.allEvents, event)
 
 	eventJSON, err := checks.PrettyPrintJSON(event, "  ")
 	if err != nil {
@@ -118,6 +118,11 @@ func startCompliance(hostname string, stopper startstop.Stopper, statsdClient *d
 		return nil, err
 	}
 
+	reporter, err = event.WithConfiguredFileReporter(stopper, reporter)
+	if err != nil {
+		return nil, err
+	}
+
 	runner := runner.NewRunner()
 	stopper.Add(runner)
 
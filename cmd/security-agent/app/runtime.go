@@ -142,6 +142,19 @@ var (
 		RunE:  generateEncodingFromActivityDump,
 	}
 
+	activityDumpGeneratePolicyCmd = &cobra.Command{
+		Use:   "policy",
+		Short: "generate a least-privilege policy from activity dumps",
+		RunE:  generatePolicyFromActivityDumps,
+	}
+
+	activityDumpPolicyArgs = struct {
+		files           []string
+		output          string
+		ruleIDPrefix    string
+		pathPrefixDepth int
+	}{}
+
 	activityDumpStopCmd = &cobra.Command{
 		Use:   "stop",
 		Short: "stops the first activity dump that matches the provided selector",
@@ -185,6 +198,17 @@ var (
 		RunE:  evalRule,
 	}
 
+	testPoliciesCmd = &cobra.Command{
+		Use:   "test <test files>",
+		Short: "Evaluate the synthetic events of test files against the policies, and report the rules coverage",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  testPolicies,
+	}
+
+	testPoliciesArgs = struct {
+		dir string
+	}{}
+
 	downloadPolicyCmd = &cobra.Command{
 		Use:   "download",
 		Short: "Download policies",
@@ -317,11 +341,38 @@ func init() {
 		"when set, the transcoding will be done by system-probe instead of the current security-agent instance",
 	)
 
+	activityDumpGeneratePolicyCmd.Flags().StringArrayVar(
+		&activityDumpPolicyArgs.files,
+		"input",
+		[]string{},
+		"path to an activity dump file, the dumps of the same workload being merged",
+	)
+	_ = activityDumpGeneratePolicyCmd.MarkFlagRequired("input")
+	activityDumpGeneratePolicyCmd.Flags().StringVar(
+		&activityDumpPolicyArgs.output,
+		"output",
+		"",
+		"path of the generated policy file, the policy is printed when empty",
+	)
+	activityDumpGeneratePolicyCmd.Flags().StringVar(
+		&activityDumpPolicyArgs.ruleIDPrefix,
+		"rule-id-prefix",
+		"least_privilege",
+		"prefix of the IDs of the generated rules and macros",
+	)
+	activityDumpGeneratePolicyCmd.Flags().IntVar(
+		&activityDumpPolicyArgs.pathPrefixDepth,
+		"path-prefix-depth",
+		sprobe.DefaultPolicyPathPrefixDepth,
+		"number of directories kept in the allowed path prefixes of the opened files",
+	)
+
 	processCacheCmd.AddCommand(processCacheDumpCmd)
 	runtimeCmd.AddCommand(processCacheCmd)
 
 	activityDumpGenerateCmd.AddCommand(activityDumpGenerateDumpCmd)
 	activityDumpGenerateCmd.AddCommand(activityDumpGenerateEncodingCmd)
+	activityDumpGenerateCmd.AddCommand(activityDumpGeneratePolicyCmd)
 
 	activityDumpCmd.AddCommand(activityDumpGenerateCmd)
 	activityDumpCmd.AddCommand(activityDumpListCmd)
@@ -339,6 +390,9 @@ func init() {
 	_ = evalCmd.MarkFlagRequired("event-file")
 	evalCmd.Flags().BoolVar(&evalArgs.debug, "debug", false, "Display an event dump if the evaluation fail")
 
+	commonPolicyCmd.AddCommand(testPoliciesCmd)
+	testPoliciesCmd.Flags().StringVar(&testPoliciesArgs.dir, "policies-dir", coreconfig.DefaultRuntimePoliciesDir, "Path to policies directory")
+
 	runtimeCmd.AddCommand(selfTestCmd)
 	runtimeCmd.AddCommand(reloadPoliciesCmd)
 
@@ -626,6 +680,38 @@ func generateEncodingFromActivityDump(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func generatePolicyFromActivityDumps(cmd *cobra.Command, args []string) error {
+	var allowLists []*sprobe.WorkloadAllowList
+	for _, file := range activityDumpPolicyArgs.files {
+		ad := sprobe.NewEmptyActivityDump()
+		if err := ad.Decode(file); err != nil {
+			return fmt.Errorf("couldn't decode activity dump %s: %w", file, err)
+		}
+
+		allowList, err := ad.GenerateWorkloadAllowList(activityDumpPolicyArgs.pathPrefixDepth)
+		if err != nil {
+			return fmt.Errorf("couldn't generate allow list from %s: %w", file, err)
+		}
+		allowLists = append(allowLists, allowList)
+	}
+
+	policy, err := sprobe.GenerateLeastPrivilegePolicy(allowLists, activityDumpPolicyArgs.ruleIDPrefix)
+	if err != nil {
+		return err
+	}
+
+	if activityDumpPolicyArgs.output == "" {
+		fmt.Print(string(policy))
+		return nil
+	}
+
+	if err := os.WriteFile(activityDumpPolicyArgs.output, policy, 0644); err != nil {
+		return fmt.Errorf("couldn't write policy: %w", err)
+	}
+	fmt.Printf("policy generated: %s\n", activityDumpPolicyArgs.output)
+	return nil
+}
+
 func newAgentVersionFilter() (*rules.AgentVersionFilter, error) {
 	agentVersion, err := utils.GetAgentSemverVersion()
 	if err != nil {
@@ -860,6 +946,95 @@ func evalRule(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func newTestEvent(eventType eval.EventType) (eval.Event, error) {
+	kind := model.ParseEvalEventType(eventType)
+	if kind == model.UnknownEventType {
+		return nil, fmt.Errorf("unknown event type `%s`", eventType)
+	}
+
+	m := &model.Model{}
+	event := m.NewEventWithType(kind)
+	event.Init()
+
+	return event, nil
+}
+
+func testPolicies(cmd *cobra.Command, args []string) error {
+	var suites []*rules.TestSuite
+	for _, filename := range args {
+		suite, err := rules.LoadTestSuiteFromFile(filename)
+		if err != nil {
+			return err
+		}
+		suites = append(suites, suite)
+	}
+
+	// enabled all the rules
+	enabled := map[eval.EventType]bool{"*": true}
+
+	var evalOpts eval.Opts
+	evalOpts.
+		WithConstants(model.SECLConstants).
+		WithVariables(model.SECLVariables).
+		WithLegacyFields(model.SECLLegacyFields)
+
+	var opts rules.Opts
+	opts.
+		WithSupportedDiscarders(sprobe.SupportedDiscarders).
+		WithEventTypeEnabled(enabled).
+		WithReservedRuleIDs(sprobe.AllCustomRuleIDs()).
+		WithStateScopes(map[rules.Scope]rules.VariableProviderFactory{
+			"process": func() rules.VariableProvider {
+				return eval.NewScopedVariables(func(ctx *eval.Context) unsafe.Pointer {
+					return unsafe.Pointer(&(*model.Event)(ctx.Object).ProcessContext)
+				}, nil)
+			},
+		}).
+		WithLogger(seclog.DefaultLogger)
+
+	model := &model.Model{}
+	ruleSet := rules.NewRuleSet(model, model.NewEvent, &opts, &evalOpts, &eval.MacroStore{})
+
+	agentVersionFilter, err := newAgentVersionFilter()
+	if err != nil {
+		return fmt.Errorf("failed to create agent version filter: %w", err)
+	}
+
+	loaderOpts := rules.PolicyLoaderOpts{
+		MacroFilters: []rules.MacroFilter{
+			agentVersionFilter,
+		},
+		RuleFilters: []rules.RuleFilter{
+			agentVersionFilter,
+		},
+	}
+
+	provider, err := rules.NewPoliciesDirProvider(testPoliciesArgs.dir, false)
+	if err != nil {
+		return err
+	}
+
+	loader := rules.NewPolicyLoader(provider)
+
+	if err := ruleSet.LoadPolicies(loader, loaderOpts); err.ErrorOrNil() != nil {
+		return err
+	}
+
+	report := ruleSet.RunTestSuites(suites, newTestEvent)
+
+	output, err := json.MarshalIndent(report, "", "    ")
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s\n", string(output))
+
+	if !report.Succeeded {
+		return fmt.Errorf("%d test(s) failed", report.Failures)
+	}
+
+	return nil
+}
+
 func runRuntimeSelfTest(cmd *cobra.Command, args []string) error {
 	client, err := secagent.NewRuntimeSecurityClient()
 	if err != nil {
@@ -0,0 +1,52 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	networkconfig "github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/network/replay"
+)
+
+func init() {
+	SysprobeCmd.AddCommand(replayCommand)
+}
+
+var (
+	replayCommand = &cobra.Command{
+		Use:   "replay <file>",
+		Short: "Print the DNS and HTTP stats computed from a pcap or pcapng file",
+		Long: `Feed the packets of a pcap or pcapng file through the DNS and HTTP parsers of the network tracer,
+and print the resulting stats in the format of the debug endpoints. HTTPS traffic can't be replayed, as it is encrypted.`,
+		Args: cobra.ExactArgs(1),
+		RunE: replayCapture,
+	}
+)
+
+func replayCapture(_ *cobra.Command, args []string) error {
+	if _, err := setupConfig(); err != nil {
+		return err
+	}
+
+	result, err := replay.File(networkconfig.New(), args[0])
+	if err != nil {
+		return err
+	}
+
+	out, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+	return nil
+}
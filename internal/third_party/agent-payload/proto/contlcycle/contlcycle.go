@@ -0,0 +1,2 @@
+// package contlcycle contains the container lifecycle event proto definitions
+package contlcycle
@@ -0,0 +1,3 @@
+package agent
+
+// this is a hack to make go module vendoring work
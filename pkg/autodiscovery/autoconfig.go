@@ -119,6 +119,7 @@ func (ac *AutoConfig) serviceListening() {
 			ac.processNewService(ctx, svc)
 		case svc := <-ac.delService:
 			ac.processDelService(ctx, svc)
+			ac.store.removeSchedulingEvents(svc.GetServiceID())
 		case <-tagFreshnessTicker.C:
 			ac.checkTagFreshness(ctx)
 		}
@@ -474,6 +475,9 @@ func (ac *AutoConfig) applyChanges(changes integration.ConfigChanges) {
 	if len(changes.Unschedule) > 0 {
 		for _, conf := range changes.Unschedule {
 			telemetry.ScheduledConfigs.Dec(conf.Provider, configType(conf))
+			if conf.ServiceID != "" && conf.Name != "" {
+				ac.store.recordSchedulingEvent(conf, false)
+			}
 		}
 
 		ac.scheduler.Unschedule(changes.Unschedule)
@@ -482,6 +486,9 @@ func (ac *AutoConfig) applyChanges(changes integration.ConfigChanges) {
 	if len(changes.Schedule) > 0 {
 		for _, conf := range changes.Schedule {
 			telemetry.ScheduledConfigs.Inc(conf.Provider, configType(conf))
+			if conf.ServiceID != "" && conf.Name != "" {
+				ac.store.recordSchedulingEvent(conf, true)
+			}
 		}
 
 		ac.scheduler.Schedule(changes.Schedule)
@@ -43,6 +43,15 @@ type configManager interface {
 	// The call is made with the manager's lock held, so callers should perform
 	// minimal work within f.
 	mapOverLoadedConfigs(func(map[string]integration.Config))
+
+	// getActiveTemplates returns all the templates known to the manager, in
+	// their unresolved state.
+	getActiveTemplates() []integration.Config
+
+	// getScheduledResolution returns the digest of the config resolved from
+	// the template with the given digest for the given service, if that
+	// config is currently scheduled.
+	getScheduledResolution(svcID, templateDigest string) (string, bool)
 }
 
 // serviceAndADIDs bundles a service and its associated AD identifiers.
@@ -273,6 +282,33 @@ func (cm *reconcilingConfigManager) mapOverLoadedConfigs(f func(map[string]integ
 	f(cm.scheduledConfigs)
 }
 
+// getScheduledResolution implements configManager#getScheduledResolution.
+func (cm *reconcilingConfigManager) getScheduledResolution(svcID, templateDigest string) (string, bool) {
+	cm.m.Lock()
+	defer cm.m.Unlock()
+
+	resolvedDigest, found := cm.serviceResolutions[svcID][templateDigest]
+	if !found {
+		return "", false
+	}
+	_, scheduled := cm.scheduledConfigs[resolvedDigest]
+	return resolvedDigest, scheduled
+}
+
+// getActiveTemplates implements configManager#getActiveTemplates.
+func (cm *reconcilingConfigManager) getActiveTemplates() []integration.Config {
+	cm.m.Lock()
+	defer cm.m.Unlock()
+
+	templates := []integration.Config{}
+	for _, config := range cm.activeConfigs {
+		if config.IsTemplate() {
+			templates = append(templates, config)
+		}
+	}
+	return templates
+}
+
 // reconcileService calculates the current set of resolved templates for the
 // given service and calculates the difference from what is currently recorded
 // in cm.serviceResolutions.  It updates cm.serviceResolutions and returns the
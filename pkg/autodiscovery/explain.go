@@ -0,0 +1,130 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package autodiscovery
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/configresolver"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/listeners"
+	"github.com/DataDog/datadog-agent/pkg/util"
+)
+
+// Explain describes how the templates known to autodiscovery match the service
+// with the given ID.  The ID can be either a service ID (e.g.
+// `docker://<container-id>`) or a bare entity ID (e.g. `<container-id>`).
+//
+// Templates are resolved against the service without scheduling anything, so
+// this is safe to call at any time.
+func (ac *AutoConfig) Explain(ctx context.Context, id string) (integration.ServiceExplanation, error) {
+	svc := ac.findService(id)
+	if svc == nil {
+		return integration.ServiceExplanation{}, fmt.Errorf("no service found matching %q", id)
+	}
+
+	explanation := integration.ServiceExplanation{
+		ServiceID:    svc.GetServiceID(),
+		TaggerEntity: svc.GetTaggerEntity(),
+		Templates:    []integration.TemplateExplanation{},
+		Events:       ac.store.getSchedulingEvents(svc.GetServiceID()),
+	}
+
+	adIdentifiers, err := svc.GetADIdentifiers(ctx)
+	if err != nil {
+		explanation.ADIdentifiersError = err.Error()
+	}
+	explanation.ADIdentifiers = adIdentifiers
+
+	serviceADIDs := make(map[string]struct{}, len(adIdentifiers))
+	for _, adID := range adIdentifiers {
+		serviceADIDs[adID] = struct{}{}
+	}
+
+	for _, tpl := range ac.cfgMgr.getActiveTemplates() {
+		tplExplanation := integration.TemplateExplanation{
+			Template: tpl,
+			Digest:   tpl.Digest(),
+		}
+
+		for _, adID := range tpl.ADIdentifiers {
+			if _, found := serviceADIDs[adID]; found {
+				tplExplanation.MatchedADIdentifiers = append(tplExplanation.MatchedADIdentifiers, adID)
+			} else {
+				tplExplanation.MissedADIdentifiers = append(tplExplanation.MissedADIdentifiers, adID)
+			}
+		}
+
+		if len(tplExplanation.MatchedADIdentifiers) > 0 {
+			ac.explainResolution(&tplExplanation, tpl, svc)
+		}
+
+		explanation.Templates = append(explanation.Templates, tplExplanation)
+	}
+
+	// show matching templates first
+	sort.SliceStable(explanation.Templates, func(i, j int) bool {
+		mi, mj := len(explanation.Templates[i].MatchedADIdentifiers), len(explanation.Templates[j].MatchedADIdentifiers)
+		if (mi > 0) != (mj > 0) {
+			return mi > 0
+		}
+		return explanation.Templates[i].Template.Name < explanation.Templates[j].Template.Name
+	})
+
+	return explanation, nil
+}
+
+// explainResolution resolves the template for the service, the same way
+// config managers do, and fills the outcome in the explanation.
+//
+// Secrets are not decrypted, so that explaining a service never calls the
+// secret backend: the ENC[] handles are left in place, and the digest of the
+// scheduled config is looked up in the config manager instead.
+func (ac *AutoConfig) explainResolution(tplExplanation *integration.TemplateExplanation, tpl integration.Config, svc listeners.Service) {
+	if util.CcaInAD() {
+		candidates := map[string]integration.Config{tplExplanation.Digest: tpl}
+		svc.FilterTemplates(candidates)
+		if _, found := candidates[tplExplanation.Digest]; !found {
+			tplExplanation.Filtered = true
+			return
+		}
+	}
+
+	resolved, err := configresolver.Resolve(tpl, svc)
+	if err != nil {
+		tplExplanation.ResolveError = err.Error()
+		return
+	}
+
+	if digest, scheduled := ac.cfgMgr.getScheduledResolution(svc.GetServiceID(), tplExplanation.Digest); scheduled {
+		tplExplanation.ResolvedDigest = digest
+		tplExplanation.Scheduled = true
+		tplExplanation.Schedulers = ac.scheduler.ConfigSchedulers(digest)
+		return
+	}
+
+	tplExplanation.ResolvedDigest = resolved.Digest()
+}
+
+// findService returns the service matching the given ID, or nil if there is
+// none.
+func (ac *AutoConfig) findService(id string) listeners.Service {
+	if svc := ac.store.getServiceForEntity(id); svc != nil {
+		return svc
+	}
+
+	for _, svc := range ac.store.getServices() {
+		serviceID := svc.GetServiceID()
+		if strings.HasSuffix(serviceID, "://"+id) || svc.GetTaggerEntity() == id {
+			return svc
+		}
+	}
+
+	return nil
+}
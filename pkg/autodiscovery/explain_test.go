@@ -0,0 +1,143 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package autodiscovery
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/scheduler"
+)
+
+func TestExplain(t *testing.T) {
+	ctx := context.Background()
+
+	msch := scheduler.NewMetaScheduler()
+	sch := &MockScheduler{scheduled: make(map[string]integration.Config)}
+	msch.Register("mock", sch, false)
+	ac := NewAutoConfig(msch)
+
+	service := dummyService{
+		ID:            "docker://a5901276aed16ae9ea11660a41fecd674da47e8f5d8d5bce0080a611feed2be9",
+		ADIdentifiers: []string{"redis"},
+	}
+	ac.processNewService(ctx, &service)
+
+	resolvable := integration.Config{
+		Name:          "redisdb",
+		ADIdentifiers: []string{"redis", "redis-custom"},
+		Instances:     []integration.Data{integration.Data("host: localhost")},
+	}
+	unresolvable := integration.Config{
+		Name:          "redis_sentinel",
+		ADIdentifiers: []string{"redis"},
+		Instances:     []integration.Data{integration.Data("host: %%host%%")},
+	}
+	unmatched := integration.Config{
+		Name:          "nginx",
+		ADIdentifiers: []string{"nginx"},
+	}
+	for _, tpl := range []integration.Config{resolvable, unresolvable, unmatched} {
+		ac.applyChanges(ac.processNewConfig(tpl))
+	}
+	require.Len(t, sch.scheduled, 1)
+
+	_, err := ac.Explain(ctx, "unknown")
+	assert.Error(t, err)
+
+	// containers can be referred to by their ID only
+	explanation, err := ac.Explain(ctx, "a5901276aed16ae9ea11660a41fecd674da47e8f5d8d5bce0080a611feed2be9")
+	require.NoError(t, err)
+
+	assert.Equal(t, service.ID, explanation.ServiceID)
+	assert.Equal(t, []string{"redis"}, explanation.ADIdentifiers)
+	require.Len(t, explanation.Templates, 3)
+
+	sentinel := explanation.Templates[0]
+	assert.Equal(t, "redis_sentinel", sentinel.Template.Name)
+	assert.Contains(t, sentinel.ResolveError, "no network found")
+	assert.False(t, sentinel.Scheduled)
+
+	redisdb := explanation.Templates[1]
+	assert.Equal(t, "redisdb", redisdb.Template.Name)
+	assert.Equal(t, []string{"redis"}, redisdb.MatchedADIdentifiers)
+	assert.Equal(t, []string{"redis-custom"}, redisdb.MissedADIdentifiers)
+	assert.Empty(t, redisdb.ResolveError)
+	assert.True(t, redisdb.Scheduled)
+	assert.Contains(t, sch.scheduled, redisdb.ResolvedDigest)
+	assert.Equal(t, []string{"mock"}, redisdb.Schedulers)
+
+	nginx := explanation.Templates[2]
+	assert.Equal(t, "nginx", nginx.Template.Name)
+	assert.Empty(t, nginx.MatchedADIdentifiers)
+	assert.Empty(t, nginx.ResolvedDigest)
+	assert.Empty(t, sentinel.Schedulers)
+	assert.Empty(t, nginx.Schedulers)
+
+	require.Len(t, explanation.Events, 1)
+	assert.True(t, explanation.Events[0].Scheduled)
+	assert.Equal(t, redisdb.ResolvedDigest, explanation.Events[0].Digest)
+
+	// removing the template unschedules the resolved config
+	ac.processRemovedConfigs([]integration.Config{resolvable})
+	explanation, err = ac.Explain(ctx, service.ID)
+	require.NoError(t, err)
+	require.Len(t, explanation.Events, 2)
+	assert.False(t, explanation.Events[1].Scheduled)
+	assert.Len(t, explanation.Templates, 2)
+}
+
+func TestExplainDoesNotDecryptSecrets(t *testing.T) {
+	ctx := context.Background()
+
+	makeScenarios := func() []mockSecretScenario {
+		return []mockSecretScenario{
+			{
+				expectedData:   []byte{},
+				expectedOrigin: "cpu",
+				returnedData:   []byte{},
+			},
+			{
+				expectedData:   []byte("param1: ENC[foo]\n"),
+				expectedOrigin: "cpu",
+				returnedData:   []byte("param1: foo\n"),
+			},
+		}
+	}
+	mockDecrypt := MockSecretDecrypt{t, makeScenarios()}
+	defer mockDecrypt.install()()
+
+	msch := scheduler.NewMetaScheduler()
+	sch := &MockScheduler{scheduled: make(map[string]integration.Config)}
+	msch.Register("mock", sch, false)
+	ac := NewAutoConfig(msch)
+
+	service := dummyService{ID: "abcd", ADIdentifiers: []string{"redis"}}
+	ac.processNewService(ctx, &service)
+	ac.applyChanges(ac.processNewConfig(integration.Config{
+		Name:          "cpu",
+		ADIdentifiers: []string{"redis"},
+		InitConfig:    []byte("param1: ENC[foo]"),
+	}))
+	require.Len(t, sch.scheduled, 1)
+	require.True(t, mockDecrypt.haveAllScenariosBeenCalled())
+
+	mockDecrypt.scenarios = makeScenarios()
+	explanation, err := ac.Explain(ctx, service.ID)
+	require.NoError(t, err)
+	assert.True(t, mockDecrypt.haveAllScenariosNotCalled())
+
+	// the scheduled config is found even though its secrets were decrypted
+	require.Len(t, explanation.Templates, 1)
+	cpu := explanation.Templates[0]
+	assert.Empty(t, cpu.ResolveError)
+	assert.True(t, cpu.Scheduled)
+	assert.Contains(t, sch.scheduled, cpu.ResolvedDigest)
+}
@@ -0,0 +1,80 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package integration
+
+import "time"
+
+// ServiceExplanation describes how autodiscovery matched the known templates
+// against a single service. It is used by diagnostic tools such as
+// `agent configcheck --explain`.
+type ServiceExplanation struct {
+	// ServiceID is the ID of the explained service
+	ServiceID string `json:"service_id"`
+
+	// TaggerEntity is the tagger entity ID of the explained service
+	TaggerEntity string `json:"tagger_entity"`
+
+	// ADIdentifiers are the AD identifiers of the service
+	ADIdentifiers []string `json:"ad_identifiers"`
+
+	// ADIdentifiersError is set if the AD identifiers of the service could
+	// not be retrieved
+	ADIdentifiersError string `json:"ad_identifiers_error,omitempty"`
+
+	// Templates lists every template known to autodiscovery, along with the
+	// outcome of its resolution against the service
+	Templates []TemplateExplanation `json:"templates"`
+
+	// Events lists the most recent scheduling events for the service
+	Events []SchedulingEvent `json:"events"`
+}
+
+// TemplateExplanation describes the outcome of matching a template against a
+// service.
+type TemplateExplanation struct {
+	// Template is the unresolved template
+	Template Config `json:"template"`
+
+	// Digest is the digest of the unresolved template
+	Digest string `json:"digest"`
+
+	// MatchedADIdentifiers are the template AD identifiers that the service has
+	MatchedADIdentifiers []string `json:"matched_ad_identifiers"`
+
+	// MissedADIdentifiers are the template AD identifiers that the service
+	// does not have
+	MissedADIdentifiers []string `json:"missed_ad_identifiers"`
+
+	// Filtered is true if the service filtered out the template (e.g. because
+	// of container exclusion rules or configs with a higher priority)
+	Filtered bool `json:"filtered"`
+
+	// ResolveError is the error returned while resolving the template
+	// variables, if any
+	ResolveError string `json:"resolve_error,omitempty"`
+
+	// ResolvedDigest is the digest of the resolved config, if the template
+	// could be resolved. When the config is not scheduled, secrets are not
+	// decrypted and the digest is the one of the config with its ENC[] handles.
+	ResolvedDigest string `json:"resolved_digest,omitempty"`
+
+	// Scheduled is true if the resolved config is currently scheduled
+	Scheduled bool `json:"scheduled"`
+
+	// Schedulers lists the schedulers the resolved config has been dispatched
+	// to, if it is scheduled
+	Schedulers []string `json:"schedulers,omitempty"`
+}
+
+// SchedulingEvent records a config being scheduled or unscheduled for a
+// service.
+type SchedulingEvent struct {
+	Timestamp time.Time `json:"timestamp"`
+	Scheduled bool      `json:"scheduled"`
+	CheckName string    `json:"check_name"`
+	Digest    string    `json:"digest"`
+	Provider  string    `json:"provider"`
+}
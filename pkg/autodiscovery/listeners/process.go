@@ -0,0 +1,72 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !serverless
+// +build !serverless
+
+package listeners
+
+import (
+	"strconv"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+// processADIdentifierPrefix is the prefix of the AD identifier of host
+// processes, so that templates meant for a container image of the same name
+// don't match them.
+const processADIdentifierPrefix = "process:"
+
+func init() {
+	Register("process", NewProcessListener)
+}
+
+// ProcessListener listens to long-running host processes through a
+// subscription to the workloadmeta store.
+type ProcessListener struct {
+	workloadmetaListener
+}
+
+// NewProcessListener returns a new ProcessListener.
+func NewProcessListener(Config) (ServiceListener, error) {
+	const name = "ad-processlistener"
+	l := &ProcessListener{}
+	f := workloadmeta.NewFilter(
+		[]workloadmeta.Kind{workloadmeta.KindProcess},
+		workloadmeta.SourceHost,
+		workloadmeta.EventTypeAll,
+	)
+
+	var err error
+	l.workloadmetaListener, err = newWorkloadmetaListener(name, f, l.createProcessService)
+	if err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+func (l *ProcessListener) createProcessService(entity workloadmeta.Entity) {
+	process := entity.(*workloadmeta.Process)
+
+	pid, err := strconv.Atoi(process.ID)
+	if err != nil {
+		log.Debugf("invalid PID for process %q: %s", process.ID, err)
+		return
+	}
+
+	svc := &service{
+		entity:        process,
+		adIdentifiers: []string{processADIdentifierPrefix + process.Name},
+		hosts:         map[string]string{"host": localServiceHost},
+		ports:         []ContainerPort{},
+		pid:           pid,
+		ready:         true,
+	}
+
+	svcID := buildSvcID(process.GetID())
+	l.AddService(svcID, svc, "")
+}
@@ -0,0 +1,47 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !serverless
+// +build !serverless
+
+package listeners
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+func TestCreateProcessService(t *testing.T) {
+	process := &workloadmeta.Process{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindProcess,
+			ID:   "1234",
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name: "nginx",
+		},
+		Exe:     "/usr/sbin/nginx",
+		Cmdline: []string{"/usr/sbin/nginx", "-g", "daemon off;"},
+	}
+
+	wlm := newTestWorkloadmetaListener(t)
+	listener := &ProcessListener{workloadmetaListener: wlm}
+
+	listener.createProcessService(process)
+
+	wlm.assertServices(map[string]wlmListenerSvc{
+		"process://1234": {
+			service: &service{
+				entity:        process,
+				adIdentifiers: []string{"process:nginx"},
+				hosts:         map[string]string{"host": "127.0.0.1"},
+				ports:         []ContainerPort{},
+				pid:           1234,
+				ready:         true,
+			},
+		},
+	})
+}
@@ -46,6 +46,9 @@ func (s *service) GetServiceID() string {
 		return containers.BuildEntityName(string(e.Runtime), e.ID)
 	case *workloadmeta.KubernetesPod:
 		return kubelet.PodUIDToEntityName(e.ID)
+	case *workloadmeta.SystemdUnit, *workloadmeta.Process:
+		entityID := e.GetID()
+		return fmt.Sprintf("%s://%s", entityID.Kind, entityID.ID)
 	default:
 		entityID := s.entity.GetID()
 		log.Errorf("cannot build AD entity ID for kind %q, ID %q", entityID.Kind, entityID.ID)
@@ -60,6 +63,9 @@ func (s *service) GetTaggerEntity() string {
 		return containers.BuildTaggerEntityName(e.ID)
 	case *workloadmeta.KubernetesPod:
 		return kubelet.PodUIDToTaggerEntityName(e.ID)
+	case *workloadmeta.SystemdUnit, *workloadmeta.Process:
+		entityID := e.GetID()
+		return fmt.Sprintf("%s://%s", entityID.Kind, entityID.ID)
 	default:
 		entityID := s.entity.GetID()
 		log.Errorf("cannot build AD entity ID for kind %q, ID %q", entityID.Kind, entityID.ID)
@@ -0,0 +1,69 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !serverless
+// +build !serverless
+
+package listeners
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+// localServiceHost is the host of services running directly on the node
+const localServiceHost = "127.0.0.1"
+
+func init() {
+	Register("systemd", NewSystemdListener)
+}
+
+// SystemdListener listens to systemd units through a subscription to the
+// workloadmeta store.
+type SystemdListener struct {
+	workloadmetaListener
+}
+
+// NewSystemdListener returns a new SystemdListener.
+func NewSystemdListener(Config) (ServiceListener, error) {
+	const name = "ad-systemdlistener"
+	l := &SystemdListener{}
+	f := workloadmeta.NewFilter(
+		[]workloadmeta.Kind{workloadmeta.KindSystemdUnit},
+		workloadmeta.SourceHost,
+		workloadmeta.EventTypeAll,
+	)
+
+	var err error
+	l.workloadmetaListener, err = newWorkloadmetaListener(name, f, l.createSystemdUnitService)
+	if err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+func (l *SystemdListener) createSystemdUnitService(entity workloadmeta.Entity) {
+	unit := entity.(*workloadmeta.SystemdUnit)
+
+	// units without a running process (e.g. oneshot units that exited)
+	// have nothing to monitor
+	if !unit.State.Running {
+		log.Debugf("systemd unit %q is not running, skipping", unit.ID)
+		return
+	}
+
+	svc := &service{
+		entity:        unit,
+		adIdentifiers: []string{unit.ID},
+		hosts:         map[string]string{"host": localServiceHost},
+		ports:         []ContainerPort{},
+		pid:           unit.MainPID,
+		ready:         true,
+	}
+
+	svcID := buildSvcID(unit.GetID())
+	l.AddService(svcID, svc, "")
+}
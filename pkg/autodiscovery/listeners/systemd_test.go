@@ -0,0 +1,80 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !serverless
+// +build !serverless
+
+package listeners
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+func TestCreateSystemdUnitService(t *testing.T) {
+	unitEntityID := workloadmeta.EntityID{
+		Kind: workloadmeta.KindSystemdUnit,
+		ID:   "nginx.service",
+	}
+
+	runningUnit := &workloadmeta.SystemdUnit{
+		EntityID: unitEntityID,
+		EntityMeta: workloadmeta.EntityMeta{
+			Name: "nginx.service",
+		},
+		MainPID: 1234,
+		State: workloadmeta.SystemdUnitState{
+			Running:     true,
+			ActiveState: "active",
+			SubState:    "running",
+		},
+	}
+
+	tests := []struct {
+		name             string
+		unit             *workloadmeta.SystemdUnit
+		expectedServices map[string]wlmListenerSvc
+	}{
+		{
+			name: "running unit",
+			unit: runningUnit,
+			expectedServices: map[string]wlmListenerSvc{
+				"systemd_unit://nginx.service": {
+					service: &service{
+						entity:        runningUnit,
+						adIdentifiers: []string{"nginx.service"},
+						hosts:         map[string]string{"host": "127.0.0.1"},
+						ports:         []ContainerPort{},
+						pid:           1234,
+						ready:         true,
+					},
+				},
+			},
+		},
+		{
+			name: "exited unit does not get collected",
+			unit: &workloadmeta.SystemdUnit{
+				EntityID: unitEntityID,
+				State: workloadmeta.SystemdUnitState{
+					ActiveState: "active",
+					SubState:    "exited",
+				},
+			},
+			expectedServices: map[string]wlmListenerSvc{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wlm := newTestWorkloadmetaListener(t)
+			listener := &SystemdListener{workloadmetaListener: wlm}
+
+			listener.createSystemdUnitService(tt.unit)
+
+			wlm.assertServices(tt.expectedServices)
+		})
+	}
+}
@@ -0,0 +1,299 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !serverless
+// +build !serverless
+
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/providers/names"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// GRPCConfigProvider implements the ConfigProvider and StreamingConfigProvider
+// interfaces. It subscribes to a gRPC service implementing the
+// AutodiscoveryConfigSource service, which streams the full set of check
+// configurations every time it changes. The version attached to each
+// response plays the same role as the ETag of the HTTPConfigProvider.
+type GRPCConfigProvider struct {
+	mu     sync.RWMutex
+	conn   *grpc.ClientConn
+	client pb.AutodiscoveryConfigSourceClient
+	target string
+	token  string
+
+	// ready is set once the config poller has been sent a first batch of
+	// changes, be it the configurations of the source or an empty batch
+	// when the source is unreachable.
+	ready bool
+
+	version     string
+	configCache map[string]integration.Config // map[config digest]integration.Config
+	errors      map[string]ErrorMsgSet
+}
+
+// NewGRPCConfigProvider creates a new GRPCConfigProvider connecting to the
+// service configured in `template_url`, which must use one of the following
+// schemes:
+//   - unix:///path/to/socket for a service listening on a unix socket
+//   - grpc://host:port for a plaintext connection to a loopback address
+//   - grpcs://host:port for a TLS connection, using ca_file, cert_file and key_file
+func NewGRPCConfigProvider(providerConfig *config.ConfigurationProviders) (ConfigProvider, error) {
+	if providerConfig == nil {
+		providerConfig = &config.ConfigurationProviders{}
+	}
+
+	templateURL, err := url.Parse(providerConfig.TemplateURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var target string
+	var creds credentials.TransportCredentials
+
+	switch templateURL.Scheme {
+	case "unix":
+		target = "unix://" + templateURL.Path
+		creds = insecure.NewCredentials()
+	case "grpc":
+		if !isLoopbackHost(templateURL.Hostname()) {
+			return nil, fmt.Errorf("plaintext connections are only allowed to loopback addresses, use grpcs:// to connect to %s", templateURL.Host)
+		}
+		target = templateURL.Host
+		creds = insecure.NewCredentials()
+	case "grpcs":
+		tlsConfig, err := buildHTTPProviderTLSConfig(providerConfig)
+		if err != nil {
+			return nil, err
+		}
+		target = templateURL.Host
+		creds = credentials.NewTLS(tlsConfig)
+	default:
+		return nil, fmt.Errorf("unsupported scheme %q in template_url, expected unix, grpc or grpcs", templateURL.Scheme)
+	}
+
+	// the connection is established lazily, when the stream is started
+	conn, err := grpc.Dial(target, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, err
+	}
+
+	return &GRPCConfigProvider{
+		conn:        conn,
+		client:      pb.NewAutodiscoveryConfigSourceClient(conn),
+		target:      target,
+		token:       providerConfig.Token,
+		configCache: make(map[string]integration.Config),
+		errors:      make(map[string]ErrorMsgSet),
+	}, nil
+}
+
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// String returns a string representation of the GRPCConfigProvider
+func (p *GRPCConfigProvider) String() string {
+	return names.GRPC
+}
+
+// Stream subscribes to the configurations served by the gRPC service until
+// the context is cancelled. The stream is re-established with an exponential
+// backoff when it fails, and the configurations received so far are kept
+// scheduled in the meantime.
+func (p *GRPCConfigProvider) Stream(ctx context.Context) <-chan integration.ConfigChanges {
+	outCh := make(chan integration.ConfigChanges)
+
+	go func() {
+		defer p.conn.Close()
+
+		expBackoff := backoff.NewExponentialBackOff()
+		expBackoff.InitialInterval = 500 * time.Millisecond
+		expBackoff.MaxInterval = 5 * time.Minute
+		expBackoff.MaxElapsedTime = 0
+
+		for {
+			err := p.streamConfigs(ctx, outCh, expBackoff)
+			if ctx.Err() != nil {
+				return
+			}
+
+			log.Warnf("error received from config source %s, will retry: %s", p.target, err)
+
+			// the config poller waits for a first batch of changes
+			// before moving on, don't hold it while the source is
+			// unreachable
+			if !p.isReady() && !p.send(ctx, outCh, integration.ConfigChanges{}) {
+				return
+			}
+
+			select {
+			case <-time.After(expBackoff.NextBackOff()):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return outCh
+}
+
+// streamConfigs establishes a stream with the service and forwards the
+// configuration changes to outCh until the stream fails.
+func (p *GRPCConfigProvider) streamConfigs(ctx context.Context, outCh chan<- integration.ConfigChanges, expBackoff *backoff.ExponentialBackOff) error {
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if p.token != "" {
+		streamCtx = metadata.NewOutgoingContext(streamCtx, metadata.MD{
+			"authorization": []string{fmt.Sprintf("Bearer %s", p.token)},
+		})
+	}
+
+	p.mu.RLock()
+	version := p.version
+	p.mu.RUnlock()
+
+	stream, err := p.client.StreamConfigs(streamCtx, &pb.AutodiscoveryStreamConfigsRequest{
+		Version: version,
+	})
+	if err != nil {
+		return err
+	}
+
+	for {
+		response, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+
+		expBackoff.Reset()
+
+		changes, changed := p.processResponse(response)
+		if !changed && p.isReady() {
+			continue
+		}
+
+		if !p.send(ctx, outCh, changes) {
+			return ctx.Err()
+		}
+	}
+}
+
+// send forwards changes to the config poller, and returns false if the
+// context got cancelled first.
+func (p *GRPCConfigProvider) send(ctx context.Context, outCh chan<- integration.ConfigChanges, changes integration.ConfigChanges) bool {
+	select {
+	case outCh <- changes:
+		p.mu.Lock()
+		p.ready = true
+		p.mu.Unlock()
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (p *GRPCConfigProvider) isReady() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.ready
+}
+
+// processResponse diffs the configurations received from the service with
+// the ones scheduled so far. The returned boolean is false when the response
+// carries the version that was already processed.
+func (p *GRPCConfigProvider) processResponse(response *pb.AutodiscoveryStreamConfigsResponse) (integration.ConfigChanges, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	changes := integration.ConfigChanges{}
+
+	if response.Version != "" && response.Version == p.version {
+		return changes, false
+	}
+
+	entries := make([]remoteConfigEntry, 0, len(response.Configs))
+	for _, c := range response.Configs {
+		entry := remoteConfigEntry{
+			Name:          c.CheckName,
+			ADIdentifiers: c.AdIdentifiers,
+			InitConfig:    json.RawMessage(c.InitConfig),
+			Logs:          json.RawMessage(c.LogsConfig),
+			ClusterCheck:  c.ClusterCheck,
+		}
+		for _, instance := range c.Instances {
+			entry.Instances = append(entry.Instances, json.RawMessage(instance))
+		}
+		entries = append(entries, entry)
+	}
+
+	configs, errors := parseRemoteConfigEntries(entries, names.GRPC+":"+p.target)
+
+	configsToUnschedule := make(map[string]integration.Config, len(p.configCache))
+	for digest, config := range p.configCache {
+		configsToUnschedule[digest] = config
+	}
+
+	for _, config := range configs {
+		digest := config.Digest()
+		if _, ok := p.configCache[digest]; ok {
+			delete(configsToUnschedule, digest)
+		} else {
+			p.configCache[digest] = config
+			changes.ScheduleConfig(config)
+		}
+	}
+
+	for digest, config := range configsToUnschedule {
+		delete(p.configCache, digest)
+		changes.UnscheduleConfig(config)
+	}
+
+	p.version = response.Version
+	p.errors = errors
+
+	return changes, true
+}
+
+// GetConfigErrors returns the errors found in the last response
+func (p *GRPCConfigProvider) GetConfigErrors() map[string]ErrorMsgSet {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	errors := make(map[string]ErrorMsgSet, len(p.errors))
+	for entity, errset := range p.errors {
+		errors[entity] = errset
+	}
+
+	return errors
+}
+
+func init() {
+	RegisterProvider(names.GRPCRegisterName, NewGRPCConfigProvider)
+}
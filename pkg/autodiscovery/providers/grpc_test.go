@@ -0,0 +1,181 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !serverless
+// +build !serverless
+
+package providers
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo"
+)
+
+type fakeConfigSource struct {
+	pb.UnimplementedAutodiscoveryConfigSourceServer
+	responses chan *pb.AutodiscoveryStreamConfigsResponse
+	requests  chan *pb.AutodiscoveryStreamConfigsRequest
+}
+
+func (s *fakeConfigSource) StreamConfigs(in *pb.AutodiscoveryStreamConfigsRequest, out pb.AutodiscoveryConfigSource_StreamConfigsServer) error {
+	md, _ := metadata.FromIncomingContext(out.Context())
+	if auth := md.Get("authorization"); len(auth) != 1 || auth[0] != "Bearer secret" {
+		return status.Error(codes.Unauthenticated, "invalid token")
+	}
+
+	s.requests <- in
+	for {
+		select {
+		case resp := <-s.responses:
+			if err := out.Send(resp); err != nil {
+				return err
+			}
+		case <-out.Context().Done():
+			return nil
+		}
+	}
+}
+
+func startFakeConfigSource(t *testing.T) (*fakeConfigSource, string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	source := &fakeConfigSource{
+		responses: make(chan *pb.AutodiscoveryStreamConfigsResponse),
+		requests:  make(chan *pb.AutodiscoveryStreamConfigsRequest, 1),
+	}
+	server := grpc.NewServer()
+	pb.RegisterAutodiscoveryConfigSourceServer(server, source)
+	go server.Serve(listener) //nolint:errcheck
+	t.Cleanup(server.Stop)
+
+	return source, "grpc://" + listener.Addr().String()
+}
+
+func receiveChanges(t *testing.T, ch <-chan integration.ConfigChanges) integration.ConfigChanges {
+	select {
+	case changes := <-ch:
+		return changes
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "no config changes received")
+	}
+	return integration.ConfigChanges{}
+}
+
+func TestGRPCConfigProvider(t *testing.T) {
+	source, templateURL := startFakeConfigSource(t)
+
+	provider, err := NewGRPCConfigProvider(&config.ConfigurationProviders{
+		TemplateURL: templateURL,
+		Token:       "secret",
+	})
+	require.NoError(t, err)
+	grpcProvider := provider.(*GRPCConfigProvider)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changesCh := grpcProvider.Stream(ctx)
+
+	req := <-source.requests
+	assert.Empty(t, req.Version)
+
+	source.responses <- &pb.AutodiscoveryStreamConfigsResponse{
+		Version: "v1",
+		Configs: []*pb.AutodiscoveryConfig{
+			{
+				CheckName:     "nginx",
+				AdIdentifiers: []string{"nginx"},
+				InitConfig:    []byte("{}"),
+				Instances:     [][]byte{[]byte(`{"nginx_status_url": "http://%%host%%/status"}`)},
+			},
+			{
+				CheckName: "empty",
+			},
+		},
+	}
+
+	changes := receiveChanges(t, changesCh)
+	require.Len(t, changes.Schedule, 1)
+	assert.Empty(t, changes.Unschedule)
+	nginx := changes.Schedule[0]
+	assert.Equal(t, "nginx", nginx.Name)
+	assert.Equal(t, []string{"nginx"}, nginx.ADIdentifiers)
+	assert.Equal(t, "grpc:"+templateURL[len("grpc://"):], nginx.Source)
+	assert.Contains(t, grpcProvider.GetConfigErrors(), "empty")
+
+	// a response with the same version is ignored
+	source.responses <- &pb.AutodiscoveryStreamConfigsResponse{Version: "v1"}
+
+	source.responses <- &pb.AutodiscoveryStreamConfigsResponse{
+		Version: "v2",
+		Configs: []*pb.AutodiscoveryConfig{
+			{
+				CheckName:  "http_check",
+				Instances:  [][]byte{[]byte(`{"name": "cmdb", "url": "http://cmdb.local"}`)},
+				LogsConfig: []byte(`[{"type": "file", "path": "/var/log/cmdb.log"}]`),
+			},
+		},
+	}
+
+	changes = receiveChanges(t, changesCh)
+	require.Len(t, changes.Schedule, 1)
+	assert.Equal(t, "http_check", changes.Schedule[0].Name)
+	assert.Equal(t, integration.Data(`[{"type": "file", "path": "/var/log/cmdb.log"}]`), changes.Schedule[0].LogsConfig)
+	require.Len(t, changes.Unschedule, 1)
+	assert.Equal(t, nginx.Digest(), changes.Unschedule[0].Digest())
+	assert.Empty(t, grpcProvider.GetConfigErrors())
+}
+
+func TestGRPCConfigProviderUnreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	listener.Close()
+
+	provider, err := NewGRPCConfigProvider(&config.ConfigurationProviders{
+		TemplateURL: "grpc://" + address,
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// an empty batch is sent so that the config poller is not held
+	changes := receiveChanges(t, provider.(*GRPCConfigProvider).Stream(ctx))
+	assert.True(t, changes.IsEmpty())
+}
+
+func TestNewGRPCConfigProvider(t *testing.T) {
+	for _, templateURL := range []string{
+		"unix:///var/run/cmdb.sock",
+		"grpc://127.0.0.1:5001",
+		"grpc://localhost:5001",
+		"grpcs://cmdb.local:5001",
+	} {
+		_, err := NewGRPCConfigProvider(&config.ConfigurationProviders{TemplateURL: templateURL})
+		assert.NoError(t, err, templateURL)
+	}
+
+	for _, templateURL := range []string{
+		"grpc://cmdb.local:5001",
+		"http://127.0.0.1:5001",
+	} {
+		_, err := NewGRPCConfigProvider(&config.ConfigurationProviders{TemplateURL: templateURL})
+		assert.Error(t, err, templateURL)
+	}
+}
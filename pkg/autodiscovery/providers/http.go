@@ -0,0 +1,307 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package providers
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/providers/names"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// maxHTTPConfigPayloadSize is the maximum size of the configurations served
+// by the endpoint
+const maxHTTPConfigPayloadSize = 10 * 1024 * 1024
+
+// httpConfigPayload is the JSON document served by the remote endpoint
+type httpConfigPayload struct {
+	Configs []remoteConfigEntry `json:"configs"`
+}
+
+// remoteConfigEntry describes a single check configuration served by the
+// http or grpc providers. The init_config, instances and logs sections are
+// kept as raw JSON, which is valid YAML and can therefore be handed to the
+// checks as is.
+type remoteConfigEntry struct {
+	Name          string            `json:"check_name"`
+	ADIdentifiers []string          `json:"ad_identifiers"`
+	InitConfig    json.RawMessage   `json:"init_config"`
+	Instances     []json.RawMessage `json:"instances"`
+	Logs          json.RawMessage   `json:"logs"`
+	ClusterCheck  bool              `json:"cluster_check"`
+}
+
+// HTTPConfigProvider implements the ConfigProvider interface.
+// It polls an HTTP endpoint serving check configurations as JSON and relies
+// on the ETag response header to avoid collecting unchanged configurations.
+type HTTPConfigProvider struct {
+	sync.Mutex
+	client *http.Client
+	url    string
+	token  string
+	// source is the url without its credentials and query, which can hold
+	// secrets, used to identify the configurations and in errors
+	source string
+
+	etag    string
+	pending bool
+	configs []integration.Config
+	errors  map[string]ErrorMsgSet
+}
+
+// NewHTTPConfigProvider creates a new HTTPConfigProvider polling the
+// endpoint configured in `template_url`.
+func NewHTTPConfigProvider(providerConfig *config.ConfigurationProviders) (ConfigProvider, error) {
+	if providerConfig == nil {
+		providerConfig = &config.ConfigurationProviders{}
+	}
+
+	templateURL, err := url.Parse(providerConfig.TemplateURL)
+	if err != nil {
+		return nil, err
+	}
+	if templateURL.Scheme != "http" && templateURL.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported scheme %q in template_url, expected http or https", templateURL.Scheme)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if templateURL.Scheme == "https" {
+		tlsConfig, err := buildHTTPProviderTLSConfig(providerConfig)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	return &HTTPConfigProvider{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   config.Datadog.GetDuration("autoconf_template_url_timeout") * time.Second,
+		},
+		url:    templateURL.String(),
+		token:  providerConfig.Token,
+		source: sanitizeURL(templateURL),
+		errors: make(map[string]ErrorMsgSet),
+	}, nil
+}
+
+// sanitizeURL returns u without its user info, query and fragment
+func sanitizeURL(u *url.URL) string {
+	sanitized := *u
+	sanitized.User = nil
+	sanitized.RawQuery = ""
+	sanitized.ForceQuery = false
+	sanitized.Fragment = ""
+	sanitized.RawFragment = ""
+	return sanitized.String()
+}
+
+func buildHTTPProviderTLSConfig(providerConfig *config.ConfigurationProviders) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if providerConfig.CAFile != "" {
+		caCert, err := os.ReadFile(providerConfig.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read ca_file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no valid certificate found in ca_file %s", providerConfig.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if providerConfig.CertFile != "" && providerConfig.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(providerConfig.CertFile, providerConfig.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// String returns a string representation of the HTTPConfigProvider
+func (p *HTTPConfigProvider) String() string {
+	return names.HTTP
+}
+
+// IsUpToDate issues a conditional request to the endpoint and returns true
+// if the served configurations did not change since the last call.
+func (p *HTTPConfigProvider) IsUpToDate(ctx context.Context) (bool, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	changed, err := p.fetch(ctx)
+	if err != nil {
+		return false, err
+	}
+	if changed {
+		// Collect will return the configurations we just fetched
+		p.pending = true
+	}
+
+	return !changed, nil
+}
+
+// Collect returns the configurations served by the endpoint
+func (p *HTTPConfigProvider) Collect(ctx context.Context) ([]integration.Config, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	if !p.pending {
+		if _, err := p.fetch(ctx); err != nil {
+			return nil, err
+		}
+	}
+	p.pending = false
+
+	return p.configs, nil
+}
+
+// fetch queries the endpoint, and updates the cached configurations if they
+// changed. The returned boolean is false when the server answered with a
+// 304 Not Modified.
+func (p *HTTPConfigProvider) fetch(ctx context.Context) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+	if p.etag != "" {
+		req.Header.Set("If-None-Match", p.etag)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("unable to query %s: %w", p.source, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return false, nil
+	case http.StatusOK:
+	default:
+		return false, fmt.Errorf("unexpected status code %d from %s", resp.StatusCode, p.source)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPConfigPayloadSize+1))
+	if err != nil {
+		return false, fmt.Errorf("unable to read response from %s: %w", p.source, err)
+	}
+	if len(body) > maxHTTPConfigPayloadSize {
+		return false, fmt.Errorf("response from %s exceeds %d bytes", p.source, maxHTTPConfigPayloadSize)
+	}
+
+	configs, errors, err := parseHTTPConfigPayload(body, p.source)
+	if err != nil {
+		return false, err
+	}
+
+	etag := resp.Header.Get("ETag")
+	if etag == "" {
+		log.Debugf("No ETag returned by %s, configurations will be collected on every poll", p.source)
+	}
+
+	p.etag = etag
+	p.configs = configs
+	p.errors = errors
+
+	return true, nil
+}
+
+// parseHTTPConfigPayload decodes the JSON payload into integration configs.
+// Invalid entries are skipped and reported in the returned errors map.
+func parseHTTPConfigPayload(body []byte, source string) ([]integration.Config, map[string]ErrorMsgSet, error) {
+	var payload httpConfigPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, nil, fmt.Errorf("unable to decode configurations from %s: %w", source, err)
+	}
+
+	configs, errors := parseRemoteConfigEntries(payload.Configs, names.HTTP+":"+source)
+
+	return configs, errors, nil
+}
+
+// parseRemoteConfigEntries converts the entries served by a remote source
+// into integration configs. Invalid entries are skipped and reported in the
+// returned errors map.
+func parseRemoteConfigEntries(entries []remoteConfigEntry, source string) ([]integration.Config, map[string]ErrorMsgSet) {
+	configs := make([]integration.Config, 0, len(entries))
+	errors := make(map[string]ErrorMsgSet)
+
+	for idx, entry := range entries {
+		if entry.Name == "" {
+			errors[fmt.Sprintf("configs[%d]", idx)] = ErrorMsgSet{"check_name is empty": struct{}{}}
+			continue
+		}
+		if len(entry.Instances) == 0 && len(entry.Logs) == 0 {
+			errors[entry.Name] = ErrorMsgSet{"no instances nor logs configuration": struct{}{}}
+			continue
+		}
+
+		conf := integration.Config{
+			Name:          entry.Name,
+			ADIdentifiers: entry.ADIdentifiers,
+			ClusterCheck:  entry.ClusterCheck,
+			Source:        source,
+		}
+
+		if len(entry.InitConfig) > 0 && string(entry.InitConfig) != "null" {
+			conf.InitConfig = integration.Data(entry.InitConfig)
+		} else {
+			conf.InitConfig = integration.Data("{}")
+		}
+
+		for _, instance := range entry.Instances {
+			conf.Instances = append(conf.Instances, integration.Data(instance))
+		}
+
+		if len(entry.Logs) > 0 && string(entry.Logs) != "null" {
+			conf.LogsConfig = integration.Data(entry.Logs)
+		}
+
+		configs = append(configs, conf)
+	}
+
+	return configs, errors
+}
+
+// GetConfigErrors returns the errors found in the last payload
+func (p *HTTPConfigProvider) GetConfigErrors() map[string]ErrorMsgSet {
+	p.Lock()
+	defer p.Unlock()
+
+	errors := make(map[string]ErrorMsgSet, len(p.errors))
+	for entity, errset := range p.errors {
+		errors[entity] = errset
+	}
+
+	return errors
+}
+
+func init() {
+	RegisterProvider(names.HTTPRegisterName, NewHTTPConfigProvider)
+}
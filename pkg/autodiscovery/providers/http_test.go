@@ -0,0 +1,160 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package providers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+const httpProviderPayload = `{
+  "configs": [
+    {
+      "check_name": "nginx",
+      "ad_identifiers": ["nginx"],
+      "init_config": {},
+      "instances": [{"nginx_status_url": "http://%%host%%/status"}]
+    },
+    {
+      "check_name": "http_check",
+      "instances": [{"name": "cmdb", "url": "http://cmdb.local"}],
+      "logs": [{"type": "file", "path": "/var/log/cmdb.log"}]
+    },
+    {
+      "instances": [{"foo": "bar"}]
+    },
+    {
+      "check_name": "empty"
+    }
+  ]
+}`
+
+func TestHTTPConfigProvider(t *testing.T) {
+	var requests int32
+	etag := `"v1"`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Write([]byte(httpProviderPayload)) //nolint:errcheck
+	}))
+	defer server.Close()
+
+	provider, err := NewHTTPConfigProvider(&config.ConfigurationProviders{
+		TemplateURL: server.URL,
+		Token:       "secret",
+	})
+	require.NoError(t, err)
+	p := provider.(*HTTPConfigProvider)
+	ctx := context.Background()
+
+	configs, err := p.Collect(ctx)
+	require.NoError(t, err)
+	require.Len(t, configs, 2)
+
+	assert.Equal(t, "nginx", configs[0].Name)
+	assert.Equal(t, []string{"nginx"}, configs[0].ADIdentifiers)
+	assert.Equal(t, integration.Data("{}"), configs[0].InitConfig)
+	assert.Equal(t, []integration.Data{integration.Data(`{"nginx_status_url": "http://%%host%%/status"}`)}, configs[0].Instances)
+	assert.Equal(t, "http:"+server.URL, configs[0].Source)
+
+	assert.Equal(t, "http_check", configs[1].Name)
+	assert.Equal(t, integration.Data("{}"), configs[1].InitConfig)
+	assert.Equal(t, integration.Data(`[{"type": "file", "path": "/var/log/cmdb.log"}]`), configs[1].LogsConfig)
+
+	assert.Len(t, p.GetConfigErrors(), 2)
+
+	upToDate, err := p.IsUpToDate(ctx)
+	require.NoError(t, err)
+	assert.True(t, upToDate)
+
+	// The server now serves a new version of the configurations
+	etag = `"v2"`
+	upToDate, err = p.IsUpToDate(ctx)
+	require.NoError(t, err)
+	assert.False(t, upToDate)
+
+	// Collect must not query the endpoint again
+	configs, err = p.Collect(ctx)
+	require.NoError(t, err)
+	assert.Len(t, configs, 2)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+}
+
+func TestHTTPConfigProviderError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	provider, err := NewHTTPConfigProvider(&config.ConfigurationProviders{TemplateURL: server.URL})
+	require.NoError(t, err)
+
+	_, err = provider.(*HTTPConfigProvider).Collect(context.Background())
+	assert.Error(t, err)
+
+	upToDate, err := provider.(*HTTPConfigProvider).IsUpToDate(context.Background())
+	assert.Error(t, err)
+	assert.False(t, upToDate)
+}
+
+func TestHTTPConfigProviderPayloadTooLarge(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"configs": [], "padding": "`))              //nolint:errcheck
+		w.Write(bytes.Repeat([]byte("a"), maxHTTPConfigPayloadSize)) //nolint:errcheck
+		w.Write([]byte(`"}`))                                        //nolint:errcheck
+	}))
+	defer server.Close()
+
+	provider, err := NewHTTPConfigProvider(&config.ConfigurationProviders{TemplateURL: server.URL})
+	require.NoError(t, err)
+
+	_, err = provider.(*HTTPConfigProvider).Collect(context.Background())
+	assert.EqualError(t, err, fmt.Sprintf("response from %s exceeds %d bytes", server.URL, maxHTTPConfigPayloadSize))
+}
+
+func TestHTTPConfigProviderSourceWithoutSecrets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secret", r.URL.Query().Get("token"))
+		w.Write([]byte(httpProviderPayload)) //nolint:errcheck
+	}))
+	defer server.Close()
+
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	serverURL.User = url.UserPassword("user", "password")
+	serverURL.RawQuery = "token=secret"
+
+	provider, err := NewHTTPConfigProvider(&config.ConfigurationProviders{TemplateURL: serverURL.String()})
+	require.NoError(t, err)
+
+	configs, err := provider.(*HTTPConfigProvider).Collect(context.Background())
+	require.NoError(t, err)
+	require.NotEmpty(t, configs)
+	assert.Equal(t, "http:"+server.URL, configs[0].Source)
+}
+
+func TestNewHTTPConfigProviderInvalidScheme(t *testing.T) {
+	_, err := NewHTTPConfigProvider(&config.ConfigurationProviders{TemplateURL: "ftp://cmdb.local"})
+	assert.Error(t, err)
+}
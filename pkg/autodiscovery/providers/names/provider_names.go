@@ -14,6 +14,8 @@ const (
 	EndpointsChecks    = "endpoints-checks"
 	Etcd               = "etcd"
 	File               = "file"
+	GRPC               = "grpc"
+	HTTP               = "http"
 	KubeContainer      = "kubernetes-container-allinone"
 	Kubernetes         = "kubernetes"
 	KubeServices       = "kubernetes-services"
@@ -35,6 +37,8 @@ const (
 	ClusterChecksRegisterName      = "clusterchecks"
 	EndpointsChecksRegisterName    = "endpointschecks"
 	EtcdRegisterName               = "etcd"
+	GRPCRegisterName               = "grpc"
+	HTTPRegisterName               = "http"
 	KubeletRegisterName            = "kubelet"
 	KubeContainerRegisterName      = "kubernetes-container-allinone"
 	KubeServicesRegisterName       = "kube_services"
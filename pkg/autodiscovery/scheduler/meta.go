@@ -6,6 +6,7 @@
 package scheduler
 
 import (
+	"sort"
 	"sync"
 
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
@@ -21,6 +22,10 @@ type MetaScheduler struct {
 	// via the metascheduler, but not subsequently unscheduled.
 	scheduledConfigs map[string]integration.Config
 
+	// dispatchedConfigs contains, for each scheduled config digest, the set of
+	// names of the schedulers the config has been dispatched to.
+	dispatchedConfigs map[string]map[string]struct{}
+
 	// activeSchedulers is the set of schedulers currently subscribed to configs.
 	activeSchedulers map[string]Scheduler
 }
@@ -28,8 +33,9 @@ type MetaScheduler struct {
 // NewMetaScheduler inits a meta scheduler
 func NewMetaScheduler() *MetaScheduler {
 	return &MetaScheduler{
-		scheduledConfigs: make(map[string]integration.Config),
-		activeSchedulers: make(map[string]Scheduler),
+		scheduledConfigs:  make(map[string]integration.Config),
+		dispatchedConfigs: make(map[string]map[string]struct{}),
+		activeSchedulers:  make(map[string]Scheduler),
 	}
 }
 
@@ -51,8 +57,9 @@ func (ms *MetaScheduler) Register(name string, s Scheduler, replayConfigs bool)
 	// scheduled or missed in this process.
 	if replayConfigs {
 		configs := make([]integration.Config, 0, len(ms.scheduledConfigs))
-		for _, config := range ms.scheduledConfigs {
+		for digest, config := range ms.scheduledConfigs {
 			configs = append(configs, config)
+			ms.dispatchedConfigs[digest][name] = struct{}{}
 		}
 		s.Schedule(configs)
 	}
@@ -67,6 +74,9 @@ func (ms *MetaScheduler) Deregister(name string) {
 		return
 	}
 	delete(ms.activeSchedulers, name)
+	for _, schedulers := range ms.dispatchedConfigs {
+		delete(schedulers, name)
+	}
 }
 
 // Schedule schedules configs to all registered schedulers
@@ -75,7 +85,13 @@ func (ms *MetaScheduler) Schedule(configs []integration.Config) {
 	defer ms.m.Unlock()
 	for _, config := range configs {
 		log.Tracef("Scheduling %s\n", config.Dump(false))
-		ms.scheduledConfigs[config.Digest()] = config
+		digest := config.Digest()
+		ms.scheduledConfigs[digest] = config
+		schedulers := make(map[string]struct{}, len(ms.activeSchedulers))
+		for name := range ms.activeSchedulers {
+			schedulers[name] = struct{}{}
+		}
+		ms.dispatchedConfigs[digest] = schedulers
 	}
 	for _, scheduler := range ms.activeSchedulers {
 		scheduler.Schedule(configs)
@@ -89,12 +105,26 @@ func (ms *MetaScheduler) Unschedule(configs []integration.Config) {
 	for _, config := range configs {
 		log.Tracef("Unscheduling %s\n", config.Dump(false))
 		delete(ms.scheduledConfigs, config.Digest())
+		delete(ms.dispatchedConfigs, config.Digest())
 	}
 	for _, scheduler := range ms.activeSchedulers {
 		scheduler.Unschedule(configs)
 	}
 }
 
+// ConfigSchedulers returns the names of the schedulers the scheduled config
+// with the given digest has been dispatched to, sorted
+func (ms *MetaScheduler) ConfigSchedulers(digest string) []string {
+	ms.m.Lock()
+	defer ms.m.Unlock()
+	names := make([]string, 0, len(ms.dispatchedConfigs[digest]))
+	for name := range ms.dispatchedConfigs[digest] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // Stop handles clean stop of registered schedulers
 func (ms *MetaScheduler) Stop() {
 	ms.m.Lock()
@@ -89,3 +89,26 @@ func TestMetaScheduler(t *testing.T) {
 	require.ElementsMatch(t, []event{}, s3.events)
 	s3.reset()
 }
+
+func TestMetaSchedulerConfigSchedulers(t *testing.T) {
+	ms := NewMetaScheduler()
+	c1 := makeConfig("one")
+	c2 := makeConfig("two")
+
+	// configs are only dispatched to the schedulers registered at the time,
+	// or replaying the configs
+	ms.Register("s1", &scheduler{}, false)
+	ms.Schedule([]integration.Config{c1})
+	ms.Register("s2", &scheduler{}, false)
+	ms.Schedule([]integration.Config{c2})
+	ms.Register("s3", &scheduler{}, true)
+	require.Equal(t, []string{"s1", "s3"}, ms.ConfigSchedulers(c1.Digest()))
+	require.Equal(t, []string{"s1", "s2", "s3"}, ms.ConfigSchedulers(c2.Digest()))
+
+	ms.Deregister("s1")
+	require.Equal(t, []string{"s3"}, ms.ConfigSchedulers(c1.Digest()))
+
+	ms.Unschedule([]integration.Config{c1})
+	require.Empty(t, ms.ConfigSchedulers(c1.Digest()))
+	require.Equal(t, []string{"s2", "s3"}, ms.ConfigSchedulers(c2.Digest()))
+}
@@ -179,6 +179,20 @@ func (cm *simpleConfigManager) mapOverLoadedConfigs(f func(map[string]integratio
 	cm.store.mapOverLoadedConfigs(f)
 }
 
+// getActiveTemplates implements configManager#getActiveTemplates.
+func (cm *simpleConfigManager) getActiveTemplates() []integration.Config {
+	cm.m.Lock()
+	defer cm.m.Unlock()
+	return cm.store.templateCache.getTemplates()
+}
+
+// getScheduledResolution implements configManager#getScheduledResolution.
+func (cm *simpleConfigManager) getScheduledResolution(svcID, templateDigest string) (string, bool) {
+	cm.m.Lock()
+	defer cm.m.Unlock()
+	return cm.store.getScheduledResolution(svcID, templateDigest)
+}
+
 // resolveTemplateForService resolves a template config for the given service
 func (cm *simpleConfigManager) resolveTemplateForService(tpl integration.Config, svc listeners.Service) (integration.Config, error) {
 	config, err := configresolver.Resolve(tpl, svc)
@@ -7,11 +7,16 @@ package autodiscovery
 
 import (
 	"sync"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/listeners"
 )
 
+// maxSchedulingEventsPerService is the number of scheduling events kept for
+// each service
+const maxSchedulingEventsPerService = 20
+
 // store holds useful mappings for the AD
 type store struct {
 	// serviceToConfigs maps service ID to a slice of resolved templates
@@ -44,6 +49,10 @@ type store struct {
 	// entityToService maps serviceIDs to Service instances.
 	entityToService map[string]listeners.Service
 
+	// serviceToEvents stores the most recent scheduling events for each
+	// service ID, see recordSchedulingEvent.
+	serviceToEvents map[string][]integration.SchedulingEvent
+
 	// templateCache stores templates by their AD identifiers.
 	templateCache *templateCache
 
@@ -62,6 +71,7 @@ func newStore() *store {
 		nameToJMXMetrics:  make(map[string]integration.Data),
 		adIDToServices:    make(map[string]map[string]struct{}),
 		entityToService:   make(map[string]listeners.Service),
+		serviceToEvents:   make(map[string][]integration.SchedulingEvent),
 		templateCache:     newTemplateCache(),
 	}
 
@@ -107,6 +117,23 @@ func (s *store) addConfigForTemplate(templateDigest string, config integration.C
 	s.templateToConfigs[templateDigest] = append(s.templateToConfigs[templateDigest], config)
 }
 
+// getScheduledResolution returns the digest of the config resolved from the
+// given template for the given service, if that config is currently loaded
+func (s *store) getScheduledResolution(serviceID, templateDigest string) (string, bool) {
+	s.m.RLock()
+	defer s.m.RUnlock()
+	for _, config := range s.templateToConfigs[templateDigest] {
+		if config.ServiceID != serviceID {
+			continue
+		}
+		digest := config.Digest()
+		if _, found := s.loadedConfigs[digest]; found {
+			return digest, true
+		}
+	}
+	return "", false
+}
+
 // getTagsHashForService return the tags hash for a specified service
 func (s *store) getTagsHashForService(serviceEntity string) string {
 	s.m.RLock()
@@ -228,3 +255,39 @@ func (s *store) removeServiceForADID(entity string, adIdentifiers []string) {
 		}
 	}
 }
+
+// recordSchedulingEvent stores a scheduling event for the service the config
+// was resolved for.  Only the most recent events are kept.
+func (s *store) recordSchedulingEvent(config integration.Config, scheduled bool) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	events := append(s.serviceToEvents[config.ServiceID], integration.SchedulingEvent{
+		Timestamp: time.Now(),
+		Scheduled: scheduled,
+		CheckName: config.Name,
+		Digest:    config.Digest(),
+		Provider:  config.Provider,
+	})
+	if len(events) > maxSchedulingEventsPerService {
+		events = events[len(events)-maxSchedulingEventsPerService:]
+	}
+	s.serviceToEvents[config.ServiceID] = events
+}
+
+// getSchedulingEvents returns a copy of the scheduling events recorded for
+// the given service
+func (s *store) getSchedulingEvents(serviceID string) []integration.SchedulingEvent {
+	s.m.RLock()
+	defer s.m.RUnlock()
+	events := make([]integration.SchedulingEvent, len(s.serviceToEvents[serviceID]))
+	copy(events, s.serviceToEvents[serviceID])
+	return events
+}
+
+// removeSchedulingEvents removes the scheduling events recorded for the given
+// service
+func (s *store) removeSchedulingEvents(serviceID string) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	delete(s.serviceToEvents, serviceID)
+}
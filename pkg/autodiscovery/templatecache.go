@@ -86,6 +86,18 @@ func (cache *templateCache) getUnresolvedTemplates() map[string][]integration.Co
 	return tpls
 }
 
+// getTemplates returns all templates in the cache.
+func (cache *templateCache) getTemplates() []integration.Config {
+	cache.m.RLock()
+	defer cache.m.RUnlock()
+
+	tpls := make([]integration.Config, 0, len(cache.digestToTemplate))
+	for _, config := range cache.digestToTemplate {
+		tpls = append(tpls, config)
+	}
+	return tpls
+}
+
 // del removes a template from the cache
 func (cache *templateCache) del(tpl integration.Config) error {
 	// compute the digest once
@@ -19,8 +19,9 @@ import (
 func buildLabelSelectors(useNamespaceSelector bool) (namespaceSelector, objectSelector *metav1.LabelSelector) {
 	var labelSelector metav1.LabelSelector
 
-	if config.Datadog.GetBool("admission_controller.mutate_unlabelled") {
-		// Accept all, ignore pods if they're explicitly filtered-out
+	if config.Datadog.GetBool("admission_controller.mutate_unlabelled") || config.Datadog.GetBool("admission_controller.namespace_policy.enabled") {
+		// Accept all, ignore pods if they're explicitly filtered-out.
+		// With namespace policies, the mutations are decided per pod by the webhook.
 		labelSelector = metav1.LabelSelector{
 			MatchExpressions: []metav1.LabelSelectorRequirement{
 				{
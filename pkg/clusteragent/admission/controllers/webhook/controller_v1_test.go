@@ -258,6 +258,29 @@ func TestGenerateTemplatesV1(t *testing.T) {
 				return []admiv1.MutatingWebhook{webhook}
 			},
 		},
+		{
+			name: "config injection, namespace policies",
+			setupConfig: func() {
+				mockConfig.Set("admission_controller.inject_config.enabled", true)
+				mockConfig.Set("admission_controller.mutate_unlabelled", false)
+				mockConfig.Set("admission_controller.namespace_policy.enabled", true)
+				mockConfig.Set("admission_controller.inject_tags.enabled", false)
+				mockConfig.Set("admission_controller.auto_instrumentation.enabled", false)
+			},
+			configFunc: func() Config { return NewConfig(false, false) },
+			want: func() []admiv1.MutatingWebhook {
+				webhook := webhook("datadog.webhook.config", "/injectconfig", &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{
+						{
+							Key:      "admission.datadoghq.com/enabled",
+							Operator: metav1.LabelSelectorOpNotIn,
+							Values:   []string{"false"},
+						},
+					},
+				}, nil)
+				return []admiv1.MutatingWebhook{webhook}
+			},
+		},
 		{
 			name: "tags injection, mutate all",
 			setupConfig: func() {
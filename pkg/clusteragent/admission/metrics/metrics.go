@@ -16,10 +16,11 @@ import (
 
 // Metric names
 const (
-	SecretControllerName   = "secrets"
-	WebhooksControllerName = "webhooks"
-	TagsMutationType       = "standard_tags"
-	ConfigMutationType     = "agent_config"
+	SecretControllerName     = "secrets"
+	WebhooksControllerName   = "webhooks"
+	TagsMutationType         = "standard_tags"
+	ConfigMutationType       = "agent_config"
+	LibInjectionMutationType = "lib_injection"
 )
 
 // Telemetry metrics
@@ -62,4 +63,7 @@ var (
 	LibInjectionErrors = telemetry.NewCounterWithOpts("admission_webhooks", "library_injection_errors",
 		[]string{"language"}, "Number of library injection failures by language",
 		telemetry.Options{NoDoubleUnderscoreSep: true})
+	PolicyDrifts = telemetry.NewCounterWithOpts("admission_webhooks", "policy_drifts",
+		[]string{"mutation_type", "policy"}, "Number of pods drifting from a namespace policy in validation mode by mutation type.",
+		telemetry.Options{NoDoubleUnderscoreSep: true})
 )
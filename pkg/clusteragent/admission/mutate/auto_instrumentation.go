@@ -57,10 +57,10 @@ var (
 
 // InjectAutoInstrumentation injects APM libraries into pods
 func InjectAutoInstrumentation(rawPod []byte, ns string, dc dynamic.Interface) ([]byte, error) {
-	return mutate(rawPod, ns, injectAutoInstrumentation, dc)
+	return mutate(rawPod, ns, metrics.LibInjectionMutationType, injectAutoInstrumentation, dc)
 }
 
-func injectAutoInstrumentation(pod *corev1.Pod, _ string, _ dynamic.Interface) error {
+func injectAutoInstrumentation(pod *corev1.Pod, ns string, dc dynamic.Interface) error {
 	if pod == nil {
 		return errors.New("cannot inject lib into nil pod")
 	}
@@ -72,7 +72,19 @@ func injectAutoInstrumentation(pod *corev1.Pod, _ string, _ dynamic.Interface) e
 		return nil
 	}
 
-	language, image, shouldInject := extractLibInfo(pod, config.Datadog.GetString("admission_controller.auto_instrumentation.container_registry"))
+	policy := getPolicy(pod, ns, dc)
+	if policy == nil && !selectedByLabel(pod) {
+		// Ignore pods matching no namespace policy the webhook object
+		// selector would have filtered out without policies
+		return nil
+	}
+
+	containerRegistry := config.Datadog.GetString("admission_controller.auto_instrumentation.container_registry")
+	language, image, shouldInject := extractLibInfo(pod, containerRegistry)
+	if !shouldInject {
+		// Fall back on the library of the namespace policy matching the pod
+		language, image, shouldInject = extractPolicyLibInfo(policy, containerRegistry)
+	}
 	if !shouldInject {
 		return nil
 	}
@@ -100,6 +112,25 @@ func extractLibInfo(pod *corev1.Pod, containerRegistry string) (language, string
 	return "", "", false
 }
 
+// extractPolicyLibInfo returns the language, the image, and a boolean
+// indicating whether the library of a namespace policy should be injected
+func extractPolicyLibInfo(policy *NamespacePolicy, containerRegistry string) (language, string, bool) {
+	if policy == nil || policy.Mutations.Library == nil {
+		return "", "", false
+	}
+	lib := policy.Mutations.Library
+	return language(lib.Language), fmt.Sprintf("%s/dd-lib-%s-init:%s", containerRegistry, lib.Language, lib.Version), true
+}
+
+func isSupportedLanguage(lang language) bool {
+	for _, supported := range supportedLanguages {
+		if lang == supported {
+			return true
+		}
+	}
+	return false
+}
+
 func injectAutoInstruConfig(pod *corev1.Pod, lang language, image string) error {
 	injected := false
 	langStr := string(lang)
@@ -22,13 +22,17 @@ import (
 type mutateFunc func(*corev1.Pod, string, dynamic.Interface) error
 
 // mutate handles mutating pods and encoding and decoding admission
-// requests and responses for the public mutate functions
-func mutate(rawPod []byte, ns string, m mutateFunc, dc dynamic.Interface) ([]byte, error) {
+// requests and responses for the public mutate functions.
+// Pods matching a namespace policy in validation mode are not mutated,
+// the mutations are reported as drift instead.
+func mutate(rawPod []byte, ns string, mutationType string, m mutateFunc, dc dynamic.Interface) ([]byte, error) {
 	var pod corev1.Pod
 	if err := json.Unmarshal(rawPod, &pod); err != nil {
 		return nil, fmt.Errorf("failed to decode raw object: %v", err)
 	}
 
+	policy := getPolicy(&pod, ns, dc)
+
 	if err := m(&pod, ns, dc); err != nil {
 		return nil, err
 	}
@@ -43,7 +47,15 @@ func mutate(rawPod []byte, ns string, m mutateFunc, dc dynamic.Interface) ([]byt
 		return nil, fmt.Errorf("failed to prepare the JSON patch: %v", err)
 	}
 
-	return json.Marshal(patchOperation)
+	patch, err := json.Marshal(patchOperation)
+	if err != nil || policy == nil || !policy.validationOnly() {
+		return patch, err
+	}
+
+	if len(patchOperation) > 0 {
+		reportDrift(&pod, policy, mutationType, patch)
+	}
+	return json.Marshal([]jsonpatch.Operation{})
 }
 
 // contains returns whether EnvVar slice contains an env var with a given name
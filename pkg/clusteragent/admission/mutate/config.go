@@ -73,14 +73,15 @@ var (
 
 // InjectConfig adds the DD_AGENT_HOST and DD_ENTITY_ID env vars to the pod template if they don't exist
 func InjectConfig(rawPod []byte, ns string, dc dynamic.Interface) ([]byte, error) {
-	return mutate(rawPod, ns, injectConfig, dc)
+	return mutate(rawPod, ns, metrics.ConfigMutationType, injectConfig, dc)
 }
 
-// injectConfig injects DD_AGENT_HOST and DD_ENTITY_ID into a pod template if needed
-func injectConfig(pod *corev1.Pod, _ string, _ dynamic.Interface) error {
-	var injectedConfig, injectedEntity bool
+// injectConfig injects DD_AGENT_HOST and DD_ENTITY_ID into a pod template if needed,
+// and the env vars of the namespace policy matching the pod
+func injectConfig(pod *corev1.Pod, ns string, dc dynamic.Interface) error {
+	var injectedConfig, injectedEntity, injectedPolicyEnv bool
 	defer func() {
-		metrics.MutationAttempts.Inc(metrics.ConfigMutationType, strconv.FormatBool(injectedConfig || injectedEntity))
+		metrics.MutationAttempts.Inc(metrics.ConfigMutationType, strconv.FormatBool(injectedConfig || injectedEntity || injectedPolicyEnv))
 	}()
 
 	if pod == nil {
@@ -88,7 +89,12 @@ func injectConfig(pod *corev1.Pod, _ string, _ dynamic.Interface) error {
 		return errors.New("cannot inject config into nil pod")
 	}
 
-	if !shouldInjectConf(pod) {
+	policy := getPolicy(pod, ns, dc)
+	if policy != nil && pod.GetLabels()[admCommon.EnabledLabelKey] != "false" {
+		injectedPolicyEnv = injectPolicyEnv(pod, policy)
+	}
+
+	if !shouldInjectConf(pod, policy) {
 		return nil
 	}
 
@@ -113,9 +119,9 @@ func injectConfig(pod *corev1.Pod, _ string, _ dynamic.Interface) error {
 	return nil
 }
 
-// shouldInjectConf returns whether the config should be injected
-// based on the pod labels and the cluster agent config
-func shouldInjectConf(pod *corev1.Pod) bool {
+// shouldInjectConf returns whether the config should be injected based on
+// the pod labels, the namespace policy matching the pod and the cluster agent config
+func shouldInjectConf(pod *corev1.Pod, policy *NamespacePolicy) bool {
 	if val, found := pod.GetLabels()[admCommon.EnabledLabelKey]; found {
 		switch val {
 		case "true":
@@ -127,6 +133,9 @@ func shouldInjectConf(pod *corev1.Pod) bool {
 			return false
 		}
 	}
+	if policy != nil && policy.Mutations.Config != nil {
+		return *policy.Mutations.Config
+	}
 	return config.Datadog.GetBool("admission_controller.mutate_unlabelled")
 }
 
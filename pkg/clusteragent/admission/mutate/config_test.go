@@ -65,7 +65,7 @@ func Test_shouldInjectConf(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.setupConfig()
-			if got := shouldInjectConf(tt.pod); got != tt.want {
+			if got := shouldInjectConf(tt.pod, nil); got != tt.want {
 				t.Errorf("shouldInjectConf() = %v, want %v", got, tt.want)
 			}
 		})
@@ -0,0 +1,269 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build kubeapiserver
+// +build kubeapiserver
+
+package mutate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	admCommon "github.com/DataDog/datadog-agent/pkg/clusteragent/admission/common"
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/admission/metrics"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/cache"
+	apiCommon "github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver/common"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	// policiesConfigMapKey is the key of the ConfigMap data holding the namespace policies
+	policiesConfigMapKey = "policies.yaml"
+	policiesCacheKey     = "admission_namespace_policies"
+
+	// Policy modes
+	policyModeMutate   = "mutate"
+	policyModeValidate = "validate"
+
+	logsAnnotationKeyFormat = "ad.datadoghq.com/%s.logs"
+)
+
+var configMapsGVR = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+// NamespacePolicy declares the mutations applied to the pods of a set of
+// namespaces matching a label selector
+type NamespacePolicy struct {
+	Name string `json:"name"`
+	// Namespaces restricts the policy to these namespaces, all namespaces match if empty
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Selector restricts the policy to the pods matching the label selector, all pods match if nil
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// Mode is either "mutate" (default) or "validate" to only report drift
+	Mode      string          `json:"mode,omitempty"`
+	Mutations PolicyMutations `json:"mutations"`
+
+	selector labels.Selector
+}
+
+// PolicyMutations declares the mutations of a NamespacePolicy, unset
+// mutations fall back on the pod labels and annotations
+type PolicyMutations struct {
+	// Config enables the agent config injection (DD_AGENT_HOST, DD_ENTITY_ID)
+	Config *bool `json:"config,omitempty"`
+	// Env holds env vars injected into the containers, like DD_ENV
+	Env map[string]string `json:"env,omitempty"`
+	// Tags enables the standard tags injection
+	Tags *bool `json:"tags,omitempty"`
+	// TagMapping maps pod or owner labels to the env vars to inject, in addition
+	// to the standard tags labels
+	TagMapping map[string]string `json:"tag_mapping,omitempty"`
+	// Logs sets the log source and service annotations of the containers
+	Logs *LogsPolicy `json:"logs,omitempty"`
+	// Library sets the APM library injected into the pods
+	Library *LibraryPolicy `json:"library,omitempty"`
+}
+
+// LogsPolicy holds the log source and service set on the containers
+type LogsPolicy struct {
+	Source  string `json:"source,omitempty"`
+	Service string `json:"service,omitempty"`
+}
+
+// LibraryPolicy holds the language and version of the injected APM library
+type LibraryPolicy struct {
+	Language string `json:"language"`
+	Version  string `json:"version"`
+}
+
+// validationOnly returns whether the policy only reports drift
+func (p *NamespacePolicy) validationOnly() bool {
+	return p.Mode == policyModeValidate
+}
+
+// matches returns whether the policy applies to a pod of a namespace
+func (p *NamespacePolicy) matches(pod *corev1.Pod, ns string) bool {
+	if len(p.Namespaces) > 0 {
+		found := false
+		for _, namespace := range p.Namespaces {
+			if namespace == ns {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return p.selector == nil || p.selector.Matches(labels.Set(pod.GetLabels()))
+}
+
+// parsePolicies parses and validates the namespace policies of the policy ConfigMap
+func parsePolicies(data []byte) ([]*NamespacePolicy, error) {
+	var policies []*NamespacePolicy
+	if err := yaml.Unmarshal(data, &policies); err != nil {
+		return nil, fmt.Errorf("failed to parse namespace policies: %v", err)
+	}
+	for i, policy := range policies {
+		if policy.Name == "" {
+			policy.Name = fmt.Sprintf("policy-%d", i)
+		}
+		switch policy.Mode {
+		case "":
+			policy.Mode = policyModeMutate
+		case policyModeMutate, policyModeValidate:
+		default:
+			return nil, fmt.Errorf("invalid mode %q for policy %q, should be either %q or %q", policy.Mode, policy.Name, policyModeMutate, policyModeValidate)
+		}
+		if policy.Selector != nil {
+			selector, err := metav1.LabelSelectorAsSelector(policy.Selector)
+			if err != nil {
+				return nil, fmt.Errorf("invalid selector for policy %q: %v", policy.Name, err)
+			}
+			policy.selector = selector
+		}
+		if lib := policy.Mutations.Library; lib != nil && !isSupportedLanguage(language(lib.Language)) {
+			return nil, fmt.Errorf("invalid library language %q for policy %q. Supported languages are %v", lib.Language, policy.Name, supportedLanguages)
+		}
+	}
+	return policies, nil
+}
+
+// getPolicy returns the first namespace policy matching a pod, or nil if the
+// namespace policies are disabled or none matches
+func getPolicy(pod *corev1.Pod, ns string, dc dynamic.Interface) *NamespacePolicy {
+	if !config.Datadog.GetBool("admission_controller.namespace_policy.enabled") {
+		return nil
+	}
+	if ns == "" {
+		ns = pod.GetNamespace()
+	}
+	for _, policy := range getAndCachePolicies(dc) {
+		if policy.matches(pod, ns) {
+			return policy
+		}
+	}
+	return nil
+}
+
+// selectedByLabel returns whether the pod is selected by its
+// admission.datadoghq.com/enabled label. The webhook object selector filters
+// pods this way when the namespace policies are disabled, but enabling them
+// widens it to every pod, so pods matching no policy must be filtered here.
+func selectedByLabel(pod *corev1.Pod) bool {
+	if !config.Datadog.GetBool("admission_controller.namespace_policy.enabled") {
+		return true
+	}
+	switch pod.GetLabels()[admCommon.EnabledLabelKey] {
+	case "true":
+		return true
+	case "false":
+		return false
+	default:
+		return config.Datadog.GetBool("admission_controller.mutate_unlabelled")
+	}
+}
+
+// getAndCachePolicies tries to get the namespace policies from cache before
+// querying the api server. Policies are ignored if the ConfigMap is invalid.
+func getAndCachePolicies(dc dynamic.Interface) []*NamespacePolicy {
+	if cached, hit := cache.Cache.Get(policiesCacheKey); hit {
+		if policies, valid := cached.([]*NamespacePolicy); valid {
+			return policies
+		}
+	}
+
+	policies, err := getPolicies(dc)
+	if err != nil {
+		log.Warnf("Ignoring namespace policies: %v", err)
+	}
+	cacheTTL := config.Datadog.GetDuration("admission_controller.namespace_policy.cache_validity") * time.Minute
+	cache.Cache.Set(policiesCacheKey, policies, cacheTTL)
+	return policies
+}
+
+// getPolicies queries the namespace policies ConfigMap
+func getPolicies(dc dynamic.Interface) ([]*NamespacePolicy, error) {
+	if dc == nil {
+		return nil, fmt.Errorf("no kubernetes client")
+	}
+	name := config.Datadog.GetString("admission_controller.namespace_policy.configmap_name")
+	obj, err := dc.Resource(configMapsGVR).Namespace(apiCommon.GetMyNamespace()).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Debugf("Namespace policies ConfigMap %q not found", name)
+			return nil, nil
+		}
+		return nil, err
+	}
+	data, _, err := unstructured.NestedStringMap(obj.Object, "data")
+	if err != nil {
+		return nil, fmt.Errorf("invalid ConfigMap %q: %v", name, err)
+	}
+	return parsePolicies([]byte(data[policiesConfigMapKey]))
+}
+
+// reportDrift logs and counts the mutations a policy in validation mode would have applied
+func reportDrift(pod *corev1.Pod, policy *NamespacePolicy, mutationType string, patch []byte) {
+	log.Infof("Pod %s drifts from namespace policy %q, %s mutation not applied: %s", podString(pod), policy.Name, mutationType, patch)
+	metrics.PolicyDrifts.Inc(mutationType, policy.Name)
+}
+
+// injectPolicyEnv injects the env vars of a policy
+func injectPolicyEnv(pod *corev1.Pod, policy *NamespacePolicy) bool {
+	names := make([]string, 0, len(policy.Mutations.Env))
+	for name := range policy.Mutations.Env {
+		names = append(names, name)
+	}
+	// sort the env vars for a stable patch
+	sort.Strings(names)
+
+	injected := false
+	for _, name := range names {
+		if injectEnv(pod, corev1.EnvVar{Name: name, Value: policy.Mutations.Env[name]}) {
+			injected = true
+		}
+	}
+	return injected
+}
+
+// injectLogsAnnotations sets the log source and service annotations of the
+// containers without logs annotation
+func injectLogsAnnotations(pod *corev1.Pod, logs *LogsPolicy) bool {
+	if logs == nil {
+		return false
+	}
+	logsConfig, err := json.Marshal([]LogsPolicy{*logs})
+	if err != nil {
+		return false
+	}
+	injected := false
+	for _, ctr := range pod.Spec.Containers {
+		key := fmt.Sprintf(logsAnnotationKeyFormat, ctr.Name)
+		if _, found := pod.GetAnnotations()[key]; found {
+			log.Debugf("Ignoring container '%s' in pod %s: annotation '%s' already exists", ctr.Name, podString(pod), key)
+			continue
+		}
+		if pod.Annotations == nil {
+			pod.Annotations = make(map[string]string)
+		}
+		pod.Annotations[key] = string(logsConfig)
+		injected = true
+	}
+	return injected
+}
@@ -0,0 +1,240 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build kubeapiserver
+// +build kubeapiserver
+
+package mutate
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/cache"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+const testPolicies = `
+- name: payments-validate
+  namespaces: [payments]
+  selector:
+    matchLabels:
+      tier: canary
+  mode: validate
+  mutations:
+    config: true
+- name: payments
+  namespaces: [payments]
+  mutations:
+    config: true
+    env:
+      DD_ENV: prod
+    tag_mapping:
+      team: DD_TEAM
+    logs:
+      source: java
+      service: checkout
+    library:
+      language: java
+      version: v0.114.0
+- name: default
+  mutations:
+    tags: false
+`
+
+func newPolicyDynamicClient(policies string) dynamic.Interface {
+	configMap := newUnstructured("v1", "ConfigMap", "default", "datadog-admission-policies")
+	configMap.Object["data"] = map[string]interface{}{"policies.yaml": policies}
+	return fake.NewSimpleDynamicClient(scheme, configMap)
+}
+
+func setupPolicies(t *testing.T) dynamic.Interface {
+	mockConfig := config.Mock(t)
+	mockConfig.Set("admission_controller.namespace_policy.enabled", true)
+	cache.Cache.Flush()
+	t.Cleanup(cache.Cache.Flush)
+	return newPolicyDynamicClient(testPolicies)
+}
+
+func Test_parsePolicies(t *testing.T) {
+	policies, err := parsePolicies([]byte(testPolicies))
+	require.NoError(t, err)
+	require.Len(t, policies, 3)
+	assert.Equal(t, "validate", policies[0].Mode)
+	assert.Equal(t, "mutate", policies[1].Mode)
+	assert.Equal(t, map[string]string{"DD_ENV": "prod"}, policies[1].Mutations.Env)
+	assert.Equal(t, &LibraryPolicy{Language: "java", Version: "v0.114.0"}, policies[1].Mutations.Library)
+	assert.False(t, *policies[2].Mutations.Tags)
+
+	_, err = parsePolicies([]byte("- name: foo\n  mode: enforce"))
+	assert.EqualError(t, err, `invalid mode "enforce" for policy "foo", should be either "mutate" or "validate"`)
+
+	_, err = parsePolicies([]byte("- name: foo\n  mutations:\n    library:\n      language: cobol"))
+	assert.EqualError(t, err, `invalid library language "cobol" for policy "foo". Supported languages are [java js python]`)
+}
+
+func TestGetPolicy(t *testing.T) {
+	dc := setupPolicies(t)
+
+	canary := withLabels(fakePod("foo-pod"), map[string]string{"tier": "canary"})
+	assert.Equal(t, "payments-validate", getPolicy(canary, "payments", dc).Name)
+	assert.Equal(t, "payments", getPolicy(fakePod("foo-pod"), "payments", dc).Name)
+	assert.Equal(t, "default", getPolicy(fakePod("foo-pod"), "other", dc).Name)
+
+	// disabled policies
+	config.Datadog.Set("admission_controller.namespace_policy.enabled", false)
+	assert.Nil(t, getPolicy(fakePod("foo-pod"), "payments", dc))
+}
+
+func TestGetPolicyInvalidConfigMap(t *testing.T) {
+	setupPolicies(t)
+	dc := newPolicyDynamicClient("- mode: enforce")
+	assert.Nil(t, getPolicy(fakePod("foo-pod"), "payments", dc))
+}
+
+func TestInjectConfigWithPolicy(t *testing.T) {
+	dc := setupPolicies(t)
+
+	pod := fakePodWithContainer("foo-pod", corev1.Container{})
+	err := injectConfig(pod, "payments", dc)
+	assert.NoError(t, err)
+	assert.Contains(t, pod.Spec.Containers[0].Env, fakeEnvWithFieldRefValue("DD_AGENT_HOST", "status.hostIP"))
+	assert.Contains(t, pod.Spec.Containers[0].Env, fakeEnvWithValue("DD_ENV", "prod"))
+
+	// pods can opt out with the enabled label
+	pod = withLabels(fakePodWithContainer("foo-pod", corev1.Container{}), map[string]string{"admission.datadoghq.com/enabled": "false"})
+	err = injectConfig(pod, "payments", dc)
+	assert.NoError(t, err)
+	assert.Empty(t, pod.Spec.Containers[0].Env)
+}
+
+func TestInjectTagsWithPolicy(t *testing.T) {
+	dc := setupPolicies(t)
+
+	pod := withLabels(fakePod("foo-pod"), map[string]string{"team": "checkout-team", "tags.datadoghq.com/env": "prod"})
+	err := injectTags(pod, "payments", dc)
+	assert.NoError(t, err)
+	assert.Contains(t, pod.Spec.Containers[0].Env, fakeEnvWithValue("DD_TEAM", "checkout-team"))
+	assert.Contains(t, pod.Spec.Containers[0].Env, fakeEnvWithValue("DD_ENV", "prod"))
+	assert.Equal(t, `[{"source":"java","service":"checkout"}]`, pod.Annotations["ad.datadoghq.com/foo-pod-container.logs"])
+
+	// the default policy disables the tags injection
+	pod = withLabels(fakePod("foo-pod"), map[string]string{"tags.datadoghq.com/env": "prod"})
+	err = injectTags(pod, "other", dc)
+	assert.NoError(t, err)
+	assert.Empty(t, pod.Spec.Containers[0].Env)
+}
+
+func TestInjectLogsAnnotationsWithoutTags(t *testing.T) {
+	setupPolicies(t)
+	dc := newPolicyDynamicClient(`
+- name: payments
+  namespaces: [payments]
+  mutations:
+    tags: false
+    logs:
+      source: java
+      service: checkout
+`)
+
+	pod := withLabels(fakePod("foo-pod"), map[string]string{"tags.datadoghq.com/env": "prod"})
+	err := injectTags(pod, "payments", dc)
+	assert.NoError(t, err)
+	assert.Empty(t, pod.Spec.Containers[0].Env)
+	assert.Equal(t, `[{"source":"java","service":"checkout"}]`, pod.Annotations["ad.datadoghq.com/foo-pod-container.logs"])
+
+	// pods can opt out with the enabled label
+	pod = withLabels(fakePod("foo-pod"), map[string]string{"admission.datadoghq.com/enabled": "false"})
+	err = injectTags(pod, "payments", dc)
+	assert.NoError(t, err)
+	assert.Empty(t, pod.Annotations)
+}
+
+func TestInjectAutoInstrumentationWithPolicy(t *testing.T) {
+	dc := setupPolicies(t)
+
+	pod := fakePod("foo-pod")
+	err := injectAutoInstrumentation(pod, "payments", dc)
+	assert.NoError(t, err)
+	require.Len(t, pod.Spec.InitContainers, 1)
+	assert.Equal(t, "gcr.io/datadoghq/dd-lib-java-init:v0.114.0", pod.Spec.InitContainers[0].Image)
+
+	// pod annotations take precedence
+	pod = fakePodWithAnnotation("admission.datadoghq.com/js-lib.version", "v1.0.0")
+	err = injectAutoInstrumentation(pod, "payments", dc)
+	assert.NoError(t, err)
+	require.Len(t, pod.Spec.InitContainers, 1)
+	assert.Equal(t, "gcr.io/datadoghq/dd-lib-js-init:v1.0.0", pod.Spec.InitContainers[0].Image)
+}
+
+func TestMutationsWithoutMatchingPolicy(t *testing.T) {
+	setupPolicies(t)
+	dc := newPolicyDynamicClient(`
+- name: payments
+  namespaces: [payments]
+  mutations:
+    config: true
+`)
+	config.Datadog.Set("admission_controller.mutate_unlabelled", false)
+
+	newPod := func(podLabels map[string]string) *corev1.Pod {
+		pod := withLabels(fakePod("foo-pod"), podLabels)
+		pod.Labels["tags.datadoghq.com/env"] = "prod"
+		pod.Annotations = map[string]string{"admission.datadoghq.com/js-lib.version": "v1.0.0"}
+		return pod
+	}
+
+	// unlabelled pods outside all policies aren't mutated
+	pod := newPod(map[string]string{})
+	assert.NoError(t, injectConfig(pod, "other", dc))
+	assert.NoError(t, injectTags(pod, "other", dc))
+	assert.NoError(t, injectAutoInstrumentation(pod, "other", dc))
+	assert.Empty(t, pod.Spec.Containers[0].Env)
+	assert.Empty(t, pod.Spec.InitContainers)
+
+	// unless they opt in with the enabled label
+	pod = newPod(map[string]string{"admission.datadoghq.com/enabled": "true"})
+	assert.NoError(t, injectConfig(pod, "other", dc))
+	assert.NoError(t, injectTags(pod, "other", dc))
+	assert.NoError(t, injectAutoInstrumentation(pod, "other", dc))
+	assert.Contains(t, pod.Spec.Containers[0].Env, fakeEnvWithFieldRefValue("DD_AGENT_HOST", "status.hostIP"))
+	assert.Contains(t, pod.Spec.Containers[0].Env, fakeEnvWithValue("DD_ENV", "prod"))
+	assert.Len(t, pod.Spec.InitContainers, 1)
+
+	// or when unlabelled pods are mutated
+	config.Datadog.Set("admission_controller.mutate_unlabelled", true)
+	pod = newPod(map[string]string{})
+	assert.NoError(t, injectTags(pod, "other", dc))
+	assert.NoError(t, injectAutoInstrumentation(pod, "other", dc))
+	assert.Contains(t, pod.Spec.Containers[0].Env, fakeEnvWithValue("DD_ENV", "prod"))
+	assert.Len(t, pod.Spec.InitContainers, 1)
+}
+
+func TestMutateValidationMode(t *testing.T) {
+	dc := setupPolicies(t)
+
+	pod := withLabels(fakePodWithContainer("foo-pod", corev1.Container{}), map[string]string{"tier": "canary"})
+	rawPod, err := json.Marshal(pod)
+	require.NoError(t, err)
+
+	// the canary pod drifts, but isn't mutated
+	patch, err := InjectConfig(rawPod, "payments", dc)
+	assert.NoError(t, err)
+	assert.Equal(t, "[]", string(patch))
+
+	// other pods are mutated
+	pod.Labels = nil
+	rawPod, err = json.Marshal(pod)
+	require.NoError(t, err)
+	patch, err = InjectConfig(rawPod, "payments", dc)
+	assert.NoError(t, err)
+	assert.Contains(t, string(patch), "DD_AGENT_HOST")
+}
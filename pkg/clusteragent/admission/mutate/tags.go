@@ -52,7 +52,7 @@ func (o *ownerInfo) buildID(ns string) string {
 // InjectTags adds the DD_ENV, DD_VERSION, DD_SERVICE env vars to
 // the pod template from pod and higher-level resource labels
 func InjectTags(rawPod []byte, ns string, dc dynamic.Interface) ([]byte, error) {
-	return mutate(rawPod, ns, injectTags, dc)
+	return mutate(rawPod, ns, metrics.TagsMutationType, injectTags, dc)
 }
 
 // injectTags injects DD_ENV, DD_VERSION, DD_SERVICE
@@ -68,15 +68,24 @@ func injectTags(pod *corev1.Pod, ns string, dc dynamic.Interface) error {
 		return errors.New("cannot inject tags into nil pod")
 	}
 
-	if !shouldInjectTags(pod) {
+	policy := getPolicy(pod, ns, dc)
+	// the logs annotations of the namespace policy are injected regardless
+	// of the tags injection, which the policy can disable
+	if policy != nil && pod.GetLabels()[common.EnabledLabelKey] != "false" && injectLogsAnnotations(pod, policy.Mutations.Logs) {
+		injected = true
+	}
+
+	if !shouldInjectTags(pod, policy) {
 		// Ignore pod if it has the label admission.datadoghq.com/enabled=false
+		// or if its namespace policy disables the tags injection
 		return nil
 	}
 
-	var found bool
-	if found, injected = injectTagsFromLabels(pod.GetLabels(), pod); found {
+	mapping := tagsLabelsToEnv(policy)
+	if found, injectedFromLabels := injectTagsFromLabels(pod.GetLabels(), mapping, pod); found {
 		// Standard labels found in the pod's labels
 		// No need to lookup the pod's owner
+		injected = injected || injectedFromLabels
 		return nil
 	}
 
@@ -102,25 +111,49 @@ func injectTags(pod *corev1.Pod, ns string, dc dynamic.Interface) error {
 	}
 
 	log.Debugf("Looking for standard labels on '%s/%s' - kind '%s' owner of pod %s", owner.GetNamespace(), owner.GetName(), owner.GetKind(), podString(pod))
-	_, injected = injectTagsFromLabels(owner.GetLabels(), pod)
+	if _, injectedFromLabels := injectTagsFromLabels(owner.GetLabels(), mapping, pod); injectedFromLabels {
+		injected = true
+	}
 
 	return nil
 }
 
-// shouldInjectConf returns whether we should try to inject standard tags
-func shouldInjectTags(pod *corev1.Pod) bool {
+// shouldInjectTags returns whether we should try to inject standard tags
+func shouldInjectTags(pod *corev1.Pod, policy *NamespacePolicy) bool {
 	if val := pod.GetLabels()[common.EnabledLabelKey]; val == "false" {
 		return false
 	}
+	if policy == nil {
+		return selectedByLabel(pod)
+	}
+	if policy.Mutations.Tags != nil {
+		return *policy.Mutations.Tags
+	}
 	return true
 }
 
-// injectTagsFromLabels looks for standard tags in pod labels
+// tagsLabelsToEnv returns the standard tags labels and the tag mapping
+// of the namespace policy, mapped to their environment variables
+func tagsLabelsToEnv(policy *NamespacePolicy) map[string]string {
+	if policy == nil || len(policy.Mutations.TagMapping) == 0 {
+		return labelsToEnv
+	}
+	mapping := make(map[string]string, len(labelsToEnv)+len(policy.Mutations.TagMapping))
+	for label, envName := range labelsToEnv {
+		mapping[label] = envName
+	}
+	for label, envName := range policy.Mutations.TagMapping {
+		mapping[label] = envName
+	}
+	return mapping
+}
+
+// injectTagsFromLabels looks for the labels of the mapping in pod labels
 // and injects them as environment variables if found
-func injectTagsFromLabels(labels map[string]string, pod *corev1.Pod) (bool, bool) {
+func injectTagsFromLabels(labels map[string]string, mapping map[string]string, pod *corev1.Pod) (bool, bool) {
 	found := false
 	injectedAtLeastOnce := false
-	for l, envName := range labelsToEnv {
+	for l, envName := range mapping {
 		if tagValue, labelFound := labels[l]; labelFound {
 			env := corev1.EnvVar{
 				Name:  envName,
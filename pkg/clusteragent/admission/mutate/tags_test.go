@@ -85,7 +85,7 @@ func Test_injectTagsFromLabels(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			found, injected := injectTagsFromLabels(tt.labels, tt.pod)
+			found, injected := injectTagsFromLabels(tt.labels, labelsToEnv, tt.pod)
 			assert.Equal(t, tt.found, found)
 			assert.Equal(t, tt.injected, injected)
 			assert.Len(t, tt.pod.Spec.Containers, 1)
@@ -119,7 +119,7 @@ func Test_shouldInjectTags(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := shouldInjectTags(tt.pod); got != tt.want {
+			if got := shouldInjectTags(tt.pod, nil); got != tt.want {
 				t.Errorf("shouldInjectTags() = %v, want %v", got, tt.want)
 			}
 		})
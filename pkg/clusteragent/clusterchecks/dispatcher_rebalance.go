@@ -13,7 +13,11 @@ import (
 	"sort"
 	"time"
 
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/clusteragent/clusterchecks/types"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
 	le "github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver/leaderelection/metrics"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
@@ -23,112 +27,260 @@ import (
 // the 0.9 value is tentative and could be changed
 const tolerationMargin float64 = 0.9
 
-// Weight holds a node name and the corresponding busyness score
-type Weight struct {
-	nodeName string
+// antiAffinityGroupKey is the instance option grouping the cluster checks that
+// shouldn't run on the same node, like high-availability check pairs
+const antiAffinityGroupKey = "anti_affinity_group"
+
+// rebalanceCheck is a cluster check that can be moved by the rebalancing
+type rebalanceCheck struct {
+	id                string
+	weight            int
+	antiAffinityGroup string
+}
+
+// rebalanceNode holds the simulated busyness and cluster checks of a node
+type rebalanceNode struct {
+	name     string
 	busyness int
+	// checks are the movable cluster checks, sorted by decreasing weight
+	checks []rebalanceCheck
+	// groups counts the checks of each anti-affinity group running on the node
+	groups map[string]int
 }
 
-// Weights is an array of node weights
-type Weights []Weight
+func (n *rebalanceNode) addCheck(c rebalanceCheck) {
+	n.busyness += c.weight
+	if c.antiAffinityGroup != "" {
+		n.groups[c.antiAffinityGroup]++
+	}
+	n.checks = append(n.checks, c)
+	sortRebalanceChecks(n.checks)
+}
 
-func (w Weights) Len() int           { return len(w) }
-func (w Weights) Less(i, j int) bool { return w[i].busyness > w[j].busyness }
-func (w Weights) Swap(i, j int)      { w[i], w[j] = w[j], w[i] }
+func (n *rebalanceNode) removeCheck(id string) {
+	for i, c := range n.checks {
+		if c.id != id {
+			continue
+		}
+		n.busyness -= c.weight
+		if c.antiAffinityGroup != "" {
+			n.groups[c.antiAffinityGroup]--
+		}
+		n.checks = append(n.checks[:i], n.checks[i+1:]...)
+		return
+	}
+}
 
-func (d *dispatcher) calculateAvg() (int, error) {
-	busyness := 0
-	length := 0
+func sortRebalanceChecks(checks []rebalanceCheck) {
+	sort.Slice(checks, func(i, j int) bool {
+		if checks[i].weight != checks[j].weight {
+			return checks[i].weight > checks[j].weight
+		}
+		return checks[i].id < checks[j].id
+	})
+}
 
-	d.store.RLock()
-	defer d.store.RUnlock()
+// rebalancePlan simulates the moves of cluster checks between nodes, to balance
+// their busyness while honouring the node capacity and the anti-affinity groups
+type rebalancePlan struct {
+	// nodes are sorted by name for deterministic plans
+	nodes []*rebalanceNode
+	// capacity is the maximum busyness of a node, 0 means no limit
+	capacity int
+	average  int
+	moves    []types.RebalanceResponse
+}
 
-	for _, node := range d.store.nodes {
-		busyness = node.GetBusyness(busynessFunc)
-		length++
+func newRebalancePlan(nodes []*rebalanceNode, capacity int) *rebalancePlan {
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].name < nodes[j].name })
+	total := 0
+	for _, node := range nodes {
+		sortRebalanceChecks(node.checks)
+		total += node.busyness
+	}
+	average := 0
+	if len(nodes) > 0 {
+		average = total / len(nodes)
 	}
+	return &rebalancePlan{
+		nodes:    nodes,
+		capacity: capacity,
+		average:  average,
+	}
+}
 
-	if length == 0 {
-		return -1, fmt.Errorf("zero nodes reporting")
+// canReceive returns whether a node can receive a check without exceeding its
+// capacity or running two checks of the same anti-affinity group
+func (p *rebalancePlan) canReceive(node *rebalanceNode, c rebalanceCheck) bool {
+	if p.capacity > 0 && node.busyness+c.weight > p.capacity {
+		return false
 	}
+	return c.antiAffinityGroup == "" || node.groups[c.antiAffinityGroup] == 0
+}
 
-	return busyness / length, nil
+// pickNode returns the least busy node that can receive a check, or nil
+func (p *rebalancePlan) pickNode(c rebalanceCheck, source *rebalanceNode) *rebalanceNode {
+	var picked *rebalanceNode
+	for _, node := range p.nodes {
+		if node == source || !p.canReceive(node, c) {
+			continue
+		}
+		if picked == nil || node.busyness < picked.busyness {
+			picked = node
+		}
+	}
+	return picked
 }
 
-// getDiffAndWeights creates a map that contains the difference between
-// the busyness on each node and the total average busyness, and a Weights
-// struct containing nodes and their busyness values
-func (d *dispatcher) getDiffAndWeights(avg int) (map[string]int, Weights) {
-	diffMap := make(map[string]int)
-	weights := Weights{}
+func (p *rebalancePlan) move(c rebalanceCheck, source, dest *rebalanceNode) {
+	p.moves = append(p.moves, types.RebalanceResponse{
+		CheckID:        c.id,
+		CheckWeight:    c.weight,
+		SourceNodeName: source.name,
+		SourceDiff:     source.busyness - p.average,
+		DestNodeName:   dest.name,
+		DestDiff:       dest.busyness - p.average,
+	})
+	source.removeCheck(c.id)
+	dest.addCheck(c)
+}
 
-	d.store.RLock()
-	defer d.store.RUnlock()
+// run computes the moves of the plan. Nodes breaking the anti-affinity groups or
+// exceeding their capacity are fixed first, then checks are moved from the busiest
+// nodes to the least busy ones.
+func (p *rebalancePlan) run() []types.RebalanceResponse {
+	for _, node := range p.nodes {
+		p.fixAntiAffinity(node)
+		p.fixCapacity(node)
+	}
 
-	for nodeName, node := range d.store.nodes {
-		busyness := node.GetBusyness(busynessFunc)
-		diffMap[nodeName] = busyness - avg
-		weights = append(weights, Weight{
-			nodeName: nodeName,
-			busyness: busyness,
-		})
+	maxMoves := 0
+	for _, node := range p.nodes {
+		maxMoves += len(node.checks)
 	}
-	return diffMap, weights
+	for i := 0; i < maxMoves; i++ {
+		if !p.balanceOnce() {
+			break
+		}
+	}
+	return p.moves
 }
 
-// updateDiff creates a map that contains the difference between
-// the busyness on each node and the total average busyness.
-func (d *dispatcher) updateDiff(avg int) map[string]int {
-	diffMap := make(map[string]int)
-
-	d.store.RLock()
-	defer d.store.RUnlock()
+func (p *rebalancePlan) fixAntiAffinity(node *rebalanceNode) {
+	seen := make(map[string]bool)
+	for _, c := range append([]rebalanceCheck{}, node.checks...) {
+		if c.antiAffinityGroup == "" {
+			continue
+		}
+		if !seen[c.antiAffinityGroup] {
+			seen[c.antiAffinityGroup] = true
+			continue
+		}
+		dest := p.pickNode(c, node)
+		if dest == nil {
+			log.Warnf("Cannot move check %s out of node %s: no node can run another check of anti-affinity group %s", c.id, node.name, c.antiAffinityGroup)
+			continue
+		}
+		p.move(c, node, dest)
+	}
+}
 
-	for nodeName, node := range d.store.nodes {
-		busyness := node.GetBusyness(busynessFunc)
-		diffMap[nodeName] = busyness - avg
+func (p *rebalancePlan) fixCapacity(node *rebalanceNode) {
+	if p.capacity <= 0 {
+		return
+	}
+	for _, c := range append([]rebalanceCheck{}, node.checks...) {
+		if node.busyness <= p.capacity {
+			return
+		}
+		if c.weight == 0 {
+			continue
+		}
+		if dest := p.pickNode(c, node); dest != nil {
+			p.move(c, node, dest)
+		}
+	}
+	if node.busyness > p.capacity {
+		log.Warnf("Node %s busyness %d exceeds the node capacity %d, no other node can receive its checks", node.name, node.busyness, p.capacity)
 	}
+}
 
-	return diffMap
+// balanceOnce moves the heaviest check that fits on a less busy node, from the
+// busiest node possible. A check moves only if it keeps the busyness of the
+// destination node lower than the busyness of the source node multiplied by the
+// tolerationMargin, to lean towards stability over perfectly optimal balance.
+func (p *rebalancePlan) balanceOnce() bool {
+	sources := append([]*rebalanceNode{}, p.nodes...)
+	sort.SliceStable(sources, func(i, j int) bool { return sources[i].busyness > sources[j].busyness })
+
+	for _, source := range sources {
+		sourceDiff := source.busyness - p.average
+		if sourceDiff <= 0 {
+			return false
+		}
+		for _, c := range source.checks {
+			if c.weight == 0 {
+				// moving checks without weight doesn't change the balance
+				continue
+			}
+			dest := p.pickNode(c, source)
+			if dest == nil {
+				continue
+			}
+			if dest.busyness-p.average+c.weight < int(float64(sourceDiff)*tolerationMargin) {
+				p.move(c, source, dest)
+				return true
+			}
+		}
+	}
+	return false
 }
 
-// pickCheckToMove select the most appropriate check to move from a node to another.
-// A check Xi running on a node N is chosen to move to another node if it satisfies the following
-// Weight(Xi) >  Weight(Xj) (for each j != i, 0 <= j < len(weights))
-// where Weight(X) is the busyness value caused by running the check X.
-func (d *dispatcher) pickCheckToMove(nodeName string) (string, int, error) {
+// buildRebalancePlan builds the plan from the runner stats of the nodes
+func (d *dispatcher) buildRebalancePlan() *rebalancePlan {
 	d.store.RLock()
-	node, found := d.store.getNodeStore(nodeName)
-	d.store.RUnlock()
+	defer d.store.RUnlock()
 
-	if !found {
-		log.Debugf("Node %s not found in store. Won't consider moving check", nodeName)
-		return "", -1, fmt.Errorf("node %s not found in store", nodeName)
+	nodes := make([]*rebalanceNode, 0, len(d.store.nodes))
+	for name, node := range d.store.nodes {
+		rebalanceNode := &rebalanceNode{
+			name:   name,
+			groups: make(map[string]int),
+		}
+		node.RLock()
+		for id, stats := range node.clcRunnerStats {
+			weight := busynessFunc(stats)
+			rebalanceNode.busyness += weight
+			if !stats.IsClusterCheck {
+				// node checks are part of the busyness, but can't move
+				continue
+			}
+			c := rebalanceCheck{id: id, weight: weight}
+			if digest, found := d.store.idToDigest[check.ID(id)]; found {
+				c.antiAffinityGroup = getAntiAffinityGroup(d.store.digestToConfig[digest])
+			}
+			if c.antiAffinityGroup != "" {
+				rebalanceNode.groups[c.antiAffinityGroup]++
+			}
+			rebalanceNode.checks = append(rebalanceNode.checks, c)
+		}
+		node.RUnlock()
+		nodes = append(nodes, rebalanceNode)
 	}
-
-	return node.GetMostWeightedClusterCheck(busynessFunc)
+	return newRebalancePlan(nodes, d.nodeCapacity)
 }
 
-// pickNode select the most appropriate node to receive a specific check.
-// A node Ni is most appropriate to receive a check with a weight W
-// if it satisfies the following
-// Diff(Ni) < Diff(Nj) (for each j != i, 0 <= j < len(nodes))
-// where Diff(N) is the difference between the busyness on N and the total average busyness.
-func pickNode(diffMap map[string]int, sourceNode string) string {
-	firstItr := true
-	minDiff := 0
-	pickedNode := ""
-	for _, node := range orderedKeys(diffMap) {
-		if node == sourceNode {
-			continue
-		}
-		if diffMap[node] < minDiff || firstItr {
-			minDiff = diffMap[node]
-			pickedNode = node
-			firstItr = false
-		}
+// getAntiAffinityGroup returns the anti-affinity group of a check config, if any
+func getAntiAffinityGroup(config integration.Config) string {
+	if len(config.Instances) == 0 {
+		return ""
 	}
-	return pickedNode
+	var instance map[string]interface{}
+	if err := yaml.Unmarshal(config.Instances[0], &instance); err != nil {
+		return ""
+	}
+	group, _ := instance[antiAffinityGroupKey].(string)
+	return group
 }
 
 // moveCheck moves a check by its ID from a node to another
@@ -167,7 +319,8 @@ func (d *dispatcher) moveCheck(src, dest, checkID string) error {
 
 // rebalance tries to optimize the checks repartition on cluster level check
 // runners with less possible check moves based on the runner stats.
-func (d *dispatcher) rebalance() []types.RebalanceResponse {
+// With dryRun, the moves are computed but not applied.
+func (d *dispatcher) rebalance(dryRun bool) []types.RebalanceResponse {
 	// Collect CLC runners stats and update cache before rebalancing
 	d.updateRunnersStats()
 
@@ -177,60 +330,30 @@ func (d *dispatcher) rebalance() []types.RebalanceResponse {
 	}()
 
 	log.Trace("Trying to rebalance cluster checks distribution if needed")
-	totalAvg, err := d.calculateAvg()
-	if err != nil {
-		log.Debugf("Cannot rebalance checks: %v", err)
+	plan := d.buildRebalancePlan()
+	if len(plan.nodes) == 0 {
+		log.Debugf("Cannot rebalance checks: zero nodes reporting")
 		return nil
 	}
 
-	checksMoved := []types.RebalanceResponse{}
-	diffMap, weights := d.getDiffAndWeights(totalAvg)
-	sort.Sort(weights)
-
-	for _, nodeWeight := range weights {
-		for diffMap[nodeWeight.nodeName] > 0 {
-			// try to move checks from a node only of the node busyness is above the average
-			sourceNodeName := nodeWeight.nodeName
-			checkID, checkWeight, err := d.pickCheckToMove(sourceNodeName)
-			if err != nil {
-				log.Debugf("Cannot pick a check to move from node %s: %v", sourceNodeName, err)
-				break
-			}
+	moves := plan.run()
+	if dryRun {
+		return moves
+	}
 
-			destNodeName := pickNode(diffMap, sourceNodeName)
-			sourceDiff := diffMap[sourceNodeName]
-			destDiff := diffMap[destNodeName]
-
-			// move a check to a new node only if it keeps the
-			// busyness of the new node lower than the original
-			// node's busyness multiplied by the tolerationMargin
-			// value the toleration margin is used to lean towards
-			// stability over perfectly optimal balance
-			if destDiff+checkWeight < int(float64(sourceDiff)*tolerationMargin) {
-				rebalancingDecisions.Inc(le.JoinLeaderValue)
-				err = d.moveCheck(sourceNodeName, destNodeName, checkID)
-				if err != nil {
-					log.Debugf("Cannot move check %s: %v", checkID, err)
-					continue
-				}
-
-				successfulRebalancing.Inc(le.JoinLeaderValue)
-				log.Tracef("Check %s with weight %d moved, total avg: %d, source diff: %d, dest diff: %d",
-					checkID, checkWeight, totalAvg, sourceDiff, destDiff)
-				// diffMap needs to be updated on every check moved
-				diffMap = d.updateDiff(totalAvg)
-				checksMoved = append(checksMoved, types.RebalanceResponse{
-					CheckID:        checkID,
-					CheckWeight:    checkWeight,
-					SourceNodeName: sourceNodeName,
-					SourceDiff:     sourceDiff,
-					DestNodeName:   destNodeName,
-					DestDiff:       destDiff,
-				})
-			} else {
-				break
-			}
+	checksMoved := []types.RebalanceResponse{}
+	for _, move := range moves {
+		rebalancingDecisions.Inc(le.JoinLeaderValue)
+		err := d.moveCheck(move.SourceNodeName, move.DestNodeName, move.CheckID)
+		if err != nil {
+			log.Debugf("Cannot move check %s: %v", move.CheckID, err)
+			continue
 		}
+
+		successfulRebalancing.Inc(le.JoinLeaderValue)
+		log.Tracef("Check %s with weight %d moved, total avg: %d, source diff: %d, dest diff: %d",
+			move.CheckID, move.CheckWeight, plan.average, move.SourceDiff, move.DestDiff)
+		checksMoved = append(checksMoved, move)
 	}
 
 	return checksMoved
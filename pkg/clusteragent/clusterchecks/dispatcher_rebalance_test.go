@@ -76,21 +76,11 @@ func TestRebalance(t *testing.T) {
 				"A": {
 					name: "A",
 					clcRunnerStats: types.CLCRunnersStats{
-						"checkA0": types.CLCRunnerStats{
-							AverageExecutionTime: 50,
-							MetricSamples:        10,
-							IsClusterCheck:       true,
-						},
 						"checkA1": types.CLCRunnerStats{
 							AverageExecutionTime: 20,
 							MetricSamples:        10,
 							IsClusterCheck:       true,
 						},
-						"checkA2": types.CLCRunnerStats{
-							AverageExecutionTime: 100,
-							MetricSamples:        10,
-							IsClusterCheck:       true,
-						},
 						"checkA3": types.CLCRunnerStats{
 							AverageExecutionTime: 300,
 							MetricSamples:        10,
@@ -116,6 +106,16 @@ func TestRebalance(t *testing.T) {
 							MetricSamples:        10,
 							IsClusterCheck:       true,
 						},
+						"checkA0": types.CLCRunnerStats{
+							AverageExecutionTime: 50,
+							MetricSamples:        10,
+							IsClusterCheck:       true,
+						},
+						"checkA2": types.CLCRunnerStats{
+							AverageExecutionTime: 100,
+							MetricSamples:        10,
+							IsClusterCheck:       true,
+						},
 					},
 				},
 			},
@@ -177,12 +177,12 @@ func TestRebalance(t *testing.T) {
 							MetricSamples:        10,
 							IsClusterCheck:       true,
 						},
-						"checkA1": types.CLCRunnerStats{
-							AverageExecutionTime: 20,
+						"checkA2": types.CLCRunnerStats{
+							AverageExecutionTime: 100,
 							MetricSamples:        10,
 							IsClusterCheck:       true,
 						},
-						"checkA2": types.CLCRunnerStats{
+						"checkB2": types.CLCRunnerStats{
 							AverageExecutionTime: 100,
 							MetricSamples:        10,
 							IsClusterCheck:       true,
@@ -192,6 +192,11 @@ func TestRebalance(t *testing.T) {
 				"B": {
 					name: "B",
 					clcRunnerStats: types.CLCRunnersStats{
+						"checkA1": types.CLCRunnerStats{
+							AverageExecutionTime: 20,
+							MetricSamples:        10,
+							IsClusterCheck:       true,
+						},
 						"checkA3": types.CLCRunnerStats{
 							AverageExecutionTime: 200,
 							MetricSamples:        10,
@@ -207,11 +212,6 @@ func TestRebalance(t *testing.T) {
 							MetricSamples:        10,
 							IsClusterCheck:       true,
 						},
-						"checkB2": types.CLCRunnerStats{
-							AverageExecutionTime: 100,
-							MetricSamples:        10,
-							IsClusterCheck:       true,
-						},
 					},
 				},
 			},
@@ -333,16 +333,21 @@ func TestRebalance(t *testing.T) {
 							MetricSamples:        10,
 							IsClusterCheck:       true,
 						},
-					},
-				},
-				"C": {
-					name: "C",
-					clcRunnerStats: types.CLCRunnersStats{
 						"checkC0": types.CLCRunnerStats{
 							AverageExecutionTime: 5,
 							MetricSamples:        10,
 							IsClusterCheck:       true,
 						},
+						"checkD0": types.CLCRunnerStats{
+							AverageExecutionTime: 10,
+							MetricSamples:        10,
+							IsClusterCheck:       true,
+						},
+					},
+				},
+				"C": {
+					name: "C",
+					clcRunnerStats: types.CLCRunnersStats{
 						"checkC1": types.CLCRunnerStats{
 							AverageExecutionTime: 90,
 							MetricSamples:        10,
@@ -358,11 +363,6 @@ func TestRebalance(t *testing.T) {
 				"D": {
 					name: "D",
 					clcRunnerStats: types.CLCRunnersStats{
-						"checkD0": types.CLCRunnerStats{
-							AverageExecutionTime: 10,
-							MetricSamples:        10,
-							IsClusterCheck:       true,
-						},
 						"checkA3": types.CLCRunnerStats{
 							AverageExecutionTime: 300,
 							MetricSamples:        10,
@@ -476,6 +476,7 @@ func TestRebalance(t *testing.T) {
 			},
 			out: map[string]*nodeStore{
 				"A": {
+					name: "A",
 					clcRunnerStats: types.CLCRunnersStats{
 						"checkA0": types.CLCRunnerStats{
 							AverageExecutionTime: 50,
@@ -492,14 +493,15 @@ func TestRebalance(t *testing.T) {
 							MetricSamples:        10,
 							IsClusterCheck:       true,
 						},
-						"checkA3": types.CLCRunnerStats{
-							AverageExecutionTime: 300,
+						"checkC2": types.CLCRunnerStats{
+							AverageExecutionTime: 100,
 							MetricSamples:        10,
 							IsClusterCheck:       true,
 						},
 					},
 				},
 				"B": {
+					name: "B",
 					clcRunnerStats: types.CLCRunnersStats{
 						"checkB0": types.CLCRunnerStats{
 							AverageExecutionTime: 50,
@@ -511,17 +513,23 @@ func TestRebalance(t *testing.T) {
 							MetricSamples:        10,
 							IsClusterCheck:       true,
 						},
-						"checkB2": types.CLCRunnerStats{
-							AverageExecutionTime: 100,
+						"checkB3": types.CLCRunnerStats{
+							AverageExecutionTime: 200,
+							MetricSamples:        10,
+							IsClusterCheck:       true,
+						},
+						"checkC0": types.CLCRunnerStats{
+							AverageExecutionTime: 20,
 							MetricSamples:        10,
 							IsClusterCheck:       true,
 						},
 					},
 				},
 				"C": {
+					name: "C",
 					clcRunnerStats: types.CLCRunnersStats{
-						"checkC0": types.CLCRunnerStats{
-							AverageExecutionTime: 20,
+						"checkA3": types.CLCRunnerStats{
+							AverageExecutionTime: 300,
 							MetricSamples:        10,
 							IsClusterCheck:       true,
 						},
@@ -530,20 +538,21 @@ func TestRebalance(t *testing.T) {
 							MetricSamples:        10,
 							IsClusterCheck:       true,
 						},
-						"checkC2": types.CLCRunnerStats{
-							AverageExecutionTime: 100,
-							MetricSamples:        10,
-							IsClusterCheck:       true,
-						},
-						"checkB3": types.CLCRunnerStats{
-							AverageExecutionTime: 200,
+						"checkE0": types.CLCRunnerStats{
+							AverageExecutionTime: 10,
 							MetricSamples:        10,
 							IsClusterCheck:       true,
 						},
 					},
 				},
 				"D": {
+					name: "D",
 					clcRunnerStats: types.CLCRunnersStats{
+						"checkB2": types.CLCRunnerStats{
+							AverageExecutionTime: 100,
+							MetricSamples:        10,
+							IsClusterCheck:       true,
+						},
 						"checkD0": types.CLCRunnerStats{
 							AverageExecutionTime: 5,
 							MetricSamples:        10,
@@ -562,12 +571,8 @@ func TestRebalance(t *testing.T) {
 					},
 				},
 				"E": {
+					name: "E",
 					clcRunnerStats: types.CLCRunnersStats{
-						"checkE0": types.CLCRunnerStats{
-							AverageExecutionTime: 10,
-							MetricSamples:        10,
-							IsClusterCheck:       true,
-						},
 						"checkB4": types.CLCRunnerStats{
 							AverageExecutionTime: 500,
 							MetricSamples:        10,
@@ -649,6 +654,7 @@ func TestRebalance(t *testing.T) {
 			},
 			out: map[string]*nodeStore{
 				"A": {
+					name: "A",
 					clcRunnerStats: types.CLCRunnersStats{
 						"checkA0": types.CLCRunnerStats{
 							AverageExecutionTime: 50,
@@ -670,9 +676,15 @@ func TestRebalance(t *testing.T) {
 							MetricSamples:        10,
 							IsClusterCheck:       true,
 						},
+						"checkB4": types.CLCRunnerStats{
+							AverageExecutionTime: 40,
+							MetricSamples:        10,
+							IsClusterCheck:       true,
+						},
 					},
 				},
 				"B": {
+					name: "B",
 					clcRunnerStats: types.CLCRunnersStats{
 						"checkB0": types.CLCRunnerStats{
 							AverageExecutionTime: 20,
@@ -689,26 +701,21 @@ func TestRebalance(t *testing.T) {
 							MetricSamples:        10,
 							IsClusterCheck:       true,
 						},
-
-						"checkB4": types.CLCRunnerStats{
-							AverageExecutionTime: 40,
-							MetricSamples:        10,
-							IsClusterCheck:       true,
-						},
 						"checkB5": types.CLCRunnerStats{
 							AverageExecutionTime: 60,
 							MetricSamples:        10,
 							IsClusterCheck:       true,
 						},
-					},
-				},
-				"C": {
-					clcRunnerStats: types.CLCRunnersStats{
 						"checkC0": types.CLCRunnerStats{
 							AverageExecutionTime: 20,
 							MetricSamples:        10,
 							IsClusterCheck:       true,
 						},
+					},
+				},
+				"C": {
+					name: "C",
+					clcRunnerStats: types.CLCRunnersStats{
 						"checkB3": types.CLCRunnerStats{
 							AverageExecutionTime: 500,
 							MetricSamples:        10,
@@ -812,18 +819,22 @@ func TestRebalance(t *testing.T) {
 			},
 			out: map[string]*nodeStore{
 				"A": {
-					clcRunnerStats: types.CLCRunnersStats{},
-				},
-				"B": {
-					clcRunnerStats: types.CLCRunnersStats{},
-				},
-				"C": {
+					name: "A",
 					clcRunnerStats: types.CLCRunnersStats{
 						"checkC0": types.CLCRunnerStats{
 							AverageExecutionTime: 20,
 							MetricSamples:        10,
 							IsClusterCheck:       true,
 						},
+					},
+				},
+				"B": {
+					name:           "B",
+					clcRunnerStats: types.CLCRunnersStats{},
+				},
+				"C": {
+					name: "C",
+					clcRunnerStats: types.CLCRunnersStats{
 						"checkC1": types.CLCRunnerStats{
 							AverageExecutionTime: 500,
 							MetricSamples:        10,
@@ -906,6 +917,7 @@ func TestRebalance(t *testing.T) {
 			},
 			out: map[string]*nodeStore{
 				"A": {
+					name: "A",
 					clcRunnerStats: types.CLCRunnersStats{
 						"checkE3": types.CLCRunnerStats{
 							AverageExecutionTime: 500,
@@ -915,13 +927,9 @@ func TestRebalance(t *testing.T) {
 					},
 				},
 				"B": {
+					name: "B",
 					clcRunnerStats: types.CLCRunnersStats{
-						"checkB0": types.CLCRunnerStats{
-							AverageExecutionTime: 20,
-							MetricSamples:        10,
-							IsClusterCheck:       true,
-						},
-						"checkE2": types.CLCRunnerStats{
+						"checkD2": types.CLCRunnerStats{
 							AverageExecutionTime: 300,
 							MetricSamples:        10,
 							IsClusterCheck:       true,
@@ -929,6 +937,7 @@ func TestRebalance(t *testing.T) {
 					},
 				},
 				"C": {
+					name: "C",
 					clcRunnerStats: types.CLCRunnersStats{
 						"checkC0": types.CLCRunnerStats{
 							AverageExecutionTime: 20,
@@ -940,10 +949,21 @@ func TestRebalance(t *testing.T) {
 							MetricSamples:        10,
 							IsClusterCheck:       true,
 						},
+						"checkE1": types.CLCRunnerStats{
+							AverageExecutionTime: 100,
+							MetricSamples:        10,
+							IsClusterCheck:       true,
+						},
 					},
 				},
 				"D": {
+					name: "D",
 					clcRunnerStats: types.CLCRunnersStats{
+						"checkB0": types.CLCRunnerStats{
+							AverageExecutionTime: 20,
+							MetricSamples:        10,
+							IsClusterCheck:       true,
+						},
 						"checkD0": types.CLCRunnerStats{
 							AverageExecutionTime: 20,
 							MetricSamples:        10,
@@ -954,22 +974,18 @@ func TestRebalance(t *testing.T) {
 							MetricSamples:        10,
 							IsClusterCheck:       true,
 						},
-						"checkD2": types.CLCRunnerStats{
-							AverageExecutionTime: 300,
+						"checkE0": types.CLCRunnerStats{
+							AverageExecutionTime: 20,
 							MetricSamples:        10,
 							IsClusterCheck:       true,
 						},
 					},
 				},
 				"E": {
+					name: "E",
 					clcRunnerStats: types.CLCRunnersStats{
-						"checkE0": types.CLCRunnerStats{
-							AverageExecutionTime: 20,
-							MetricSamples:        10,
-							IsClusterCheck:       true,
-						},
-						"checkE1": types.CLCRunnerStats{
-							AverageExecutionTime: 100,
+						"checkE2": types.CLCRunnerStats{
+							AverageExecutionTime: 300,
 							MetricSamples:        10,
 							IsClusterCheck:       true,
 						},
@@ -1050,6 +1066,7 @@ func TestRebalance(t *testing.T) {
 			},
 			out: map[string]*nodeStore{
 				"A": {
+					name: "A",
 					clcRunnerStats: types.CLCRunnersStats{
 						"checkE3": types.CLCRunnerStats{
 							AverageExecutionTime: 500,
@@ -1059,10 +1076,21 @@ func TestRebalance(t *testing.T) {
 					},
 				},
 				"B": {
+					name: "B",
 					clcRunnerStats: types.CLCRunnersStats{
-						"checkB0": types.CLCRunnerStats{
+						"checkD2": types.CLCRunnerStats{
+							AverageExecutionTime: 300,
+							MetricSamples:        600,
+							IsClusterCheck:       true,
+						},
+					},
+				},
+				"C": {
+					name: "C",
+					clcRunnerStats: types.CLCRunnersStats{
+						"checkC0": types.CLCRunnerStats{
 							AverageExecutionTime: 20,
-							MetricSamples:        10,
+							MetricSamples:        5,
 							IsClusterCheck:       true,
 						},
 						"checkE2": types.CLCRunnerStats{
@@ -1072,11 +1100,12 @@ func TestRebalance(t *testing.T) {
 						},
 					},
 				},
-				"C": {
+				"D": {
+					name: "D",
 					clcRunnerStats: types.CLCRunnersStats{
-						"checkC0": types.CLCRunnerStats{
+						"checkB0": types.CLCRunnerStats{
 							AverageExecutionTime: 20,
-							MetricSamples:        5,
+							MetricSamples:        10,
 							IsClusterCheck:       true,
 						},
 						"checkC1": types.CLCRunnerStats{
@@ -1084,10 +1113,6 @@ func TestRebalance(t *testing.T) {
 							MetricSamples:        20,
 							IsClusterCheck:       true,
 						},
-					},
-				},
-				"D": {
-					clcRunnerStats: types.CLCRunnersStats{
 						"checkD0": types.CLCRunnerStats{
 							AverageExecutionTime: 20,
 							MetricSamples:        5,
@@ -1098,14 +1123,10 @@ func TestRebalance(t *testing.T) {
 							MetricSamples:        50,
 							IsClusterCheck:       true,
 						},
-						"checkD2": types.CLCRunnerStats{
-							AverageExecutionTime: 300,
-							MetricSamples:        600,
-							IsClusterCheck:       true,
-						},
 					},
 				},
 				"E": {
+					name: "E",
 					clcRunnerStats: types.CLCRunnersStats{
 						"checkE0": types.CLCRunnerStats{
 							AverageExecutionTime: 20,
@@ -1371,7 +1392,7 @@ func TestRebalance(t *testing.T) {
 			}
 
 			// rebalance checks
-			dispatcher.rebalance()
+			dispatcher.rebalance(false)
 
 			// assert runner stats repartition is updated correctly
 			for node, store := range tc.out {
@@ -1449,3 +1470,114 @@ func TestMoveCheck(t *testing.T) {
 		})
 	}
 }
+
+func TestRebalancePlanCapacity(t *testing.T) {
+	for i, tc := range []struct {
+		nodes    []*rebalanceNode
+		capacity int
+		moves    []string
+	}{
+		{
+			// node A exceeds the capacity, its heaviest check moves
+			nodes: []*rebalanceNode{
+				{name: "A", busyness: 100, checks: []rebalanceCheck{{id: "a0", weight: 50}, {id: "a1", weight: 30}, {id: "a2", weight: 20}}},
+				{name: "B"},
+			},
+			capacity: 60,
+			moves:    []string{"a0"},
+		},
+		{
+			// node B would exceed the capacity, no check moves
+			nodes: []*rebalanceNode{
+				{name: "A", busyness: 80, checks: []rebalanceCheck{{id: "a0", weight: 40}, {id: "a1", weight: 40}}},
+				{name: "B", busyness: 50},
+			},
+			capacity: 60,
+			moves:    []string{},
+		},
+		{
+			// without capacity, checks move to balance the nodes
+			nodes: []*rebalanceNode{
+				{name: "A", busyness: 80, checks: []rebalanceCheck{{id: "a0", weight: 40}, {id: "a1", weight: 40}}},
+				{name: "B", busyness: 10},
+			},
+			moves: []string{"a0"},
+		},
+	} {
+		t.Run(fmt.Sprintf("case %d", i), func(t *testing.T) {
+			for _, node := range tc.nodes {
+				node.groups = make(map[string]int)
+			}
+			plan := newRebalancePlan(tc.nodes, tc.capacity)
+
+			moves := []string{}
+			for _, move := range plan.run() {
+				moves = append(moves, move.CheckID)
+			}
+			assert.Equal(t, tc.moves, moves)
+		})
+	}
+}
+
+func TestRebalancePlanAntiAffinity(t *testing.T) {
+	// checks of the same group running on a node are split
+	plan := newRebalancePlan([]*rebalanceNode{
+		{
+			name:     "A",
+			busyness: 70,
+			checks:   []rebalanceCheck{{id: "a0", weight: 10, antiAffinityGroup: "ha"}, {id: "a1", weight: 10, antiAffinityGroup: "ha"}, {id: "a2", weight: 50}},
+			groups:   map[string]int{"ha": 2},
+		},
+		{name: "B", busyness: 50, checks: []rebalanceCheck{{id: "b0", weight: 50}}, groups: map[string]int{}},
+	}, 0)
+	moves := plan.run()
+	assert.Len(t, moves, 1)
+	assert.Equal(t, "a1", moves[0].CheckID)
+	assert.Equal(t, "B", moves[0].DestNodeName)
+
+	// checks don't move to a node running a check of the same group
+	plan = newRebalancePlan([]*rebalanceNode{
+		{
+			name:     "A",
+			busyness: 200,
+			checks:   []rebalanceCheck{{id: "a0", weight: 100, antiAffinityGroup: "ha"}, {id: "a1", weight: 100}},
+			groups:   map[string]int{"ha": 1},
+		},
+		{name: "B", busyness: 10, checks: []rebalanceCheck{{id: "b0", weight: 10, antiAffinityGroup: "ha"}}, groups: map[string]int{"ha": 1}},
+	}, 0)
+	moves = plan.run()
+	assert.Len(t, moves, 1)
+	assert.Equal(t, "a1", moves[0].CheckID)
+}
+
+func TestRebalanceDryRun(t *testing.T) {
+	dispatcher := newDispatcher()
+	dispatcher.store.active = true
+	dispatcher.store.nodes["A"] = newNodeStore("A", "")
+	dispatcher.store.nodes["B"] = newNodeStore("B", "")
+
+	stats := types.CLCRunnersStats{}
+	for _, name := range []string{"check0", "check1"} {
+		config := integration.Config{
+			Name:       name,
+			Instances:  []integration.Data{integration.Data("anti_affinity_group: ha")},
+			InitConfig: integration.Data(""),
+		}
+		dispatcher.addConfig(config, "A")
+		id := check.BuildID(config.Name, config.Instances[0], config.InitConfig)
+		stats[string(id)] = types.CLCRunnerStats{AverageExecutionTime: 10, MetricSamples: 10, IsClusterCheck: true}
+	}
+	dispatcher.store.nodes["A"].clcRunnerStats = stats
+
+	moves := dispatcher.rebalance(true)
+	assert.Len(t, moves, 1)
+	assert.Equal(t, "A", moves[0].SourceNodeName)
+	assert.Equal(t, "B", moves[0].DestNodeName)
+
+	// the checks don't move
+	assert.Len(t, dispatcher.store.nodes["A"].clcRunnerStats, 2)
+	assert.Len(t, dispatcher.store.nodes["A"].digestToConfig, 2)
+	assert.Len(t, dispatcher.store.nodes["B"].digestToConfig, 0)
+
+	requireNotLocked(t, dispatcher.store)
+}
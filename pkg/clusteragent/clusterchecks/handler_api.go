@@ -98,13 +98,14 @@ func (h *Handler) GetAllEndpointsCheckConfigs() (types.ConfigResponse, error) {
 	return response, err
 }
 
-// RebalanceClusterChecks triggers an attempt to rebalance cluster checks
-func (h *Handler) RebalanceClusterChecks() ([]types.RebalanceResponse, error) {
+// RebalanceClusterChecks triggers an attempt to rebalance cluster checks.
+// With dryRun, the checks that would move are returned without moving them.
+func (h *Handler) RebalanceClusterChecks(dryRun bool) ([]types.RebalanceResponse, error) {
 	if !h.dispatcher.advancedDispatching {
 		return nil, fmt.Errorf("no checks to rebalance: advanced dispatching is not enabled")
 	}
 
-	rebalancingDecisions := h.dispatcher.rebalance()
+	rebalancingDecisions := h.dispatcher.rebalance(dryRun)
 	response := []types.RebalanceResponse{}
 
 	for _, decision := range rebalancingDecisions {
@@ -9,7 +9,6 @@
 package clusterchecks
 
 import (
-	"sort"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
@@ -54,13 +53,3 @@ func busynessFunc(s types.CLCRunnerStats) int {
 	}
 	return int(checkExecutionTimeWeight*float64(s.AverageExecutionTime) + checkMetricSamplesWeight*float64(s.MetricSamples))
 }
-
-// orderedKeys sorts the keys of a map and return them in a slice
-func orderedKeys(m map[string]int) []string {
-	keys := []string{}
-	for key := range m {
-		keys = append(keys, key)
-	}
-	sort.Strings(keys)
-	return keys
-}
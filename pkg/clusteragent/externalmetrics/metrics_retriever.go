@@ -15,6 +15,8 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/clusteragent/externalmetrics/model"
 	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/autoscalers"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
+
+	"k8s.io/client-go/dynamic"
 )
 
 const (
@@ -32,17 +34,24 @@ type MetricsRetriever struct {
 	processor     autoscalers.ProcessorInterface
 	store         *DatadogMetricsInternalStore
 	isLeader      func() bool
+	metricsServer metricsServerQuerier
 }
 
 // NewMetricsRetriever returns a new MetricsRetriever
-func NewMetricsRetriever(refreshPeriod, metricsMaxAge int64, processor autoscalers.ProcessorInterface, isLeader func() bool, store *DatadogMetricsInternalStore) (*MetricsRetriever, error) {
-	return &MetricsRetriever{
+// The dynamic client is used to query the metrics server for the DatadogMetrics with a
+// metrics-server fallback policy, it can be nil.
+func NewMetricsRetriever(refreshPeriod, metricsMaxAge int64, processor autoscalers.ProcessorInterface, dynamicClient dynamic.Interface, isLeader func() bool, store *DatadogMetricsInternalStore) (*MetricsRetriever, error) {
+	mr := &MetricsRetriever{
 		refreshPeriod: refreshPeriod,
 		metricsMaxAge: metricsMaxAge,
 		processor:     processor,
 		store:         store,
 		isLeader:      isLeader,
-	}, nil
+	}
+	if dynamicClient != nil {
+		mr.metricsServer = &dynamicMetricsServerQuerier{client: dynamicClient}
+	}
+	return mr, nil
 }
 
 // Run starts retrieving external metrics
@@ -128,10 +137,59 @@ func (mr *MetricsRetriever) retrieveMetricsValues() {
 			datadogMetricFromStore.UpdateTime = currentTime
 		}
 
+		mr.applyFallback(datadogMetricFromStore, currentTime)
+
 		mr.store.UnlockSet(datadogMetric.ID, *datadogMetricFromStore, metricRetrieverStoreID)
 	}
 }
 
+// applyFallback uses the fallback policy of an invalid DatadogMetric to keep a valid value.
+// The error from Datadog is kept, and the source of the value is tracked in ValueSource.
+func (mr *MetricsRetriever) applyFallback(datadogMetric *model.DatadogMetricInternal, currentTime time.Time) {
+	fallback := datadogMetric.Fallback
+	if fallback == nil {
+		datadogMetric.ValueSource = ""
+		return
+	}
+
+	if datadogMetric.Valid {
+		datadogMetric.ValueSource = model.ValueSourceDatadog
+		datadogMetric.LastValidValue = datadogMetric.Value
+		datadogMetric.LastValidTime = datadogMetric.UpdateTime
+		return
+	}
+
+	datadogMetric.ValueSource = model.ValueSourceDatadog
+	switch fallback.Type {
+	case model.FallbackTypeLastKnownGood:
+		if datadogMetric.LastValidTime.IsZero() || currentTime.Sub(datadogMetric.LastValidTime) > fallback.MaxAge {
+			log.Debugf("No last known good value for DatadogMetric %s within %v", datadogMetric.ID, fallback.MaxAge)
+			return
+		}
+		datadogMetric.Value = datadogMetric.LastValidValue
+	case model.FallbackTypeStatic:
+		datadogMetric.Value = fallback.Value
+	case model.FallbackTypeMetricsServer:
+		if mr.metricsServer == nil {
+			log.Debugf("Cannot use metrics server fallback for DatadogMetric %s: no metrics server client", datadogMetric.ID)
+			return
+		}
+		value, err := mr.metricsServer.queryPodsUsage(fallback.Namespace, fallback.Selector, fallback.Resource)
+		if err != nil {
+			log.Warnf("Metrics server fallback failed for DatadogMetric %s: %v", datadogMetric.ID, err)
+			datadogMetric.Error = fmt.Errorf("%v, metrics server fallback failed: %v", datadogMetric.Error, err)
+			return
+		}
+		datadogMetric.Value = value
+	default:
+		return
+	}
+
+	log.Debugf("Using %s fallback value %v for DatadogMetric %s: %v", fallback.Type, datadogMetric.Value, datadogMetric.ID, datadogMetric.Error)
+	datadogMetric.Valid = true
+	datadogMetric.ValueSource = fallback.Source()
+}
+
 func getUniqueQueries(datadogMetrics []model.DatadogMetricInternal) []string {
 	queries := make([]string, 0, len(datadogMetrics))
 	unique := make(map[string]struct{}, len(queries))
@@ -18,6 +18,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/autoscalers"
 
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
 )
 
 type mockedProcessor struct {
@@ -66,7 +67,7 @@ func (f *metricsFixture) run(t *testing.T, testTime time.Time) {
 		points: f.queryResults,
 		err:    f.queryError,
 	}
-	metricsRetriever, err := NewMetricsRetriever(0, f.maxAge, &mockedProcessor, getIsLeaderFunction(true), &store)
+	metricsRetriever, err := NewMetricsRetriever(0, f.maxAge, &mockedProcessor, nil, getIsLeaderFunction(true), &store)
 	assert.Nil(t, err)
 	metricsRetriever.retrieveMetricsValues()
 
@@ -630,3 +631,121 @@ func TestRetrieveMetricsNotActive(t *testing.T) {
 		})
 	}
 }
+
+type mockedMetricsServer struct {
+	value float64
+	err   error
+}
+
+func (m *mockedMetricsServer) queryPodsUsage(namespace, selector string, resourceName corev1.ResourceName) (float64, error) {
+	return m.value, m.err
+}
+
+func TestRetrieveMetricsFallback(t *testing.T) {
+	defaultTestTime := time.Now().Add(time.Duration(-1) * time.Second).UTC().Truncate(time.Second)
+	lastValidTime := time.Now().Add(-2 * time.Minute).UTC().Truncate(time.Second)
+	backendError := fmt.Errorf(invalidMetricBackendErrorMessage, "query-metric0")
+
+	tests := []struct {
+		desc                string
+		fallback            *model.FallbackPolicy
+		queryResult         autoscalers.Point
+		metricsServer       *mockedMetricsServer
+		expectedValid       bool
+		expectedValue       float64
+		expectedError       error
+		expectedValueSource string
+	}{
+		{
+			desc:                "valid value from Datadog",
+			fallback:            &model.FallbackPolicy{Type: model.FallbackTypeStatic, Value: 5},
+			queryResult:         autoscalers.Point{Value: 10.0, Timestamp: defaultTestTime.Unix(), Valid: true},
+			expectedValid:       true,
+			expectedValue:       10.0,
+			expectedValueSource: model.ValueSourceDatadog,
+		},
+		{
+			desc:                "static fallback",
+			fallback:            &model.FallbackPolicy{Type: model.FallbackTypeStatic, Value: 5},
+			queryResult:         autoscalers.Point{Valid: false},
+			expectedValid:       true,
+			expectedValue:       5.0,
+			expectedError:       backendError,
+			expectedValueSource: model.ValueSourceStatic,
+		},
+		{
+			desc:                "last known good fallback",
+			fallback:            &model.FallbackPolicy{Type: model.FallbackTypeLastKnownGood, MaxAge: 5 * time.Minute},
+			queryResult:         autoscalers.Point{Valid: false},
+			expectedValid:       true,
+			expectedValue:       8.0,
+			expectedError:       backendError,
+			expectedValueSource: model.ValueSourceLastKnownGood,
+		},
+		{
+			desc:                "last known good fallback too old",
+			fallback:            &model.FallbackPolicy{Type: model.FallbackTypeLastKnownGood, MaxAge: time.Minute},
+			queryResult:         autoscalers.Point{Valid: false},
+			expectedValid:       false,
+			expectedValue:       0,
+			expectedError:       backendError,
+			expectedValueSource: model.ValueSourceDatadog,
+		},
+		{
+			desc:                "metrics server fallback",
+			fallback:            &model.FallbackPolicy{Type: model.FallbackTypeMetricsServer, Namespace: "default", Selector: "app=foo", Resource: corev1.ResourceCPU},
+			queryResult:         autoscalers.Point{Valid: false},
+			metricsServer:       &mockedMetricsServer{value: 0.25},
+			expectedValid:       true,
+			expectedValue:       0.25,
+			expectedError:       backendError,
+			expectedValueSource: model.ValueSourceMetricsServer,
+		},
+		{
+			desc:                "metrics server fallback error",
+			fallback:            &model.FallbackPolicy{Type: model.FallbackTypeMetricsServer, Namespace: "default", Selector: "app=foo", Resource: corev1.ResourceCPU},
+			queryResult:         autoscalers.Point{Valid: false},
+			metricsServer:       &mockedMetricsServer{err: fmt.Errorf("metrics server unavailable")},
+			expectedValid:       false,
+			expectedValue:       0,
+			expectedError:       fmt.Errorf("%v, metrics server fallback failed: metrics server unavailable", backendError),
+			expectedValueSource: model.ValueSourceDatadog,
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("#%d %s", i, test.desc), func(t *testing.T) {
+			store := NewDatadogMetricsInternalStore()
+			ddm := model.DatadogMetricInternal{
+				ID:             "metric0",
+				Active:         true,
+				Valid:          true,
+				Value:          8.0,
+				UpdateTime:     lastValidTime,
+				Fallback:       test.fallback,
+				LastValidValue: 8.0,
+				LastValidTime:  lastValidTime,
+			}
+			ddm.SetQueries("query-metric0")
+			store.Set(ddm.ID, ddm, "utest")
+
+			mockedProcessor := mockedProcessor{
+				points: map[string]autoscalers.Point{"query-metric0": test.queryResult},
+			}
+			metricsRetriever, err := NewMetricsRetriever(0, 30, &mockedProcessor, nil, getIsLeaderFunction(true), &store)
+			assert.Nil(t, err)
+			if test.metricsServer != nil {
+				metricsRetriever.metricsServer = test.metricsServer
+			}
+			metricsRetriever.retrieveMetricsValues()
+
+			datadogMetric := store.Get("metric0")
+			assert.Equal(t, test.expectedValid, datadogMetric.Valid)
+			if test.expectedValid {
+				assert.Equal(t, test.expectedValue, datadogMetric.Value)
+			}
+			assert.Equal(t, test.expectedError, datadogMetric.Error)
+			assert.Equal(t, test.expectedValueSource, datadogMetric.ValueSource)
+		})
+	}
+}
@@ -0,0 +1,70 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build kubeapiserver
+// +build kubeapiserver
+
+package externalmetrics
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var podMetricsGVR = schema.GroupVersionResource{Group: "metrics.k8s.io", Version: "v1beta1", Resource: "pods"}
+
+// metricsServerQuerier queries the resource usage of pods from the Kubernetes metrics server
+type metricsServerQuerier interface {
+	queryPodsUsage(namespace, selector string, resourceName corev1.ResourceName) (float64, error)
+}
+
+// dynamicMetricsServerQuerier queries the metrics server through the dynamic client
+type dynamicMetricsServerQuerier struct {
+	client dynamic.Interface
+}
+
+// queryPodsUsage returns the average usage of a resource across the pods matching the
+// selector, in cores for cpu and in bytes for memory
+func (q *dynamicMetricsServerQuerier) queryPodsUsage(namespace, selector string, resourceName corev1.ResourceName) (float64, error) {
+	podMetricsList, err := q.client.Resource(podMetricsGVR).Namespace(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return 0, fmt.Errorf("unable to query the metrics server: %v", err)
+	}
+	if len(podMetricsList.Items) == 0 {
+		return 0, fmt.Errorf("no pod metrics from the metrics server for selector %q in namespace %s", selector, namespace)
+	}
+
+	total := 0.0
+	for _, podMetrics := range podMetricsList.Items {
+		containers, _, err := unstructured.NestedSlice(podMetrics.Object, "containers")
+		if err != nil {
+			return 0, fmt.Errorf("invalid pod metrics %s/%s: %v", namespace, podMetrics.GetName(), err)
+		}
+		for _, container := range containers {
+			containerMap, ok := container.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			usage, found, _ := unstructured.NestedString(containerMap, "usage", string(resourceName))
+			if !found {
+				continue
+			}
+			quantity, err := resource.ParseQuantity(usage)
+			if err != nil {
+				return 0, fmt.Errorf("invalid %s usage %q in pod metrics %s/%s: %v", resourceName, usage, namespace, podMetrics.GetName(), err)
+			}
+			total += quantity.AsApproximateFloat64()
+		}
+	}
+
+	return total / float64(len(podMetricsList.Items)), nil
+}
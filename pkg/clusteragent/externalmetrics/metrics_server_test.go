@@ -0,0 +1,74 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build kubeapiserver
+// +build kubeapiserver
+
+package externalmetrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func newPodMetrics(name string, labels map[string]interface{}, usages ...map[string]interface{}) *unstructured.Unstructured {
+	containers := make([]interface{}, 0, len(usages))
+	for _, usage := range usages {
+		containers = append(containers, map[string]interface{}{"usage": usage})
+	}
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "metrics.k8s.io/v1beta1",
+			"kind":       "PodMetrics",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": "default",
+				"labels":    labels,
+			},
+			"containers": containers,
+		},
+	}
+}
+
+func TestDynamicMetricsServerQuerier(t *testing.T) {
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{podMetricsGVR: "PodMetricsList"})
+	for _, podMetrics := range []*unstructured.Unstructured{
+		newPodMetrics("foo-1", map[string]interface{}{"app": "foo"},
+			map[string]interface{}{"cpu": "100m", "memory": "64Mi"},
+			map[string]interface{}{"cpu": "200m", "memory": "64Mi"},
+		),
+		newPodMetrics("foo-2", map[string]interface{}{"app": "foo"},
+			map[string]interface{}{"cpu": "500m", "memory": "256Mi"},
+		),
+		newPodMetrics("bar-1", map[string]interface{}{"app": "bar"},
+			map[string]interface{}{"cpu": "2", "memory": "1Gi"},
+		),
+	} {
+		// The fake client can't guess the resource of PodMetrics objects passed at creation
+		_, err := client.Resource(podMetricsGVR).Namespace("default").Create(context.TODO(), podMetrics, metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+	querier := &dynamicMetricsServerQuerier{client: client}
+
+	value, err := querier.queryPodsUsage("default", "app=foo", corev1.ResourceCPU)
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.4, value, 0.0001)
+
+	value, err = querier.queryPodsUsage("default", "app=foo", corev1.ResourceMemory)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(192*1024*1024), value)
+
+	_, err = querier.queryPodsUsage("default", "app=baz", corev1.ResourceCPU)
+	assert.EqualError(t, err, `no pod metrics from the metrics server for selector "app=baz" in namespace default`)
+}
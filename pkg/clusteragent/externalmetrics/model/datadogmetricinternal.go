@@ -43,6 +43,14 @@ type DatadogMetricInternal struct {
 	UpdateTime           time.Time
 	Error                error
 	MaxAge               time.Duration
+	// Fallback is the fallback policy used when the query fails or is outdated
+	Fallback *FallbackPolicy
+	// ValueSource is the source of the current value when a fallback policy is set
+	ValueSource string
+	// LastValidValue and LastValidTime hold the last valid value from Datadog,
+	// only tracked when a fallback policy is set
+	LastValidValue float64
+	LastValidTime  time.Time
 }
 
 // NewDatadogMetricInternal returns a `DatadogMetricInternal` object from a `DatadogMetric` CRD Object
@@ -58,14 +66,19 @@ func NewDatadogMetricInternal(id string, datadogMetric datadoghq.DatadogMetric)
 		AutoscalerReferences: datadogMetric.Status.AutoscalerReferences,
 		MaxAge:               datadogMetric.Spec.MaxAge.Duration,
 	}
+	internal.updateFallback(datadogMetric)
 
 	if len(datadogMetric.Spec.ExternalMetricName) > 0 {
 		internal.Autogen = true
 		internal.ExternalMetricName = datadogMetric.Spec.ExternalMetricName
 	}
 
+	var fallbackTransitionTime time.Time
 	for _, condition := range datadogMetric.Status.Conditions {
 		switch {
+		case condition.Type == DatadogMetricConditionTypeFallback && condition.Status == corev1.ConditionTrue:
+			internal.ValueSource = condition.Reason
+			fallbackTransitionTime = condition.LastTransitionTime.UTC()
 		case condition.Type == datadoghq.DatadogMetricConditionTypeValid && condition.Status == corev1.ConditionTrue:
 			internal.Valid = true
 		case condition.Type == datadoghq.DatadogMetricConditionTypeActive && condition.Status == corev1.ConditionTrue:
@@ -95,6 +108,19 @@ func NewDatadogMetricInternal(id string, datadogMetric datadoghq.DatadogMetric)
 	}
 	internal.Value = value
 
+	// Restore the last valid value, the last known good value is valid
+	// until its max age after the fallback became active
+	if internal.Fallback != nil && internal.Valid {
+		switch internal.ValueSource {
+		case "", ValueSourceDatadog:
+			internal.LastValidValue = internal.Value
+			internal.LastValidTime = internal.UpdateTime
+		case ValueSourceLastKnownGood:
+			internal.LastValidValue = internal.Value
+			internal.LastValidTime = fallbackTransitionTime
+		}
+	}
+
 	return internal
 }
 
@@ -127,13 +153,25 @@ func (d *DatadogMetricInternal) RawQuery() string {
 	return d.query
 }
 
-// UpdateFrom updates the `DatadogMetricInternal` from `DatadogMetric` Spec
-func (d *DatadogMetricInternal) UpdateFrom(currentSpec datadoghq.DatadogMetricSpec) {
+// UpdateFrom updates the `DatadogMetricInternal` from `DatadogMetric` Spec and fallback annotations
+func (d *DatadogMetricInternal) UpdateFrom(current datadoghq.DatadogMetric) {
+	currentSpec := current.Spec
 	if d.shouldResolveQuery(currentSpec) {
 		d.resolveQuery(currentSpec.Query)
 	}
 	d.query = currentSpec.Query
 	d.MaxAge = currentSpec.MaxAge.Duration
+	d.updateFallback(current)
+}
+
+// updateFallback sets the fallback policy from the `DatadogMetric` annotations,
+// an invalid policy is ignored
+func (d *DatadogMetricInternal) updateFallback(datadogMetric datadoghq.DatadogMetric) {
+	fallback, err := ParseFallbackPolicy(datadogMetric.Namespace, datadogMetric.Annotations)
+	if err != nil {
+		log.Warnf("Ignoring fallback policy of DatadogMetric %s: %v", d.ID, err)
+	}
+	d.Fallback = fallback
 }
 
 // shouldResolveQuery returns whether we should try to resolve a new query
@@ -168,6 +206,7 @@ func (d *DatadogMetricInternal) BuildStatus(currentStatus *datadoghq.DatadogMetr
 		datadoghq.DatadogMetricConditionTypeValid:   nil,
 		datadoghq.DatadogMetricConditionTypeUpdated: nil,
 		datadoghq.DatadogMetricConditionTypeError:   nil,
+		DatadogMetricConditionTypeFallback:          nil,
 	}
 
 	if currentStatus != nil {
@@ -188,9 +227,22 @@ func (d *DatadogMetricInternal) BuildStatus(currentStatus *datadoghq.DatadogMetr
 		errorCondition.Message = d.Error.Error()
 	}
 
+	conditions := []datadoghq.DatadogMetricCondition{activeCondition, validCondition, updatedCondition, errorCondition}
+	if d.Fallback != nil || existingConditions[DatadogMetricConditionTypeFallback] != nil {
+		// The Fallback condition shows the source of the value
+		fallbackActive := d.Fallback != nil && d.ValueSource != "" && d.ValueSource != ValueSourceDatadog
+		fallbackCondition := d.newCondition(fallbackActive, updateTime, DatadogMetricConditionTypeFallback, existingConditions[DatadogMetricConditionTypeFallback])
+		fallbackCondition.Reason = ValueSourceDatadog
+		if fallbackActive {
+			fallbackCondition.Reason = d.ValueSource
+			fallbackCondition.Message = fmt.Sprintf("Using %s fallback value", d.Fallback.Type)
+		}
+		conditions = append(conditions, fallbackCondition)
+	}
+
 	newStatus := datadoghq.DatadogMetricStatus{
 		Value:                formatDatadogMetricValue(d.Value),
-		Conditions:           []datadoghq.DatadogMetricCondition{activeCondition, validCondition, updatedCondition, errorCondition},
+		Conditions:           conditions,
 		AutoscalerReferences: d.AutoscalerReferences,
 	}
 
@@ -13,6 +13,7 @@ import (
 	"testing"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	datadoghq "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
@@ -145,7 +146,7 @@ func TestDatadogMetricInternal_UpdateFrom(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			tt.ddmInternal.UpdateFrom(tt.newSpec)
+			tt.ddmInternal.UpdateFrom(datadoghq.DatadogMetric{Spec: tt.newSpec})
 			assert.Equal(t, tt.expectedQuery, tt.ddmInternal.query)
 			if tt.expectedResolvedQuery == nil {
 				assert.Nil(t, tt.ddmInternal.resolvedQuery)
@@ -230,3 +231,51 @@ func TestDatadogMetricInternal_resolveQuery(t *testing.T) {
 		})
 	}
 }
+
+func TestDatadogMetricInternal_BuildStatusFallback(t *testing.T) {
+	updateTime := time.Now().UTC().Truncate(time.Second)
+	ddm := DatadogMetricInternal{
+		ID:          "default/dd-metric-0",
+		Valid:       true,
+		Active:      true,
+		Value:       42,
+		Error:       errors.New("Invalid metric (from backend), query: foo"),
+		UpdateTime:  updateTime,
+		Fallback:    &FallbackPolicy{Type: FallbackTypeStatic, Value: 42},
+		ValueSource: ValueSourceStatic,
+	}
+
+	status := ddm.BuildStatus(nil)
+	assert.Equal(t, "42", status.Value)
+	assert.Len(t, status.Conditions, 5)
+	fallbackCondition := status.Conditions[4]
+	assert.Equal(t, DatadogMetricConditionTypeFallback, fallbackCondition.Type)
+	assert.Equal(t, corev1.ConditionTrue, fallbackCondition.Status)
+	assert.Equal(t, ValueSourceStatic, fallbackCondition.Reason)
+	assert.Equal(t, "Using static fallback value", fallbackCondition.Message)
+
+	// Back to Datadog values
+	ddm.Error = nil
+	ddm.ValueSource = ValueSourceDatadog
+	status = ddm.BuildStatus(status)
+	assert.Equal(t, corev1.ConditionFalse, status.Conditions[4].Status)
+	assert.Equal(t, ValueSourceDatadog, status.Conditions[4].Reason)
+
+	// The last known good value is restored from the status
+	lkg := NewDatadogMetricInternal("default/dd-metric-0", datadoghq.DatadogMetric{
+		ObjectMeta: v1.ObjectMeta{
+			Namespace: "default",
+			Annotations: map[string]string{
+				FallbackTypeAnnotation:   "last-known-good",
+				FallbackMaxAgeAnnotation: "5m",
+			},
+		},
+		Status: *status,
+	})
+	assert.Equal(t, 42.0, lkg.LastValidValue)
+	assert.Equal(t, updateTime, lkg.LastValidTime)
+
+	// Without fallback policy, the condition is not created
+	ddm.Fallback = nil
+	assert.Len(t, ddm.BuildStatus(nil).Conditions, 4)
+}
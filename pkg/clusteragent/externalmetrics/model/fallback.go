@@ -0,0 +1,122 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build kubeapiserver
+// +build kubeapiserver
+
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	datadoghq "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// DatadogMetric annotations declaring the fallback policy, used when the query
+// fails or its result is outdated
+const (
+	FallbackTypeAnnotation     = "external-metrics.datadoghq.com/fallback"
+	FallbackMaxAgeAnnotation   = "external-metrics.datadoghq.com/fallback-max-age"
+	FallbackValueAnnotation    = "external-metrics.datadoghq.com/fallback-value"
+	FallbackSelectorAnnotation = "external-metrics.datadoghq.com/fallback-pod-selector"
+	FallbackResourceAnnotation = "external-metrics.datadoghq.com/fallback-resource"
+)
+
+// DatadogMetricConditionTypeFallback is true when the value of a DatadogMetric comes
+// from its fallback policy, the reason of the condition is the active source
+const DatadogMetricConditionTypeFallback datadoghq.DatadogMetricConditionType = "Fallback"
+
+// FallbackType is the type of a fallback policy
+type FallbackType string
+
+// Fallback policy types
+const (
+	// FallbackTypeLastKnownGood keeps the last valid value from Datadog, up to a max age
+	FallbackTypeLastKnownGood FallbackType = "last-known-good"
+	// FallbackTypeStatic uses a static value
+	FallbackTypeStatic FallbackType = "static"
+	// FallbackTypeMetricsServer uses the average resource usage of pods from the Kubernetes metrics server
+	FallbackTypeMetricsServer FallbackType = "metrics-server"
+)
+
+// Sources of the value of a DatadogMetric, reported as reason of the Fallback condition
+const (
+	ValueSourceDatadog       string = "Datadog"
+	ValueSourceLastKnownGood string = "LastKnownGood"
+	ValueSourceStatic        string = "Static"
+	ValueSourceMetricsServer string = "MetricsServer"
+)
+
+// FallbackPolicy holds the fallback policy of a DatadogMetric
+type FallbackPolicy struct {
+	Type FallbackType
+	// MaxAge is the maximum age of the last known good value
+	MaxAge time.Duration
+	// Value is the static value
+	Value float64
+	// Namespace, Selector and Resource select the pods and the resource queried
+	// from the metrics server
+	Namespace string
+	Selector  string
+	Resource  corev1.ResourceName
+}
+
+// Source returns the value source of the fallback policy
+func (p *FallbackPolicy) Source() string {
+	switch p.Type {
+	case FallbackTypeLastKnownGood:
+		return ValueSourceLastKnownGood
+	case FallbackTypeStatic:
+		return ValueSourceStatic
+	case FallbackTypeMetricsServer:
+		return ValueSourceMetricsServer
+	}
+	return ValueSourceDatadog
+}
+
+// ParseFallbackPolicy returns the fallback policy from the annotations of a DatadogMetric,
+// or nil if it doesn't have any
+func ParseFallbackPolicy(namespace string, annotations map[string]string) (*FallbackPolicy, error) {
+	fallbackType, found := annotations[FallbackTypeAnnotation]
+	if !found {
+		return nil, nil
+	}
+
+	policy := &FallbackPolicy{Type: FallbackType(fallbackType)}
+	switch policy.Type {
+	case FallbackTypeLastKnownGood:
+		maxAge, err := time.ParseDuration(annotations[FallbackMaxAgeAnnotation])
+		if err != nil || maxAge <= 0 {
+			return nil, fmt.Errorf("invalid %s annotation %q, a positive duration is required", FallbackMaxAgeAnnotation, annotations[FallbackMaxAgeAnnotation])
+		}
+		policy.MaxAge = maxAge
+	case FallbackTypeStatic:
+		value, err := strconv.ParseFloat(annotations[FallbackValueAnnotation], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s annotation %q: %v", FallbackValueAnnotation, annotations[FallbackValueAnnotation], err)
+		}
+		policy.Value = value
+	case FallbackTypeMetricsServer:
+		selector := annotations[FallbackSelectorAnnotation]
+		if _, err := labels.Parse(selector); err != nil || selector == "" {
+			return nil, fmt.Errorf("invalid %s annotation %q, a label selector is required", FallbackSelectorAnnotation, selector)
+		}
+		resource := corev1.ResourceName(annotations[FallbackResourceAnnotation])
+		if resource != corev1.ResourceCPU && resource != corev1.ResourceMemory {
+			return nil, fmt.Errorf("invalid %s annotation %q, should be either %q or %q", FallbackResourceAnnotation, resource, corev1.ResourceCPU, corev1.ResourceMemory)
+		}
+		policy.Namespace = namespace
+		policy.Selector = selector
+		policy.Resource = resource
+	default:
+		return nil, fmt.Errorf("invalid %s annotation %q, should be either %q, %q or %q", FallbackTypeAnnotation, fallbackType, FallbackTypeLastKnownGood, FallbackTypeStatic, FallbackTypeMetricsServer)
+	}
+	return policy, nil
+}
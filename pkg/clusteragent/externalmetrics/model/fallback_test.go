@@ -0,0 +1,95 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build kubeapiserver
+// +build kubeapiserver
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestParseFallbackPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		expected    *FallbackPolicy
+		expectedErr string
+	}{
+		{
+			name:        "no fallback",
+			annotations: map[string]string{"foo": "bar"},
+			expected:    nil,
+		},
+		{
+			name: "last known good",
+			annotations: map[string]string{
+				FallbackTypeAnnotation:   "last-known-good",
+				FallbackMaxAgeAnnotation: "10m",
+			},
+			expected: &FallbackPolicy{Type: FallbackTypeLastKnownGood, MaxAge: 10 * time.Minute},
+		},
+		{
+			name:        "last known good without max age",
+			annotations: map[string]string{FallbackTypeAnnotation: "last-known-good"},
+			expectedErr: `invalid external-metrics.datadoghq.com/fallback-max-age annotation "", a positive duration is required`,
+		},
+		{
+			name: "static",
+			annotations: map[string]string{
+				FallbackTypeAnnotation:  "static",
+				FallbackValueAnnotation: "42.5",
+			},
+			expected: &FallbackPolicy{Type: FallbackTypeStatic, Value: 42.5},
+		},
+		{
+			name: "static with invalid value",
+			annotations: map[string]string{
+				FallbackTypeAnnotation:  "static",
+				FallbackValueAnnotation: "foo",
+			},
+			expectedErr: `invalid external-metrics.datadoghq.com/fallback-value annotation "foo": strconv.ParseFloat: parsing "foo": invalid syntax`,
+		},
+		{
+			name: "metrics server",
+			annotations: map[string]string{
+				FallbackTypeAnnotation:     "metrics-server",
+				FallbackSelectorAnnotation: "app=nginx",
+				FallbackResourceAnnotation: "cpu",
+			},
+			expected: &FallbackPolicy{Type: FallbackTypeMetricsServer, Namespace: "default", Selector: "app=nginx", Resource: corev1.ResourceCPU},
+		},
+		{
+			name: "metrics server with unsupported resource",
+			annotations: map[string]string{
+				FallbackTypeAnnotation:     "metrics-server",
+				FallbackSelectorAnnotation: "app=nginx",
+				FallbackResourceAnnotation: "ephemeral-storage",
+			},
+			expectedErr: `invalid external-metrics.datadoghq.com/fallback-resource annotation "ephemeral-storage", should be either "cpu" or "memory"`,
+		},
+		{
+			name:        "unknown type",
+			annotations: map[string]string{FallbackTypeAnnotation: "foo"},
+			expectedErr: `invalid external-metrics.datadoghq.com/fallback annotation "foo", should be either "last-known-good", "static" or "metrics-server"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := ParseFallbackPolicy("default", tt.annotations)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, policy)
+		})
+	}
+}
@@ -17,6 +17,8 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
 	jsoniter "github.com/json-iterator/go"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 )
 
@@ -120,6 +122,7 @@ func (p *Processor) Process(ctx *ProcessorContext, list interface{}) (processRes
 	for _, resource := range resourceList {
 		// Scrub before extraction.
 		p.h.ScrubBeforeExtraction(ctx, resource)
+		resource = redactMetadata(ctx, resource)
 
 		// Extract the message model from the resource.
 		resourceMetadataModel := p.h.ExtractResource(ctx, resource)
@@ -152,6 +155,14 @@ func (p *Processor) Process(ctx *ProcessorContext, list interface{}) (processRes
 			continue
 		}
 
+		// Apply the redaction rules to the manifest.
+		if ctx.Cfg.Redactor != nil {
+			if yaml, err = ctx.Cfg.Redactor.RedactManifest(ctx.NodeType.String(), yaml); err != nil {
+				log.Warnf("Unable to redact %s manifest, skipping it: %s", ctx.NodeType, err)
+				continue
+			}
+		}
+
 		// Execute code after marshalling.
 		if skip := p.h.AfterMarshalling(ctx, resource, resourceMetadataModel, yaml); skip {
 			continue
@@ -195,6 +206,22 @@ func (p *Processor) Process(ctx *ProcessorContext, list interface{}) (processRes
 	return processResult, len(resourceMetadataModels)
 }
 
+// redactMetadata applies the redaction rules to the annotations and labels of a
+// resource. Resources come from the informers cache, so they are copied to not
+// alter the labels seen by the other informer consumers.
+func redactMetadata(ctx *ProcessorContext, resource interface{}) interface{} {
+	if !ctx.Cfg.Redactor.HasMetadataRules(ctx.NodeType.String()) {
+		return resource
+	}
+	if obj, ok := resource.(runtime.Object); ok {
+		resource = obj.DeepCopyObject()
+	}
+	if obj, ok := resource.(metav1.Object); ok {
+		ctx.Cfg.Redactor.RedactMetadata(ctx.NodeType.String(), obj)
+	}
+	return resource
+}
+
 // build orchestrator manifest message
 func buildManifestMessageBody(ctx *ProcessorContext, resourceManifests []interface{}, groupSize int) model.MessageBody {
 	manifests := make([]*model.Manifest, 0, len(resourceManifests))
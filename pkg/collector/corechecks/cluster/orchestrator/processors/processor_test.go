@@ -14,6 +14,11 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	model "github.com/DataDog/agent-payload/v5/process"
+
+	"github.com/DataDog/datadog-agent/pkg/orchestrator"
+	"github.com/DataDog/datadog-agent/pkg/orchestrator/config"
+	"github.com/DataDog/datadog-agent/pkg/orchestrator/redact"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -259,3 +264,28 @@ func TestSortedMarshal(t *testing.T) {
 	actualJson := string(json)
 	assert.JSONEq(t, expectedJson, actualJson)
 }
+
+func TestRedactMetadata(t *testing.T) {
+	redactor, err := redact.NewRedactor([]redact.RedactionRule{
+		{Kinds: []string{"Pod"}, AnnotationKeys: []string{"secret/*"}},
+	})
+	assert.NoError(t, err)
+	ctx := &ProcessorContext{
+		Cfg:      &config.OrchestratorConfig{Redactor: redactor},
+		NodeType: orchestrator.K8sPod,
+	}
+
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"secret/token": "foo"}}}
+	redacted := redactMetadata(ctx, pod).(*corev1.Pod)
+	assert.Equal(t, "********", redacted.Annotations["secret/token"])
+	// the resource from the informer cache isn't modified
+	assert.Equal(t, "foo", pod.Annotations["secret/token"])
+
+	// no copy when no rule applies
+	ctx.NodeType = orchestrator.K8sService
+	service := &corev1.Service{}
+	assert.Same(t, service, redactMetadata(ctx, service))
+
+	ctx.Cfg.Redactor = nil
+	assert.Same(t, pod, redactMetadata(ctx, pod))
+}
@@ -0,0 +1,244 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// FIXME: we require the `cgo` build tag because of this dep relationship:
+// github.com/DataDog/datadog-agent/pkg/process/net depends on `github.com/DataDog/agent-payload/v5/process`,
+// which has a hard dependency on `github.com/DataDog/zstd_0`, which requires CGO.
+// Should be removed once `github.com/DataDog/agent-payload/v5/process` can be imported with CGO disabled.
+//go:build cgo && linux
+// +build cgo,linux
+
+package ebpf
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	dd_config "github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/network"
+	process_net "github.com/DataDog/datadog-agent/pkg/process/net"
+	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+	"github.com/DataDog/datadog-agent/pkg/util/containers/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	tcpHealthCheckName = "tcp_health"
+
+	// tcpHealthClientID identifies the check to the network tracer, which
+	// reports the connections seen since the previous request of each client.
+	// The tracer keeps a state for each client, so the check costs as much
+	// memory as the process-agent one: up to
+	// system_probe_config.max_closed_connections_buffered closed connections,
+	// and the stats of the connections reported on the previous run. The
+	// state is dropped two minutes after the check stops running.
+	tcpHealthClientID = "tcp-health-check"
+
+	// unknownRemoteService tags the destinations which weren't resolved
+	// through DNS, so that their IPs don't end up in tag values
+	unknownRemoteService = "unknown"
+
+	pidToContainerCacheDuration = time.Minute
+)
+
+// TCPHealthConfig is the config of the TCP health check
+type TCPHealthConfig struct {
+	CollectTCPHealth bool `yaml:"collect_tcp_health"`
+	// CollectRTT enables the submission of the RTT histogram, which has one
+	// sample per connection
+	CollectRTT bool `yaml:"collect_rtt"`
+}
+
+// tcpHealthSource provides the TCP stats aggregated by system-probe
+type tcpHealthSource interface {
+	GetTCPHealthStats(clientID string) (*network.TCPHealthStats, error)
+}
+
+// TCPHealthCheck aggregates the connections tracked by the network tracer of
+// system-probe into TCP health metrics by destination
+type TCPHealthCheck struct {
+	core.CheckBase
+	instance *TCPHealthConfig
+
+	// the fields below can be overridden in tests
+	source         func() (tcpHealthSource, error)
+	containerIDFor func(pid uint32) string
+	containerTags  func(containerID string) []string
+}
+
+func init() {
+	core.RegisterCheck(tcpHealthCheckName, TCPHealthFactory)
+}
+
+// TCPHealthFactory is exported for integration testing
+func TCPHealthFactory() check.Check {
+	return &TCPHealthCheck{
+		CheckBase:      core.NewCheckBase(tcpHealthCheckName),
+		instance:       &TCPHealthConfig{},
+		source:         remoteTCPHealthSource,
+		containerIDFor: containerIDForPID,
+		containerTags:  containerTags,
+	}
+}
+
+func remoteTCPHealthSource() (tcpHealthSource, error) {
+	return process_net.GetRemoteSystemProbeUtil()
+}
+
+func containerIDForPID(pid uint32) string {
+	containerID, err := metrics.GetProvider().GetMetaCollector().GetContainerIDForPID(int(pid), pidToContainerCacheDuration)
+	if err != nil {
+		log.Debugf("Unable to get the container of pid %d: %s", pid, err)
+		return ""
+	}
+	return containerID
+}
+
+func containerTags(containerID string) []string {
+	tags, err := tagger.Tag(containers.BuildTaggerEntityName(containerID), collectors.HighCardinality)
+	if err != nil {
+		log.Errorf("Error collecting tags for container %s: %s", containerID, err)
+	}
+	return tags
+}
+
+// Parse parses the check configuration and init the check
+func (t *TCPHealthConfig) Parse(data []byte) error {
+	// default values
+	t.CollectTCPHealth = true
+	t.CollectRTT = true
+
+	return yaml.Unmarshal(data, t)
+}
+
+// Configure parses the check configuration and init the check
+func (t *TCPHealthCheck) Configure(config, initConfig integration.Data, source string) error {
+	// TODO: Remove that hard-code and put it somewhere else
+	process_net.SetSystemProbePath(dd_config.Datadog.GetString("system_probe_config.sysprobe_socket"))
+
+	err := t.CommonConfigure(initConfig, config, source)
+	if err != nil {
+		return err
+	}
+
+	return t.instance.Parse(config)
+}
+
+// tcpHealthContext holds the stats of the destinations sharing the same tags,
+// such as the ones of the processes of a container
+type tcpHealthContext struct {
+	tags  []string
+	stats network.TCPDestinationStats
+}
+
+// Run executes the check
+func (t *TCPHealthCheck) Run() error {
+	if !t.instance.CollectTCPHealth {
+		return nil
+	}
+
+	source, err := t.source()
+	if err != nil {
+		return err
+	}
+
+	health, err := source.GetTCPHealthStats(tcpHealthClientID)
+	if err != nil {
+		return err
+	}
+
+	sender, err := t.GetSender()
+	if err != nil {
+		return err
+	}
+
+	for _, ctx := range t.contexts(health) {
+		submitTCPHealthMetrics(sender, ctx, t.instance.CollectRTT)
+	}
+
+	sender.Commit()
+	return nil
+}
+
+// contexts merges the stats of the destinations by tags
+func (t *TCPHealthCheck) contexts(health *network.TCPHealthStats) []*tcpHealthContext {
+	tagsByContainer := make(map[string][]string)
+	byKey := make(map[string]*tcpHealthContext)
+	var keys []string
+
+	for _, dest := range health.Destinations {
+		service := dest.Hostname
+		if service == "" {
+			service = unknownRemoteService
+		}
+		tags := []string{
+			"remote_service:" + service,
+			"remote_port:" + strconv.Itoa(int(dest.DestPort)),
+		}
+
+		if containerID := t.containerIDFor(dest.Pid); containerID != "" {
+			ctrTags, ok := tagsByContainer[containerID]
+			if !ok {
+				ctrTags = t.containerTags(containerID)
+				tagsByContainer[containerID] = ctrTags
+			}
+			tags = append(tags, ctrTags...)
+		}
+
+		sort.Strings(tags)
+		key := strings.Join(tags, ",")
+		ctx, ok := byKey[key]
+		if !ok {
+			ctx = &tcpHealthContext{tags: tags}
+			byKey[key] = ctx
+			keys = append(keys, key)
+		}
+
+		ctx.stats.Connections += dest.Connections
+		ctx.stats.Established += dest.Established
+		ctx.stats.FailedConnects += dest.FailedConnects
+		ctx.stats.Resets += dest.Resets
+		ctx.stats.Retransmits += dest.Retransmits
+		ctx.stats.SentPackets += dest.SentPackets
+		ctx.stats.RTTs = append(ctx.stats.RTTs, dest.RTTs...)
+	}
+
+	contexts := make([]*tcpHealthContext, 0, len(keys))
+	for _, key := range keys {
+		contexts = append(contexts, byKey[key])
+	}
+	return contexts
+}
+
+func submitTCPHealthMetrics(sender aggregator.Sender, ctx *tcpHealthContext, collectRTT bool) {
+	stats := ctx.stats
+	sender.Count("tcp_health.connections", float64(stats.Connections), "", ctx.tags)
+	sender.Count("tcp_health.established", float64(stats.Established), "", ctx.tags)
+	sender.Count("tcp_health.failed_connects", float64(stats.FailedConnects), "", ctx.tags)
+	sender.Count("tcp_health.resets", float64(stats.Resets), "", ctx.tags)
+	sender.Count("tcp_health.retransmits", float64(stats.Retransmits), "", ctx.tags)
+
+	// packets are only counted by the runtime compiled tracer
+	if stats.SentPackets > 0 {
+		sender.Gauge("tcp_health.retransmit_ratio", float64(stats.Retransmits)/float64(stats.SentPackets), "", ctx.tags)
+	}
+
+	if collectRTT {
+		for _, rtt := range stats.RTTs {
+			// RTTs are tracked in microseconds and submitted in milliseconds
+			sender.Histogram("tcp_health.rtt", float64(rtt)/1000.0, "", ctx.tags)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build cgo && linux
+// +build cgo,linux
+
+package ebpf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/network"
+)
+
+type fakeTCPHealthSource struct {
+	stats *network.TCPHealthStats
+}
+
+func (f *fakeTCPHealthSource) GetTCPHealthStats(clientID string) (*network.TCPHealthStats, error) {
+	return f.stats, nil
+}
+
+func TestTCPHealthCheck(t *testing.T) {
+	source := &fakeTCPHealthSource{stats: &network.TCPHealthStats{
+		Destinations: []network.TCPDestinationStats{
+			// two processes of the same container
+			{Pid: 10, Dest: "10.0.0.2", DestPort: 5432, Hostname: "db.example.com", Connections: 2, Established: 2, Retransmits: 3, SentPackets: 100, RTTs: []uint32{1000, 3000}},
+			{Pid: 11, Dest: "10.0.0.2", DestPort: 5432, Hostname: "db.example.com", Connections: 1, FailedConnects: 1, Resets: 1},
+			// a process of the host
+			{Pid: 20, Dest: "10.0.0.3", DestPort: 443, Connections: 1, Established: 1, RTTs: []uint32{500}},
+		},
+	}}
+
+	check := TCPHealthFactory().(*TCPHealthCheck)
+	check.source = func() (tcpHealthSource, error) { return source, nil }
+	check.containerIDFor = func(pid uint32) string {
+		if pid < 20 {
+			return "abcdef"
+		}
+		return ""
+	}
+	check.containerTags = func(containerID string) []string {
+		return []string{"container_id:" + containerID}
+	}
+	require.NoError(t, check.Configure([]byte(``), []byte(``), "test"))
+
+	mockSender := mocksender.NewMockSender(check.ID())
+	mockSender.SetupAcceptAll()
+
+	require.NoError(t, check.Run())
+
+	containerTags := []string{"container_id:abcdef", "remote_port:5432", "remote_service:db.example.com"}
+	mockSender.AssertMetric(t, "Count", "tcp_health.connections", 3, "", containerTags)
+	mockSender.AssertMetric(t, "Count", "tcp_health.established", 2, "", containerTags)
+	mockSender.AssertMetric(t, "Count", "tcp_health.failed_connects", 1, "", containerTags)
+	mockSender.AssertMetric(t, "Count", "tcp_health.resets", 1, "", containerTags)
+	mockSender.AssertMetric(t, "Count", "tcp_health.retransmits", 3, "", containerTags)
+	mockSender.AssertMetric(t, "Gauge", "tcp_health.retransmit_ratio", 0.03, "", containerTags)
+	mockSender.AssertMetric(t, "Histogram", "tcp_health.rtt", 1, "", containerTags)
+	mockSender.AssertMetric(t, "Histogram", "tcp_health.rtt", 3, "", containerTags)
+
+	// destinations which weren't resolved are not tagged with their IP
+	hostTags := []string{"remote_port:443", "remote_service:unknown"}
+	mockSender.AssertMetric(t, "Count", "tcp_health.connections", 1, "", hostTags)
+	mockSender.AssertMetric(t, "Histogram", "tcp_health.rtt", 0.5, "", hostTags)
+	mockSender.AssertNotCalled(t, "Gauge", "tcp_health.retransmit_ratio", mock.Anything, "", hostTags)
+	mockSender.AssertNumberOfCalls(t, "Histogram", 3)
+	mockSender.AssertNumberOfCalls(t, "Gauge", 1)
+}
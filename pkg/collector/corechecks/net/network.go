@@ -24,6 +24,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
 	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/network/dns"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
@@ -83,6 +84,10 @@ type networkInstanceConfig struct {
 	ExcludedInterfaces       []string `yaml:"excluded_interfaces"`
 	ExcludedInterfaceRe      string   `yaml:"excluded_interface_re"`
 	ExcludedInterfacePattern *regexp.Regexp
+	// CollectDNSHealth enables the DNS metrics collected by system-probe,
+	// with the DNSTopFailingDomains domains failing the most for each container
+	CollectDNSHealth     bool `yaml:"collect_dns_health"`
+	DNSTopFailingDomains int  `yaml:"dns_top_failing_domains"`
 }
 
 type networkInitConfig struct{}
@@ -97,6 +102,9 @@ type networkStats interface {
 	ProtoCounters(protocols []string) ([]net.ProtoCountersStat, error)
 	Connections(kind string) ([]net.ConnectionStat, error)
 	NetstatTCPExtCounters() (map[string]int64, error)
+	DNSHealthStats() (*dns.HealthStats, error)
+	ContainerIDsByIP() map[string]string
+	ContainerTags(containerID string) ([]string, error)
 }
 
 type defaultNetworkStats struct{}
@@ -180,6 +188,16 @@ func (c *NetworkCheck) Run() error {
 		submitConnectionsMetrics(sender, "tcp6", tcpStateMetricsSuffixMapping, connectionsStats)
 	}
 
+	if c.config.instance.CollectDNSHealth {
+		// system-probe may not run, which must not prevent the other metrics
+		// from being sent
+		if stats, err := c.net.DNSHealthStats(); err != nil {
+			log.Debugf("Unable to get DNS stats from system-probe: %s", err)
+		} else {
+			c.submitDNSHealthMetrics(sender, stats)
+		}
+	}
+
 	sender.Commit()
 	return nil
 }
@@ -289,6 +307,7 @@ func (c *NetworkCheck) Configure(rawInstance integration.Data, rawInitConfig int
 	if err != nil {
 		return err
 	}
+	c.config.instance.DNSTopFailingDomains = defaultDNSTopFailingDomains
 	err = yaml.Unmarshal(rawInstance, &c.config.instance)
 	if err != nil {
 		return err
@@ -0,0 +1,126 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package net
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/network/dns"
+	process_net "github.com/DataDog/datadog-agent/pkg/process/net"
+	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+const (
+	defaultDNSTopFailingDomains = 10
+
+	// timeoutRcode is the rcode tag of the queries which got no response
+	timeoutRcode = "TIMEOUT"
+)
+
+func (n defaultNetworkStats) DNSHealthStats() (*dns.HealthStats, error) {
+	sysProbeUtil, err := process_net.GetRemoteSystemProbeUtil()
+	if err != nil {
+		return nil, err
+	}
+	return sysProbeUtil.GetDNSHealthStats()
+}
+
+func (n defaultNetworkStats) ContainerIDsByIP() map[string]string {
+	containerIDs := make(map[string]string)
+	for _, container := range workloadmeta.GetGlobalStore().ListContainersWithFilter(workloadmeta.GetRunningContainers) {
+		for _, ip := range container.NetworkIPs {
+			containerIDs[ip] = container.ID
+		}
+	}
+	return containerIDs
+}
+
+func (n defaultNetworkStats) ContainerTags(containerID string) ([]string, error) {
+	return tagger.Tag(containers.BuildTaggerEntityName(containerID), collectors.HighCardinality)
+}
+
+// submitDNSHealthMetrics submits the DNS responses by resolver, and the top
+// failing domains of each container
+func (c *NetworkCheck) submitDNSHealthMetrics(sender aggregator.Sender, stats *dns.HealthStats) {
+	for _, resolver := range stats.Resolvers {
+		tags := []string{"resolver:" + resolver.Resolver}
+		for rcode, count := range resolver.CountByRcode {
+			sender.Count("system.net.dns.responses", float64(count), "", withTag(tags, "rcode:"+rcode))
+		}
+		sender.Count("system.net.dns.timeouts", float64(resolver.Timeouts), "", tags)
+		sender.Count("system.net.dns.truncated_responses", float64(resolver.Truncated), "", tags)
+		if resolver.MaxAnswerTTL > 0 {
+			sender.Gauge("system.net.dns.answer_ttl.min", float64(resolver.MinAnswerTTL), "", tags)
+			sender.Gauge("system.net.dns.answer_ttl.max", float64(resolver.MaxAnswerTTL), "", tags)
+		}
+	}
+
+	// failures are grouped by the container owning the client IP, the ones
+	// of other clients (such as the host) being reported together
+	containerIDs := c.net.ContainerIDsByIP()
+	byContainer := make(map[string][]dns.FailureStats)
+	for _, failure := range stats.Failures {
+		containerID := containerIDs[failure.ClientIP]
+		byContainer[containerID] = append(byContainer[containerID], failure)
+	}
+
+	for containerID, failures := range byContainer {
+		var containerTags []string
+		if containerID != "" {
+			tags, err := c.net.ContainerTags(containerID)
+			if err != nil {
+				log.Debugf("Unable to get tags for container %s: %s", containerID, err)
+			}
+			containerTags = tags
+		}
+
+		for _, failure := range topFailuresByDomain(failures, c.config.instance.DNSTopFailingDomains) {
+			tags := append([]string{"domain:" + failure.Domain}, containerTags...)
+			for rcode, count := range failure.CountByRcode {
+				sender.Count("system.net.dns.domain_failures", float64(count), "", withTag(tags, "rcode:"+rcode))
+			}
+			if failure.Timeouts > 0 {
+				sender.Count("system.net.dns.domain_failures", float64(failure.Timeouts), "", withTag(tags, "rcode:"+timeoutRcode))
+			}
+		}
+	}
+}
+
+// topFailuresByDomain merges the failures of the clients of a container by
+// domain, and returns the n domains with the most failures
+func topFailuresByDomain(failures []dns.FailureStats, n int) []dns.FailureStats {
+	byDomain := make(map[string]*dns.FailureStats)
+	// merged can't grow beyond its capacity, so pointers to its elements
+	// remain valid
+	merged := make([]dns.FailureStats, 0, len(failures))
+	for _, failure := range failures {
+		domain, ok := byDomain[failure.Domain]
+		if !ok {
+			merged = append(merged, dns.FailureStats{
+				Domain:       failure.Domain,
+				CountByRcode: make(map[string]uint32),
+			})
+			domain = &merged[len(merged)-1]
+			byDomain[failure.Domain] = domain
+		}
+		domain.Timeouts += failure.Timeouts
+		for rcode, count := range failure.CountByRcode {
+			domain.CountByRcode[rcode] += count
+		}
+	}
+	return dns.TopFailures(merged, n)
+}
+
+// withTag returns a copy of tags with an additional tag
+func withTag(tags []string, tag string) []string {
+	return append(append(make([]string, 0, len(tags)+1), tags...), tag)
+}
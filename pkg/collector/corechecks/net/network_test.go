@@ -16,6 +16,7 @@ import (
 	"github.com/stretchr/testify/mock"
 
 	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/network/dns"
 )
 
 type fakeNetworkStats struct {
@@ -33,6 +34,9 @@ type fakeNetworkStats struct {
 	connectionStatsTCP6Error    error
 	netstatTCPExtCountersValues map[string]int64
 	netstatTCPExtCountersError  error
+	dnsHealthStats              *dns.HealthStats
+	dnsHealthStatsError         error
+	containerIDsByIP            map[string]string
 }
 
 // IOCounters returns the inner values of counterStats and counterStatsError
@@ -64,6 +68,18 @@ func (n *fakeNetworkStats) NetstatTCPExtCounters() (map[string]int64, error) {
 	return n.netstatTCPExtCountersValues, n.netstatTCPExtCountersError
 }
 
+func (n *fakeNetworkStats) DNSHealthStats() (*dns.HealthStats, error) {
+	return n.dnsHealthStats, n.dnsHealthStatsError
+}
+
+func (n *fakeNetworkStats) ContainerIDsByIP() map[string]string {
+	return n.containerIDsByIP
+}
+
+func (n *fakeNetworkStats) ContainerTags(containerID string) ([]string, error) {
+	return []string{"container_id:" + containerID}, nil
+}
+
 func TestDefaultConfiguration(t *testing.T) {
 	check := NetworkCheck{}
 	check.Configure([]byte(``), []byte(``), "test")
@@ -71,6 +87,8 @@ func TestDefaultConfiguration(t *testing.T) {
 	assert.Equal(t, false, check.config.instance.CollectConnectionState)
 	assert.Equal(t, []string(nil), check.config.instance.ExcludedInterfaces)
 	assert.Equal(t, "", check.config.instance.ExcludedInterfaceRe)
+	assert.Equal(t, false, check.config.instance.CollectDNSHealth)
+	assert.Equal(t, 10, check.config.instance.DNSTopFailingDomains)
 }
 
 func TestConfiguration(t *testing.T) {
@@ -515,3 +533,62 @@ excluded_interface_re: "eth[0-9]"
 	mockSender.AssertCalled(t, "Rate", "system.net.packets_out.drop", float64(32), "", lo0Tags)
 	mockSender.AssertCalled(t, "Rate", "system.net.packets_out.error", float64(33), "", lo0Tags)
 }
+
+func TestDNSHealthMetrics(t *testing.T) {
+	net := &fakeNetworkStats{
+		dnsHealthStats: &dns.HealthStats{
+			Resolvers: []dns.ResolverStats{
+				{
+					Resolver:     "10.0.0.10",
+					CountByRcode: map[string]uint32{"NOERROR": 40, "NXDOMAIN": 6},
+					Timeouts:     2,
+					Truncated:    1,
+					MinAnswerTTL: 5,
+					MaxAnswerTTL: 30,
+				},
+			},
+			Failures: []dns.FailureStats{
+				{ClientIP: "172.17.0.2", Domain: "missing.svc", CountByRcode: map[string]uint32{"NXDOMAIN": 3}},
+				{ClientIP: "172.17.0.3", Domain: "missing.svc", CountByRcode: map[string]uint32{"NXDOMAIN": 2}},
+				{ClientIP: "172.17.0.2", Domain: "flaky.svc", CountByRcode: map[string]uint32{"NXDOMAIN": 1}, Timeouts: 2},
+				{ClientIP: "172.17.0.2", Domain: "rare.svc", CountByRcode: map[string]uint32{"SERVFAIL": 1}},
+				{ClientIP: "10.0.0.1", Domain: "host.svc", CountByRcode: map[string]uint32{"REFUSED": 1}},
+			},
+		},
+		containerIDsByIP: map[string]string{
+			"172.17.0.2": "abc",
+			"172.17.0.3": "abc",
+		},
+	}
+
+	networkCheck := NetworkCheck{
+		net: net,
+	}
+	networkCheck.Configure([]byte(`
+collect_dns_health: true
+dns_top_failing_domains: 2
+`), []byte(``), "test")
+
+	mockSender := mocksender.NewMockSender(networkCheck.ID())
+	mockSender.SetupAcceptAll()
+
+	err := networkCheck.Run()
+	assert.Nil(t, err)
+
+	resolverTags := []string{"resolver:10.0.0.10"}
+	mockSender.AssertCalled(t, "Count", "system.net.dns.responses", float64(40), "", []string{"resolver:10.0.0.10", "rcode:NOERROR"})
+	mockSender.AssertCalled(t, "Count", "system.net.dns.responses", float64(6), "", []string{"resolver:10.0.0.10", "rcode:NXDOMAIN"})
+	mockSender.AssertCalled(t, "Count", "system.net.dns.timeouts", float64(2), "", resolverTags)
+	mockSender.AssertCalled(t, "Count", "system.net.dns.truncated_responses", float64(1), "", resolverTags)
+	mockSender.AssertCalled(t, "Gauge", "system.net.dns.answer_ttl.min", float64(5), "", resolverTags)
+	mockSender.AssertCalled(t, "Gauge", "system.net.dns.answer_ttl.max", float64(30), "", resolverTags)
+
+	// the failures of the clients of a container are merged by domain
+	mockSender.AssertCalled(t, "Count", "system.net.dns.domain_failures", float64(5), "", []string{"domain:missing.svc", "container_id:abc", "rcode:NXDOMAIN"})
+	mockSender.AssertCalled(t, "Count", "system.net.dns.domain_failures", float64(1), "", []string{"domain:flaky.svc", "container_id:abc", "rcode:NXDOMAIN"})
+	mockSender.AssertCalled(t, "Count", "system.net.dns.domain_failures", float64(2), "", []string{"domain:flaky.svc", "container_id:abc", "rcode:TIMEOUT"})
+	// only the top failing domains are reported
+	mockSender.AssertNotCalled(t, "Count", "system.net.dns.domain_failures", float64(1), "", []string{"domain:rare.svc", "container_id:abc", "rcode:SERVFAIL"})
+	// clients outside of containers
+	mockSender.AssertCalled(t, "Count", "system.net.dns.domain_failures", float64(1), "", []string{"domain:host.svc", "rcode:REFUSED"})
+}
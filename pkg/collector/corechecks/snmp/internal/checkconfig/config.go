@@ -65,6 +65,7 @@ type InitConfig struct {
 	OidBatchSize          Number           `yaml:"oid_batch_size"`
 	BulkMaxRepetitions    Number           `yaml:"bulk_max_repetitions"`
 	CollectDeviceMetadata Boolean          `yaml:"collect_device_metadata"`
+	CollectTopology       Boolean          `yaml:"collect_topology"`
 	UseDeviceIDAsHostname Boolean          `yaml:"use_device_id_as_hostname"`
 	MinCollectionInterval int              `yaml:"min_collection_interval"`
 	Namespace             string           `yaml:"namespace"`
@@ -90,6 +91,7 @@ type InstanceConfig struct {
 	Profile               string            `yaml:"profile"`
 	UseGlobalMetrics      bool              `yaml:"use_global_metrics"`
 	CollectDeviceMetadata *Boolean          `yaml:"collect_device_metadata"`
+	CollectTopology       *Boolean          `yaml:"collect_topology"`
 	UseDeviceIDAsHostname *Boolean          `yaml:"use_device_id_as_hostname"`
 
 	// ExtraTags is a workaround to pass tags from snmp listener to snmp integration via AD template
@@ -120,6 +122,10 @@ type InstanceConfig struct {
 	DiscoveryWorkers         int      `yaml:"discovery_workers"`
 	Workers                  int      `yaml:"workers"`
 	Namespace                string   `yaml:"namespace"`
+
+	// DiscoverNeighbors queues the LLDP and CDP neighbors of the discovered devices
+	// for discovery, even if they are outside of the network
+	DiscoverNeighbors bool `yaml:"discover_neighbors"`
 }
 
 // CheckConfig holds config needed for an integration instance to run
@@ -150,6 +156,7 @@ type CheckConfig struct {
 	ExtraTags             []string
 	InstanceTags          []string
 	CollectDeviceMetadata bool
+	CollectTopology       bool
 	UseDeviceIDAsHostname bool
 	DeviceID              string
 	DeviceIDTags          []string
@@ -164,6 +171,7 @@ type CheckConfig struct {
 	DiscoveryInterval        int
 	IgnoredIPAddresses       map[string]bool
 	DiscoveryAllowedFailures int
+	DiscoverNeighbors        bool
 }
 
 // RefreshWithProfile refreshes config based on profile
@@ -178,6 +186,9 @@ func (c *CheckConfig) RefreshWithProfile(profile string) error {
 	c.Profile = profile
 
 	c.Metadata = updateMetadataDefinitionWithLegacyFallback(definition.Metadata)
+	if c.CollectTopology {
+		c.Metadata = updateMetadataDefinitionWithTopology(c.Metadata)
+	}
 	c.Metrics = append(c.Metrics, definition.Metrics...)
 	c.MetricTags = append(c.MetricTags, definition.MetricTags...)
 
@@ -308,6 +319,17 @@ func NewCheckConfig(rawInstance integration.Data, rawInitConfig integration.Data
 		c.CollectDeviceMetadata = bool(initConfig.CollectDeviceMetadata)
 	}
 
+	if instance.CollectTopology != nil {
+		c.CollectTopology = bool(*instance.CollectTopology)
+	} else {
+		c.CollectTopology = bool(initConfig.CollectTopology)
+	}
+
+	c.DiscoverNeighbors = instance.DiscoverNeighbors
+	if c.DiscoverNeighbors && !c.CollectTopology {
+		return nil, fmt.Errorf("`discover_neighbors` requires `collect_topology` to be enabled")
+	}
+
 	if instance.UseDeviceIDAsHostname != nil {
 		c.UseDeviceIDAsHostname = bool(*instance.UseDeviceIDAsHostname)
 	} else {
@@ -441,6 +463,9 @@ func NewCheckConfig(rawInstance integration.Data, rawInitConfig integration.Data
 	c.addUptimeMetric()
 
 	c.Metadata = updateMetadataDefinitionWithLegacyFallback(nil)
+	if c.CollectTopology {
+		c.Metadata = updateMetadataDefinitionWithTopology(c.Metadata)
+	}
 	c.OidConfig.addScalarOids(c.parseScalarOids(c.Metrics, c.MetricTags, c.Metadata))
 	c.OidConfig.addColumnOids(c.parseColumnOids(c.Metrics, c.Metadata))
 
@@ -578,6 +603,7 @@ func (c *CheckConfig) Copy() *CheckConfig {
 	newConfig.ExtraTags = common.CopyStrings(c.ExtraTags)
 	newConfig.InstanceTags = common.CopyStrings(c.InstanceTags)
 	newConfig.CollectDeviceMetadata = c.CollectDeviceMetadata
+	newConfig.CollectTopology = c.CollectTopology
 	newConfig.UseDeviceIDAsHostname = c.UseDeviceIDAsHostname
 	newConfig.DeviceID = c.DeviceID
 
@@ -1065,6 +1065,55 @@ collect_device_metadata: true
 	assert.Equal(t, false, config.CollectDeviceMetadata)
 }
 
+func Test_buildConfig_collectTopology(t *testing.T) {
+	// language=yaml
+	rawInstanceConfig := []byte(`
+ip_address: 1.2.3.4
+community_string: "abc"
+`)
+	config, err := NewCheckConfig(rawInstanceConfig, []byte(``))
+	assert.Nil(t, err)
+	assert.Equal(t, false, config.CollectTopology)
+	assert.NotContains(t, config.OidConfig.ColumnOids, "1.0.8802.1.1.2.1.4.1.1.5")
+
+	// language=yaml
+	rawInstanceConfig = []byte(`
+ip_address: 1.2.3.4
+community_string: "abc"
+collect_topology: true
+`)
+	config, err = NewCheckConfig(rawInstanceConfig, []byte(``))
+	assert.Nil(t, err)
+	assert.Equal(t, true, config.CollectTopology)
+	assert.Contains(t, config.OidConfig.ColumnOids, "1.0.8802.1.1.2.1.4.1.1.5")
+	assert.Contains(t, config.OidConfig.ColumnOids, "1.3.6.1.4.1.9.9.23.1.2.1.1.6")
+	assert.Contains(t, config.Metadata, "interface")
+
+	// language=yaml
+	rawInstanceConfig = []byte(`
+network_address: 10.0.0.0/30
+community_string: "abc"
+discover_neighbors: true
+`)
+	// language=yaml
+	rawInitConfig := []byte(`
+collect_topology: true
+`)
+	config, err = NewCheckConfig(rawInstanceConfig, rawInitConfig)
+	assert.Nil(t, err)
+	assert.Equal(t, true, config.CollectTopology)
+	assert.Equal(t, true, config.DiscoverNeighbors)
+
+	// language=yaml
+	rawInstanceConfig = []byte(`
+network_address: 10.0.0.0/30
+community_string: "abc"
+discover_neighbors: true
+`)
+	_, err = NewCheckConfig(rawInstanceConfig, []byte(``))
+	assert.EqualError(t, err, "`discover_neighbors` requires `collect_topology` to be enabled")
+}
+
 func Test_buildConfig_namespace(t *testing.T) {
 	defer coreconfig.Datadog.Set("network_devices.namespace", "default")
 
@@ -1585,6 +1634,7 @@ func TestCheckConfig_Copy(t *testing.T) {
 		ExtraTags:             []string{"ExtraTags:tag"},
 		InstanceTags:          []string{"InstanceTags:tag"},
 		CollectDeviceMetadata: true,
+		CollectTopology:       true,
 		UseDeviceIDAsHostname: true,
 		DeviceID:              "123",
 		DeviceIDTags:          []string{"DeviceIDTags:tag"},
@@ -1623,6 +1673,7 @@ func TestCheckConfig_Copy(t *testing.T) {
 	assertNotSameButEqualElements(t, config.ExtraTags, configCopy.ExtraTags)
 	assertNotSameButEqualElements(t, config.InstanceTags, configCopy.InstanceTags)
 	assert.Equal(t, config.CollectDeviceMetadata, configCopy.CollectDeviceMetadata)
+	assert.Equal(t, config.CollectTopology, configCopy.CollectTopology)
 	assert.Equal(t, config.UseDeviceIDAsHostname, configCopy.UseDeviceIDAsHostname)
 	assert.Equal(t, config.DeviceID, configCopy.DeviceID)
 	assertNotSameButEqualElements(t, config.DeviceIDTags, configCopy.DeviceIDTags)
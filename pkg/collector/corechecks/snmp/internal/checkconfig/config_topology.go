@@ -0,0 +1,137 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checkconfig
+
+// TopologyMetadataConfig contains the metadata config of the LLDP-MIB and
+// CISCO-CDP-MIB neighbor tables, collected when `collect_topology` is enabled
+var TopologyMetadataConfig = MetadataConfig{
+	// LLDP-MIB::lldpRemTable, indexed by lldpRemTimeMark.lldpRemLocalPortNum.lldpRemIndex
+	"lldp_remote": {
+		Fields: map[string]MetadataField{
+			"chassis_id_type": {
+				Symbol: SymbolConfig{
+					OID:  "1.0.8802.1.1.2.1.4.1.1.4",
+					Name: "lldpRemChassisIdSubtype",
+				},
+			},
+			"chassis_id": {
+				Symbol: SymbolConfig{
+					OID:  "1.0.8802.1.1.2.1.4.1.1.5",
+					Name: "lldpRemChassisId",
+				},
+			},
+			"interface_id_type": {
+				Symbol: SymbolConfig{
+					OID:  "1.0.8802.1.1.2.1.4.1.1.6",
+					Name: "lldpRemPortIdSubtype",
+				},
+			},
+			"interface_id": {
+				Symbol: SymbolConfig{
+					OID:  "1.0.8802.1.1.2.1.4.1.1.7",
+					Name: "lldpRemPortId",
+				},
+			},
+			"interface_desc": {
+				Symbol: SymbolConfig{
+					OID:  "1.0.8802.1.1.2.1.4.1.1.8",
+					Name: "lldpRemPortDesc",
+				},
+			},
+			"device_name": {
+				Symbol: SymbolConfig{
+					OID:  "1.0.8802.1.1.2.1.4.1.1.9",
+					Name: "lldpRemSysName",
+				},
+			},
+			"device_desc": {
+				Symbol: SymbolConfig{
+					OID:  "1.0.8802.1.1.2.1.4.1.1.10",
+					Name: "lldpRemSysDesc",
+				},
+			},
+		},
+	},
+	// LLDP-MIB::lldpRemManAddrTable, the management address is part of the index
+	"lldp_remote_management": {
+		Fields: map[string]MetadataField{
+			"interface_id_type": {
+				Symbol: SymbolConfig{
+					OID:  "1.0.8802.1.1.2.1.4.2.1.3",
+					Name: "lldpRemManAddrIfSubtype",
+				},
+			},
+		},
+	},
+	// LLDP-MIB::lldpLocPortTable, indexed by lldpLocPortNum
+	"lldp_local": {
+		Fields: map[string]MetadataField{
+			"interface_id_type": {
+				Symbol: SymbolConfig{
+					OID:  "1.0.8802.1.1.2.1.3.7.1.2",
+					Name: "lldpLocPortIdSubtype",
+				},
+			},
+			"interface_id": {
+				Symbol: SymbolConfig{
+					OID:  "1.0.8802.1.1.2.1.3.7.1.3",
+					Name: "lldpLocPortId",
+				},
+			},
+		},
+	},
+	// CISCO-CDP-MIB::cdpCacheTable, indexed by cdpCacheIfIndex.cdpCacheDeviceIndex
+	"cdp_remote": {
+		Fields: map[string]MetadataField{
+			"address_type": {
+				Symbol: SymbolConfig{
+					OID:  "1.3.6.1.4.1.9.9.23.1.2.1.1.3",
+					Name: "cdpCacheAddressType",
+				},
+			},
+			"address": {
+				Symbol: SymbolConfig{
+					OID:  "1.3.6.1.4.1.9.9.23.1.2.1.1.4",
+					Name: "cdpCacheAddress",
+				},
+			},
+			"device_id": {
+				Symbol: SymbolConfig{
+					OID:  "1.3.6.1.4.1.9.9.23.1.2.1.1.6",
+					Name: "cdpCacheDeviceId",
+				},
+			},
+			"interface_id": {
+				Symbol: SymbolConfig{
+					OID:  "1.3.6.1.4.1.9.9.23.1.2.1.1.7",
+					Name: "cdpCacheDevicePort",
+				},
+			},
+			"device_platform": {
+				Symbol: SymbolConfig{
+					OID:  "1.3.6.1.4.1.9.9.23.1.2.1.1.8",
+					Name: "cdpCachePlatform",
+				},
+			},
+		},
+	},
+}
+
+// updateMetadataDefinitionWithTopology returns a copy of the metadata config with
+// the config of the neighbor tables, without overriding the resources defined by
+// the profile. The config is copied since it might be shared by profiles.
+func updateMetadataDefinitionWithTopology(config MetadataConfig) MetadataConfig {
+	newConfig := make(MetadataConfig, len(config)+len(TopologyMetadataConfig))
+	for resourceName, resourceConfig := range config {
+		newConfig[resourceName] = resourceConfig
+	}
+	for resourceName, resourceConfig := range TopologyMetadataConfig {
+		if _, ok := newConfig[resourceName]; !ok {
+			newConfig[resourceName] = resourceConfig
+		}
+	}
+	return newConfig
+}
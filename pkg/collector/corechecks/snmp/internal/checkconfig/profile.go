@@ -146,6 +146,16 @@ func readProfileDefinition(definitionFile string) (*profileDefinition, error) {
 	return profileDefinition, nil
 }
 
+// ValidateProfileDefinitionFile reads a profile definition file and the profiles
+// it extends, and returns the errors found
+func ValidateProfileDefinitionFile(definitionFile string) error {
+	profileDefinition, err := readProfileDefinition(definitionFile)
+	if err != nil {
+		return err
+	}
+	return recursivelyExpandBaseProfiles(profileDefinition, profileDefinition.Extends, []string{})
+}
+
 func resolveProfileDefinitionPath(definitionFile string) string {
 	if filepath.IsAbs(definitionFile) {
 		return definitionFile
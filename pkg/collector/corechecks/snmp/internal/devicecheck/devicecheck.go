@@ -42,6 +42,7 @@ type DeviceCheck struct {
 	sender           *report.MetricSender
 	session          session.Session
 	savedDynamicTags []string
+	neighborIPs      []string
 }
 
 // NewDeviceCheck returns a new DeviceCheck
@@ -74,6 +75,28 @@ func (d *DeviceCheck) GetIDTags() []string {
 	return d.config.DeviceIDTags
 }
 
+// GetNeighborIPAddresses returns the IP addresses of the LLDP and CDP neighbors
+// found by the last run
+func (d *DeviceCheck) GetNeighborIPAddresses() []string {
+	return d.neighborIPs
+}
+
+func getNeighborIPAddresses(links []metadata.TopologyLinkMetadata) []string {
+	var ipAddresses []string
+	seen := make(map[string]bool)
+	for _, link := range links {
+		if link.Remote == nil || link.Remote.Device == nil {
+			continue
+		}
+		ipAddress := link.Remote.Device.IPAddress
+		if ipAddress != "" && !seen[ipAddress] {
+			seen[ipAddress] = true
+			ipAddresses = append(ipAddresses, ipAddress)
+		}
+	}
+	return ipAddresses
+}
+
 // GetDeviceHostname returns DeviceID as hostname if UseDeviceIDAsHostname is true
 func (d *DeviceCheck) GetDeviceHostname() (string, error) {
 	if d.config.UseDeviceIDAsHostname {
@@ -123,7 +146,8 @@ func (d *DeviceCheck) Run(collectionTime time.Time) error {
 		deviceMetadataTags := append(common.CopyStrings(tags), d.config.InstanceTags...)
 		deviceMetadataTags = append(deviceMetadataTags, common.GetAgentVersionTag())
 
-		d.sender.ReportNetworkDeviceMetadata(d.config, values, deviceMetadataTags, collectionTime, deviceStatus)
+		links := d.sender.ReportNetworkDeviceMetadata(d.config, values, deviceMetadataTags, collectionTime, deviceStatus)
+		d.neighborIPs = getNeighborIPAddresses(links)
 	}
 
 	d.submitTelemetryMetrics(startTime, tags)
@@ -21,6 +21,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/common"
 	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/checkconfig"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/metadata"
 	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/report"
 	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/session"
 	"github.com/DataDog/datadog-agent/pkg/snmp/gosnmplib"
@@ -644,3 +645,14 @@ profiles:
 	assert.Error(t, err, "some error")
 	sender.Mock.AssertCalled(t, "ServiceCheck", "snmp.can_check", metrics.ServiceCheckCritical, "", mocksender.MatchTagsContains(snmpTags), "snmp connection error: some error")
 }
+
+func Test_getNeighborIPAddresses(t *testing.T) {
+	links := []metadata.TopologyLinkMetadata{
+		{Remote: &metadata.TopologyLinkSide{Device: &metadata.TopologyLinkDevice{IPAddress: "10.0.0.2"}}},
+		{Remote: &metadata.TopologyLinkSide{Device: &metadata.TopologyLinkDevice{Name: "no-ip"}}},
+		{Remote: &metadata.TopologyLinkSide{Device: &metadata.TopologyLinkDevice{IPAddress: "10.0.0.3"}}},
+		{Remote: &metadata.TopologyLinkSide{Device: &metadata.TopologyLinkDevice{IPAddress: "10.0.0.2"}}},
+		{},
+	}
+	assert.Equal(t, []string{"10.0.0.2", "10.0.0.3"}, getNeighborIPAddresses(links))
+}
@@ -9,6 +9,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net"
+	"sort"
 	"sync"
 	"time"
 
@@ -23,6 +24,10 @@ import (
 const cacheKeyPrefix = "snmp"
 const sysObjectIDOid = "1.3.6.1.2.1.1.2.0"
 
+// maxPendingNeighbors bounds the number of neighbors queued for discovery
+// at once, so that crawling through the neighbors of the devices stays bounded
+const maxPendingNeighbors = 100
+
 // Discovery handles snmp discovery states
 type Discovery struct {
 	config    *checkconfig.CheckConfig
@@ -34,6 +39,14 @@ type Discovery struct {
 	// see also CheckConfig.DeviceDigest()
 	discoveredDevices map[checkconfig.DeviceDigest]Device
 
+	// pendingNeighbors contains the IP addresses of device neighbors queued for discovery
+	// failedNeighbors contains the IP addresses of device neighbors which failed to answer
+	// since the last discovery run, they are not queued again until the next discovery run
+	neighborsMu      sync.Mutex
+	pendingNeighbors map[string]bool
+	failedNeighbors  map[string]bool
+	neighborsQueued  chan struct{}
+
 	sessionFactory session.Factory
 }
 
@@ -62,6 +75,7 @@ type snmpSubnet struct {
 type checkDeviceJob struct {
 	subnet    *snmpSubnet
 	currentIP net.IP
+	neighbor  bool
 }
 
 // Start discovery
@@ -141,6 +155,10 @@ func (d *Discovery) discoverDevices() {
 
 	for {
 		log.Debugf("subnet %s: Run discovery", d.config.Network)
+		d.neighborsMu.Lock()
+		d.failedNeighbors = make(map[string]bool)
+		d.neighborsMu.Unlock()
+
 		startingIP := make(net.IP, len(subnet.startingIP))
 		copy(startingIP, subnet.startingIP)
 		for currentIP := startingIP; subnet.network.Contains(currentIP); incrementIP(currentIP) {
@@ -165,13 +183,109 @@ func (d *Discovery) discoverDevices() {
 			}
 		}
 
+		if stopped := d.scheduleDevicesOutsideNetwork(&subnet, jobs); stopped {
+			return
+		}
+
+	waitLoop:
+		for {
+			select {
+			case <-d.stop:
+				log.Debugf("subnet %s: Stop scheduling devices", d.config.Network)
+				return
+			case <-d.neighborsQueued:
+				if stopped := d.scheduleNeighbors(&subnet, jobs); stopped {
+					return
+				}
+			case <-discoveryTicker.C:
+				break waitLoop
+			}
+		}
+	}
+}
+
+// QueueNeighbors queues the IP addresses of the neighbors of discovered devices
+// for discovery. Ignored and already discovered devices are skipped, as well as
+// the neighbors which failed to answer since the last discovery run. At most
+// maxPendingNeighbors neighbors are queued at once.
+func (d *Discovery) QueueNeighbors(ipAddresses []string) {
+	var queued bool
+
+	d.discDevMu.RLock()
+	d.neighborsMu.Lock()
+	for _, ipAddress := range ipAddresses {
+		if len(d.pendingNeighbors) >= maxPendingNeighbors {
+			log.Debugf("subnet %s: Too many pending neighbors, skipping the remaining ones", d.config.Network)
+			break
+		}
+		ip := net.ParseIP(ipAddress)
+		if ip == nil || d.config.IsIPIgnored(ip) || d.pendingNeighbors[ip.String()] || d.failedNeighbors[ip.String()] {
+			continue
+		}
+		if _, present := d.discoveredDevices[d.config.DeviceDigest(ip.String())]; present {
+			continue
+		}
+		d.pendingNeighbors[ip.String()] = true
+		queued = true
+	}
+	d.neighborsMu.Unlock()
+	d.discDevMu.RUnlock()
+
+	if queued {
+		select {
+		case d.neighborsQueued <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// scheduleNeighbors schedules the check of the queued neighbors, and returns
+// true if the discovery has been stopped
+func (d *Discovery) scheduleNeighbors(subnet *snmpSubnet, jobs chan<- checkDeviceJob) bool {
+	d.neighborsMu.Lock()
+	ipAddresses := make([]string, 0, len(d.pendingNeighbors))
+	for ipAddress := range d.pendingNeighbors {
+		ipAddresses = append(ipAddresses, ipAddress)
+	}
+	d.pendingNeighbors = make(map[string]bool)
+	d.neighborsMu.Unlock()
+
+	sort.Strings(ipAddresses)
+	log.Debugf("subnet %s: Scheduling neighbors %v", d.config.Network, ipAddresses)
+	return d.scheduleDevices(subnet, ipAddresses, true, jobs)
+}
+
+// scheduleDevicesOutsideNetwork schedules the check of the devices discovered as
+// neighbors outside of the network, and returns true if the discovery has been stopped
+func (d *Discovery) scheduleDevicesOutsideNetwork(subnet *snmpSubnet, jobs chan<- checkDeviceJob) bool {
+	var ipAddresses []string
+	d.discDevMu.RLock()
+	for _, ipAddress := range subnet.devices {
+		if ip := net.ParseIP(ipAddress); ip != nil && !subnet.network.Contains(ip) {
+			ipAddresses = append(ipAddresses, ipAddress)
+		}
+	}
+	d.discDevMu.RUnlock()
+
+	sort.Strings(ipAddresses)
+	return d.scheduleDevices(subnet, ipAddresses, false, jobs)
+}
+
+func (d *Discovery) scheduleDevices(subnet *snmpSubnet, ipAddresses []string, neighbor bool, jobs chan<- checkDeviceJob) bool {
+	for _, ipAddress := range ipAddresses {
+		job := checkDeviceJob{
+			subnet:    subnet,
+			currentIP: net.ParseIP(ipAddress),
+			neighbor:  neighbor,
+		}
 		select {
 		case <-d.stop:
 			log.Debugf("subnet %s: Stop scheduling devices", d.config.Network)
-			return
-		case <-discoveryTicker.C:
+			return true
+		case jobs <- job:
 		}
 	}
+	return false
 }
 
 func (d *Discovery) checkDevice(job checkDeviceJob) error {
@@ -186,6 +300,7 @@ func (d *Discovery) checkDevice(job checkDeviceJob) error {
 	if err := sess.Connect(); err != nil {
 		log.Debugf("subnet %s: SNMP connect to %s error: %v", d.config.Network, deviceIP, err)
 		d.deleteDevice(deviceDigest, job.subnet)
+		d.neighborFailed(job)
 	} else {
 		defer sess.Close()
 
@@ -197,9 +312,11 @@ func (d *Discovery) checkDevice(job checkDeviceJob) error {
 		if err != nil {
 			log.Debugf("subnet %s: SNMP get to %s error: %v", d.config.Network, deviceIP, err)
 			d.deleteDevice(deviceDigest, job.subnet)
+			d.neighborFailed(job)
 		} else if len(value.Variables) < 1 || value.Variables[0].Value == nil {
 			log.Debugf("subnet %s: SNMP get to %s no data", d.config.Network, deviceIP)
 			d.deleteDevice(deviceDigest, job.subnet)
+			d.neighborFailed(job)
 		} else {
 			log.Debugf("subnet %s: SNMP get to %s success: %v", d.config.Network, deviceIP, value.Variables[0].Value)
 			d.createDevice(deviceDigest, job.subnet, deviceIP, true)
@@ -208,6 +325,17 @@ func (d *Discovery) checkDevice(job checkDeviceJob) error {
 	return nil
 }
 
+// neighborFailed remembers that a neighbor failed to answer, so that it is not
+// queued again until the next discovery run
+func (d *Discovery) neighborFailed(job checkDeviceJob) {
+	if !job.neighbor {
+		return
+	}
+	d.neighborsMu.Lock()
+	defer d.neighborsMu.Unlock()
+	d.failedNeighbors[job.currentIP.String()] = true
+}
+
 func (d *Discovery) createDevice(deviceDigest checkconfig.DeviceDigest, subnet *snmpSubnet, deviceIP string, writeCache bool) {
 	deviceCk, err := devicecheck.NewDeviceCheck(subnet.config, deviceIP, d.sessionFactory)
 	if err != nil {
@@ -310,6 +438,9 @@ func (d *Discovery) writeCache(subnet *snmpSubnet) {
 func NewDiscovery(config *checkconfig.CheckConfig, sessionFactory session.Factory) Discovery {
 	return Discovery{
 		discoveredDevices: make(map[checkconfig.DeviceDigest]Device),
+		pendingNeighbors:  make(map[string]bool),
+		failedNeighbors:   make(map[string]bool),
+		neighborsQueued:   make(chan struct{}, 1),
 		stop:              make(chan struct{}),
 		config:            config,
 		sessionFactory:    sessionFactory,
@@ -155,6 +155,102 @@ func TestDiscoveryCache(t *testing.T) {
 	assert.ElementsMatch(t, expectedDiscoveredIps, actualDiscoveredIpsFromCache)
 }
 
+func TestDiscoveryNeighbors(t *testing.T) {
+	path, _ := filepath.Abs(filepath.Join(".", "test", "run_path", "TestDiscoveryNeighbors"))
+	config.Datadog.Set("run_path", path)
+
+	sess := session.CreateMockSession()
+	sessionFactory := func(*checkconfig.CheckConfig) (session.Session, error) {
+		return sess, nil
+	}
+
+	packet := gosnmp.SnmpPacket{
+		Variables: []gosnmp.SnmpPDU{
+			{
+				Name:  "1.3.6.1.2.1.1.2.0",
+				Type:  gosnmp.ObjectIdentifier,
+				Value: "1.3.6.1.4.1.3375.2.1.3.4.1",
+			},
+		},
+	}
+	sess.On("Get", []string{"1.3.6.1.2.1.1.2.0"}).Return(&packet, nil)
+
+	// the seed device
+	checkConfig := &checkconfig.CheckConfig{
+		Network:            "192.168.0.1/32",
+		CommunityString:    "public",
+		DiscoveryInterval:  3600,
+		DiscoveryWorkers:   1,
+		IgnoredIPAddresses: map[string]bool{"10.0.0.3": true},
+	}
+	discovery := NewDiscovery(checkConfig, sessionFactory)
+	discovery.Start()
+	assert.NoError(t, waitForDiscoveredDevices(&discovery, 1, 2*time.Second))
+
+	// neighbors outside of the network are discovered, ignored and invalid IPs are skipped
+	discovery.QueueNeighbors([]string{"192.168.0.1", "10.0.0.2", "10.0.0.3", "invalid"})
+	assert.NoError(t, waitForDiscoveredDevices(&discovery, 2, 2*time.Second))
+	discovery.Stop()
+
+	var actualDiscoveredIps []string
+	for _, deviceCk := range discovery.GetDiscoveredDeviceConfigs() {
+		actualDiscoveredIps = append(actualDiscoveredIps, deviceCk.GetIPAddress())
+	}
+	assert.ElementsMatch(t, []string{"192.168.0.1", "10.0.0.2"}, actualDiscoveredIps)
+	discovery.neighborsMu.Lock()
+	assert.Empty(t, discovery.pendingNeighbors)
+	discovery.neighborsMu.Unlock()
+}
+
+func TestDiscoveryNeighborsBounded(t *testing.T) {
+	SetTestRunPath()
+	checkConfig := &checkconfig.CheckConfig{
+		Network:           "192.168.0.0/32",
+		CommunityString:   "public",
+		DiscoveryInterval: 3600,
+		DiscoveryWorkers:  1,
+	}
+	_, ipNet, err := net.ParseCIDR(checkConfig.Network)
+	assert.Nil(t, err)
+	subnet := snmpSubnet{
+		config:         checkConfig,
+		network:        *ipNet,
+		cacheKey:       "abc:123",
+		devices:        map[checkconfig.DeviceDigest]string{},
+		deviceFailures: map[checkconfig.DeviceDigest]int{},
+	}
+
+	sess := session.CreateMockSession()
+	sess.ConnectErr = fmt.Errorf("connection error")
+	discovery := NewDiscovery(checkConfig, func(*checkconfig.CheckConfig) (session.Session, error) {
+		return sess, nil
+	})
+
+	// a neighbor which doesn't answer is not queued again until the next discovery run
+	discovery.QueueNeighbors([]string{"10.0.0.2"})
+	assert.Equal(t, map[string]bool{"10.0.0.2": true}, discovery.pendingNeighbors)
+	discovery.pendingNeighbors = make(map[string]bool)
+	err = discovery.checkDevice(checkDeviceJob{subnet: &subnet, currentIP: net.ParseIP("10.0.0.2"), neighbor: true})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]bool{"10.0.0.2": true}, discovery.failedNeighbors)
+	discovery.QueueNeighbors([]string{"10.0.0.2"})
+	assert.Empty(t, discovery.pendingNeighbors)
+
+	// devices of the network are not remembered as failed neighbors
+	err = discovery.checkDevice(checkDeviceJob{subnet: &subnet, currentIP: net.ParseIP("192.168.0.0")})
+	assert.Nil(t, err)
+	assert.Len(t, discovery.failedNeighbors, 1)
+
+	// the number of pending neighbors is capped
+	var ipAddresses []string
+	for i := 0; i < 2*maxPendingNeighbors; i++ {
+		ipAddresses = append(ipAddresses, fmt.Sprintf("10.1.%d.%d", i/256, i%256))
+	}
+	discovery.QueueNeighbors(ipAddresses)
+	assert.Len(t, discovery.pendingNeighbors, maxPendingNeighbors)
+	assert.True(t, discovery.pendingNeighbors["10.1.0.0"])
+}
+
 func TestDiscoveryTicker(t *testing.T) {
 	t.Skip() // TODO: FIX ME, currently this test is leading to data race when ran with other tests
 
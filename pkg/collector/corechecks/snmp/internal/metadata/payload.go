@@ -21,11 +21,12 @@ const (
 
 // NetworkDevicesMetadata contains network devices metadata
 type NetworkDevicesMetadata struct {
-	Subnet           string              `json:"subnet"`
-	Namespace        string              `json:"namespace"`
-	Devices          []DeviceMetadata    `json:"devices,omitempty"`
-	Interfaces       []InterfaceMetadata `json:"interfaces,omitempty"`
-	CollectTimestamp int64               `json:"collect_timestamp"`
+	Subnet           string                 `json:"subnet"`
+	Namespace        string                 `json:"namespace"`
+	Devices          []DeviceMetadata       `json:"devices,omitempty"`
+	Interfaces       []InterfaceMetadata    `json:"interfaces,omitempty"`
+	Links            []TopologyLinkMetadata `json:"links,omitempty"`
+	CollectTimestamp int64                  `json:"collect_timestamp"`
 }
 
 // DeviceMetadata contains device metadata
@@ -63,3 +64,36 @@ type InterfaceMetadata struct {
 	AdminStatus int32    `json:"admin_status,omitempty"` // IF-MIB ifAdminStatus type is INTEGER
 	OperStatus  int32    `json:"oper_status,omitempty"`  // IF-MIB ifOperStatus type is INTEGER
 }
+
+// TopologyLinkMetadata contains a link between a local interface and a remote
+// device, discovered in the LLDP or CDP neighbors of the device
+type TopologyLinkMetadata struct {
+	ID         string            `json:"id"`
+	SourceType string            `json:"source_type"`
+	Local      *TopologyLinkSide `json:"local"`
+	Remote     *TopologyLinkSide `json:"remote"`
+}
+
+// TopologyLinkSide contains the device and interface of one end of a link
+type TopologyLinkSide struct {
+	Device    *TopologyLinkDevice    `json:"device,omitempty"`
+	Interface *TopologyLinkInterface `json:"interface,omitempty"`
+}
+
+// TopologyLinkDevice contains the device of a link side
+type TopologyLinkDevice struct {
+	DeviceID    string `json:"dd_id,omitempty"` // device ID of the device when it is monitored
+	ID          string `json:"id,omitempty"`
+	IDType      string `json:"id_type,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	IPAddress   string `json:"ip_address,omitempty"`
+}
+
+// TopologyLinkInterface contains the interface of a link side
+type TopologyLinkInterface struct {
+	DeviceID    string `json:"dd_id,omitempty"` // `<device ID>:<ifIndex>` of the interface when it is monitored
+	ID          string `json:"id"`
+	IDType      string `json:"id_type,omitempty"`
+	Description string `json:"description,omitempty"`
+}
@@ -20,6 +20,7 @@ var bandwidthMetricNameToUsage = map[string]string{
 }
 
 const ifHighSpeedOID = "1.3.6.1.2.1.31.1.1.1.15"
+const ifSpeedOID = "1.3.6.1.2.1.2.2.1.5"
 
 func (ms *MetricSender) trySendBandwidthUsageMetric(symbol checkconfig.SymbolConfig, fullIndex string, values *valuestore.ResultValueStore, tags []string) {
 	err := ms.sendBandwidthUsageMetric(symbol, fullIndex, values, tags)
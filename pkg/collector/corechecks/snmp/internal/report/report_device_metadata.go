@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/epforwarder"
+	"github.com/DataDog/datadog-agent/pkg/snmp/interfacestore"
 	"github.com/DataDog/datadog-agent/pkg/util"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
@@ -21,8 +22,8 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/valuestore"
 )
 
-// ReportNetworkDeviceMetadata reports device metadata
-func (ms *MetricSender) ReportNetworkDeviceMetadata(config *checkconfig.CheckConfig, store *valuestore.ResultValueStore, origTags []string, collectTime time.Time, deviceStatus metadata.DeviceStatus) {
+// ReportNetworkDeviceMetadata reports device metadata, and returns the topology links of the device
+func (ms *MetricSender) ReportNetworkDeviceMetadata(config *checkconfig.CheckConfig, store *valuestore.ResultValueStore, origTags []string, collectTime time.Time, deviceStatus metadata.DeviceStatus) []metadata.TopologyLinkMetadata {
 	tags := common.CopyStrings(origTags)
 	tags = util.SortUniqInPlace(tags)
 
@@ -31,17 +32,26 @@ func (ms *MetricSender) ReportNetworkDeviceMetadata(config *checkconfig.CheckCon
 	device := buildNetworkDeviceMetadata(config.DeviceID, config.DeviceIDTags, config, metadataStore, tags, deviceStatus)
 
 	interfaces := buildNetworkInterfacesMetadata(config.DeviceID, metadataStore)
+	if len(interfaces) > 0 {
+		interfacestore.DefaultStore.SetDeviceInterfaces(config.Namespace, config.IPAddress, buildStoreInterfaces(interfaces, store))
+	}
+
+	var links []metadata.TopologyLinkMetadata
+	if config.CollectTopology {
+		links = buildNetworkTopologyMetadata(config.DeviceID, metadataStore, interfaces)
+	}
 
-	metadataPayloads := batchPayloads(config.Namespace, config.ResolvedSubnetName, collectTime, metadata.PayloadMetadataBatchSize, device, interfaces)
+	metadataPayloads := batchPayloads(config.Namespace, config.ResolvedSubnetName, collectTime, metadata.PayloadMetadataBatchSize, device, interfaces, links)
 
 	for _, payload := range metadataPayloads {
 		payloadBytes, err := json.Marshal(payload)
 		if err != nil {
 			log.Errorf("Error marshalling device metadata: %s", err)
-			return
+			return links
 		}
 		ms.sender.EventPlatformEvent(string(payloadBytes), epforwarder.EventTypeNetworkDevicesMetadata)
 	}
+	return links
 }
 
 func buildMetadataStore(metadataConfigs checkconfig.MetadataConfig, values *valuestore.ResultValueStore) *metadata.Store {
@@ -188,7 +198,37 @@ func buildNetworkInterfacesMetadata(deviceID string, store *metadata.Store) []me
 	return interfaces
 }
 
-func batchPayloads(namespace string, subnet string, collectTime time.Time, batchSize int, device metadata.DeviceMetadata, interfaces []metadata.InterfaceMetadata) []metadata.NetworkDevicesMetadata {
+// buildStoreInterfaces returns the interfaces shared with the other network devices
+// components, with their speed when `ifHighSpeed` or `ifSpeed` is collected
+func buildStoreInterfaces(interfaces []metadata.InterfaceMetadata, values *valuestore.ResultValueStore) []interfacestore.Interface {
+	highSpeedValues, _ := values.GetColumnValues(ifHighSpeedOID)
+	speedValues, _ := values.GetColumnValues(ifSpeedOID)
+
+	storeInterfaces := make([]interfacestore.Interface, 0, len(interfaces))
+	for _, iface := range interfaces {
+		strIndex := strconv.Itoa(int(iface.Index))
+		var speed uint64
+		if value, ok := highSpeedValues[strIndex]; ok {
+			if floatValue, err := value.ToFloat64(); err == nil {
+				speed = uint64(floatValue * 1e6)
+			}
+		}
+		if value, ok := speedValues[strIndex]; ok && speed == 0 {
+			if floatValue, err := value.ToFloat64(); err == nil {
+				speed = uint64(floatValue)
+			}
+		}
+		storeInterfaces = append(storeInterfaces, interfacestore.Interface{
+			Index: iface.Index,
+			Name:  iface.Name,
+			Alias: iface.Alias,
+			Speed: speed,
+		})
+	}
+	return storeInterfaces
+}
+
+func batchPayloads(namespace string, subnet string, collectTime time.Time, batchSize int, device metadata.DeviceMetadata, interfaces []metadata.InterfaceMetadata, links []metadata.TopologyLinkMetadata) []metadata.NetworkDevicesMetadata {
 	var payloads []metadata.NetworkDevicesMetadata
 	var resourceCount int
 	payload := metadata.NetworkDevicesMetadata{
@@ -215,6 +255,20 @@ func batchPayloads(namespace string, subnet string, collectTime time.Time, batch
 		payload.Interfaces = append(payload.Interfaces, interfaceMetadata)
 	}
 
+	for _, linkMetadata := range links {
+		if resourceCount == batchSize {
+			payloads = append(payloads, payload)
+			payload = metadata.NetworkDevicesMetadata{
+				Subnet:           subnet,
+				Namespace:        namespace,
+				CollectTimestamp: collectTime.Unix(),
+			}
+			resourceCount = 0
+		}
+		resourceCount++
+		payload.Links = append(payload.Links, linkMetadata)
+	}
+
 	payloads = append(payloads, payload)
 	return payloads
 }
@@ -16,6 +16,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
+	"github.com/DataDog/datadog-agent/pkg/snmp/interfacestore"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
 	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
@@ -237,6 +238,15 @@ func Test_metricSender_reportNetworkDeviceMetadata_withInterfaces(t *testing.T)
 				"1": valuestore.ResultValue{Value: float64(21)},
 				"2": valuestore.ResultValue{Value: float64(22)},
 			},
+			// ifHighSpeed
+			"1.3.6.1.2.1.31.1.1.1.15": {
+				"1": valuestore.ResultValue{Value: float64(1000)},
+			},
+			// ifSpeed
+			"1.3.6.1.2.1.2.2.1.5": {
+				"1": valuestore.ResultValue{Value: float64(4294967295)},
+				"2": valuestore.ResultValue{Value: float64(100000000)},
+			},
 		},
 	}
 	sender := mocksender.NewMockSender("testID") // required to initiate aggregator
@@ -326,6 +336,13 @@ func Test_metricSender_reportNetworkDeviceMetadata_withInterfaces(t *testing.T)
 	assert.NoError(t, err)
 
 	sender.AssertEventPlatformEvent(t, compactEvent.String(), "network-devices-metadata")
+
+	iface, ok := interfacestore.DefaultStore.GetInterface("my-ns", "1.2.3.4", 1)
+	assert.True(t, ok)
+	assert.Equal(t, interfacestore.Interface{Index: 1, Name: "21", Speed: 1000000000}, iface)
+	iface, ok = interfacestore.DefaultStore.GetInterface("my-ns", "1.2.3.4", 2)
+	assert.True(t, ok)
+	assert.Equal(t, interfacestore.Interface{Index: 2, Name: "22", Speed: 100000000}, iface)
 }
 
 func Test_metricSender_reportNetworkDeviceMetadata_fallbackOnFieldValue(t *testing.T) {
@@ -406,7 +423,7 @@ func Test_batchPayloads(t *testing.T) {
 	for i := 0; i < 350; i++ {
 		interfaces = append(interfaces, metadata.InterfaceMetadata{DeviceID: deviceID, Index: int32(i)})
 	}
-	payloads := batchPayloads("my-ns", "127.0.0.0/30", collectTime, 100, device, interfaces)
+	payloads := batchPayloads("my-ns", "127.0.0.0/30", collectTime, 100, device, interfaces, nil)
 
 	assert.Equal(t, 4, len(payloads))
 
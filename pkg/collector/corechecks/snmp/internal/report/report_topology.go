@@ -0,0 +1,266 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package report
+
+import (
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/snmp/gosnmplib"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/metadata"
+)
+
+const (
+	topologySourceLLDP = "lldp"
+	topologySourceCDP  = "cdp"
+
+	// cdpAddressTypeIP is the `ip` value of CISCO-CDP-MIB::CiscoNetworkProtocol
+	cdpAddressTypeIP = 1
+)
+
+// lldpChassisIDTypes maps LLDP-MIB::LldpChassisIdSubtype values to id types
+var lldpChassisIDTypes = map[int]string{
+	1: "chassis_component",
+	2: "interface_alias",
+	3: "port_component",
+	4: "mac_address",
+	5: "network_address",
+	6: "interface_name",
+	7: "local",
+}
+
+// lldpPortIDTypes maps LLDP-MIB::LldpPortIdSubtype values to id types
+var lldpPortIDTypes = map[int]string{
+	1: "interface_alias",
+	2: "port_component",
+	3: "mac_address",
+	4: "network_address",
+	5: "interface_name",
+	6: "agent_circuit_id",
+	7: "local",
+}
+
+// formatTopologyID formats an LLDP chassis or port ID according to its type
+func formatTopologyID(id []byte, idType string) string {
+	switch idType {
+	case "mac_address":
+		return formatColonSepBytes(id)
+	case "network_address":
+		// the first byte is the IANA address family, 1 for IPv4 and 2 for IPv6
+		if len(id) == net.IPv4len+1 || len(id) == net.IPv6len+1 {
+			return net.IP(id[1:]).String()
+		}
+	}
+	if gosnmplib.IsStringPrintable(id) {
+		return string(id)
+	}
+	return formatColonSepBytes(id)
+}
+
+// buildNetworkTopologyMetadata returns the links of the device to its LLDP and CDP neighbors
+func buildNetworkTopologyMetadata(deviceID string, store *metadata.Store, interfaces []metadata.InterfaceMetadata) []metadata.TopologyLinkMetadata {
+	if store == nil {
+		return nil
+	}
+	links := buildLLDPTopologyLinks(deviceID, store, interfaces)
+	return append(links, buildCDPTopologyLinks(deviceID, store, interfaces)...)
+}
+
+func buildLLDPTopologyLinks(deviceID string, store *metadata.Store, interfaces []metadata.InterfaceMetadata) []metadata.TopologyLinkMetadata {
+	indexes := store.GetColumnIndexes("lldp_remote.chassis_id")
+	if len(indexes) == 0 {
+		return nil
+	}
+	sort.Strings(indexes)
+
+	managementAddresses := getLLDPManagementAddresses(store)
+
+	var links []metadata.TopologyLinkMetadata
+	for _, strIndex := range indexes {
+		// lldpRemTimeMark.lldpRemLocalPortNum.lldpRemIndex
+		indexElems := strings.Split(strIndex, ".")
+		if len(indexElems) != 3 {
+			continue
+		}
+		localPortNum, remIndex := indexElems[1], indexElems[2]
+
+		chassisIDType := lldpChassisIDTypes[int(store.GetColumnAsFloat("lldp_remote.chassis_id_type", strIndex))]
+		portIDType := lldpPortIDTypes[int(store.GetColumnAsFloat("lldp_remote.interface_id_type", strIndex))]
+
+		remote := &metadata.TopologyLinkSide{
+			Device: &metadata.TopologyLinkDevice{
+				ID:          formatTopologyID(store.GetColumnAsByteArray("lldp_remote.chassis_id", strIndex), chassisIDType),
+				IDType:      chassisIDType,
+				Name:        store.GetColumnAsString("lldp_remote.device_name", strIndex),
+				Description: store.GetColumnAsString("lldp_remote.device_desc", strIndex),
+				IPAddress:   managementAddresses[localPortNum+"."+remIndex],
+			},
+			Interface: &metadata.TopologyLinkInterface{
+				ID:          formatTopologyID(store.GetColumnAsByteArray("lldp_remote.interface_id", strIndex), portIDType),
+				IDType:      portIDType,
+				Description: store.GetColumnAsString("lldp_remote.interface_desc", strIndex),
+			},
+		}
+
+		localPortIDType := lldpPortIDTypes[int(store.GetColumnAsFloat("lldp_local.interface_id_type", localPortNum))]
+		localPortID := formatTopologyID(store.GetColumnAsByteArray("lldp_local.interface_id", localPortNum), localPortIDType)
+		local := &metadata.TopologyLinkSide{
+			Device: &metadata.TopologyLinkDevice{DeviceID: deviceID},
+			Interface: &metadata.TopologyLinkInterface{
+				ID:     localPortID,
+				IDType: localPortIDType,
+			},
+		}
+		if iface := resolveLLDPLocalInterface(localPortNum, localPortID, localPortIDType, interfaces); iface != nil {
+			local.Interface.DeviceID = deviceID + ":" + strconv.Itoa(int(iface.Index))
+			if local.Interface.ID == "" {
+				local.Interface.ID = iface.Name
+				local.Interface.IDType = "interface_name"
+			}
+		}
+
+		links = append(links, metadata.TopologyLinkMetadata{
+			ID:         deviceID + ":" + localPortNum + "." + remIndex,
+			SourceType: topologySourceLLDP,
+			Local:      local,
+			Remote:     remote,
+		})
+	}
+	return links
+}
+
+// getLLDPManagementAddresses returns the first management address of each
+// neighbor, by `lldpRemLocalPortNum.lldpRemIndex`
+func getLLDPManagementAddresses(store *metadata.Store) map[string]string {
+	indexes := store.GetColumnIndexes("lldp_remote_management.interface_id_type")
+	sort.Strings(indexes)
+
+	addresses := make(map[string]string)
+	for _, strIndex := range indexes {
+		// lldpRemTimeMark.lldpRemLocalPortNum.lldpRemIndex.lldpRemManAddrSubtype.<address length>.<address>
+		indexElems := strings.Split(strIndex, ".")
+		if len(indexElems) < 5 {
+			continue
+		}
+		addrLen, err := strconv.Atoi(indexElems[4])
+		if err != nil || (addrLen != net.IPv4len && addrLen != net.IPv6len) || len(indexElems) != 5+addrLen {
+			continue
+		}
+		ip := make(net.IP, addrLen)
+		valid := true
+		for i, elem := range indexElems[5:] {
+			b, err := strconv.ParseUint(elem, 10, 8)
+			if err != nil {
+				valid = false
+				break
+			}
+			ip[i] = byte(b)
+		}
+		neighbor := indexElems[1] + "." + indexElems[2]
+		if _, ok := addresses[neighbor]; valid && !ok {
+			addresses[neighbor] = ip.String()
+		}
+	}
+	return addresses
+}
+
+// resolveLLDPLocalInterface returns the interface of an LLDP local port, matched by
+// its port ID, or by its port number that is usually the ifIndex of the interface
+func resolveLLDPLocalInterface(localPortNum string, localPortID string, localPortIDType string, interfaces []metadata.InterfaceMetadata) *metadata.InterfaceMetadata {
+	if localPortID != "" {
+		for i := range interfaces {
+			iface := &interfaces[i]
+			switch localPortIDType {
+			case "interface_name", "local":
+				if iface.Name == localPortID {
+					return iface
+				}
+			case "interface_alias":
+				if iface.Alias == localPortID {
+					return iface
+				}
+			case "mac_address":
+				if iface.MacAddress == localPortID {
+					return iface
+				}
+			}
+		}
+	}
+	return findInterfaceByIndex(localPortNum, interfaces)
+}
+
+func findInterfaceByIndex(strIndex string, interfaces []metadata.InterfaceMetadata) *metadata.InterfaceMetadata {
+	index, err := strconv.ParseInt(strIndex, 10, 32)
+	if err != nil {
+		return nil
+	}
+	for i := range interfaces {
+		if interfaces[i].Index == int32(index) {
+			return &interfaces[i]
+		}
+	}
+	return nil
+}
+
+func buildCDPTopologyLinks(deviceID string, store *metadata.Store, interfaces []metadata.InterfaceMetadata) []metadata.TopologyLinkMetadata {
+	indexes := store.GetColumnIndexes("cdp_remote.device_id")
+	if len(indexes) == 0 {
+		return nil
+	}
+	sort.Strings(indexes)
+
+	var links []metadata.TopologyLinkMetadata
+	for _, strIndex := range indexes {
+		// cdpCacheIfIndex.cdpCacheDeviceIndex
+		indexElems := strings.Split(strIndex, ".")
+		if len(indexElems) != 2 {
+			continue
+		}
+		ifIndex := indexElems[0]
+
+		remoteDevice := &metadata.TopologyLinkDevice{
+			ID:          store.GetColumnAsString("cdp_remote.device_id", strIndex),
+			Name:        store.GetColumnAsString("cdp_remote.device_id", strIndex),
+			Description: store.GetColumnAsString("cdp_remote.device_platform", strIndex),
+		}
+		address := store.GetColumnAsByteArray("cdp_remote.address", strIndex)
+		if int(store.GetColumnAsFloat("cdp_remote.address_type", strIndex)) == cdpAddressTypeIP && len(address) == net.IPv4len {
+			remoteDevice.IPAddress = net.IP(address).String()
+		}
+
+		local := &metadata.TopologyLinkSide{
+			Device: &metadata.TopologyLinkDevice{DeviceID: deviceID},
+			Interface: &metadata.TopologyLinkInterface{
+				ID:     ifIndex,
+				IDType: "interface_index",
+			},
+		}
+		if iface := findInterfaceByIndex(ifIndex, interfaces); iface != nil {
+			local.Interface.DeviceID = deviceID + ":" + ifIndex
+			if iface.Name != "" {
+				local.Interface.ID = iface.Name
+				local.Interface.IDType = "interface_name"
+			}
+		}
+
+		links = append(links, metadata.TopologyLinkMetadata{
+			ID:         deviceID + ":cdp:" + strIndex,
+			SourceType: topologySourceCDP,
+			Local:      local,
+			Remote: &metadata.TopologyLinkSide{
+				Device: remoteDevice,
+				Interface: &metadata.TopologyLinkInterface{
+					ID:     store.GetColumnAsString("cdp_remote.interface_id", strIndex),
+					IDType: "interface_name",
+				},
+			},
+		})
+	}
+	return links
+}
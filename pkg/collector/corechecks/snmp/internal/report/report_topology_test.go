@@ -0,0 +1,106 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package report
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/common"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/checkconfig"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/metadata"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/valuestore"
+)
+
+func Test_buildNetworkTopologyMetadata(t *testing.T) {
+	values := &valuestore.ResultValueStore{
+		ColumnValues: valuestore.ColumnResultValuesType{
+			// lldpRemTable
+			"1.0.8802.1.1.2.1.4.1.1.4": {"0.3.1": {Value: float64(4)}},
+			"1.0.8802.1.1.2.1.4.1.1.5": {"0.3.1": {Value: []byte{0x00, 0x1b, 0x21, 0x3a, 0x4f, 0x5e}}},
+			"1.0.8802.1.1.2.1.4.1.1.6": {"0.3.1": {Value: float64(5)}},
+			"1.0.8802.1.1.2.1.4.1.1.7": {"0.3.1": {Value: []byte("Gi0/1")}},
+			"1.0.8802.1.1.2.1.4.1.1.8": {"0.3.1": {Value: []byte("uplink")}},
+			"1.0.8802.1.1.2.1.4.1.1.9": {"0.3.1": {Value: []byte("switch-2")}},
+			// lldpRemManAddrTable
+			"1.0.8802.1.1.2.1.4.2.1.3": {"0.3.1.1.4.10.0.0.2": {Value: float64(2)}},
+			// lldpLocPortTable
+			"1.0.8802.1.1.2.1.3.7.1.2": {"3": {Value: float64(5)}},
+			"1.0.8802.1.1.2.1.3.7.1.3": {"3": {Value: []byte("eth2")}},
+			// cdpCacheTable
+			"1.3.6.1.4.1.9.9.23.1.2.1.1.3": {"1.7": {Value: float64(1)}},
+			"1.3.6.1.4.1.9.9.23.1.2.1.1.4": {"1.7": {Value: []byte{10, 0, 0, 3}}},
+			"1.3.6.1.4.1.9.9.23.1.2.1.1.6": {"1.7": {Value: []byte("router-3")}},
+			"1.3.6.1.4.1.9.9.23.1.2.1.1.7": {"1.7": {Value: []byte("Gi0/0")}},
+			"1.3.6.1.4.1.9.9.23.1.2.1.1.8": {"1.7": {Value: []byte("cisco ISR4331")}},
+		},
+	}
+	interfaces := []metadata.InterfaceMetadata{
+		{DeviceID: "default:1.2.3.4", Index: 1, Name: "eth0"},
+		{DeviceID: "default:1.2.3.4", Index: 12, Name: "eth2"},
+	}
+
+	store := buildMetadataStore(checkconfig.TopologyMetadataConfig, values)
+	links := buildNetworkTopologyMetadata("default:1.2.3.4", store, interfaces)
+
+	assert.Equal(t, []metadata.TopologyLinkMetadata{
+		{
+			ID:         "default:1.2.3.4:3.1",
+			SourceType: "lldp",
+			Local: &metadata.TopologyLinkSide{
+				Device:    &metadata.TopologyLinkDevice{DeviceID: "default:1.2.3.4"},
+				Interface: &metadata.TopologyLinkInterface{DeviceID: "default:1.2.3.4:12", ID: "eth2", IDType: "interface_name"},
+			},
+			Remote: &metadata.TopologyLinkSide{
+				Device: &metadata.TopologyLinkDevice{
+					ID:        "00:1b:21:3a:4f:5e",
+					IDType:    "mac_address",
+					Name:      "switch-2",
+					IPAddress: "10.0.0.2",
+				},
+				Interface: &metadata.TopologyLinkInterface{ID: "Gi0/1", IDType: "interface_name", Description: "uplink"},
+			},
+		},
+		{
+			ID:         "default:1.2.3.4:cdp:1.7",
+			SourceType: "cdp",
+			Local: &metadata.TopologyLinkSide{
+				Device:    &metadata.TopologyLinkDevice{DeviceID: "default:1.2.3.4"},
+				Interface: &metadata.TopologyLinkInterface{DeviceID: "default:1.2.3.4:1", ID: "eth0", IDType: "interface_name"},
+			},
+			Remote: &metadata.TopologyLinkSide{
+				Device: &metadata.TopologyLinkDevice{
+					ID:          "router-3",
+					Name:        "router-3",
+					Description: "cisco ISR4331",
+					IPAddress:   "10.0.0.3",
+				},
+				Interface: &metadata.TopologyLinkInterface{ID: "Gi0/0", IDType: "interface_name"},
+			},
+		},
+	}, links)
+}
+
+func Test_formatTopologyID(t *testing.T) {
+	assert.Equal(t, "00:1b:21:3a:4f:5e", formatTopologyID([]byte{0x00, 0x1b, 0x21, 0x3a, 0x4f, 0x5e}, "mac_address"))
+	assert.Equal(t, "10.0.0.1", formatTopologyID([]byte{1, 10, 0, 0, 1}, "network_address"))
+	assert.Equal(t, "switch-1", formatTopologyID([]byte("switch-1"), "local"))
+	assert.Equal(t, "01:ff", formatTopologyID([]byte{0x01, 0xff}, "chassis_component"))
+}
+
+func Test_batchPayloads_withLinks(t *testing.T) {
+	device := metadata.DeviceMetadata{ID: "123"}
+	interfaces := []metadata.InterfaceMetadata{{DeviceID: "123", Index: 1}}
+	links := []metadata.TopologyLinkMetadata{{ID: "123:1.1"}, {ID: "123:2.1"}}
+
+	payloads := batchPayloads("my-ns", "127.0.0.0/30", common.MockTimeNow(), 3, device, interfaces, links)
+
+	assert.Equal(t, 2, len(payloads))
+	assert.Equal(t, interfaces, payloads[0].Interfaces)
+	assert.Equal(t, links[0:1], payloads[0].Links)
+	assert.Equal(t, links[1:2], payloads[1].Links)
+}
@@ -0,0 +1,151 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package session
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// WalkSession is a Session serving the variables of a recorded snmpwalk from memory
+type WalkSession struct {
+	variables []gosnmp.SnmpPDU
+	oids      [][]int
+	Version   gosnmp.SnmpVersion
+}
+
+// NewWalkSession creates a WalkSession serving the given variables
+func NewWalkSession(variables []gosnmp.SnmpPDU) *WalkSession {
+	type walkVariable struct {
+		pdu gosnmp.SnmpPDU
+		oid []int
+	}
+	sorted := make([]walkVariable, 0, len(variables))
+	for _, variable := range variables {
+		variable.Name = strings.TrimLeft(variable.Name, ".")
+		sorted = append(sorted, walkVariable{pdu: variable, oid: parseOID(variable.Name)})
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return compareOIDs(sorted[i].oid, sorted[j].oid) < 0
+	})
+
+	s := &WalkSession{Version: gosnmp.Version2c}
+	for i, variable := range sorted {
+		// keep the last recorded value of duplicated OIDs
+		if i+1 < len(sorted) && compareOIDs(variable.oid, sorted[i+1].oid) == 0 {
+			continue
+		}
+		s.variables = append(s.variables, variable.pdu)
+		s.oids = append(s.oids, variable.oid)
+	}
+	return s
+}
+
+// Connect is used to create a new connection
+func (s *WalkSession) Connect() error {
+	return nil
+}
+
+// Close is used to close the connection
+func (s *WalkSession) Close() error {
+	return nil
+}
+
+// Get will send a SNMPGET command
+func (s *WalkSession) Get(oids []string) (result *gosnmp.SnmpPacket, err error) {
+	var variables []gosnmp.SnmpPDU
+	for _, oid := range oids {
+		oid = strings.TrimLeft(oid, ".")
+		i := s.search(parseOID(oid))
+		if i < len(s.variables) && s.variables[i].Name == oid {
+			variables = append(variables, s.variables[i])
+		} else {
+			variables = append(variables, gosnmp.SnmpPDU{Name: oid, Type: gosnmp.NoSuchObject})
+		}
+	}
+	return &gosnmp.SnmpPacket{Variables: variables}, nil
+}
+
+// GetBulk will send a SNMP BULKGET command
+func (s *WalkSession) GetBulk(oids []string, bulkMaxRepetitions uint32) (result *gosnmp.SnmpPacket, err error) {
+	var variables []gosnmp.SnmpPDU
+	next := make([]string, len(oids))
+	copy(next, oids)
+	for rep := uint32(0); rep < bulkMaxRepetitions; rep++ {
+		for i, oid := range next {
+			variable := s.getNext(oid)
+			variables = append(variables, variable)
+			next[i] = variable.Name
+		}
+	}
+	return &gosnmp.SnmpPacket{Variables: variables}, nil
+}
+
+// GetNext will send a SNMP GETNEXT command
+func (s *WalkSession) GetNext(oids []string) (result *gosnmp.SnmpPacket, err error) {
+	var variables []gosnmp.SnmpPDU
+	for _, oid := range oids {
+		variables = append(variables, s.getNext(oid))
+	}
+	return &gosnmp.SnmpPacket{Variables: variables}, nil
+}
+
+// GetVersion returns the snmp version used
+func (s *WalkSession) GetVersion() gosnmp.SnmpVersion {
+	return s.Version
+}
+
+// getNext returns the first variable following oid, or an EndOfMibView variable
+func (s *WalkSession) getNext(oid string) gosnmp.SnmpPDU {
+	oid = strings.TrimLeft(oid, ".")
+	parsed := parseOID(oid)
+	i := s.search(parsed)
+	if i < len(s.variables) && compareOIDs(s.oids[i], parsed) == 0 {
+		i++
+	}
+	if i >= len(s.variables) {
+		return gosnmp.SnmpPDU{Name: oid, Type: gosnmp.EndOfMibView}
+	}
+	return s.variables[i]
+}
+
+// search returns the index of the first variable whose OID is greater or equal to oid
+func (s *WalkSession) search(oid []int) int {
+	return sort.Search(len(s.oids), func(i int) bool {
+		return compareOIDs(s.oids[i], oid) >= 0
+	})
+}
+
+func parseOID(oid string) []int {
+	var parsed []int
+	for _, part := range strings.Split(oid, ".") {
+		if part == "" {
+			continue
+		}
+		value, err := strconv.Atoi(part)
+		if err != nil {
+			value = -1
+		}
+		parsed = append(parsed, value)
+	}
+	return parsed
+}
+
+// compareOIDs compares two OIDs in lexicographic order of their sub-identifiers
+func compareOIDs(a, b []int) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return len(a) - len(b)
+}
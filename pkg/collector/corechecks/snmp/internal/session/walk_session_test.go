@@ -0,0 +1,47 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package session
+
+import (
+	"testing"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWalkSession(t *testing.T) {
+	sess := NewWalkSession([]gosnmp.SnmpPDU{
+		{Name: ".1.3.6.1.2.1.2.2.1.10.2", Type: gosnmp.Counter32, Value: uint(2)},
+		{Name: ".1.3.6.1.2.1.1.2.0", Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.4.1.8072.3.2.10"},
+		{Name: ".1.3.6.1.2.1.2.2.1.10.10", Type: gosnmp.Counter32, Value: uint(10)},
+		{Name: ".1.3.6.1.2.1.2.2.1.10.1", Type: gosnmp.Counter32, Value: uint(1)},
+	})
+
+	sysObjectID, err := FetchSysObjectID(sess)
+	assert.NoError(t, err)
+	assert.Equal(t, "1.3.6.1.4.1.8072.3.2.10", sysObjectID)
+
+	result, err := sess.Get([]string{"1.3.6.1.2.1.1.5.0"})
+	assert.NoError(t, err)
+	assert.Equal(t, []gosnmp.SnmpPDU{{Name: "1.3.6.1.2.1.1.5.0", Type: gosnmp.NoSuchObject}}, result.Variables)
+
+	// OIDs are ordered numerically
+	result, err = sess.GetNext([]string{"1.3.6.1.2.1.2.2.1.10.1", "1.3.6.1.2.1.2.2.1.10.10"})
+	assert.NoError(t, err)
+	assert.Equal(t, []gosnmp.SnmpPDU{
+		{Name: "1.3.6.1.2.1.2.2.1.10.2", Type: gosnmp.Counter32, Value: uint(2)},
+		{Name: "1.3.6.1.2.1.2.2.1.10.10", Type: gosnmp.EndOfMibView},
+	}, result.Variables)
+
+	result, err = sess.GetBulk([]string{"1.3.6.1.2.1.1", "1.3.6.1.2.1.2.2.1.10"}, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []gosnmp.SnmpPDU{
+		{Name: "1.3.6.1.2.1.1.2.0", Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.4.1.8072.3.2.10"},
+		{Name: "1.3.6.1.2.1.2.2.1.10.1", Type: gosnmp.Counter32, Value: uint(1)},
+		{Name: "1.3.6.1.2.1.2.2.1.10.1", Type: gosnmp.Counter32, Value: uint(1)},
+		{Name: "1.3.6.1.2.1.2.2.1.10.2", Type: gosnmp.Counter32, Value: uint(2)},
+	}, result.Variables)
+}
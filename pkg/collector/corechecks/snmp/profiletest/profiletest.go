@@ -0,0 +1,293 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package profiletest runs SNMP profiles against recorded snmpwalks, to test
+// them without a real device.
+package profiletest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/checkconfig"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/fetch"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/metadata"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/report"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/session"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/valuestore"
+)
+
+// walkDeviceIPAddress is the IP address of the device serving the recorded walk
+const walkDeviceIPAddress = "127.0.0.1"
+
+// Metric is a metric sample reported for the walk
+type Metric struct {
+	Type  string
+	Name  string
+	Value float64
+	Tags  []string
+}
+
+// ProfileOID is an OID referenced by a profile
+type ProfileOID struct {
+	OID string
+	// Usage describes where the OID is used in the profile
+	Usage string
+}
+
+// Result holds everything reported by a profile for a recorded walk
+type Result struct {
+	Profile string
+	// Tags are the tags of the device, common to all metrics
+	Tags    []string
+	Metrics []Metric
+	// Devices and Interfaces are the metadata fields reported for the device
+	Devices    []map[string]interface{}
+	Interfaces []map[string]interface{}
+	// UnmatchedOIDs are the OIDs of the profile without any value in the walk
+	UnmatchedOIDs []ProfileOID
+}
+
+// recordingSender records the samples and events sent by the report package
+type recordingSender struct {
+	aggregator.Sender
+	metrics  []Metric
+	payloads []string
+}
+
+func (s *recordingSender) record(metricType string, metric string, value float64, tags []string) {
+	s.metrics = append(s.metrics, Metric{Type: metricType, Name: metric, Value: value, Tags: append([]string{}, tags...)})
+}
+
+func (s *recordingSender) Gauge(metric string, value float64, hostname string, tags []string) {
+	s.record("gauge", metric, value, tags)
+}
+
+func (s *recordingSender) Rate(metric string, value float64, hostname string, tags []string) {
+	s.record("rate", metric, value, tags)
+}
+
+func (s *recordingSender) Count(metric string, value float64, hostname string, tags []string) {
+	s.record("count", metric, value, tags)
+}
+
+func (s *recordingSender) MonotonicCount(metric string, value float64, hostname string, tags []string) {
+	s.record("monotonic_count", metric, value, tags)
+}
+
+func (s *recordingSender) ServiceCheck(checkName string, status metrics.ServiceCheckStatus, hostname string, tags []string, message string) {
+}
+
+func (s *recordingSender) EventPlatformEvent(rawEvent string, eventType string) {
+	s.payloads = append(s.payloads, rawEvent)
+}
+
+// newCheckConfig returns the config of a check running a profile. The profile is
+// either the path of a profile definition file, or the name of a profile of the
+// `snmp.d/profiles` directory.
+func newCheckConfig(profile string) (*checkconfig.CheckConfig, error) {
+	instance := map[string]interface{}{
+		"ip_address":       walkDeviceIPAddress,
+		"community_string": "public",
+	}
+	initConfig := map[string]interface{}{}
+
+	if strings.HasSuffix(profile, ".yaml") || strings.ContainsRune(profile, filepath.Separator) {
+		definitionFile, err := filepath.Abs(profile)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkconfig.ValidateProfileDefinitionFile(definitionFile); err != nil {
+			return nil, fmt.Errorf("invalid profile `%s`: %s", profile, err)
+		}
+		profile = strings.TrimSuffix(filepath.Base(definitionFile), filepath.Ext(definitionFile))
+		initConfig["profiles"] = map[string]interface{}{
+			profile: map[string]string{"definition_file": definitionFile},
+		}
+	}
+	instance["profile"] = profile
+
+	rawInstance, err := yaml.Marshal(instance)
+	if err != nil {
+		return nil, err
+	}
+	rawInitConfig, err := yaml.Marshal(initConfig)
+	if err != nil {
+		return nil, err
+	}
+	return checkconfig.NewCheckConfig(rawInstance, rawInitConfig)
+}
+
+// Run fetches the OIDs of a profile from the variables of a recorded walk, and
+// returns the metrics, tags and metadata reported for them
+func Run(profile string, variables []gosnmp.SnmpPDU) (*Result, error) {
+	config, err := newCheckConfig(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := fetch.Fetch(session.NewWalkSession(variables), config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch values: %s", err)
+	}
+
+	sender := &recordingSender{}
+	metricSender := report.NewMetricSender(sender, "")
+
+	tags := append(config.GetStaticTags(), config.ProfileTags...)
+	tags = append(tags, metricSender.GetCheckInstanceMetricTags(config.MetricTags, values)...)
+	metricSender.ReportMetrics(config.Metrics, values, tags)
+	if config.CollectDeviceMetadata {
+		metadataTags := append(append([]string{}, tags...), config.InstanceTags...)
+		metricSender.ReportNetworkDeviceMetadata(config, values, metadataTags, time.Now(), metadata.DeviceStatusReachable)
+	}
+
+	result := &Result{
+		Profile:       config.Profile,
+		Tags:          tags,
+		Metrics:       sender.metrics,
+		UnmatchedOIDs: unmatchedOIDs(config, values),
+	}
+	// column rows are reported in random order
+	sort.SliceStable(result.Metrics, func(i, j int) bool {
+		if result.Metrics[i].Name != result.Metrics[j].Name {
+			return result.Metrics[i].Name < result.Metrics[j].Name
+		}
+		return strings.Join(result.Metrics[i].Tags, ",") < strings.Join(result.Metrics[j].Tags, ",")
+	})
+
+	for _, payload := range sender.payloads {
+		var content struct {
+			Devices    []map[string]interface{} `json:"devices"`
+			Interfaces []map[string]interface{} `json:"interfaces"`
+		}
+		if err := json.Unmarshal([]byte(payload), &content); err != nil {
+			return nil, fmt.Errorf("failed to decode device metadata: %s", err)
+		}
+		result.Devices = append(result.Devices, content.Devices...)
+		result.Interfaces = append(result.Interfaces, content.Interfaces...)
+	}
+	return result, nil
+}
+
+// unmatchedOIDs returns the OIDs referenced by the profile of the config that
+// don't have any value
+func unmatchedOIDs(config *checkconfig.CheckConfig, values *valuestore.ResultValueStore) []ProfileOID {
+	if config.ProfileDef == nil {
+		return nil
+	}
+
+	var unmatched []ProfileOID
+	seen := make(map[string]bool)
+	check := func(oid string, column bool, usage string) {
+		oid = strings.TrimLeft(oid, ".")
+		if oid == "" || seen[oid] {
+			return
+		}
+		seen[oid] = true
+		if column && len(values.ColumnValues[oid]) > 0 {
+			return
+		}
+		if _, ok := values.ScalarValues[oid]; !column && ok {
+			return
+		}
+		unmatched = append(unmatched, ProfileOID{OID: oid, Usage: usage})
+	}
+
+	for _, metric := range config.ProfileDef.Metrics {
+		check(metric.Symbol.OID, false, "metric "+metric.Symbol.Name)
+		for _, symbol := range metric.Symbols {
+			check(symbol.OID, true, "metric "+symbol.Name)
+		}
+		for _, metricTag := range metric.MetricTags {
+			check(metricTag.Column.OID, true, "tag "+metricTag.Tag)
+		}
+	}
+	for _, metricTag := range config.ProfileDef.MetricTags {
+		check(metricTag.OID, false, "tag "+metricTag.Tag)
+	}
+	if config.CollectDeviceMetadata {
+		resources := make([]string, 0, len(config.ProfileDef.Metadata))
+		for resource := range config.ProfileDef.Metadata {
+			resources = append(resources, resource)
+		}
+		sort.Strings(resources)
+		for _, resource := range resources {
+			resourceConfig := config.ProfileDef.Metadata[resource]
+			column := !checkconfig.IsMetadataResourceWithScalarOids(resource)
+			fields := make([]string, 0, len(resourceConfig.Fields))
+			for field := range resourceConfig.Fields {
+				fields = append(fields, field)
+			}
+			sort.Strings(fields)
+			for _, field := range fields {
+				metadataField := resourceConfig.Fields[field]
+				check(metadataField.Symbol.OID, column, "metadata "+resource+"."+field)
+				for _, symbol := range metadataField.Symbols {
+					check(symbol.OID, column, "metadata "+resource+"."+field)
+				}
+			}
+			for _, idTag := range resourceConfig.IDTags {
+				check(idTag.Column.OID, true, "metadata "+resource+" tag "+idTag.Tag)
+			}
+		}
+	}
+	return unmatched
+}
+
+// Print writes the result in a human readable format
+func (r *Result) Print(w io.Writer) {
+	fmt.Fprintf(w, "Profile: %s\n", r.Profile)
+
+	fmt.Fprintf(w, "\nTags:\n")
+	for _, tag := range r.Tags {
+		fmt.Fprintf(w, "  %s\n", tag)
+	}
+
+	fmt.Fprintf(w, "\nMetrics:\n")
+	for _, metric := range r.Metrics {
+		fmt.Fprintf(w, "  %s (%s) = %v %v\n", metric.Name, metric.Type, metric.Value, metric.Tags)
+	}
+
+	fmt.Fprintf(w, "\nMetadata:\n")
+	for _, device := range r.Devices {
+		printMetadataFields(w, "device", device)
+	}
+	for _, iface := range r.Interfaces {
+		printMetadataFields(w, fmt.Sprintf("interface %v", iface["index"]), iface)
+	}
+
+	fmt.Fprintf(w, "\nUnmatched OIDs:\n")
+	if len(r.UnmatchedOIDs) == 0 {
+		fmt.Fprintf(w, "  none\n")
+	}
+	for _, oid := range r.UnmatchedOIDs {
+		fmt.Fprintf(w, "  %s (%s)\n", oid.OID, oid.Usage)
+	}
+}
+
+func printMetadataFields(w io.Writer, resource string, fields map[string]interface{}) {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	fmt.Fprintf(w, "  %s:\n", resource)
+	for _, key := range keys {
+		fmt.Fprintf(w, "    %s: %v\n", key, fields[key])
+	}
+}
@@ -0,0 +1,68 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package profiletest
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	f, err := os.Open("testdata/device.snmprec")
+	require.NoError(t, err)
+	defer f.Close()
+	variables, err := ParseWalk(f)
+	require.NoError(t, err)
+
+	result, err := Run("testdata/profile.yaml", variables)
+	require.NoError(t, err)
+
+	assert.Equal(t, "profile", result.Profile)
+	assert.Equal(t, []string{
+		"device_namespace:default",
+		"snmp_device:127.0.0.1",
+		"snmp_profile:profile",
+		"snmp_host:test-host",
+	}, result.Tags)
+
+	metrics := make(map[string][]Metric)
+	for _, metric := range result.Metrics {
+		metrics[metric.Name] = append(metrics[metric.Name], metric)
+	}
+	assert.Equal(t, []Metric{{Type: "gauge", Name: "snmp.laLoadInt", Value: 42, Tags: result.Tags}}, metrics["snmp.laLoadInt"])
+	assert.Equal(t, []Metric{{Type: "gauge", Name: "snmp.sysUpTimeInstance", Value: 123456, Tags: result.Tags}}, metrics["snmp.sysUpTimeInstance"])
+	require.Len(t, metrics["snmp.ifInOctets"], 2)
+	assert.Equal(t, "rate", metrics["snmp.ifInOctets"][0].Type)
+	assert.Contains(t, metrics["snmp.ifInOctets"][0].Tags, "interface:eth0")
+	assert.Contains(t, metrics["snmp.ifInOctets"][1].Tags, "interface:lo")
+
+	require.Len(t, result.Devices, 1)
+	assert.Equal(t, "net-snmp", result.Devices[0]["vendor"])
+	assert.Equal(t, "profile", result.Devices[0]["profile"])
+	require.Len(t, result.Interfaces, 2)
+	assert.Equal(t, "eth0", result.Interfaces[1]["name"])
+	assert.Equal(t, float64(2), result.Interfaces[1]["oper_status"])
+
+	assert.Equal(t, []ProfileOID{
+		{OID: "1.3.6.1.2.1.2.2.1.14", Usage: "metric ifInErrors"},
+		{OID: "1.3.6.1.4.1.8072.1.1.1.0", Usage: "metadata device.serial_number"},
+	}, result.UnmatchedOIDs)
+
+	var out bytes.Buffer
+	result.Print(&out)
+	assert.Contains(t, out.String(), "  snmp.laLoadInt (gauge) = 42 [")
+	assert.Contains(t, out.String(), "    name: eth0\n")
+	assert.Contains(t, out.String(), "  1.3.6.1.2.1.2.2.1.14 (metric ifInErrors)\n")
+}
+
+func TestRunInvalidProfile(t *testing.T) {
+	_, err := Run("testdata/missing.yaml", nil)
+	assert.Error(t, err)
+}
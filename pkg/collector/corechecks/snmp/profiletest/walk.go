@@ -0,0 +1,199 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package profiletest
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+var (
+	// snmprecLineRegex matches `<oid>|<tag>|<value>` lines of .snmprec files, the tag
+	// may be suffixed by `x` for hex encoded values
+	snmprecLineRegex = regexp.MustCompile(`^\.?(\d+(?:\.\d+)*)\|(\d+)([a-z:]*)\|(.*)$`)
+	// snmpwalkLineRegex matches `<oid> = [<type>: ]<value>` lines of snmpwalk outputs,
+	// produced by `snmpwalk -On` or `agent snmp walk`
+	snmpwalkLineRegex = regexp.MustCompile(`^\.?(\d+(?:\.\d+)*) = (?:([A-Za-z0-9 -]+): )?(.*)$`)
+	// enumValueRegex matches enum values like `up(1)`
+	enumValueRegex = regexp.MustCompile(`^[A-Za-z0-9_-]*\((-?\d+)\)$`)
+	// timeticksValueRegex matches net-snmp timeticks like `(123456) 0:20:34.56`
+	timeticksValueRegex = regexp.MustCompile(`^\((\d+)\)`)
+)
+
+// ParseWalk parses a recorded snmpwalk output or .snmprec file into SNMP variables
+func ParseWalk(r io.Reader) ([]gosnmp.SnmpPDU, error) {
+	var variables []gosnmp.SnmpPDU
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var variable gosnmp.SnmpPDU
+		var err error
+		if matches := snmprecLineRegex.FindStringSubmatch(line); matches != nil {
+			variable, err = parseSnmprecVariable(matches[1], matches[2], matches[3], matches[4])
+		} else if matches := snmpwalkLineRegex.FindStringSubmatch(line); matches != nil {
+			if matches[2] == "" && (strings.HasPrefix(matches[3], "No Such ") || strings.HasPrefix(matches[3], "No more variables")) {
+				continue
+			}
+			variable, err = parseSnmpwalkVariable(matches[1], matches[2], matches[3])
+		} else if len(variables) > 0 && variables[len(variables)-1].Type == gosnmp.OctetString {
+			// continuation of a multi-line string value
+			last := &variables[len(variables)-1]
+			last.Value = append(last.Value.([]byte), []byte("\n"+strings.TrimSuffix(line, `"`))...)
+			continue
+		} else {
+			return nil, fmt.Errorf("line %d: unrecognized walk line: %s", lineNumber, line)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", lineNumber, err)
+		}
+		variables = append(variables, variable)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return variables, nil
+}
+
+// parseSnmprecVariable parses a variable recorded in the snmpsim .snmprec format
+// See https://github.com/etingof/snmpsim/blob/master/docs/source/documentation/building-simulation-data.rst
+func parseSnmprecVariable(oid string, tag string, flags string, value string) (gosnmp.SnmpPDU, error) {
+	variable := gosnmp.SnmpPDU{Name: oid}
+	if strings.Contains(flags, "x") {
+		decoded, err := hex.DecodeString(value)
+		if err != nil {
+			return variable, fmt.Errorf("invalid hex value `%s` of %s: %s", value, oid, err)
+		}
+		value = string(decoded)
+	}
+
+	var err error
+	switch tag {
+	case "2":
+		variable.Type = gosnmp.Integer
+		variable.Value, err = strconv.Atoi(value)
+	case "4":
+		variable.Type = gosnmp.OctetString
+		variable.Value = []byte(value)
+	case "5":
+		variable.Type = gosnmp.Null
+	case "6":
+		variable.Type = gosnmp.ObjectIdentifier
+		variable.Value = "." + strings.TrimLeft(value, ".")
+	case "64":
+		variable.Type = gosnmp.IPAddress
+		if strings.Contains(flags, "x") && len(value) == net.IPv4len {
+			value = net.IP(value).String()
+		}
+		variable.Value = value
+	case "65", "66", "67":
+		variable.Type = map[string]gosnmp.Asn1BER{"65": gosnmp.Counter32, "66": gosnmp.Gauge32, "67": gosnmp.TimeTicks}[tag]
+		variable.Value, err = parseUint(value, variable.Type)
+	case "70":
+		variable.Type = gosnmp.Counter64
+		variable.Value, err = strconv.ParseUint(value, 10, 64)
+	default:
+		return variable, fmt.Errorf("unsupported snmprec type `%s` of %s", tag, oid)
+	}
+	if err != nil {
+		return variable, fmt.Errorf("invalid value `%s` of %s: %s", value, oid, err)
+	}
+	return variable, nil
+}
+
+// parseSnmpwalkVariable parses a variable printed by snmpwalk
+func parseSnmpwalkVariable(oid string, typeName string, value string) (gosnmp.SnmpPDU, error) {
+	variable := gosnmp.SnmpPDU{Name: oid}
+	typeName = strings.ToLower(strings.ReplaceAll(typeName, " ", ""))
+
+	var err error
+	switch typeName {
+	case "string", "":
+		if typeName == "" && !strings.HasPrefix(value, `"`) {
+			// `agent snmp walk` prints timeticks without type
+			if ticks, err := strconv.ParseUint(value, 10, 32); err == nil {
+				variable.Type = gosnmp.TimeTicks
+				variable.Value = uint32(ticks)
+				return variable, nil
+			}
+		}
+		variable.Type = gosnmp.OctetString
+		variable.Value = []byte(unquote(value))
+	case "hex-string", "bits":
+		variable.Type = gosnmp.OctetString
+		variable.Value, err = hex.DecodeString(strings.Join(strings.Fields(value), ""))
+	case "integer", "integer32":
+		variable.Type = gosnmp.Integer
+		variable.Value, err = strconv.Atoi(enumValue(value))
+	case "counter32", "gauge32", "unsigned32", "timeticks":
+		variable.Type = map[string]gosnmp.Asn1BER{"counter32": gosnmp.Counter32, "gauge32": gosnmp.Gauge32, "unsigned32": gosnmp.Gauge32, "timeticks": gosnmp.TimeTicks}[typeName]
+		if matches := timeticksValueRegex.FindStringSubmatch(value); matches != nil {
+			value = matches[1]
+		}
+		variable.Value, err = parseUint(value, variable.Type)
+	case "counter64":
+		variable.Type = gosnmp.Counter64
+		variable.Value, err = strconv.ParseUint(value, 10, 64)
+	case "oid":
+		variable.Type = gosnmp.ObjectIdentifier
+		variable.Value = "." + strings.TrimLeft(value, ".")
+	case "ipaddress":
+		variable.Type = gosnmp.IPAddress
+		variable.Value = value
+	case "opaque":
+		variable.Type = gosnmp.OpaqueFloat
+		var floatValue float64
+		floatValue, err = strconv.ParseFloat(strings.TrimPrefix(strings.TrimPrefix(value, "Float: "), "Double: "), 32)
+		variable.Value = float32(floatValue)
+	default:
+		return variable, fmt.Errorf("unsupported snmpwalk type `%s` of %s", typeName, oid)
+	}
+	if err != nil {
+		return variable, fmt.Errorf("invalid value `%s` of %s: %s", value, oid, err)
+	}
+	return variable, nil
+}
+
+// parseUint returns the value of 32 bits unsigned types, typed as gosnmp decodes them
+func parseUint(value string, berType gosnmp.Asn1BER) (interface{}, error) {
+	parsed, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return nil, err
+	}
+	if berType == gosnmp.TimeTicks {
+		return uint32(parsed), nil
+	}
+	return uint(parsed), nil
+}
+
+// enumValue returns the numeric value of enum values like `up(1)`
+func enumValue(value string) string {
+	if matches := enumValueRegex.FindStringSubmatch(value); matches != nil {
+		return matches[1]
+	}
+	return value
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+		return value[1 : len(value)-1]
+	}
+	return strings.TrimPrefix(value, `"`)
+}
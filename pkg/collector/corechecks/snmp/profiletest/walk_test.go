@@ -0,0 +1,69 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package profiletest
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWalk(t *testing.T) {
+	for _, walkFile := range []string{"testdata/device.snmprec", "testdata/device.walk"} {
+		t.Run(walkFile, func(t *testing.T) {
+			f, err := os.Open(walkFile)
+			require.NoError(t, err)
+			defer f.Close()
+
+			variables, err := ParseWalk(f)
+			require.NoError(t, err)
+			require.Len(t, variables, 11)
+
+			assert.Equal(t, gosnmp.SnmpPDU{Name: "1.3.6.1.2.1.1.1.0", Type: gosnmp.OctetString, Value: []byte("Linux test 5.10.0")}, variables[0])
+			assert.Equal(t, gosnmp.SnmpPDU{Name: "1.3.6.1.2.1.1.2.0", Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.4.1.8072.3.2.10"}, variables[1])
+			assert.Equal(t, gosnmp.SnmpPDU{Name: "1.3.6.1.2.1.1.3.0", Type: gosnmp.TimeTicks, Value: uint32(123456)}, variables[2])
+			assert.Equal(t, gosnmp.SnmpPDU{Name: "1.3.6.1.2.1.2.2.1.8.2", Type: gosnmp.Integer, Value: 2}, variables[5])
+			assert.Equal(t, gosnmp.SnmpPDU{Name: "1.3.6.1.2.1.2.2.1.10.1", Type: gosnmp.Counter32, Value: uint(1000)}, variables[6])
+			assert.Equal(t, gosnmp.SnmpPDU{Name: "1.3.6.1.2.1.31.1.1.1.1.2", Type: gosnmp.OctetString, Value: []byte("eth0")}, variables[9])
+		})
+	}
+}
+
+func TestParseWalkAgentOutput(t *testing.T) {
+	// output of `agent snmp walk`
+	walk := `1.3.6.1.2.1.1.1.0 = STRING: first line
+second line
+1.3.6.1.2.1.1.3.0 = 4242
+1.3.6.1.2.1.2.2.1.10.1 = Counter 32: 10
+1.3.6.1.2.1.31.1.1.1.6.1 = Counter 64: 20
+1.3.6.1.2.1.4.20.1.1.10.0.0.1 = IpAddress: 10.0.0.1
+`
+	variables, err := ParseWalk(strings.NewReader(walk))
+	require.NoError(t, err)
+	assert.Equal(t, []gosnmp.SnmpPDU{
+		{Name: "1.3.6.1.2.1.1.1.0", Type: gosnmp.OctetString, Value: []byte("first line\nsecond line")},
+		{Name: "1.3.6.1.2.1.1.3.0", Type: gosnmp.TimeTicks, Value: uint32(4242)},
+		{Name: "1.3.6.1.2.1.2.2.1.10.1", Type: gosnmp.Counter32, Value: uint(10)},
+		{Name: "1.3.6.1.2.1.31.1.1.1.6.1", Type: gosnmp.Counter64, Value: uint64(20)},
+		{Name: "1.3.6.1.2.1.4.20.1.1.10.0.0.1", Type: gosnmp.IPAddress, Value: "10.0.0.1"},
+	}, variables)
+}
+
+func TestParseWalkErrors(t *testing.T) {
+	for _, walk := range []string{
+		"not a walk",
+		"1.3.6.1.2.1.1.3.0|67|abc",
+		"1.3.6.1.2.1.1.3.0|99|1",
+		".1.3.6.1.2.1.1.3.0 = Unknown: 1",
+	} {
+		_, err := ParseWalk(strings.NewReader(walk))
+		assert.Error(t, err, walk)
+	}
+}
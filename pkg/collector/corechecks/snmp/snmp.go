@@ -68,6 +68,12 @@ func (c *Check) Run() error {
 		close(jobs)
 		wg.Wait() // wait for all workers to finish
 
+		if c.config.DiscoverNeighbors {
+			for _, deviceCk := range discoveredDevices {
+				c.discovery.QueueNeighbors(deviceCk.GetNeighborIPAddresses())
+			}
+		}
+
 		tags := append(c.config.GetStaticTags(), "network:"+c.config.Network)
 		tags = append(tags, c.config.GetNetworkTags()...)
 		sender.Gauge("snmp.discovered_devices_count", float64(len(discoveredDevices)), "", tags)
@@ -0,0 +1,212 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/checks/env"
+	"github.com/DataDog/datadog-agent/pkg/compliance/eval"
+	"github.com/DataDog/datadog-agent/pkg/util/jsonquery"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const defaultKubeletProcessName = "kubelet"
+
+var kubeletConfigReportedFields = []string{
+	compliance.KubeletConfigFieldPath,
+}
+
+type kubeletFlagKind int
+
+const (
+	kubeletFlagString kubeletFlagKind = iota
+	kubeletFlagBool
+	kubeletFlagInt
+	kubeletFlagList
+)
+
+// kubeletConfigFlag is the field of the kubelet config file which is
+// overridden by a command line flag
+type kubeletConfigFlag struct {
+	path []string
+	kind kubeletFlagKind
+}
+
+// kubeletConfigFlags lists the flags which take precedence over the fields of
+// the kubelet config file, as audited by the CIS Kubernetes benchmark
+var kubeletConfigFlags = map[string]kubeletConfigFlag{
+	"--anonymous-auth":                    {[]string{"authentication", "anonymous", "enabled"}, kubeletFlagBool},
+	"--authentication-token-webhook":      {[]string{"authentication", "webhook", "enabled"}, kubeletFlagBool},
+	"--client-ca-file":                    {[]string{"authentication", "x509", "clientCAFile"}, kubeletFlagString},
+	"--authorization-mode":                {[]string{"authorization", "mode"}, kubeletFlagString},
+	"--read-only-port":                    {[]string{"readOnlyPort"}, kubeletFlagInt},
+	"--streaming-connection-idle-timeout": {[]string{"streamingConnectionIdleTimeout"}, kubeletFlagString},
+	"--protect-kernel-defaults":           {[]string{"protectKernelDefaults"}, kubeletFlagBool},
+	"--make-iptables-util-chains":         {[]string{"makeIPTablesUtilChains"}, kubeletFlagBool},
+	"--event-qps":                         {[]string{"eventRecordQPS"}, kubeletFlagInt},
+	"--tls-cert-file":                     {[]string{"tlsCertFile"}, kubeletFlagString},
+	"--tls-private-key-file":              {[]string{"tlsPrivateKeyFile"}, kubeletFlagString},
+	"--tls-cipher-suites":                 {[]string{"tlsCipherSuites"}, kubeletFlagList},
+	"--rotate-certificates":               {[]string{"rotateCertificates"}, kubeletFlagBool},
+	"--pod-max-pids":                      {[]string{"podPidsLimit"}, kubeletFlagInt},
+}
+
+func resolveKubeletConfig(_ context.Context, e env.Env, id string, res compliance.ResourceCommon, rego bool) (resolved, error) {
+	if res.KubeletConfig == nil {
+		return nil, fmt.Errorf("%s: expecting kubelet config resource in kubelet config check", id)
+	}
+
+	kubeletConfig := res.KubeletConfig
+
+	processName := kubeletConfig.ProcessName
+	if processName == "" {
+		processName = defaultKubeletProcessName
+	}
+
+	log.Debugf("%s: running kubelet config check: %s", id, processName)
+
+	processes, err := getProcesses(cacheValidity)
+	if err != nil {
+		return nil, log.Errorf("%s: Unable to fetch processes: %v", id, err)
+	}
+
+	matchedProcesses := processes.findProcessesByName(processName)
+	if len(matchedProcesses) == 0 {
+		if rego {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%s: no running %s process", id, processName)
+	}
+
+	// only one kubelet runs on a node
+	flagValues := parseProcessCmdLine(matchedProcesses[0].CmdlineSlice())
+
+	path := flagValues["--config"]
+	if path == "" {
+		path = kubeletConfig.Path
+	}
+
+	config := make(map[string]interface{})
+	if path != "" {
+		content, err := readContent(e.NormalizeToHostRoot(path), "yaml")
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to read kubelet config %s: %w", id, path, err)
+		}
+		if content, ok := content.(map[string]interface{}); ok {
+			config = content
+		}
+	}
+
+	effective, err := kubeletEffectiveConfig(config, flagValues)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", id, err)
+	}
+
+	instance := eval.NewInstance(
+		eval.VarMap{
+			compliance.KubeletConfigFieldPath:      path,
+			compliance.KubeletConfigFieldFlags:     flagValues,
+			compliance.KubeletConfigFieldConfig:    config,
+			compliance.KubeletConfigFieldEffective: effective,
+		},
+		eval.FunctionMap{
+			compliance.KubeletConfigFuncJQ: kubeletConfigJQ(effective),
+		},
+		eval.RegoInputMap{
+			"path":      path,
+			"flags":     flagValues,
+			"config":    config,
+			"effective": effective,
+		},
+	)
+
+	resourceID := path
+	if resourceID == "" {
+		resourceID = processName
+	}
+
+	return newResolvedInstance(instance, resourceID, "kubelet_config"), nil
+}
+
+// kubeletEffectiveConfig returns the kubelet config file content, overridden
+// by the values of the command line flags
+func kubeletEffectiveConfig(config map[string]interface{}, flagValues map[string]string) (map[string]interface{}, error) {
+	effective := config
+	for flag, value := range flagValues {
+		configFlag, found := kubeletConfigFlags[flag]
+		if !found {
+			continue
+		}
+
+		v, err := configFlag.parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value `%s` for kubelet flag %s: %w", value, flag, err)
+		}
+		effective = withConfigValue(effective, configFlag.path, v)
+	}
+	return effective, nil
+}
+
+func (f kubeletConfigFlag) parse(value string) (interface{}, error) {
+	switch f.kind {
+	case kubeletFlagBool:
+		// a boolean flag without value is set
+		if value == "" {
+			return true, nil
+		}
+		return strconv.ParseBool(value)
+	case kubeletFlagInt:
+		return strconv.Atoi(value)
+	case kubeletFlagList:
+		var values []interface{}
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+		return values, nil
+	default:
+		return value, nil
+	}
+}
+
+// withConfigValue returns a copy of config with the value set at the given
+// path, leaving config untouched
+func withConfigValue(config map[string]interface{}, path []string, value interface{}) map[string]interface{} {
+	res := make(map[string]interface{}, len(config)+1)
+	for k, v := range config {
+		res[k] = v
+	}
+
+	if len(path) == 1 {
+		res[path[0]] = value
+		return res
+	}
+
+	child, _ := res[path[0]].(map[string]interface{})
+	res[path[0]] = withConfigValue(child, path[1:], value)
+	return res
+}
+
+func kubeletConfigJQ(config map[string]interface{}) eval.Function {
+	return func(_ eval.Instance, args ...interface{}) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf(`invalid number of arguments, expecting 1 got %d`, len(args))
+		}
+		query, ok := args[0].(string)
+		if !ok {
+			return nil, errors.New(`expecting string value for query argument`)
+		}
+		value, _, err := jsonquery.RunSingleOutput(query, config)
+		return value, err
+	}
+}
@@ -0,0 +1,144 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+package checks
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+	"github.com/DataDog/datadog-agent/pkg/compliance/mocks"
+	"github.com/DataDog/datadog-agent/pkg/util/cache"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestKubeletConfigCheck(t *testing.T) {
+	tests := []struct {
+		name         string
+		resource     compliance.Resource
+		processes    processes
+		expectReport *compliance.Report
+	}{
+		{
+			name: "config file only",
+			resource: compliance.Resource{
+				ResourceCommon: compliance.ResourceCommon{
+					KubeletConfig: &compliance.KubeletConfig{},
+				},
+				Condition: `kubelet.config.jq(".authorization.mode") == "Webhook"`,
+			},
+			processes: processes{
+				NewCheckedFakeProcess(42, "kubelet", []string{"kubelet", "--config=/var/lib/kubelet/config.yaml"}),
+			},
+			expectReport: &compliance.Report{
+				Passed: true,
+				Data: event.Data{
+					"kubelet.config.path": "/var/lib/kubelet/config.yaml",
+				},
+				Resource: compliance.ReportResource{
+					ID:   "/var/lib/kubelet/config.yaml",
+					Type: "kubelet_config",
+				},
+			},
+		},
+		{
+			name: "flag overriding config file",
+			resource: compliance.Resource{
+				ResourceCommon: compliance.ResourceCommon{
+					KubeletConfig: &compliance.KubeletConfig{},
+				},
+				Condition: `kubelet.config.jq(".authentication.anonymous.enabled") == "false"`,
+			},
+			processes: processes{
+				NewCheckedFakeProcess(42, "kubelet", []string{"kubelet", "--config=/var/lib/kubelet/config.yaml", "--anonymous-auth=false"}),
+			},
+			expectReport: &compliance.Report{
+				Passed: true,
+				Data: event.Data{
+					"kubelet.config.path": "/var/lib/kubelet/config.yaml",
+				},
+				Resource: compliance.ReportResource{
+					ID:   "/var/lib/kubelet/config.yaml",
+					Type: "kubelet_config",
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			cache.Cache.Delete(processCacheKey)
+			processFetcher = func() (processes, error) {
+				return test.processes, nil
+			}
+
+			env := &mocks.Env{}
+			env.On("MaxEventsPerRun").Return(30).Maybe()
+			env.On("NormalizeToHostRoot", "/var/lib/kubelet/config.yaml").Return("./testdata/kubelet/config.yaml")
+			defer env.AssertExpectations(t)
+
+			kubeletCheck, err := newResourceCheck(env, "rule-id", test.resource)
+			assert.NoError(err)
+
+			reports := kubeletCheck.check(env)
+			assert.Equal(test.expectReport, reports[0])
+		})
+	}
+}
+
+func TestKubeletConfigRegoInput(t *testing.T) {
+	assert := assert.New(t)
+
+	cache.Cache.Delete(processCacheKey)
+	processFetcher = func() (processes, error) {
+		return processes{
+			NewCheckedFakeProcess(42, "kubelet", []string{"kubelet", "--config", "/var/lib/kubelet/config.yaml", "--read-only-port=10255", "--tls-cipher-suites=TLS_AES_128_GCM_SHA256,TLS_AES_256_GCM_SHA384"}),
+		}, nil
+	}
+
+	env := &mocks.Env{}
+	env.On("NormalizeToHostRoot", "/var/lib/kubelet/config.yaml").Return("./testdata/kubelet/config.yaml")
+	defer env.AssertExpectations(t)
+
+	resolved, err := resolveKubeletConfig(context.Background(), env, "rule-id", compliance.ResourceCommon{
+		KubeletConfig: &compliance.KubeletConfig{},
+	}, true)
+	assert.NoError(err)
+
+	input := resolved.(resolvedInstance).RegoInput()
+	assert.Equal("/var/lib/kubelet/config.yaml", input["path"])
+	assert.Equal("10255", input["flags"].(map[string]string)["--read-only-port"])
+
+	config := input["config"].(map[string]interface{})
+	assert.Equal(0, config["readOnlyPort"])
+
+	effective := input["effective"].(map[string]interface{})
+	assert.Equal(10255, effective["readOnlyPort"])
+	assert.Equal([]interface{}{"TLS_AES_128_GCM_SHA256", "TLS_AES_256_GCM_SHA384"}, effective["tlsCipherSuites"])
+	assert.Equal(true, effective["protectKernelDefaults"])
+	assert.Equal("Webhook", effective["authorization"].(map[string]interface{})["mode"])
+}
+
+func TestKubeletConfigNoProcess(t *testing.T) {
+	assert := assert.New(t)
+
+	cache.Cache.Delete(processCacheKey)
+	processFetcher = func() (processes, error) {
+		return processes{}, nil
+	}
+
+	env := &mocks.Env{}
+	defer env.AssertExpectations(t)
+
+	resolved, err := resolveKubeletConfig(context.Background(), env, "rule-id", compliance.ResourceCommon{
+		KubeletConfig: &compliance.KubeletConfig{},
+	}, true)
+	assert.NoError(err)
+	assert.Nil(resolved)
+}
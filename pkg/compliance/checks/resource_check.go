@@ -208,6 +208,10 @@ func resourceKindToResolverAndFields(env env.Env, ruleID string, kind compliance
 			return nil, nil, log.Errorf("%s: kube client not initialized", ruleID)
 		}
 		return resolveKubeapiserver, kubeResourceReportedFields, nil
+	case compliance.KindKubeletConfig:
+		return resolveKubeletConfig, kubeletConfigReportedFields, nil
+	case compliance.KindSysctl:
+		return resolveSysctl, sysctlReportedFields, nil
 	case compliance.KindConstants:
 		return resolveConstants, nil, nil
 	default:
@@ -0,0 +1,135 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/checks/env"
+	"github.com/DataDog/datadog-agent/pkg/compliance/eval"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const procSysPath = "/proc/sys"
+
+var sysctlReportedFields = []string{
+	compliance.SysctlFieldPrefix,
+}
+
+func resolveSysctl(_ context.Context, e env.Env, id string, res compliance.ResourceCommon, rego bool) (resolved, error) {
+	if res.Sysctl == nil {
+		return nil, fmt.Errorf("%s: expecting sysctl resource in sysctl check", id)
+	}
+
+	prefix := strings.Trim(res.Sysctl.Prefix, ".")
+
+	log.Debugf("%s: running sysctl check: %s", id, prefix)
+
+	values, err := readSysctls(e.NormalizeToHostRoot(procSysPath), prefix)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to read kernel parameters: %w", id, err)
+	}
+
+	if len(values) == 0 && rego {
+		return nil, nil
+	}
+
+	instance := eval.NewInstance(
+		eval.VarMap{
+			compliance.SysctlFieldPrefix: prefix,
+			compliance.SysctlFieldValues: values,
+		},
+		eval.FunctionMap{
+			compliance.SysctlFuncValue: sysctlValue(values),
+		},
+		eval.RegoInputMap{
+			"prefix": prefix,
+			"values": values,
+		},
+	)
+
+	resourceID := prefix
+	if resourceID == "" {
+		resourceID = "sysctl"
+	}
+
+	return newResolvedInstance(instance, resourceID, "sysctl"), nil
+}
+
+// readSysctls returns the values of the kernel parameters found under root,
+// keyed by their dotted name such as `net.ipv4.ip_forward`. Whitespaces of
+// multi-valued parameters are collapsed. Unreadable parameters are skipped.
+func readSysctls(root, prefix string) (map[string]string, error) {
+	dir := filepath.Join(root, strings.ReplaceAll(prefix, ".", string(filepath.Separator)))
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return map[string]string{}, nil
+		}
+		return nil, err
+	}
+
+	values := make(map[string]string)
+
+	// the prefix is a single parameter
+	if !info.IsDir() {
+		if data, err := os.ReadFile(dir); err == nil {
+			values[prefix] = normalizeSysctlValue(data)
+		}
+		return values, nil
+	}
+
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if d != nil && d.IsDir() && path != dir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		}
+		values[strings.ReplaceAll(rel, string(filepath.Separator), ".")] = normalizeSysctlValue(data)
+		return nil
+	})
+
+	return values, err
+}
+
+func normalizeSysctlValue(data []byte) string {
+	return strings.Join(strings.Fields(string(data)), " ")
+}
+
+func sysctlValue(values map[string]string) eval.Function {
+	return func(_ eval.Instance, args ...interface{}) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf(`invalid number of arguments, expecting 1 got %d`, len(args))
+		}
+		name, ok := args[0].(string)
+		if !ok {
+			return nil, errors.New(`expecting string value for name argument`)
+		}
+		return values[name], nil
+	}
+}
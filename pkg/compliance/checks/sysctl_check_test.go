@@ -0,0 +1,96 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+package checks
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+	"github.com/DataDog/datadog-agent/pkg/compliance/mocks"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func newProcSys(t *testing.T, values map[string]string) string {
+	root := t.TempDir()
+	for path, value := range values {
+		path = filepath.Join(root, path)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(value), 0644))
+	}
+	return root
+}
+
+func TestSysctlCheck(t *testing.T) {
+	assert := assert.New(t)
+
+	root := newProcSys(t, map[string]string{
+		"net/ipv4/ip_forward":          "0\n",
+		"net/ipv4/tcp_rmem":            "4096\t131072\t6291456\n",
+		"kernel/randomize_va_space":    "2\n",
+		"kernel/kptr_restrict":         "1\n",
+		"net/ipv6/conf/all/forwarding": "0\n",
+	})
+
+	env := &mocks.Env{}
+	env.On("MaxEventsPerRun").Return(30).Maybe()
+	env.On("NormalizeToHostRoot", "/proc/sys").Return(root)
+	defer env.AssertExpectations(t)
+
+	sysctlCheck, err := newResourceCheck(env, "rule-id", compliance.Resource{
+		ResourceCommon: compliance.ResourceCommon{
+			Sysctl: &compliance.Sysctl{
+				Prefix: "kernel",
+			},
+		},
+		Condition: `sysctl.value("kernel.randomize_va_space") == "2" && sysctl.value("kernel.kptr_restrict") != "0"`,
+	})
+	assert.NoError(err)
+
+	reports := sysctlCheck.check(env)
+	assert.Equal(&compliance.Report{
+		Passed: true,
+		Data: event.Data{
+			"sysctl.prefix": "kernel",
+		},
+		Resource: compliance.ReportResource{
+			ID:   "kernel",
+			Type: "sysctl",
+		},
+	}, reports[0])
+
+	resolved, err := resolveSysctl(context.Background(), env, "rule-id", compliance.ResourceCommon{
+		Sysctl: &compliance.Sysctl{
+			Prefix: "net.ipv4",
+		},
+	}, true)
+	assert.NoError(err)
+	assert.Equal(map[string]string{
+		"net.ipv4.ip_forward": "0",
+		"net.ipv4.tcp_rmem":   "4096 131072 6291456",
+	}, resolved.(resolvedInstance).RegoInput()["values"])
+
+	resolved, err = resolveSysctl(context.Background(), env, "rule-id", compliance.ResourceCommon{
+		Sysctl: &compliance.Sysctl{
+			Prefix: "net.ipv4.ip_forward",
+		},
+	}, true)
+	assert.NoError(err)
+	assert.Equal(map[string]string{
+		"net.ipv4.ip_forward": "0",
+	}, resolved.(resolvedInstance).RegoInput()["values"])
+
+	resolved, err = resolveSysctl(context.Background(), env, "rule-id", compliance.ResourceCommon{
+		Sysctl: &compliance.Sysctl{
+			Prefix: "vm",
+		},
+	}, true)
+	assert.NoError(err)
+	assert.Nil(resolved)
+}
@@ -0,0 +1,78 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package event
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+)
+
+// FrameworkSummary holds the number of findings of a framework by result
+type FrameworkSummary struct {
+	Total  int `json:"total"`
+	Passed int `json:"passed"`
+	Failed int `json:"failed"`
+	Errors int `json:"errors"`
+}
+
+// BenchmarkReport describes the findings of a run of the compliance checks
+type BenchmarkReport struct {
+	Hostname     string                       `json:"hostname,omitempty"`
+	AgentVersion string                       `json:"agent_version,omitempty"`
+	Format       string                       `json:"format"`
+	GeneratedAt  time.Time                    `json:"generated_at"`
+	Frameworks   map[string]*FrameworkSummary `json:"frameworks"`
+	Findings     []interface{}                `json:"findings"`
+}
+
+// NewBenchmarkReport returns the report of the given events, the findings
+// being in the given format
+func NewBenchmarkReport(events []*Event, format string, hostname, agentVersion string, ts time.Time) (*BenchmarkReport, error) {
+	if err := ValidateFormat(format); err != nil {
+		return nil, err
+	}
+
+	report := &BenchmarkReport{
+		Hostname:     hostname,
+		AgentVersion: agentVersion,
+		Format:       format,
+		GeneratedAt:  ts.UTC(),
+		Frameworks:   make(map[string]*FrameworkSummary),
+		Findings:     make([]interface{}, 0, len(events)),
+	}
+
+	for _, e := range events {
+		summary, ok := report.Frameworks[e.AgentFrameworkID]
+		if !ok {
+			summary = &FrameworkSummary{}
+			report.Frameworks[e.AgentFrameworkID] = summary
+		}
+
+		summary.Total++
+		switch e.Result {
+		case Passed:
+			summary.Passed++
+		case Failed:
+			summary.Failed++
+		default:
+			summary.Errors++
+		}
+
+		report.Findings = append(report.Findings, formatEvent(e, format, ts))
+	}
+
+	return report, nil
+}
+
+// WriteFile writes the report as indented JSON to a file
+func (r *BenchmarkReport) WriteFile(path string) error {
+	buf, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf, 0640)
+}
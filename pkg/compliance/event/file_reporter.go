@@ -0,0 +1,224 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	coreconfig "github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/util/startstop"
+)
+
+const (
+	// FormatJSON is the format of the events as sent to the intake, with
+	// the time at which they were reported
+	FormatJSON = "json"
+	// FormatOCSF is the format of the OCSF Compliance Findings
+	FormatOCSF = "ocsf"
+)
+
+// fileRecord is an event reported in the JSON format
+type fileRecord struct {
+	Timestamp time.Time `json:"timestamp"`
+	*Event
+}
+
+// formatEvent returns an event in the given format
+func formatEvent(e *Event, format string, ts time.Time) interface{} {
+	if format == FormatOCSF {
+		return NewOCSFFinding(e, ts)
+	}
+	return &fileRecord{Timestamp: ts.UTC(), Event: e}
+}
+
+// ValidateFormat returns an error if the format is unknown
+func ValidateFormat(format string) error {
+	if format != FormatJSON && format != FormatOCSF {
+		return fmt.Errorf("unknown report format `%s`, expected `%s` or `%s`", format, FormatJSON, FormatOCSF)
+	}
+	return nil
+}
+
+// FileReporter writes the events as JSON lines to a local file, which is
+// rotated when it exceeds a maximum size
+type FileReporter struct {
+	sync.Mutex
+	path     string
+	format   string
+	maxSize  int64
+	maxRolls int
+
+	file *os.File
+	size int64
+}
+
+// NewFileReporter returns a reporter writing to path the events in the given
+// format. Once the file exceeds maxSize bytes, it is renamed with a `.1`
+// suffix, the previous rolls being shifted up to maxRolls.
+func NewFileReporter(path, format string, maxSize int64, maxRolls int) (*FileReporter, error) {
+	if err := ValidateFormat(format); err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return nil, fmt.Errorf("failed to create the directory of %s: %w", path, err)
+	}
+
+	r := &FileReporter{
+		path:     path,
+		format:   format,
+		maxSize:  maxSize,
+		maxRolls: maxRolls,
+	}
+
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *FileReporter) open() error {
+	file, err := os.OpenFile(r.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0640)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	r.file = file
+	r.size = info.Size()
+	return nil
+}
+
+// rotate shifts the rolls of the file, and reopens it
+func (r *FileReporter) rotate() error {
+	if err := r.file.Close(); err != nil {
+		log.Warnf("Failed to close %s: %v", r.path, err)
+	}
+
+	if r.maxRolls > 0 {
+		for i := r.maxRolls - 1; i > 0; i-- {
+			roll := fmt.Sprintf("%s.%d", r.path, i)
+			if _, err := os.Stat(roll); err == nil {
+				if err := os.Rename(roll, fmt.Sprintf("%s.%d", r.path, i+1)); err != nil {
+					return err
+				}
+			}
+		}
+		if err := os.Rename(r.path, r.path+".1"); err != nil {
+			return err
+		}
+	} else if err := os.Remove(r.path); err != nil {
+		return err
+	}
+
+	return r.open()
+}
+
+// Report writes an event to the file
+func (r *FileReporter) Report(event *Event) {
+	buf, err := json.Marshal(formatEvent(event, r.format, time.Now()))
+	if err != nil {
+		log.Errorf("Failed to serialize rule event for rule %s", event.AgentRuleID)
+		return
+	}
+	r.ReportRaw(buf, "")
+}
+
+// ReportRaw writes raw content to the file, as a line
+func (r *FileReporter) ReportRaw(content []byte, service string, tags ...string) {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.file == nil {
+		return
+	}
+
+	if r.maxSize > 0 && r.size > 0 && r.size+int64(len(content))+1 > r.maxSize {
+		if err := r.rotate(); err != nil {
+			log.Errorf("Failed to rotate %s: %v", r.path, err)
+			r.file = nil
+			return
+		}
+	}
+
+	n, err := r.file.Write(append(content, '\n'))
+	r.size += int64(n)
+	if err != nil {
+		log.Errorf("Failed to write to %s: %v", r.path, err)
+	}
+}
+
+// Stop closes the file
+func (r *FileReporter) Stop() {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.file != nil {
+		r.file.Close()
+		r.file = nil
+	}
+}
+
+type multiReporter struct {
+	reporters []Reporter
+}
+
+// NewMultiReporter returns a reporter reporting to all the given reporters
+func NewMultiReporter(reporters ...Reporter) Reporter {
+	return &multiReporter{reporters: reporters}
+}
+
+func (r *multiReporter) Report(event *Event) {
+	for _, reporter := range r.reporters {
+		reporter.Report(event)
+	}
+}
+
+func (r *multiReporter) ReportRaw(content []byte, service string, tags ...string) {
+	for _, reporter := range r.reporters {
+		reporter.ReportRaw(content, service, tags...)
+	}
+}
+
+// WithConfiguredFileReporter returns a reporter also writing the events to
+// the local file of `compliance_config.report_file`, when it's enabled
+func WithConfiguredFileReporter(stopper startstop.Stopper, reporter Reporter) (Reporter, error) {
+	if !coreconfig.Datadog.GetBool("compliance_config.report_file.enabled") {
+		return reporter, nil
+	}
+
+	path := coreconfig.Datadog.GetString("compliance_config.report_file.path")
+	if path == "" {
+		path = filepath.Join(coreconfig.Datadog.GetString("compliance_config.run_path"), "compliance-findings.json")
+	}
+
+	fileReporter, err := NewFileReporter(
+		path,
+		coreconfig.Datadog.GetString("compliance_config.report_file.format"),
+		int64(coreconfig.Datadog.GetInt("compliance_config.report_file.max_size_mb"))*1024*1024,
+		coreconfig.Datadog.GetInt("compliance_config.report_file.max_rolls"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up compliance file reporter: %w", err)
+	}
+	stopper.Add(fileReporter)
+
+	log.Infof("Writing compliance findings to %s", path)
+
+	return NewMultiReporter(reporter, fileReporter), nil
+}
@@ -0,0 +1,139 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package event
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestEvent(ruleID, framework, result string) *Event {
+	return &Event{
+		AgentRuleID:      ruleID,
+		AgentFrameworkID: framework,
+		AgentVersion:     "7.40.0",
+		Result:           result,
+		ResourceType:     "docker_daemon",
+		ResourceID:       "host_daemon",
+		Data:             Data{"file.permissions": 420},
+	}
+}
+
+func readLines(t *testing.T, path string) []map[string]interface{} {
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var lines []map[string]interface{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var line map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		lines = append(lines, line)
+	}
+	require.NoError(t, scanner.Err())
+	return lines
+}
+
+func TestFileReporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "compliance", "findings.json")
+
+	r, err := NewFileReporter(path, FormatJSON, 0, 0)
+	require.NoError(t, err)
+	r.Report(newTestEvent("cis-docker-1", "cis-docker", Passed))
+	r.Report(newTestEvent("cis-docker-2", "cis-docker", Failed))
+	r.Stop()
+
+	lines := readLines(t, path)
+	require.Len(t, lines, 2)
+	assert.Equal(t, "cis-docker-1", lines[0]["agent_rule_id"])
+	assert.Equal(t, "passed", lines[0]["result"])
+	assert.Equal(t, "host_daemon", lines[0]["resource_id"])
+	assert.Contains(t, lines[0], "timestamp")
+	assert.Equal(t, map[string]interface{}{"file.permissions": float64(420)}, lines[0]["data"])
+	assert.Equal(t, "failed", lines[1]["result"])
+}
+
+func TestFileReporterRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "findings.json")
+
+	line, err := json.Marshal(formatEvent(newTestEvent("cis-docker-1", "cis-docker", Passed), FormatJSON, time.Now()))
+	require.NoError(t, err)
+
+	// two events fit in a file
+	r, err := NewFileReporter(path, FormatJSON, int64(2*(len(line)+1)), 2)
+	require.NoError(t, err)
+	for i := 0; i < 7; i++ {
+		r.Report(newTestEvent("cis-docker-1", "cis-docker", Passed))
+	}
+	r.Stop()
+
+	assert.Len(t, readLines(t, path), 1)
+	assert.Len(t, readLines(t, path+".1"), 2)
+	assert.Len(t, readLines(t, path+".2"), 2)
+	assert.NoFileExists(t, path+".3")
+}
+
+func TestFileReporterOCSF(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "findings.json")
+
+	r, err := NewFileReporter(path, FormatOCSF, 0, 0)
+	require.NoError(t, err)
+	r.Report(newTestEvent("cis-docker-2", "cis-docker", Failed))
+	r.Stop()
+
+	lines := readLines(t, path)
+	require.Len(t, lines, 1)
+	assert.Equal(t, float64(2003), lines[0]["class_uid"])
+	assert.Equal(t, float64(200301), lines[0]["type_uid"])
+	assert.Equal(t, map[string]interface{}{
+		"requirements": []interface{}{"cis-docker-2"},
+		"standards":    []interface{}{"cis-docker"},
+		"status":       "Fail",
+		"status_id":    float64(3),
+	}, lines[0]["compliance"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"uid": "host_daemon", "type": "docker_daemon"}}, lines[0]["resources"])
+}
+
+func TestFileReporterUnknownFormat(t *testing.T) {
+	_, err := NewFileReporter(filepath.Join(t.TempDir(), "findings.json"), "xml", 0, 0)
+	assert.Error(t, err)
+}
+
+func TestBenchmarkReport(t *testing.T) {
+	events := []*Event{
+		newTestEvent("cis-docker-1", "cis-docker", Passed),
+		newTestEvent("cis-docker-2", "cis-docker", Failed),
+		newTestEvent("cis-docker-3", "cis-docker", Error),
+		newTestEvent("cis-kubernetes-1", "cis-kubernetes", Passed),
+	}
+
+	report, err := NewBenchmarkReport(events, FormatOCSF, "host", "7.40.0", time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]*FrameworkSummary{
+		"cis-docker":     {Total: 3, Passed: 1, Failed: 1, Errors: 1},
+		"cis-kubernetes": {Total: 1, Passed: 1},
+	}, report.Frameworks)
+	require.Len(t, report.Findings, 4)
+	assert.Equal(t, "Error", report.Findings[2].(*OCSFFinding).Compliance.Status)
+
+	path := filepath.Join(t.TempDir(), "report.json")
+	require.NoError(t, report.WriteFile(path))
+
+	buf, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf, &decoded))
+	assert.Equal(t, "ocsf", decoded["format"])
+	assert.Len(t, decoded["findings"], 4)
+}
@@ -0,0 +1,127 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package event
+
+import (
+	"time"
+)
+
+// The constants below come from the Compliance Finding class of the Open
+// Cybersecurity Schema Framework (https://schema.ocsf.io/classes/compliance_finding)
+const (
+	ocsfVersion = "1.0.0"
+
+	ocsfCategoryFindings       = 2
+	ocsfClassComplianceFinding = 2003
+	ocsfActivityCreate         = 1
+
+	ocsfSeverityInformational = 1
+	ocsfSeverityMedium        = 3
+
+	ocsfComplianceStatusPass  = 1
+	ocsfComplianceStatusFail  = 3
+	ocsfComplianceStatusOther = 99
+)
+
+// OCSFProduct describes the product reporting a finding
+type OCSFProduct struct {
+	Name       string `json:"name"`
+	VendorName string `json:"vendor_name"`
+	Version    string `json:"version,omitempty"`
+}
+
+// OCSFMetadata describes the metadata of a finding
+type OCSFMetadata struct {
+	Product OCSFProduct `json:"product"`
+	Version string      `json:"version"`
+}
+
+// OCSFCompliance describes the compliance status of a finding
+type OCSFCompliance struct {
+	Requirements []string `json:"requirements"`
+	Standards    []string `json:"standards,omitempty"`
+	Status       string   `json:"status"`
+	StatusID     int      `json:"status_id"`
+}
+
+// OCSFFindingInfo describes a finding
+type OCSFFindingInfo struct {
+	UID   string `json:"uid"`
+	Title string `json:"title"`
+}
+
+// OCSFResource describes the resource of a finding
+type OCSFResource struct {
+	UID  string `json:"uid"`
+	Type string `json:"type,omitempty"`
+}
+
+// OCSFFinding is an OCSF Compliance Finding
+type OCSFFinding struct {
+	ActivityID int             `json:"activity_id"`
+	CategoryID int             `json:"category_uid"`
+	ClassID    int             `json:"class_uid"`
+	TypeID     int             `json:"type_uid"`
+	Time       int64           `json:"time"`
+	SeverityID int             `json:"severity_id"`
+	Metadata   OCSFMetadata    `json:"metadata"`
+	Compliance OCSFCompliance  `json:"compliance"`
+	Finding    OCSFFindingInfo `json:"finding"`
+	Resources  []OCSFResource  `json:"resources"`
+	Unmapped   *Event          `json:"unmapped,omitempty"`
+}
+
+// NewOCSFFinding returns the OCSF Compliance Finding of an event reported at
+// the given time. The event itself is kept in the unmapped attributes.
+func NewOCSFFinding(e *Event, ts time.Time) *OCSFFinding {
+	finding := &OCSFFinding{
+		ActivityID: ocsfActivityCreate,
+		CategoryID: ocsfCategoryFindings,
+		ClassID:    ocsfClassComplianceFinding,
+		TypeID:     ocsfClassComplianceFinding*100 + ocsfActivityCreate,
+		Time:       ts.UnixMilli(),
+		SeverityID: ocsfSeverityInformational,
+		Metadata: OCSFMetadata{
+			Product: OCSFProduct{
+				Name:       "Datadog Agent",
+				VendorName: "Datadog",
+				Version:    e.AgentVersion,
+			},
+			Version: ocsfVersion,
+		},
+		Compliance: OCSFCompliance{
+			Requirements: []string{e.AgentRuleID},
+		},
+		Finding: OCSFFindingInfo{
+			UID:   e.AgentRuleID + ":" + e.ResourceType + ":" + e.ResourceID,
+			Title: e.AgentRuleID,
+		},
+		Resources: []OCSFResource{{
+			UID:  e.ResourceID,
+			Type: e.ResourceType,
+		}},
+		Unmapped: e,
+	}
+
+	if e.AgentFrameworkID != "" {
+		finding.Compliance.Standards = []string{e.AgentFrameworkID}
+	}
+
+	switch e.Result {
+	case Passed:
+		finding.Compliance.Status = "Pass"
+		finding.Compliance.StatusID = ocsfComplianceStatusPass
+	case Failed:
+		finding.Compliance.Status = "Fail"
+		finding.Compliance.StatusID = ocsfComplianceStatusFail
+		finding.SeverityID = ocsfSeverityMedium
+	default:
+		finding.Compliance.Status = "Error"
+		finding.Compliance.StatusID = ocsfComplianceStatusOther
+	}
+
+	return finding
+}
@@ -30,6 +30,10 @@ const (
 	KindAudit = ResourceKind("audit")
 	// KindKubernetes is used for a KubernetesResource
 	KindKubernetes = ResourceKind("kubernetes")
+	// KindKubeletConfig is used for a KubeletConfig resource
+	KindKubeletConfig = ResourceKind("kubelet_config")
+	// KindSysctl is used for a Sysctl resource
+	KindSysctl = ResourceKind("sysctl")
 	// KindConstants is used for Constants check
 	KindConstants = ResourceKind("constants")
 	// KindCustom is used for a Custom check
@@ -45,6 +49,8 @@ type ResourceCommon struct {
 	Audit         *Audit              `yaml:"audit,omitempty"`
 	Docker        *DockerResource     `yaml:"docker,omitempty"`
 	KubeApiserver *KubernetesResource `yaml:"kubeApiserver,omitempty"`
+	KubeletConfig *KubeletConfig      `yaml:"kubeletConfig,omitempty"`
+	Sysctl        *Sysctl             `yaml:"sysctl,omitempty"`
 	Constants     *ConstantsResource  `yaml:"constants,omitempty"`
 	Custom        *Custom             `yaml:"custom,omitempty"`
 }
@@ -92,6 +98,10 @@ func (r *ResourceCommon) Kind() ResourceKind {
 		return KindDocker
 	case r.KubeApiserver != nil:
 		return KindKubernetes
+	case r.KubeletConfig != nil:
+		return KindKubeletConfig
+	case r.Sysctl != nil:
+		return KindSysctl
 	case r.Constants != nil:
 		return KindConstants
 	case r.Custom != nil:
@@ -183,6 +193,40 @@ type KubernetesAPIRequest struct {
 	ResourceName string `yaml:"resourceName,omitempty"`
 }
 
+// Fields & functions available for KubeletConfig
+const (
+	KubeletConfigFieldPath      = "kubelet.config.path"
+	KubeletConfigFieldFlags     = "kubelet.config.flags"
+	KubeletConfigFieldConfig    = "kubelet.config.config"
+	KubeletConfigFieldEffective = "kubelet.config.effective"
+
+	KubeletConfigFuncJQ = "kubelet.config.jq"
+)
+
+// KubeletConfig describes the configuration of a running kubelet, read from
+// its config file and its command line flags
+type KubeletConfig struct {
+	// Name of the kubelet process. Defaults to `kubelet`.
+	ProcessName string `yaml:"processName,omitempty"`
+	// Path of the config file when the kubelet doesn't have a `--config` flag
+	Path string `yaml:"path,omitempty"`
+}
+
+// Fields & functions available for Sysctl
+const (
+	SysctlFieldPrefix = "sysctl.prefix"
+	SysctlFieldValues = "sysctl.values"
+
+	SysctlFuncValue = "sysctl.value"
+)
+
+// Sysctl describes the kernel parameters found in /proc/sys
+type Sysctl struct {
+	// Restricts the parameters to a subtree, such as `net.ipv4`.
+	// Defaults to everything.
+	Prefix string `yaml:"prefix,omitempty"`
+}
+
 // Fields & functions available for Group
 const (
 	GroupFieldName  = "group.name"
@@ -31,6 +31,18 @@ func DiscoverComponentsFromConfig() ([]config.ConfigurationProviders, []config.L
 		detectedProviders = append(detectedProviders, prometheusProvider)
 	}
 
+	// Auto-add the systemd listener based on `systemd_units.enabled`
+	if config.Datadog.GetBool("systemd_units.enabled") {
+		log.Info("Systemd units collection is enabled: Adding the systemd listener")
+		detectedListeners = append(detectedListeners, config.Listeners{Name: "systemd"})
+	}
+
+	// Auto-add the process listener based on `host_processes.enabled`
+	if config.Datadog.GetBool("host_processes.enabled") {
+		log.Info("Host processes collection is enabled: Adding the process listener")
+		detectedListeners = append(detectedListeners, config.Listeners{Name: "process"})
+	}
+
 	// Auto-add file-based kube service and endpoints config providers based on check config files.
 	if flavor.GetFlavor() == flavor.ClusterAgent {
 		advancedConfigs, _, err := providers.ReadConfigFiles(providers.WithAdvancedADOnly)
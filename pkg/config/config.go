@@ -634,6 +634,15 @@ func InitConfig(config Config) {
 	config.SetEnvKeyTransformer("prometheus_scrape.checks", PrometheusScrapeChecksTransformer)
 	config.BindEnvAndSetDefault("prometheus_scrape.version", 1) // Version of the openmetrics check to be scheduled by the Prometheus auto-discovery
 
+	// systemd units autodiscovery
+	config.BindEnvAndSetDefault("systemd_units.enabled", false)                 // Collects systemd units in workloadmeta and enables the systemd listener
+	config.BindEnvAndSetDefault("systemd_units.include", []string{"*.service"}) // Unit name patterns collected when systemd_units.enabled is true
+
+	// long-running host processes autodiscovery
+	config.BindEnvAndSetDefault("host_processes.enabled", false)            // Collects host processes in workloadmeta and enables the process listener
+	config.BindEnvAndSetDefault("host_processes.include", []string{})       // Regular expressions matched against the command line of the processes to collect
+	config.BindEnvAndSetDefault("host_processes.min_uptime", 5*time.Minute) // Minimum uptime of the processes to collect
+
 	// Network Devices Monitoring
 	bindEnvAndSetLogsConfigKeys(config, "network_devices.metadata.")
 	config.BindEnvAndSetDefault("network_devices.namespace", "default")
@@ -656,6 +665,7 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("network_devices.snmp_traps.bind_host", "0.0.0.0")
 	config.BindEnvAndSetDefault("network_devices.snmp_traps.stop_timeout", 5) // in seconds
 	config.SetKnown("network_devices.snmp_traps.users")
+	config.SetKnown("network_devices.snmp_traps.metrics")
 
 	// NetFlow
 	config.SetKnown("network_devices.netflow.listeners")
@@ -665,6 +675,7 @@ func InitConfig(config Config) {
 	config.SetKnown("network_devices.netflow.aggregator_flow_context_ttl")
 	config.SetKnown("network_devices.netflow.aggregator_port_rollup_threshold")
 	config.SetKnown("network_devices.netflow.aggregator_rollup_tracker_refresh_interval")
+	config.SetKnown("network_devices.netflow.enrichment")
 	config.BindEnvAndSetDefault("network_devices.netflow.enabled", "false")
 	bindEnvAndSetLogsConfigKeys(config, "network_devices.netflow.forwarder.")
 
@@ -949,6 +960,7 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("cluster_checks.extra_tags", []string{})
 	config.BindEnvAndSetDefault("cluster_checks.advanced_dispatching_enabled", false)
 	config.BindEnvAndSetDefault("cluster_checks.clc_runners_port", 5005)
+	config.BindEnvAndSetDefault("cluster_checks.node_capacity", 0) // maximum busyness of a node when rebalancing, 0 means no limit
 	// Cluster check runner
 	config.BindEnvAndSetDefault("clc_runner_enabled", false)
 	config.BindEnvAndSetDefault("clc_runner_id", "")
@@ -982,6 +994,9 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("admission_controller.auto_instrumentation.enabled", true)
 	config.BindEnvAndSetDefault("admission_controller.auto_instrumentation.endpoint", "/injectlib")
 	config.BindEnvAndSetDefault("admission_controller.auto_instrumentation.container_registry", "gcr.io/datadoghq")
+	config.BindEnvAndSetDefault("admission_controller.namespace_policy.enabled", false)
+	config.BindEnvAndSetDefault("admission_controller.namespace_policy.configmap_name", "datadog-admission-policies")
+	config.BindEnvAndSetDefault("admission_controller.namespace_policy.cache_validity", 1) // in minutes
 
 	// Telemetry
 	// Enable telemetry metrics on the internals of the Agent.
@@ -1013,6 +1028,8 @@ func InitConfig(config Config) {
 	// this option will potentially impact the CPU usage of the agent
 	config.BindEnvAndSetDefault("orchestrator_explorer.container_scrubbing.enabled", true)
 	config.BindEnvAndSetDefault("orchestrator_explorer.custom_sensitive_words", []string{})
+	// redaction rules applied to the annotations, labels, data fields and JSONPaths of the collected resources
+	config.SetKnown("orchestrator_explorer.redaction_rules")
 	config.BindEnvAndSetDefault("orchestrator_explorer.collector_discovery.enabled", true)
 	config.BindEnv("orchestrator_explorer.max_per_message")
 	config.BindEnv("orchestrator_explorer.max_message_bytes")
@@ -1072,6 +1089,11 @@ func InitConfig(config Config) {
 	bindEnvAndSetLogsConfigKeys(config, "compliance_config.endpoints.")
 	config.BindEnvAndSetDefault("compliance_config.ignore_host_selectors", true)
 	config.BindEnvAndSetDefault("compliance_config.opa.metrics.enabled", false)
+	config.BindEnvAndSetDefault("compliance_config.report_file.enabled", false)
+	config.BindEnvAndSetDefault("compliance_config.report_file.path", "")
+	config.BindEnvAndSetDefault("compliance_config.report_file.format", "json")
+	config.BindEnvAndSetDefault("compliance_config.report_file.max_size_mb", 10)
+	config.BindEnvAndSetDefault("compliance_config.report_file.max_rolls", 5)
 
 	// Datadog security agent (runtime)
 	config.BindEnvAndSetDefault("runtime_security_config.enabled", false)
@@ -177,6 +177,11 @@ func setupProcesses(config Config) {
 
 	procBindEnvAndSetDefault(config, "process_config.drop_check_payloads", []string{})
 
+	// Process Groups
+	procBindEnvAndSetDefault(config, "process_config.process_groups.enabled", false)
+	procBindEnvAndSetDefault(config, "process_config.process_groups.group_by_cmdline", false)
+	config.SetKnown("process_config.process_groups.groups")
+
 	// Process Lifecycle Events
 	procBindEnvAndSetDefault(config, "process_config.event_collection.store.max_items", DefaultProcessEventStoreMaxItems)
 	procBindEnvAndSetDefault(config, "process_config.event_collection.store.max_pending_pushes", DefaultProcessEventStoreMaxPendingPushes)
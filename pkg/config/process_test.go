@@ -122,6 +122,14 @@ func TestProcessDefaultConfig(t *testing.T) {
 			key:          "process_config.event_collection.interval",
 			defaultValue: DefaultProcessEventsCheckInterval,
 		},
+		{
+			key:          "process_config.process_groups.enabled",
+			defaultValue: false,
+		},
+		{
+			key:          "process_config.process_groups.group_by_cmdline",
+			defaultValue: false,
+		},
 	} {
 		t.Run(tc.key+" default", func(t *testing.T) {
 			assert.Equal(t, tc.defaultValue, cfg.Get(tc.key))
@@ -151,6 +151,15 @@ func InitSystemProbeConfig(cfg Config) {
 		}
 		return out
 	})
+	cfg.BindEnvAndSetDefault(join(netNS, "enable_kafka_monitoring"), false, "DD_SYSTEM_PROBE_NETWORK_ENABLE_KAFKA_MONITORING")
+	cfg.BindEnvAndSetDefault(join(netNS, "enable_postgres_monitoring"), false, "DD_SYSTEM_PROBE_NETWORK_ENABLE_POSTGRES_MONITORING")
+	cfg.BindEnvAndSetDefault(join(netNS, "enable_redis_monitoring"), false, "DD_SYSTEM_PROBE_NETWORK_ENABLE_REDIS_MONITORING")
+	cfg.BindEnvAndSetDefault(join(netNS, "kafka_ports"), []string{"9092"}, "DD_SYSTEM_PROBE_NETWORK_KAFKA_PORTS")
+	cfg.BindEnvAndSetDefault(join(netNS, "postgres_ports"), []string{"5432"}, "DD_SYSTEM_PROBE_NETWORK_POSTGRES_PORTS")
+	cfg.BindEnvAndSetDefault(join(netNS, "redis_ports"), []string{"6379"}, "DD_SYSTEM_PROBE_NETWORK_REDIS_PORTS")
+	cfg.BindEnvAndSetDefault(join(netNS, "enable_http2_monitoring"), false, "DD_SYSTEM_PROBE_NETWORK_ENABLE_HTTP2_MONITORING")
+	cfg.BindEnvAndSetDefault(join(netNS, "http2_ports"), []string{"50051"}, "DD_SYSTEM_PROBE_NETWORK_HTTP2_PORTS")
+	cfg.BindEnvAndSetDefault(join(netNS, "max_protocol_stats_buffered"), 100000, "DD_SYSTEM_PROBE_NETWORK_MAX_PROTOCOL_STATS_BUFFERED")
 	cfg.BindEnvAndSetDefault(join(netNS, "max_tracked_http_connections"), 1024)
 	cfg.BindEnvAndSetDefault(join(netNS, "http_notification_threshold"), 512)
 	cfg.BindEnvAndSetDefault(join(netNS, "http_max_request_fragment"), 160)
@@ -4,4 +4,4 @@
 
 package runtime
 
-var Http = NewAsset("http.c", "0518c075f400ad4a462e5a39b6fd31e7a4afaa99cd652a6848e3b2f18334051a")
+var Http = NewAsset("http.c", "0a2db0d63da63b6d691462de356c2bf5cbd0f5a629d120ac1629da1b4357993e")
@@ -4,4 +4,4 @@
 
 package runtime
 
-var Tracer = NewAsset("tracer.c", "9d798d82c6e212ae8154336649b01ae4f4f5138a48a5278a9725522de1d3a0ee")
+var Tracer = NewAsset("tracer.c", "5e2b5927779d58fdbcf9b04e741245c3033f88935d01f10aa4eeb4cd5913cb2d")
@@ -9,6 +9,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/fatih/color"
 
@@ -163,3 +166,96 @@ func printContainerExclusionRulesInfo(w io.Writer, c *integration.Config) {
 		fmt.Fprintln(w, fmt.Sprintf("%s", color.BlueString(msg)))
 	}
 }
+
+// GetConfigCheckExplain prints how the autodiscovery templates match the
+// given service (or container) ID
+func GetConfigCheckExplain(w io.Writer, id string) error {
+	if w != color.Output {
+		color.NoColor = true
+	}
+
+	c := util.GetClient(false) // FIX: get certificates right then make this true
+
+	// Set session token
+	err := util.SetAuthToken()
+	if err != nil {
+		return err
+	}
+	ipcAddress, err := config.GetIPCAddress()
+	if err != nil {
+		return err
+	}
+	explainURL := fmt.Sprintf("https://%v:%v/agent/config-check/explain?service=%s", ipcAddress, config.Datadog.GetInt("cmd_port"), url.QueryEscape(id))
+	r, err := util.DoGet(c, explainURL, util.LeaveConnectionOpen)
+	if err != nil {
+		if r != nil && string(r) != "" {
+			return fmt.Errorf("the agent ran into an error while explaining configs: %s", string(r))
+		}
+		return fmt.Errorf("failed to query the agent (running?): %s", err)
+	}
+
+	explanation := integration.ServiceExplanation{}
+	err = json.Unmarshal(r, &explanation)
+	if err != nil {
+		return err
+	}
+
+	PrintServiceExplanation(w, explanation)
+	return nil
+}
+
+// PrintServiceExplanation prints a human-readable representation of a service explanation
+func PrintServiceExplanation(w io.Writer, e integration.ServiceExplanation) {
+	fmt.Fprintln(w, fmt.Sprintf("=== Service %s ===", color.GreenString(e.ServiceID)))
+	fmt.Fprintln(w, fmt.Sprintf("%s: %s", color.BlueString("Tagger entity"), color.CyanString(e.TaggerEntity)))
+	if e.ADIdentifiersError != "" {
+		fmt.Fprintln(w, fmt.Sprintf("%s: %s", color.BlueString("Auto-discovery IDs"), color.RedString(e.ADIdentifiersError)))
+	} else {
+		fmt.Fprintln(w, fmt.Sprintf("%s:", color.BlueString("Auto-discovery IDs")))
+		for _, id := range e.ADIdentifiers {
+			fmt.Fprintln(w, fmt.Sprintf("* %s", color.CyanString(id)))
+		}
+	}
+
+	fmt.Fprintln(w, fmt.Sprintf("\n=== %s ===", color.GreenString("Templates")))
+	for _, tpl := range e.Templates {
+		var outcome string
+		switch {
+		case len(tpl.MatchedADIdentifiers) == 0:
+			outcome = color.YellowString("no matching AD identifier")
+		case tpl.Filtered:
+			outcome = color.YellowString("filtered out by the service")
+		case tpl.ResolveError != "":
+			outcome = color.RedString("resolution failed: %s", tpl.ResolveError)
+		case tpl.Scheduled:
+			outcome = color.GreenString("scheduled as %s", tpl.ResolvedDigest)
+		default:
+			outcome = color.YellowString("resolved as %s, not scheduled", tpl.ResolvedDigest)
+		}
+
+		fmt.Fprintln(w, fmt.Sprintf("\n%s (%s, %s)", color.GreenString(tpl.Template.Name), tpl.Template.Provider, tpl.Template.Source))
+		fmt.Fprintln(w, fmt.Sprintf("%s: %s", color.BlueString("Template digest"), tpl.Digest))
+		if len(tpl.MatchedADIdentifiers) > 0 {
+			fmt.Fprintln(w, fmt.Sprintf("%s: %s", color.BlueString("Matched AD identifiers"), strings.Join(tpl.MatchedADIdentifiers, ", ")))
+		}
+		if len(tpl.MissedADIdentifiers) > 0 {
+			fmt.Fprintln(w, fmt.Sprintf("%s: %s", color.BlueString("Missed AD identifiers"), strings.Join(tpl.MissedADIdentifiers, ", ")))
+		}
+		fmt.Fprintln(w, fmt.Sprintf("%s: %s", color.BlueString("Outcome"), outcome))
+		if tpl.Scheduled {
+			fmt.Fprintln(w, fmt.Sprintf("%s: %s", color.BlueString("Schedulers"), color.CyanString(strings.Join(tpl.Schedulers, ", "))))
+		}
+	}
+
+	if len(e.Events) > 0 {
+		fmt.Fprintln(w, fmt.Sprintf("\n=== %s ===", color.GreenString("Scheduling events")))
+		for _, event := range e.Events {
+			action := color.GreenString("scheduled")
+			if !event.Scheduled {
+				action = color.YellowString("unscheduled")
+			}
+			fmt.Fprintln(w, fmt.Sprintf("%s %s %s (digest %s, provider %s)", event.Timestamp.Format(time.RFC3339), action, event.CheckName, event.Digest, event.Provider))
+		}
+	}
+	fmt.Fprintln(w, "===")
+}
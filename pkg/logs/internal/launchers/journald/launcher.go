@@ -24,6 +24,7 @@ import (
 // Launcher is in charge of starting and stopping new journald tailers
 type Launcher struct {
 	sources          chan *sources.LogSource
+	removedSources   chan *sources.LogSource
 	pipelineProvider pipeline.Provider
 	registry         auditor.Registry
 	tailers          map[string]*tailer.Tailer
@@ -40,7 +41,7 @@ func NewLauncher() *Launcher {
 
 // Start starts the launcher.
 func (l *Launcher) Start(sourceProvider launchers.SourceProvider, pipelineProvider pipeline.Provider, registry auditor.Registry) {
-	l.sources = sourceProvider.GetAddedForType(config.JournaldType)
+	l.sources, l.removedSources = sourceProvider.SubscribeForType(config.JournaldType)
 	l.pipelineProvider = pipelineProvider
 	l.registry = registry
 	go l.run()
@@ -51,7 +52,7 @@ func (l *Launcher) run() {
 	for {
 		select {
 		case source := <-l.sources:
-			identifier := source.Config.Path
+			identifier := tailerKey(source)
 			if _, exists := l.tailers[identifier]; exists {
 				// set up only one tailer per journal
 				continue
@@ -62,12 +63,28 @@ func (l *Launcher) run() {
 			} else {
 				l.tailers[identifier] = tailer
 			}
+		case source := <-l.removedSources:
+			identifier := tailerKey(source)
+			if tailer, exists := l.tailers[identifier]; exists && tailer.Source() == source {
+				tailer.Stop()
+				delete(l.tailers, identifier)
+			}
 		case <-l.stop:
 			return
 		}
 	}
 }
 
+// tailerKey returns the key of the tailer for the source.  Sources scheduled
+// by autodiscovery for a service, such as a systemd unit, have an identifier
+// and get their own tailer on the journal.
+func tailerKey(source *sources.LogSource) string {
+	if source.Config.Identifier != "" {
+		return source.Config.Path + ":" + source.Config.Identifier
+	}
+	return source.Config.Path
+}
+
 // Stop stops all active tailers
 func (l *Launcher) Stop() {
 	l.stop <- struct{}{}
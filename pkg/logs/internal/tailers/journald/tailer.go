@@ -407,9 +407,17 @@ const journaldIntegration = "journald"
 
 // Identifier returns the unique identifier of the current journal being tailed.
 func (t *Tailer) Identifier() string {
+	if t.source.Config.Identifier != "" {
+		return journaldIntegration + ":" + t.journalPath() + ":" + t.source.Config.Identifier
+	}
 	return journaldIntegration + ":" + t.journalPath()
 }
 
+// Source returns the source being tailed.
+func (t *Tailer) Source() *sources.LogSource {
+	return t.source
+}
+
 // journalPath returns the path of the journal
 func (t *Tailer) journalPath() string {
 	if t.source.Config.Path != "" {
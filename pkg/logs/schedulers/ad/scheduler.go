@@ -24,6 +24,9 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
+// systemdUnitServiceType is the type of the services created for systemd units
+const systemdUnitServiceType = "systemd_unit"
+
 // Scheduler creates and deletes new sources and services to start or stop
 // log collection based on information from autodiscovery.
 //
@@ -231,7 +234,13 @@ func (s *Scheduler) toSources(config integration.Config) ([]*sourcesPkg.LogSourc
 		if service != nil {
 			// a config defined in a container label or a pod annotation does not always contain a type,
 			// override it here to ensure that the config won't be dropped at validation.
-			if cfg.Type == logsConfig.FileType && (config.Provider == names.Kubernetes || config.Provider == names.Container || config.Provider == names.KubeContainer) {
+			if service.Type == systemdUnitServiceType {
+				// systemd units log to the journal, which is tailed for the
+				// unit rather than matched with a service
+				cfg.Type = logsConfig.JournaldType
+				cfg.IncludeSystemUnits = []string{service.Identifier}
+				cfg.Identifier = service.Identifier
+			} else if cfg.Type == logsConfig.FileType && (config.Provider == names.Kubernetes || config.Provider == names.Container || config.Provider == names.KubeContainer) {
 				// cfg.Type is not overwritten as tailing a file from a Docker or Kubernetes AD configuration
 				// is explicitly supported (other combinations may be supported later)
 				cfg.Identifier = service.Identifier
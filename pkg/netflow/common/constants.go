@@ -23,4 +23,7 @@ const (
 
 	// DefaultBindHost is the default bind host used for flow listeners
 	DefaultBindHost = "0.0.0.0"
+
+	// DefaultEnrichmentCacheSize is the default number of IP addresses kept in the enrichment cache
+	DefaultEnrichmentCacheSize = 10000
 )
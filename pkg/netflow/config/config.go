@@ -7,6 +7,7 @@ package config
 
 import (
 	"fmt"
+	"net"
 
 	coreconfig "github.com/DataDog/datadog-agent/pkg/config"
 
@@ -27,6 +28,22 @@ type NetflowConfig struct {
 
 	// AggregatorRollupTrackerRefreshInterval is useful to speed up testing to avoid wait for 1h default
 	AggregatorRollupTrackerRefreshInterval uint `mapstructure:"aggregator_rollup_tracker_refresh_interval"`
+
+	Enrichment EnrichmentConfig `mapstructure:"enrichment"`
+}
+
+// EnrichmentConfig contains configuration for the enrichment of flow IP addresses
+type EnrichmentConfig struct {
+	GeoIPDatabase string             `mapstructure:"geoip_database"`
+	ASNDatabase   string             `mapstructure:"asn_database"`
+	CIDRLabels    []CIDRLabelsConfig `mapstructure:"cidr_labels"`
+	CacheSize     int                `mapstructure:"cache_size"`
+}
+
+// CIDRLabelsConfig contains the labels of the IP addresses of a CIDR
+type CIDRLabelsConfig struct {
+	CIDR   string   `mapstructure:"cidr"`
+	Labels []string `mapstructure:"labels"`
 }
 
 // ListenerConfig contains configuration for a single flow listener
@@ -97,9 +114,23 @@ func ReadConfig() (*NetflowConfig, error) {
 		mainConfig.AggregatorRollupTrackerRefreshInterval = common.DefaultAggregatorRollupTrackerRefreshInterval
 	}
 
+	for _, cidrConfig := range mainConfig.Enrichment.CIDRLabels {
+		if _, _, err := net.ParseCIDR(cidrConfig.CIDR); err != nil {
+			return nil, fmt.Errorf("invalid cidr `%s` in enrichment cidr_labels: %s", cidrConfig.CIDR, err)
+		}
+	}
+	if mainConfig.Enrichment.CacheSize == 0 {
+		mainConfig.Enrichment.CacheSize = common.DefaultEnrichmentCacheSize
+	}
+
 	return &mainConfig, nil
 }
 
+// IsEnabled returns whether IP addresses are enriched
+func (c *EnrichmentConfig) IsEnabled() bool {
+	return c.GeoIPDatabase != "" || c.ASNDatabase != "" || len(c.CIDRLabels) > 0
+}
+
 // Addr returns the host:port address to listen on.
 func (c *ListenerConfig) Addr() string {
 	return fmt.Sprintf("%s:%d", c.BindHost, c.Port)
@@ -37,6 +37,15 @@ network_devices:
     aggregator_rollup_tracker_refresh_interval: 60
     log_payloads: true
     aggregator_port_rollup_disabled: true
+    enrichment:
+      geoip_database: /opt/geoip/GeoLite2-City.mmdb
+      asn_database: /opt/geoip/GeoLite2-ASN.mmdb
+      cidr_labels:
+        - cidr: 10.20.0.0/16
+          labels: [datacenter:ams]
+        - cidr: 10.30.0.0/16
+          labels: [datacenter:par, env:prod]
+      cache_size: 500
     listeners:
       - flow_type: netflow9
         bind_host: 127.0.0.1
@@ -59,6 +68,15 @@ network_devices:
 				AggregatorPortRollupThreshold:          20,
 				AggregatorRollupTrackerRefreshInterval: 60,
 				AggregatorPortRollupDisabled:           true,
+				Enrichment: EnrichmentConfig{
+					GeoIPDatabase: "/opt/geoip/GeoLite2-City.mmdb",
+					ASNDatabase:   "/opt/geoip/GeoLite2-ASN.mmdb",
+					CIDRLabels: []CIDRLabelsConfig{
+						{CIDR: "10.20.0.0/16", Labels: []string{"datacenter:ams"}},
+						{CIDR: "10.30.0.0/16", Labels: []string{"datacenter:par", "env:prod"}},
+					},
+					CacheSize: 500,
+				},
 				Listeners: []ListenerConfig{
 					{
 						FlowType:  common.TypeNetFlow9,
@@ -93,6 +111,9 @@ network_devices:
 				AggregatorFlowContextTTL:               300,
 				AggregatorPortRollupThreshold:          10,
 				AggregatorRollupTrackerRefreshInterval: 300,
+				Enrichment: EnrichmentConfig{
+					CacheSize: 10000,
+				},
 				Listeners: []ListenerConfig{
 					{
 						FlowType:  common.TypeNetFlow9,
@@ -121,6 +142,9 @@ network_devices:
 				AggregatorFlowContextTTL:               50,
 				AggregatorPortRollupThreshold:          10,
 				AggregatorRollupTrackerRefreshInterval: 300,
+				Enrichment: EnrichmentConfig{
+					CacheSize: 10000,
+				},
 				Listeners: []ListenerConfig{
 					{
 						FlowType:  common.TypeNetFlow9,
@@ -143,6 +167,21 @@ network_devices:
 `,
 			expectedError: "the provided flow type `invalidType` is not valid",
 		},
+		{
+			name: "invalid enrichment cidr",
+			configYaml: `
+network_devices:
+  netflow:
+    enabled: true
+    enrichment:
+      cidr_labels:
+        - cidr: 10.20.0.0/33
+          labels: [datacenter:ams]
+    listeners:
+      - flow_type: netflow9
+`,
+			expectedError: "invalid cidr `10.20.0.0/33` in enrichment cidr_labels",
+		},
 		{
 			name: "invalid namespace with >100 chars",
 			configYaml: `
@@ -0,0 +1,129 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package enrichment
+
+import (
+	"fmt"
+	"net"
+
+	lru "github.com/hashicorp/golang-lru"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+
+	"github.com/DataDog/datadog-agent/pkg/netflow/config"
+)
+
+// IPInfo contains the geolocation, autonomous system and labels of an IP address
+type IPInfo struct {
+	Country string
+	City    string
+	ASN     uint32
+	ASOrg   string
+	Labels  []string
+}
+
+type cidrLabels struct {
+	network *net.IPNet
+	labels  []string
+}
+
+// IPEnricher resolves the IPInfo of IP addresses from MaxMind DB files and CIDR
+// labels, the results are kept in a bounded LRU cache
+type IPEnricher struct {
+	geoIPDatabase *mmdbReader
+	asnDatabase   *mmdbReader
+	cidrLabels    []cidrLabels
+	cache         *lru.Cache
+}
+
+// NewIPEnricher returns an IPEnricher for the enrichment config
+func NewIPEnricher(conf config.EnrichmentConfig) (*IPEnricher, error) {
+	enricher := &IPEnricher{}
+	var err error
+	if conf.GeoIPDatabase != "" {
+		enricher.geoIPDatabase, err = openMMDB(conf.GeoIPDatabase)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load GeoIP database: %s", err)
+		}
+	}
+	if conf.ASNDatabase != "" {
+		enricher.asnDatabase, err = openMMDB(conf.ASNDatabase)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load ASN database: %s", err)
+		}
+	}
+	for _, cidrConfig := range conf.CIDRLabels {
+		_, network, err := net.ParseCIDR(cidrConfig.CIDR)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cidr `%s`: %s", cidrConfig.CIDR, err)
+		}
+		enricher.cidrLabels = append(enricher.cidrLabels, cidrLabels{network: network, labels: cidrConfig.Labels})
+	}
+	enricher.cache, err = lru.New(conf.CacheSize)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cache size %d: %s", conf.CacheSize, err)
+	}
+	return enricher, nil
+}
+
+// Enrich returns the IPInfo of an IP address
+func (e *IPEnricher) Enrich(ipAddr []byte) IPInfo {
+	if len(ipAddr) == 0 {
+		return IPInfo{}
+	}
+	key := string(ipAddr)
+	if info, ok := e.cache.Get(key); ok {
+		return info.(IPInfo)
+	}
+	info := e.resolve(net.IP(ipAddr))
+	e.cache.Add(key, info)
+	return info
+}
+
+func (e *IPEnricher) resolve(ip net.IP) IPInfo {
+	var info IPInfo
+	if record := lookupMap(e.geoIPDatabase, ip); record != nil {
+		info.Country = getString(record, "country", "iso_code")
+		info.City = getString(record, "city", "names", "en")
+	}
+	if record := lookupMap(e.asnDatabase, ip); record != nil {
+		info.ASN = uint32(mmdbUint(record["autonomous_system_number"]))
+		info.ASOrg = getString(record, "autonomous_system_organization")
+	}
+	for _, cidr := range e.cidrLabels {
+		if cidr.network.Contains(ip) {
+			info.Labels = append(info.Labels, cidr.labels...)
+		}
+	}
+	return info
+}
+
+// lookupMap returns the record of an IP address in a MaxMind DB, or nil if not found
+func lookupMap(database *mmdbReader, ip net.IP) map[string]interface{} {
+	if database == nil {
+		return nil
+	}
+	record, err := database.lookup(ip)
+	if err != nil {
+		log.Debugf("failed to lookup %s in %s database: %s", ip, database.databaseType, err)
+		return nil
+	}
+	recordMap, _ := record.(map[string]interface{})
+	return recordMap
+}
+
+// getString returns the string at the path of nested maps, or an empty string
+func getString(record map[string]interface{}, path ...string) string {
+	for _, key := range path[:len(path)-1] {
+		nested, ok := record[key].(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		record = nested
+	}
+	value, _ := record[path[len(path)-1]].(string)
+	return value
+}
@@ -0,0 +1,70 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package enrichment
+
+import (
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/netflow/config"
+)
+
+func TestIPEnricher(t *testing.T) {
+	dir := t.TempDir()
+	geoIPDatabase := filepath.Join(dir, "GeoLite2-City.mmdb")
+	require.NoError(t, ioutil.WriteFile(geoIPDatabase, buildTestMMDB(t, 6, 28, []testMMDBNetwork{
+		{cidr: "81.2.69.0/24", data: map[string]interface{}{
+			"city":    map[string]interface{}{"names": map[string]interface{}{"en": "London", "fr": "Londres"}},
+			"country": map[string]interface{}{"iso_code": "GB"},
+		}},
+	}), 0600))
+	asnDatabase := filepath.Join(dir, "GeoLite2-ASN.mmdb")
+	require.NoError(t, ioutil.WriteFile(asnDatabase, buildTestMMDB(t, 6, 24, []testMMDBNetwork{
+		{cidr: "81.2.69.0/24", data: map[string]interface{}{
+			"autonomous_system_number":       uint32(20712),
+			"autonomous_system_organization": "Andrews & Arnold Ltd",
+		}},
+	}), 0600))
+
+	enricher, err := NewIPEnricher(config.EnrichmentConfig{
+		GeoIPDatabase: geoIPDatabase,
+		ASNDatabase:   asnDatabase,
+		CIDRLabels: []config.CIDRLabelsConfig{
+			{CIDR: "10.20.0.0/16", Labels: []string{"datacenter:ams"}},
+			{CIDR: "10.0.0.0/8", Labels: []string{"network:private"}},
+		},
+		CacheSize: 2,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, IPInfo{Country: "GB", City: "London", ASN: 20712, ASOrg: "Andrews & Arnold Ltd"}, enricher.Enrich(net.ParseIP("81.2.69.142").To4()))
+	assert.Equal(t, IPInfo{Labels: []string{"datacenter:ams", "network:private"}}, enricher.Enrich([]byte{10, 20, 1, 1}))
+	assert.Equal(t, IPInfo{Labels: []string{"network:private"}}, enricher.Enrich([]byte{10, 30, 1, 1}))
+	assert.Equal(t, IPInfo{}, enricher.Enrich([]byte{8, 8, 8, 8}))
+	assert.Equal(t, IPInfo{}, enricher.Enrich(nil))
+
+	// the cache is bounded
+	assert.Equal(t, 2, enricher.cache.Len())
+	info, ok := enricher.cache.Get(string([]byte{8, 8, 8, 8}))
+	assert.True(t, ok)
+	assert.Equal(t, IPInfo{}, info)
+}
+
+func TestNewIPEnricher_errors(t *testing.T) {
+	_, err := NewIPEnricher(config.EnrichmentConfig{GeoIPDatabase: "/does/not/exist.mmdb", CacheSize: 10})
+	assert.ErrorContains(t, err, "failed to load GeoIP database")
+
+	_, err = NewIPEnricher(config.EnrichmentConfig{CIDRLabels: []config.CIDRLabelsConfig{{CIDR: "10.0.0.0"}}, CacheSize: 10})
+	assert.EqualError(t, err, "invalid cidr `10.0.0.0`: invalid CIDR address: 10.0.0.0")
+
+	_, err = NewIPEnricher(config.EnrichmentConfig{})
+	assert.ErrorContains(t, err, "invalid cache size 0")
+}
@@ -0,0 +1,384 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package enrichment
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net"
+)
+
+// mmdbMetadataStartMarker precedes the metadata section, at the end of MaxMind DB files
+var mmdbMetadataStartMarker = []byte("\xAB\xCD\xEFMaxMind.com")
+
+// mmdbDataSectionSeparatorSize is the size of the zeroes between the search tree and the data section
+const mmdbDataSectionSeparatorSize = 16
+
+// mmdbMaxDepth bounds the nesting of maps and arrays, which pointers to
+// enclosing values of malformed files would make infinite
+const mmdbMaxDepth = 512
+
+// mmdbMaxDecodedValues bounds the number of values decoded at once, GeoIP
+// records hold a few hundred values at most
+const mmdbMaxDecodedValues = 1 << 16
+
+// MaxMind DB data types
+// See https://maxmind.github.io/MaxMind-DB/
+const (
+	mmdbTypeExtended = iota
+	mmdbTypePointer
+	mmdbTypeString
+	mmdbTypeDouble
+	mmdbTypeBytes
+	mmdbTypeUint16
+	mmdbTypeUint32
+	mmdbTypeMap
+	mmdbTypeInt32
+	mmdbTypeUint64
+	mmdbTypeUint128
+	mmdbTypeArray
+	mmdbTypeContainer
+	mmdbTypeEndMarker
+	mmdbTypeBoolean
+	mmdbTypeFloat
+)
+
+// mmdbReader looks up IP addresses in a MaxMind DB file, like the GeoLite2 databases
+type mmdbReader struct {
+	buffer       []byte
+	data         []byte
+	nodeCount    uint
+	recordSize   uint
+	ipVersion    uint
+	databaseType string
+	// ipv4Start is the node of the `::/96` subtree holding IPv4 addresses in IPv6 databases
+	ipv4Start uint
+}
+
+// openMMDB reads a MaxMind DB file
+func openMMDB(path string) (*mmdbReader, error) {
+	buffer, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := newMMDBReader(buffer)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MaxMind DB file `%s`: %s", path, err)
+	}
+	return reader, nil
+}
+
+func newMMDBReader(buffer []byte) (*mmdbReader, error) {
+	metadataStart := bytes.LastIndex(buffer, mmdbMetadataStartMarker)
+	if metadataStart == -1 {
+		return nil, fmt.Errorf("metadata section not found")
+	}
+	metadataStart += len(mmdbMetadataStartMarker)
+	decodedMetadata, _, err := (&mmdbDecoder{buffer: buffer[metadataStart:]}).decode(0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode metadata: %s", err)
+	}
+	metadata, ok := decodedMetadata.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("metadata is not a map")
+	}
+
+	reader := &mmdbReader{
+		buffer:     buffer,
+		nodeCount:  mmdbUint(metadata["node_count"]),
+		recordSize: mmdbUint(metadata["record_size"]),
+		ipVersion:  mmdbUint(metadata["ip_version"]),
+	}
+	reader.databaseType, _ = metadata["database_type"].(string)
+	if reader.recordSize != 24 && reader.recordSize != 28 && reader.recordSize != 32 {
+		return nil, fmt.Errorf("unsupported record size %d", reader.recordSize)
+	}
+	if reader.ipVersion != 4 && reader.ipVersion != 6 {
+		return nil, fmt.Errorf("unsupported ip version %d", reader.ipVersion)
+	}
+
+	// check the node count before computing the search tree size, which
+	// would overflow for huge counts
+	if reader.nodeCount > uint(len(buffer))*4/reader.recordSize {
+		return nil, fmt.Errorf("search tree of %d nodes exceeds the file size", reader.nodeCount)
+	}
+	searchTreeSize := reader.nodeCount * reader.recordSize / 4
+	dataStart := searchTreeSize + mmdbDataSectionSeparatorSize
+	if dataStart > uint(metadataStart-len(mmdbMetadataStartMarker)) {
+		return nil, fmt.Errorf("search tree of %d nodes exceeds the file size", reader.nodeCount)
+	}
+	reader.data = buffer[dataStart : metadataStart-len(mmdbMetadataStartMarker)]
+
+	if reader.ipVersion == 6 {
+		node := uint(0)
+		for i := 0; i < 96 && node < reader.nodeCount; i++ {
+			node = reader.readRecord(node, 0)
+		}
+		reader.ipv4Start = node
+	}
+	return reader, nil
+}
+
+// readRecord returns the left (bit 0) or right (bit 1) record of a node
+func (r *mmdbReader) readRecord(node uint, bit uint) uint {
+	switch r.recordSize {
+	case 24:
+		offset := node*6 + bit*3
+		b := r.buffer[offset : offset+3]
+		return uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+	case 28:
+		offset := node * 7
+		b := r.buffer[offset : offset+7]
+		if bit == 0 {
+			return uint(b[3]&0xF0)<<20 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+		}
+		return uint(b[3]&0x0F)<<24 | uint(b[4])<<16 | uint(b[5])<<8 | uint(b[6])
+	default:
+		offset := node*8 + bit*4
+		return uint(binary.BigEndian.Uint32(r.buffer[offset : offset+4]))
+	}
+}
+
+// lookup returns the data of the network containing the IP, or nil if not found
+func (r *mmdbReader) lookup(ip net.IP) (interface{}, error) {
+	node := uint(0)
+	if ipv4 := ip.To4(); ipv4 != nil {
+		ip = ipv4
+		if r.ipVersion == 6 {
+			node = r.ipv4Start
+		}
+	} else if r.ipVersion == 4 {
+		return nil, nil
+	}
+
+	bitCount := uint(len(ip) * 8)
+	for i := uint(0); i < bitCount && node < r.nodeCount; i++ {
+		bit := uint(ip[i>>3]>>(7-(i&7))) & 1
+		node = r.readRecord(node, bit)
+	}
+	if node <= r.nodeCount {
+		// node == nodeCount means the IP is not in the database
+		return nil, nil
+	}
+	offset := node - r.nodeCount - mmdbDataSectionSeparatorSize
+	if offset >= uint(len(r.data)) {
+		return nil, fmt.Errorf("invalid data section offset %d", offset)
+	}
+	value, _, err := (&mmdbDecoder{buffer: r.data}).decode(offset)
+	return value, err
+}
+
+// mmdbDecoder decodes values of a MaxMind DB data section
+type mmdbDecoder struct {
+	buffer []byte
+	// pointedValues memoises the values pointers point to, so that values shared
+	// through pointers are decoded once
+	pointedValues map[uint]interface{}
+	// decodedValues counts the values decoded, bounded by mmdbMaxDecodedValues
+	decodedValues int
+}
+
+// decode decodes the value at offset, and returns the offset following it
+func (d *mmdbDecoder) decode(offset uint) (interface{}, uint, error) {
+	return d.decodeValue(offset, 0)
+}
+
+// decodeValue decodes the value at offset, nested in depth maps and arrays
+func (d *mmdbDecoder) decodeValue(offset uint, depth int) (interface{}, uint, error) {
+	if depth > mmdbMaxDepth {
+		return nil, 0, fmt.Errorf("values nested deeper than %d levels at offset %d", mmdbMaxDepth, offset)
+	}
+	d.decodedValues++
+	if d.decodedValues > mmdbMaxDecodedValues {
+		return nil, 0, fmt.Errorf("more than %d values decoded at offset %d", mmdbMaxDecodedValues, offset)
+	}
+
+	start := offset
+	dataType, size, offset, err := d.decodeControl(offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if dataType == mmdbTypePointer {
+		pointer, next, err := d.decodePointer(size, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		// pointers can't point to pointers, see
+		// https://maxmind.github.io/MaxMind-DB/#pointer---1
+		if pointedType, _, _, err := d.decodeControl(pointer); err != nil {
+			return nil, 0, err
+		} else if pointedType == mmdbTypePointer {
+			return nil, 0, fmt.Errorf("pointer at offset %d points to a pointer", start)
+		}
+		if value, found := d.pointedValues[pointer]; found {
+			return value, next, nil
+		}
+		value, _, err := d.decodeValue(pointer, depth)
+		if err != nil {
+			return nil, 0, err
+		}
+		if d.pointedValues == nil {
+			d.pointedValues = make(map[uint]interface{})
+		}
+		d.pointedValues[pointer] = value
+		return value, next, nil
+	}
+
+	// each entry takes at least one byte, which bounds the memory allocated
+	// for malformed sizes
+	capacity := size
+	if remaining := uint(len(d.buffer)) - offset; capacity > remaining {
+		capacity = remaining
+	}
+
+	switch dataType {
+	case mmdbTypeMap:
+		value := make(map[string]interface{}, capacity)
+		for i := uint(0); i < size; i++ {
+			key, next, err := d.decodeValue(offset, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			strKey, ok := key.(string)
+			if !ok {
+				return nil, 0, fmt.Errorf("invalid map key at offset %d", offset)
+			}
+			value[strKey], offset, err = d.decodeValue(next, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+		}
+		return value, offset, nil
+	case mmdbTypeArray:
+		value := make([]interface{}, 0, capacity)
+		for i := uint(0); i < size; i++ {
+			var item interface{}
+			item, offset, err = d.decodeValue(offset, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			value = append(value, item)
+		}
+		return value, offset, nil
+	case mmdbTypeBoolean:
+		return size != 0, offset, nil
+	}
+
+	if offset+size > uint(len(d.buffer)) {
+		return nil, 0, fmt.Errorf("value of type %d at offset %d exceeds the data section", dataType, offset)
+	}
+	raw := d.buffer[offset : offset+size]
+	next := offset + size
+	switch dataType {
+	case mmdbTypeString:
+		return string(raw), next, nil
+	case mmdbTypeBytes:
+		return append([]byte{}, raw...), next, nil
+	case mmdbTypeDouble:
+		if size != 8 {
+			return nil, 0, fmt.Errorf("invalid double size %d", size)
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(raw)), next, nil
+	case mmdbTypeFloat:
+		if size != 4 {
+			return nil, 0, fmt.Errorf("invalid float size %d", size)
+		}
+		return math.Float32frombits(binary.BigEndian.Uint32(raw)), next, nil
+	case mmdbTypeUint16, mmdbTypeUint32, mmdbTypeUint64, mmdbTypeInt32:
+		if size > 8 {
+			return nil, 0, fmt.Errorf("invalid integer size %d", size)
+		}
+		var value uint64
+		for _, b := range raw {
+			value = value<<8 | uint64(b)
+		}
+		if dataType == mmdbTypeInt32 {
+			return int32(value), next, nil
+		}
+		return value, next, nil
+	case mmdbTypeUint128:
+		// not used by the GeoIP databases, kept as raw bytes
+		return append([]byte{}, raw...), next, nil
+	}
+	return nil, 0, fmt.Errorf("unsupported data type %d at offset %d", dataType, offset)
+}
+
+// decodeControl decodes the control byte of a value, and returns its type, its size
+// and the offset of its payload
+func (d *mmdbDecoder) decodeControl(offset uint) (uint, uint, uint, error) {
+	if offset >= uint(len(d.buffer)) {
+		return 0, 0, 0, fmt.Errorf("unexpected end of data at offset %d", offset)
+	}
+	control := d.buffer[offset]
+	offset++
+	dataType := uint(control >> 5)
+	if dataType == mmdbTypeExtended {
+		if offset >= uint(len(d.buffer)) {
+			return 0, 0, 0, fmt.Errorf("unexpected end of data at offset %d", offset)
+		}
+		dataType = uint(d.buffer[offset]) + 7
+		offset++
+	}
+	size := uint(control & 0x1f)
+	if dataType == mmdbTypePointer || size < 29 {
+		return dataType, size, offset, nil
+	}
+
+	extraBytes := size - 28
+	if offset+extraBytes > uint(len(d.buffer)) {
+		return 0, 0, 0, fmt.Errorf("unexpected end of data at offset %d", offset)
+	}
+	var extra uint
+	for _, b := range d.buffer[offset : offset+extraBytes] {
+		extra = extra<<8 | uint(b)
+	}
+	offset += extraBytes
+	switch size {
+	case 29:
+		size = 29 + extra
+	case 30:
+		size = 285 + extra
+	default:
+		size = 65821 + extra
+	}
+	return dataType, size, offset, nil
+}
+
+// decodePointer returns the data section offset of a pointer, with the low bits of
+// the control byte passed as size
+func (d *mmdbDecoder) decodePointer(size uint, offset uint) (uint, uint, error) {
+	pointerSize := ((size >> 3) & 0x3) + 1
+	if offset+pointerSize > uint(len(d.buffer)) {
+		return 0, 0, fmt.Errorf("unexpected end of data at offset %d", offset)
+	}
+	var prefix uint
+	if pointerSize != 4 {
+		prefix = size & 0x7
+	}
+	pointer := prefix
+	for _, b := range d.buffer[offset : offset+pointerSize] {
+		pointer = pointer<<8 | uint(b)
+	}
+	switch pointerSize {
+	case 2:
+		pointer += 2048
+	case 3:
+		pointer += 526336
+	}
+	return pointer, offset + pointerSize, nil
+}
+
+// mmdbUint returns the value of a decoded unsigned integer
+func mmdbUint(value interface{}) uint {
+	if intValue, ok := value.(uint64); ok {
+		return uint(intValue)
+	}
+	return 0
+}
@@ -0,0 +1,301 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package enrichment
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testMMDBNetwork struct {
+	cidr string
+	data map[string]interface{}
+}
+
+// encodeTestMMDBValue encodes maps, strings and unsigned integers in the MaxMind DB format,
+// with sizes up to 284
+func encodeTestMMDBValue(value interface{}) []byte {
+	control := func(dataType int, size int) []byte {
+		var extraSize []byte
+		if size >= 29 {
+			extraSize = []byte{byte(size - 29)}
+			size = 29
+		}
+		if dataType > 7 {
+			return append([]byte{byte(size), byte(dataType - 7)}, extraSize...)
+		}
+		return append([]byte{byte(dataType<<5 | size)}, extraSize...)
+	}
+	uintBytes := func(value uint64) []byte {
+		var buf []byte
+		for ; value > 0; value >>= 8 {
+			buf = append([]byte{byte(value)}, buf...)
+		}
+		return buf
+	}
+	switch v := value.(type) {
+	case string:
+		return append(control(mmdbTypeString, len(v)), v...)
+	case uint16:
+		b := uintBytes(uint64(v))
+		return append(control(mmdbTypeUint16, len(b)), b...)
+	case uint32:
+		b := uintBytes(uint64(v))
+		return append(control(mmdbTypeUint32, len(b)), b...)
+	case uint64:
+		b := uintBytes(v)
+		return append(control(mmdbTypeUint64, len(b)), b...)
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		buf := control(mmdbTypeMap, len(v))
+		for _, key := range keys {
+			buf = append(buf, encodeTestMMDBValue(key)...)
+			buf = append(buf, encodeTestMMDBValue(v[key])...)
+		}
+		return buf
+	}
+	panic(fmt.Sprintf("unsupported test value %#v", value))
+}
+
+// buildTestMMDB builds a MaxMind DB file holding the networks
+func buildTestMMDB(t *testing.T, ipVersion uint16, recordSize uint16, networks []testMMDBNetwork) []byte {
+	const emptyRecord = -1
+	// records are node indexes, emptyRecord or -(2 + data index)
+	nodes := [][2]int{{emptyRecord, emptyRecord}}
+	var data []byte
+	var dataOffsets []int
+
+	for i, network := range networks {
+		_, ipNet, err := net.ParseCIDR(network.cidr)
+		require.NoError(t, err)
+		ip := []byte(ipNet.IP)
+		prefixLen, _ := ipNet.Mask.Size()
+		if ipVersion == 6 && len(ip) == net.IPv4len {
+			ip = append(make([]byte, 12), ip...)
+			prefixLen += 96
+		}
+
+		dataOffsets = append(dataOffsets, len(data))
+		data = append(data, encodeTestMMDBValue(network.data)...)
+
+		node := 0
+		for bitIndex := 0; bitIndex < prefixLen; bitIndex++ {
+			bit := (ip[bitIndex/8] >> (7 - bitIndex%8)) & 1
+			if bitIndex == prefixLen-1 {
+				nodes[node][bit] = -(2 + i)
+				break
+			}
+			if nodes[node][bit] == emptyRecord {
+				nodes = append(nodes, [2]int{emptyRecord, emptyRecord})
+				nodes[node][bit] = len(nodes) - 1
+			}
+			node = nodes[node][bit]
+		}
+	}
+
+	nodeCount := len(nodes)
+	resolve := func(record int) uint32 {
+		switch {
+		case record == emptyRecord:
+			return uint32(nodeCount)
+		case record < emptyRecord:
+			return uint32(nodeCount + mmdbDataSectionSeparatorSize + dataOffsets[-record-2])
+		}
+		return uint32(record)
+	}
+
+	var buffer []byte
+	for _, node := range nodes {
+		left, right := resolve(node[0]), resolve(node[1])
+		switch recordSize {
+		case 24:
+			buffer = append(buffer, byte(left>>16), byte(left>>8), byte(left), byte(right>>16), byte(right>>8), byte(right))
+		case 28:
+			buffer = append(buffer, byte(left>>16), byte(left>>8), byte(left), byte(left>>24)<<4|byte(right>>24)&0x0f, byte(right>>16), byte(right>>8), byte(right))
+		case 32:
+			buffer = binary.BigEndian.AppendUint32(buffer, left)
+			buffer = binary.BigEndian.AppendUint32(buffer, right)
+		}
+	}
+	buffer = append(buffer, make([]byte, mmdbDataSectionSeparatorSize)...)
+	buffer = append(buffer, data...)
+	buffer = append(buffer, mmdbMetadataStartMarker...)
+	buffer = append(buffer, encodeTestMMDBValue(map[string]interface{}{
+		"node_count":    uint32(nodeCount),
+		"record_size":   recordSize,
+		"ip_version":    ipVersion,
+		"database_type": "Test",
+	})...)
+	return buffer
+}
+
+func Test_mmdbReader_lookup(t *testing.T) {
+	networks := []testMMDBNetwork{
+		{cidr: "1.2.3.0/24", data: map[string]interface{}{"country": map[string]interface{}{"iso_code": "FR"}}},
+		{cidr: "8.8.0.0/16", data: map[string]interface{}{"autonomous_system_number": uint32(15169)}},
+		{cidr: "2001:db8::/32", data: map[string]interface{}{"country": map[string]interface{}{"iso_code": "NL"}}},
+	}
+	for _, recordSize := range []uint16{24, 28, 32} {
+		t.Run(fmt.Sprintf("record size %d", recordSize), func(t *testing.T) {
+			reader, err := newMMDBReader(buildTestMMDB(t, 6, recordSize, networks))
+			require.NoError(t, err)
+			assert.Equal(t, "Test", reader.databaseType)
+
+			record, err := reader.lookup(net.ParseIP("1.2.3.4"))
+			assert.NoError(t, err)
+			assert.Equal(t, map[string]interface{}{"country": map[string]interface{}{"iso_code": "FR"}}, record)
+
+			record, err = reader.lookup(net.ParseIP("8.8.8.8"))
+			assert.NoError(t, err)
+			assert.Equal(t, map[string]interface{}{"autonomous_system_number": uint64(15169)}, record)
+
+			record, err = reader.lookup(net.ParseIP("2001:db8::1"))
+			assert.NoError(t, err)
+			assert.Equal(t, map[string]interface{}{"country": map[string]interface{}{"iso_code": "NL"}}, record)
+
+			record, err = reader.lookup(net.ParseIP("1.2.4.4"))
+			assert.NoError(t, err)
+			assert.Nil(t, record)
+		})
+	}
+}
+
+func Test_mmdbReader_lookupIPv4Database(t *testing.T) {
+	reader, err := newMMDBReader(buildTestMMDB(t, 4, 24, []testMMDBNetwork{
+		{cidr: "1.2.3.0/24", data: map[string]interface{}{"country": map[string]interface{}{"iso_code": "FR"}}},
+	}))
+	require.NoError(t, err)
+
+	record, err := reader.lookup(net.ParseIP("1.2.3.4"))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"country": map[string]interface{}{"iso_code": "FR"}}, record)
+
+	record, err = reader.lookup(net.ParseIP("2001:db8::1"))
+	assert.NoError(t, err)
+	assert.Nil(t, record)
+}
+
+func Test_mmdbDecoder_decode(t *testing.T) {
+	longString := "a string longer than 29 characters"
+	buffer := []byte{0x42, 'a', 'b'}
+	// pointer to offset 0
+	buffer = append(buffer, 0x20, 0x00)
+	// string with a size extended by one byte
+	buffer = append(buffer, 0x5d, byte(len(longString)-29))
+	buffer = append(buffer, longString...)
+	// boolean true, extended type 14
+	buffer = append(buffer, 0x01, 0x07)
+	decoder := &mmdbDecoder{buffer: buffer}
+
+	value, next, err := decoder.decode(3)
+	assert.NoError(t, err)
+	assert.Equal(t, "ab", value)
+	assert.Equal(t, uint(5), next)
+
+	value, next, err = decoder.decode(5)
+	assert.NoError(t, err)
+	assert.Equal(t, longString, value)
+
+	value, _, err = decoder.decode(next)
+	assert.NoError(t, err)
+	assert.Equal(t, true, value)
+
+	_, _, err = (&mmdbDecoder{buffer: []byte{0x45, 'a'}}).decode(0)
+	assert.EqualError(t, err, "value of type 2 at offset 1 exceeds the data section")
+}
+
+func Test_mmdbDecoder_decodeMalformed(t *testing.T) {
+	// pointer to itself
+	_, _, err := (&mmdbDecoder{buffer: []byte{0x20, 0x00}}).decode(0)
+	assert.EqualError(t, err, "pointer at offset 0 points to a pointer")
+
+	// pointers to each other
+	_, _, err = (&mmdbDecoder{buffer: []byte{0x20, 0x02, 0x20, 0x00}}).decode(0)
+	assert.EqualError(t, err, "pointer at offset 0 points to a pointer")
+
+	// map {"a": pointer to the map}
+	_, _, err = (&mmdbDecoder{buffer: []byte{0xe1, 0x41, 'a', 0x20, 0x00}}).decode(0)
+	assert.EqualError(t, err, "values nested deeper than 512 levels at offset 1")
+
+	// map announcing more entries than the buffer could hold
+	_, _, err = (&mmdbDecoder{buffer: []byte{0xff, 0xff, 0xff, 0xff}}).decode(0)
+	assert.Error(t, err)
+}
+
+func Test_mmdbDecoder_decodeSharedValues(t *testing.T) {
+	// uint16 0, then maps {"a": pointer to the previous value, "b": pointer to the previous value},
+	// which would decode 2^100 values if the pointed values were not shared
+	buffer := []byte{0xa0}
+	offsets := []int{0}
+	for i := 0; i < 100; i++ {
+		pointer := offsets[len(offsets)-1]
+		offsets = append(offsets, len(buffer))
+		buffer = append(buffer, 0xe2)
+		buffer = append(buffer, 0x41, 'a', 0x20|byte(pointer>>8), byte(pointer))
+		buffer = append(buffer, 0x41, 'b', 0x20|byte(pointer>>8), byte(pointer))
+	}
+
+	value, _, err := (&mmdbDecoder{buffer: buffer}).decode(uint(offsets[len(offsets)-1]))
+	require.NoError(t, err)
+	for i := 0; i < 100; i++ {
+		record, ok := value.(map[string]interface{})
+		require.True(t, ok)
+		require.Len(t, record, 2)
+		value = record["b"]
+	}
+	assert.Equal(t, uint64(0), value)
+}
+
+func Test_mmdbDecoder_decodeTooManyValues(t *testing.T) {
+	// array of mmdbMaxDecodedValues uint16, with a size extended by two bytes
+	size := mmdbMaxDecodedValues - 285
+	buffer := []byte{0x1e, 0x04, byte(size >> 8), byte(size)}
+	buffer = append(buffer, bytes.Repeat([]byte{0xa0}, mmdbMaxDecodedValues)...)
+	_, _, err := (&mmdbDecoder{buffer: buffer}).decode(0)
+	assert.EqualError(t, err, fmt.Sprintf("more than %d values decoded at offset %d", mmdbMaxDecodedValues, len(buffer)-1))
+}
+
+func Test_newMMDBReaderMalformed(t *testing.T) {
+	// metadata pointing to itself
+	buffer := append(append([]byte{}, mmdbMetadataStartMarker...), 0x20, 0x00)
+	_, err := newMMDBReader(buffer)
+	assert.EqualError(t, err, "failed to decode metadata: pointer at offset 0 points to a pointer")
+
+	// node count overflowing the search tree size
+	buffer = append(append([]byte{}, mmdbMetadataStartMarker...), encodeTestMMDBValue(map[string]interface{}{
+		"node_count":  uint64(math.MaxUint64),
+		"record_size": uint16(24),
+		"ip_version":  uint16(6),
+	})...)
+	_, err = newMMDBReader(buffer)
+	assert.EqualError(t, err, fmt.Sprintf("search tree of %d nodes exceeds the file size", uint64(math.MaxUint64)))
+}
+
+func Test_openMMDB(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.mmdb")
+	require.NoError(t, ioutil.WriteFile(path, buildTestMMDB(t, 6, 24, nil), 0600))
+	_, err := openMMDB(path)
+	assert.NoError(t, err)
+
+	invalidPath := filepath.Join(t.TempDir(), "invalid.mmdb")
+	require.NoError(t, ioutil.WriteFile(invalidPath, []byte("not a database"), 0600))
+	_, err = openMMDB(invalidPath)
+	assert.EqualError(t, err, fmt.Sprintf("invalid MaxMind DB file `%s`: metadata section not found", invalidPath))
+}
@@ -13,10 +13,12 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/aggregator"
 	"github.com/DataDog/datadog-agent/pkg/epforwarder"
+	"github.com/DataDog/datadog-agent/pkg/snmp/interfacestore"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
 	"github.com/DataDog/datadog-agent/pkg/netflow/common"
 	"github.com/DataDog/datadog-agent/pkg/netflow/config"
+	"github.com/DataDog/datadog-agent/pkg/netflow/enrichment"
 )
 
 const flowAggregatorFlushInterval = 10 * time.Second
@@ -32,6 +34,9 @@ type FlowAggregator struct {
 	receivedFlowCount            *atomic.Uint64
 	flushedFlowCount             *atomic.Uint64
 	hostname                     string
+	interfaceStore               *interfacestore.Store
+	interfaceUsage               *interfaceUsage
+	ipEnricher                   *enrichment.IPEnricher
 }
 
 // NewFlowAggregator returns a new FlowAggregator
@@ -39,6 +44,15 @@ func NewFlowAggregator(sender aggregator.Sender, config *config.NetflowConfig, h
 	flushInterval := time.Duration(config.AggregatorFlushInterval) * time.Second
 	flowContextTTL := time.Duration(config.AggregatorFlowContextTTL) * time.Second
 	rollupTrackerRefreshInterval := time.Duration(config.AggregatorRollupTrackerRefreshInterval) * time.Second
+
+	var ipEnricher *enrichment.IPEnricher
+	if config.Enrichment.IsEnabled() {
+		var err error
+		ipEnricher, err = enrichment.NewIPEnricher(config.Enrichment)
+		if err != nil {
+			log.Errorf("Error loading IP enrichment, flows won't be enriched: %s", err)
+		}
+	}
 	return &FlowAggregator{
 		flowIn:                       make(chan *common.Flow, config.AggregatorBufferSize),
 		flowAcc:                      newFlowAccumulator(flushInterval, flowContextTTL, config.AggregatorPortRollupThreshold, config.AggregatorPortRollupDisabled),
@@ -49,6 +63,9 @@ func NewFlowAggregator(sender aggregator.Sender, config *config.NetflowConfig, h
 		receivedFlowCount:            atomic.NewUint64(0),
 		flushedFlowCount:             atomic.NewUint64(0),
 		hostname:                     hostname,
+		interfaceStore:               interfacestore.DefaultStore,
+		interfaceUsage:               newInterfaceUsage(),
+		ipEnricher:                   ipEnricher,
 	}
 }
 
@@ -84,7 +101,8 @@ func (agg *FlowAggregator) run() {
 
 func (agg *FlowAggregator) sendFlows(flows []*common.Flow) {
 	for _, flow := range flows {
-		flowPayload := buildPayload(flow, agg.hostname)
+		agg.interfaceUsage.add(flow)
+		flowPayload := buildPayload(flow, agg.hostname, agg.interfaceStore, agg.ipEnricher)
 		payloadBytes, err := json.Marshal(flowPayload)
 		if err != nil {
 			log.Errorf("Error marshalling device metadata: %s", err)
@@ -108,6 +126,13 @@ func (agg *FlowAggregator) flushLoop() {
 
 	rollupTrackersRefresh := time.NewTicker(agg.rollupTrackerRefreshInterval).C
 
+	// flow contexts are flushed once per flowFlushInterval, the interface usage is
+	// reported at the same interval to cover the bytes of all flow contexts
+	var interfaceUsageTicker <-chan time.Time
+	if agg.flowAcc.flowFlushInterval > 0 {
+		interfaceUsageTicker = time.NewTicker(agg.flowAcc.flowFlushInterval).C
+	}
+
 	for {
 		select {
 		// stop sequence
@@ -119,6 +144,9 @@ func (agg *FlowAggregator) flushLoop() {
 		// refresh rollup trackers
 		case <-rollupTrackersRefresh:
 			agg.rollupTrackersRefresh()
+		// report interface utilization
+		case <-interfaceUsageTicker:
+			agg.interfaceUsage.report(agg.sender, agg.interfaceStore, agg.flowAcc.flowFlushInterval)
 		}
 	}
 }
@@ -6,20 +6,23 @@
 package flowaggregator
 
 import (
+	"github.com/DataDog/datadog-agent/pkg/snmp/interfacestore"
+
 	"github.com/DataDog/datadog-agent/pkg/netflow/common"
 	"github.com/DataDog/datadog-agent/pkg/netflow/enrichment"
 	"github.com/DataDog/datadog-agent/pkg/netflow/payload"
 	"github.com/DataDog/datadog-agent/pkg/netflow/portrollup"
 )
 
-func buildPayload(aggFlow *common.Flow, hostname string) payload.FlowPayload {
-	return payload.FlowPayload{
+func buildPayload(aggFlow *common.Flow, hostname string, interfaceStore *interfacestore.Store, ipEnricher *enrichment.IPEnricher) payload.FlowPayload {
+	deviceIP := common.IPBytesToString(aggFlow.DeviceAddr)
+	flowPayload := payload.FlowPayload{
 		// TODO: Implement Tos
 		FlowType:     string(aggFlow.FlowType),
 		SamplingRate: aggFlow.SamplingRate,
 		Direction:    enrichment.RemapDirection(aggFlow.Direction),
 		Device: payload.Device{
-			IP:        common.IPBytesToString(aggFlow.DeviceAddr),
+			IP:        deviceIP,
 			Namespace: aggFlow.Namespace,
 		},
 		Start:      aggFlow.StartTimestamp,
@@ -41,14 +44,10 @@ func buildPayload(aggFlow *common.Flow, hostname string) payload.FlowPayload {
 			Mask: enrichment.FormatMask(aggFlow.DstAddr, aggFlow.DstMask),
 		},
 		Ingress: payload.ObservationPoint{
-			Interface: payload.Interface{
-				Index: aggFlow.InputInterface,
-			},
+			Interface: buildInterface(interfaceStore, aggFlow.Namespace, deviceIP, aggFlow.InputInterface),
 		},
 		Egress: payload.ObservationPoint{
-			Interface: payload.Interface{
-				Index: aggFlow.OutputInterface,
-			},
+			Interface: buildInterface(interfaceStore, aggFlow.Namespace, deviceIP, aggFlow.OutputInterface),
 		},
 		Host:     hostname,
 		TCPFlags: enrichment.FormatFCPFlags(aggFlow.TCPFlags),
@@ -56,4 +55,32 @@ func buildPayload(aggFlow *common.Flow, hostname string) payload.FlowPayload {
 			IP: common.IPBytesToString(aggFlow.NextHop),
 		},
 	}
+	if ipEnricher != nil {
+		enrichEndpoint(&flowPayload.Source, ipEnricher.Enrich(aggFlow.SrcAddr))
+		enrichEndpoint(&flowPayload.Destination, ipEnricher.Enrich(aggFlow.DstAddr))
+	}
+	return flowPayload
+}
+
+func enrichEndpoint(endpoint *payload.Endpoint, info enrichment.IPInfo) {
+	endpoint.Country = info.Country
+	endpoint.City = info.City
+	endpoint.ASN = info.ASN
+	endpoint.ASOrg = info.ASOrg
+	endpoint.Labels = info.Labels
+}
+
+// buildInterface returns the interface of an exporter, with its name and alias when
+// the exporter is monitored by the SNMP check
+func buildInterface(interfaceStore *interfacestore.Store, namespace string, deviceIP string, index uint32) payload.Interface {
+	iface := payload.Interface{Index: index}
+	if interfaceStore == nil {
+		return iface
+	}
+	if storeIface, ok := interfaceStore.GetInterface(namespace, deviceIP, int32(index)); ok {
+		iface.Name = storeIface.Name
+		iface.Alias = storeIface.Alias
+		iface.Speed = storeIface.Speed
+	}
+	return iface
 }
@@ -9,8 +9,13 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/snmp/interfacestore"
 
 	"github.com/DataDog/datadog-agent/pkg/netflow/common"
+	"github.com/DataDog/datadog-agent/pkg/netflow/config"
+	"github.com/DataDog/datadog-agent/pkg/netflow/enrichment"
 	"github.com/DataDog/datadog-agent/pkg/netflow/payload"
 )
 
@@ -209,8 +214,48 @@ func Test_buildPayload(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			flowPayload := buildPayload(&tt.flow, "my-hostname")
+			flowPayload := buildPayload(&tt.flow, "my-hostname", nil, nil)
 			assert.Equal(t, tt.expectedPayload, flowPayload)
 		})
 	}
 }
+
+func Test_buildPayload_interfaces(t *testing.T) {
+	interfaceStore := interfacestore.NewStore(interfacestore.DefaultTTL)
+	interfaceStore.SetDeviceInterfaces("my-namespace", "127.0.0.1", []interfacestore.Interface{
+		{Index: 3, Name: "Gi0/1", Alias: "uplink-to-core", Speed: 1000000000},
+	})
+	flow := common.Flow{
+		Namespace:       "my-namespace",
+		DeviceAddr:      []byte{127, 0, 0, 1},
+		InputInterface:  3,
+		OutputInterface: 4,
+	}
+
+	flowPayload := buildPayload(&flow, "my-hostname", interfaceStore, nil)
+	assert.Equal(t, payload.Interface{Index: 3, Name: "Gi0/1", Alias: "uplink-to-core", Speed: 1000000000}, flowPayload.Ingress.Interface)
+	assert.Equal(t, payload.Interface{Index: 4}, flowPayload.Egress.Interface)
+
+	flow.Namespace = "other-namespace"
+	flowPayload = buildPayload(&flow, "my-hostname", interfaceStore, nil)
+	assert.Equal(t, payload.Interface{Index: 3}, flowPayload.Ingress.Interface)
+}
+
+func Test_buildPayload_ipEnrichment(t *testing.T) {
+	ipEnricher, err := enrichment.NewIPEnricher(config.EnrichmentConfig{
+		CIDRLabels: []config.CIDRLabelsConfig{
+			{CIDR: "10.20.0.0/16", Labels: []string{"datacenter:ams"}},
+		},
+		CacheSize: 10,
+	})
+	require.NoError(t, err)
+	flow := common.Flow{
+		DeviceAddr: []byte{127, 0, 0, 1},
+		SrcAddr:    []byte{10, 20, 1, 1},
+		DstAddr:    []byte{10, 30, 1, 1},
+	}
+
+	flowPayload := buildPayload(&flow, "my-hostname", nil, ipEnricher)
+	assert.Equal(t, []string{"datacenter:ams"}, flowPayload.Source.Labels)
+	assert.Nil(t, flowPayload.Destination.Labels)
+}
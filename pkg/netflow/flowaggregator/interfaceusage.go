@@ -0,0 +1,82 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package flowaggregator
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/snmp/interfacestore"
+
+	"github.com/DataDog/datadog-agent/pkg/netflow/common"
+)
+
+const interfaceUtilizationMetric = "netflow.interface.utilization"
+
+type interfaceUsageKey struct {
+	namespace string
+	deviceIP  string
+	index     uint32
+	direction string
+}
+
+// interfaceUsage accumulates the bytes of the flushed flows by exporter interface,
+// to report the utilization of the interfaces with a known speed.
+// It's only used by the flush loop, hence not protected by a mutex.
+type interfaceUsage struct {
+	bytes map[interfaceUsageKey]uint64
+}
+
+func newInterfaceUsage() *interfaceUsage {
+	return &interfaceUsage{
+		bytes: make(map[interfaceUsageKey]uint64),
+	}
+}
+
+// add accounts the bytes of a flow to its input and output interfaces
+func (u *interfaceUsage) add(flow *common.Flow) {
+	bytes := flow.Bytes
+	if flow.SamplingRate > 0 {
+		bytes *= flow.SamplingRate
+	}
+	deviceIP := common.IPBytesToString(flow.DeviceAddr)
+	u.bytes[interfaceUsageKey{namespace: flow.Namespace, deviceIP: deviceIP, index: flow.InputInterface, direction: "ingress"}] += bytes
+	u.bytes[interfaceUsageKey{namespace: flow.Namespace, deviceIP: deviceIP, index: flow.OutputInterface, direction: "egress"}] += bytes
+}
+
+// report sends the utilization of the interfaces with a known speed, and resets the
+// accumulated bytes. Every flow context is flushed once per interval, so the utilization
+// in percent is `bytes * 8 * 100 / (speed * interval)`, given:
+//   - bytes: the bytes of the flows flushed during the interval, scaled by the sampling rate
+//   - speed: the bandwidth of the interface in bits per second, collected by the SNMP check
+//     from `ifHighSpeed` or `ifSpeed`
+func (u *interfaceUsage) report(sender aggregator.Sender, interfaceStore *interfacestore.Store, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	for key, bytes := range u.bytes {
+		iface, ok := interfaceStore.GetInterface(key.namespace, key.deviceIP, int32(key.index))
+		if !ok || iface.Speed == 0 {
+			continue
+		}
+		utilization := (float64(bytes) * 8 * 100) / (float64(iface.Speed) * interval.Seconds())
+		tags := []string{
+			"device_namespace:" + key.namespace,
+			"snmp_device:" + key.deviceIP,
+			"interface_index:" + strconv.Itoa(int(key.index)),
+			"direction:" + key.direction,
+		}
+		if iface.Name != "" {
+			tags = append(tags, "interface:"+iface.Name)
+		}
+		if iface.Alias != "" {
+			tags = append(tags, "interface_alias:"+iface.Alias)
+		}
+		sender.Gauge(interfaceUtilizationMetric, utilization, "", tags)
+	}
+	u.bytes = make(map[interfaceUsageKey]uint64)
+}
@@ -0,0 +1,45 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package flowaggregator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/snmp/interfacestore"
+
+	"github.com/DataDog/datadog-agent/pkg/netflow/common"
+)
+
+func Test_interfaceUsage_report(t *testing.T) {
+	sender := mocksender.NewMockSender("")
+	sender.On("Gauge", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+
+	interfaceStore := interfacestore.NewStore(interfacestore.DefaultTTL)
+	interfaceStore.SetDeviceInterfaces("my-ns", "127.0.0.1", []interfacestore.Interface{
+		{Index: 3, Name: "Gi0/1", Alias: "uplink-to-core", Speed: 1000000},
+		{Index: 4, Name: "Gi0/2"},
+	})
+
+	usage := newInterfaceUsage()
+	usage.add(&common.Flow{Namespace: "my-ns", DeviceAddr: []byte{127, 0, 0, 1}, Bytes: 1000, SamplingRate: 10, InputInterface: 3, OutputInterface: 4})
+	usage.add(&common.Flow{Namespace: "my-ns", DeviceAddr: []byte{127, 0, 0, 1}, Bytes: 2500, InputInterface: 4, OutputInterface: 3})
+	usage.add(&common.Flow{Namespace: "my-ns", DeviceAddr: []byte{127, 0, 0, 2}, Bytes: 2500, InputInterface: 3, OutputInterface: 4})
+
+	usage.report(sender, interfaceStore, 10*time.Second)
+
+	// 10000 bytes * 8 / (1 Mbps * 10s) = 0.8%
+	sender.AssertMetric(t, "Gauge", "netflow.interface.utilization", 0.8, "", []string{"device_namespace:my-ns", "snmp_device:127.0.0.1", "interface_index:3", "direction:ingress", "interface:Gi0/1", "interface_alias:uplink-to-core"})
+	// 2500 bytes * 8 / (1 Mbps * 10s) = 0.2%
+	sender.AssertMetric(t, "Gauge", "netflow.interface.utilization", 0.2, "", []string{"device_namespace:my-ns", "snmp_device:127.0.0.1", "interface_index:3", "direction:egress", "interface:Gi0/1", "interface_alias:uplink-to-core"})
+	// interfaces without speed and unknown exporters are not reported
+	sender.AssertNumberOfCalls(t, "Gauge", 2)
+	assert.Empty(t, usage.bytes)
+}
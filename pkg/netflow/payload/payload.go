@@ -17,6 +17,12 @@ type Endpoint struct {
 	Port string `json:"port"` // Port number can be zero/positive or `*` (ephemeral port)
 	Mac  string `json:"mac"`
 	Mask string `json:"mask"`
+	// Country, City, ASN and ASOrg are resolved from the configured MaxMind databases
+	Country string   `json:"country,omitempty"`
+	City    string   `json:"city,omitempty"`
+	ASN     uint32   `json:"asn,omitempty"`
+	ASOrg   string   `json:"as_org,omitempty"`
+	Labels  []string `json:"labels,omitempty"`
 }
 
 // NextHop contains next hop details
@@ -27,6 +33,11 @@ type NextHop struct {
 // Interface contains interface details
 type Interface struct {
 	Index uint32 `json:"index"`
+	// Name, Alias and Speed are resolved from the interfaces collected by the SNMP check
+	Name  string `json:"name,omitempty"`
+	Alias string `json:"alias,omitempty"`
+	// Speed is the bandwidth of the interface in bits per second
+	Speed uint64 `json:"speed,omitempty"`
 }
 
 // ObservationPoint contains ingress or egress observation point
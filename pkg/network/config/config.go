@@ -6,6 +6,7 @@
 package config
 
 import (
+	"strconv"
 	"strings"
 	"time"
 
@@ -75,6 +76,31 @@ type Config struct {
 	// Supported libraries: OpenSSL
 	EnableHTTPSMonitoring bool
 
+	// EnableKafkaMonitoring specifies whether the tracer should monitor Kafka traffic
+	EnableKafkaMonitoring bool
+
+	// EnablePostgresMonitoring specifies whether the tracer should monitor PostgreSQL traffic
+	EnablePostgresMonitoring bool
+
+	// EnableRedisMonitoring specifies whether the tracer should monitor Redis traffic
+	EnableRedisMonitoring bool
+
+	// KafkaPorts, PostgresPorts and RedisPorts are the server ports on which
+	// the traffic of each protocol is monitored
+	KafkaPorts    []uint16
+	PostgresPorts []uint16
+	RedisPorts    []uint16
+
+	// EnableHTTP2Monitoring specifies whether the tracer should monitor
+	// HTTP/2 traffic, including gRPC, sent in cleartext to HTTP2Ports, or
+	// over TLS to any port when EnableHTTPSMonitoring is set
+	EnableHTTP2Monitoring bool
+	HTTP2Ports            []uint16
+
+	// MaxProtocolStatsBuffered represents the maximum number of Kafka, PostgreSQL and Redis stats we'll buffer
+	// in memory. These stats get flushed on every client request (default 30s check interval)
+	MaxProtocolStatsBuffered int
+
 	// MaxTrackedHTTPConnections max number of http(s) flows that will be concurrently tracked.
 	// value is currently Windows only
 	MaxTrackedHTTPConnections int64
@@ -192,6 +218,20 @@ func join(pieces ...string) string {
 	return strings.Join(pieces, ".")
 }
 
+// parsePorts returns the list of ports of key, ignoring invalid ones
+func parsePorts(cfg ddconfig.Config, key string) []uint16 {
+	var ports []uint16
+	for _, p := range cfg.GetStringSlice(key) {
+		port, err := strconv.ParseUint(strings.TrimSpace(p), 10, 16)
+		if err != nil || port == 0 {
+			log.Warnf("ignoring invalid port %q of %s", p, key)
+			continue
+		}
+		ports = append(ports, uint16(port))
+	}
+	return ports
+}
+
 // New creates a config for the network tracer
 func New() *Config {
 	cfg := ddconfig.Datadog
@@ -234,6 +274,16 @@ func New() *Config {
 		EnableHTTPSMonitoring: cfg.GetBool(join(netNS, "enable_https_monitoring")),
 		MaxHTTPStatsBuffered:  cfg.GetInt(join(netNS, "max_http_stats_buffered")),
 
+		EnableKafkaMonitoring:    cfg.GetBool(join(netNS, "enable_kafka_monitoring")),
+		EnablePostgresMonitoring: cfg.GetBool(join(netNS, "enable_postgres_monitoring")),
+		EnableRedisMonitoring:    cfg.GetBool(join(netNS, "enable_redis_monitoring")),
+		KafkaPorts:               parsePorts(cfg, join(netNS, "kafka_ports")),
+		PostgresPorts:            parsePorts(cfg, join(netNS, "postgres_ports")),
+		RedisPorts:               parsePorts(cfg, join(netNS, "redis_ports")),
+		MaxProtocolStatsBuffered: cfg.GetInt(join(netNS, "max_protocol_stats_buffered")),
+		EnableHTTP2Monitoring:    cfg.GetBool(join(netNS, "enable_http2_monitoring")),
+		HTTP2Ports:               parsePorts(cfg, join(netNS, "http2_ports")),
+
 		MaxTrackedHTTPConnections: cfg.GetInt64(join(netNS, "max_tracked_http_connections")),
 		HTTPNotificationThreshold: cfg.GetInt64(join(netNS, "http_notification_threshold")),
 		HTTPMaxRequestFragment:    cfg.GetInt64(join(netNS, "http_max_request_fragment")),
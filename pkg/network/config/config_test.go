@@ -395,6 +395,58 @@ network_config:
 	})
 }
 
+func TestProtocolMonitoringPorts(t *testing.T) {
+	t.Run("default values", func(t *testing.T) {
+		newConfig()
+		t.Cleanup(restoreGlobalConfig)
+
+		cfg := New()
+		assert.False(t, cfg.EnableKafkaMonitoring)
+		assert.Equal(t, []uint16{9092}, cfg.KafkaPorts)
+		assert.Equal(t, []uint16{5432}, cfg.PostgresPorts)
+		assert.Equal(t, []uint16{6379}, cfg.RedisPorts)
+		assert.False(t, cfg.EnableHTTP2Monitoring)
+		assert.Equal(t, []uint16{50051}, cfg.HTTP2Ports)
+	})
+
+	t.Run("value set through env var", func(t *testing.T) {
+		newConfig()
+		t.Cleanup(restoreGlobalConfig)
+		t.Setenv("DD_SYSTEM_PROBE_NETWORK_ENABLE_REDIS_MONITORING", "true")
+		t.Setenv("DD_SYSTEM_PROBE_NETWORK_REDIS_PORTS", "6379 6380")
+
+		cfg := New()
+		assert.True(t, cfg.EnableRedisMonitoring)
+		assert.Equal(t, []uint16{6379, 6380}, cfg.RedisPorts)
+	})
+
+	t.Run("HTTP/2 value set through env var", func(t *testing.T) {
+		newConfig()
+		t.Cleanup(restoreGlobalConfig)
+		t.Setenv("DD_SYSTEM_PROBE_NETWORK_ENABLE_HTTP2_MONITORING", "true")
+		t.Setenv("DD_SYSTEM_PROBE_NETWORK_HTTP2_PORTS", "8080 50051")
+
+		cfg := New()
+		assert.True(t, cfg.EnableHTTP2Monitoring)
+		assert.Equal(t, []uint16{8080, 50051}, cfg.HTTP2Ports)
+	})
+
+	t.Run("value set through yaml", func(t *testing.T) {
+		newConfig()
+		t.Cleanup(restoreGlobalConfig)
+
+		cfg := configurationFromYAML(t, `
+network_config:
+  enable_postgres_monitoring: true
+  postgres_ports: [5432, 5433, 70000]
+`)
+
+		assert.True(t, cfg.EnablePostgresMonitoring)
+		// invalid ports are ignored
+		assert.Equal(t, []uint16{5432, 5433}, cfg.PostgresPorts)
+	})
+}
+
 func TestNetworkConfigEnabled(t *testing.T) {
 	ys := true
 
@@ -0,0 +1,85 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package debugging
+
+import (
+	"syscall"
+
+	"github.com/google/gopacket/layers"
+
+	"github.com/DataDog/datadog-agent/pkg/network/dns"
+)
+
+// RequestSummary represents a (debug-friendly) aggregated view of the DNS
+// queries matching a (client, server, protocol, domain, query type) tuple
+type RequestSummary struct {
+	Client    Address
+	Server    string
+	Protocol  string
+	Domain    string
+	QueryType string
+	Stats     Stats
+}
+
+// Address represents represents a IP:Port
+type Address struct {
+	IP   string
+	Port uint16
+}
+
+// Stats holds the outcome of the queries, with response codes replaced by
+// their mnemonic
+type Stats struct {
+	CountByRcode      map[string]uint32
+	Timeouts          uint32
+	SuccessLatencySum uint64
+	FailureLatencySum uint64
+}
+
+// DNS returns a debug-friendly representation of dns.StatsByKeyByNameByType
+func DNS(stats dns.StatsByKeyByNameByType) []RequestSummary {
+	var all []RequestSummary
+	for key, byDomain := range stats {
+		for domain, byType := range byDomain {
+			for qtype, stat := range byType {
+				summary := RequestSummary{
+					Client: Address{
+						IP:   key.ClientIP.String(),
+						Port: key.ClientPort,
+					},
+					Server:    key.ServerIP.String(),
+					Protocol:  formatProtocol(key.Protocol),
+					Domain:    dns.ToString(domain),
+					QueryType: layers.DNSType(qtype).String(),
+					Stats: Stats{
+						CountByRcode:      make(map[string]uint32, len(stat.CountByRcode)),
+						Timeouts:          stat.Timeouts,
+						SuccessLatencySum: stat.SuccessLatencySum,
+						FailureLatencySum: stat.FailureLatencySum,
+					},
+				}
+				for rcode, count := range stat.CountByRcode {
+					summary.Stats.CountByRcode[dns.RcodeName(rcode)] = count
+				}
+
+				all = append(all, summary)
+			}
+		}
+	}
+
+	return all
+}
+
+func formatProtocol(protocol uint8) string {
+	switch protocol {
+	case syscall.IPPROTO_TCP:
+		return "tcp"
+	case syscall.IPPROTO_UDP:
+		return "udp"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,101 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package dns
+
+import (
+	"fmt"
+	"sort"
+)
+
+// HealthStats summarizes the outcome of the DNS queries seen since the
+// previous call to GetHealthStats, by resolver and by failing domain. Unlike
+// the stats attached to connections, which can't hold them, they include
+// every response code, truncated responses and answer TTLs.
+type HealthStats struct {
+	Resolvers []ResolverStats `json:"resolvers"`
+	Failures  []FailureStats  `json:"failures"`
+
+	// DroppedFailures is the number of failures which weren't reported, as
+	// too many domains failed
+	DroppedFailures uint32 `json:"dropped_failures"`
+}
+
+// ResolverStats holds statistics corresponding to a DNS server
+type ResolverStats struct {
+	Resolver     string            `json:"resolver"`
+	CountByRcode map[string]uint32 `json:"count_by_rcode"`
+	Timeouts     uint32            `json:"timeouts"`
+
+	// Truncated is the number of responses with the TC flag set, which
+	// clients usually retry over TCP
+	Truncated uint32 `json:"truncated"`
+
+	// MinAnswerTTL and MaxAnswerTTL are the extreme TTLs, in seconds, of the
+	// answers of successful responses
+	MinAnswerTTL uint32 `json:"min_answer_ttl"`
+	MaxAnswerTTL uint32 `json:"max_answer_ttl"`
+
+	SuccessLatencySum uint64 `json:"success_latency_sum"`
+	FailureLatencySum uint64 `json:"failure_latency_sum"`
+}
+
+// FailureStats holds the failed queries sent by a client for a domain
+type FailureStats struct {
+	ClientIP     string            `json:"client_ip"`
+	Domain       string            `json:"domain"`
+	CountByRcode map[string]uint32 `json:"count_by_rcode"`
+	Timeouts     uint32            `json:"timeouts"`
+}
+
+// Count returns the number of failed queries
+func (f *FailureStats) Count() uint32 {
+	count := f.Timeouts
+	for _, c := range f.CountByRcode {
+		count += c
+	}
+	return count
+}
+
+// TopFailures returns the n failures with the most failed queries
+func TopFailures(failures []FailureStats, n int) []FailureStats {
+	sorted := make([]FailureStats, len(failures))
+	copy(sorted, failures)
+	sort.Slice(sorted, func(i, j int) bool {
+		if ci, cj := sorted[i].Count(), sorted[j].Count(); ci != cj {
+			return ci > cj
+		}
+		if sorted[i].Domain != sorted[j].Domain {
+			return sorted[i].Domain < sorted[j].Domain
+		}
+		return sorted[i].ClientIP < sorted[j].ClientIP
+	})
+	if len(sorted) > n {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
+
+var rcodeNames = map[uint32]string{
+	0:  "NOERROR",
+	1:  "FORMERR",
+	2:  "SERVFAIL",
+	3:  "NXDOMAIN",
+	4:  "NOTIMP",
+	5:  "REFUSED",
+	6:  "YXDOMAIN",
+	7:  "YXRRSET",
+	8:  "NXRRSET",
+	9:  "NOTAUTH",
+	10: "NOTZONE",
+}
+
+// RcodeName returns the mnemonic of a DNS response code, such as NXDOMAIN
+func RcodeName(rcode uint32) string {
+	if name, ok := rcodeNames[rcode]; ok {
+		return name
+	}
+	return fmt.Sprintf("RCODE%d", rcode)
+}
@@ -27,6 +27,10 @@ func (nullReverseDNS) GetDNSStats() StatsByKeyByNameByType {
 	return nil
 }
 
+func (nullReverseDNS) GetHealthStats() HealthStats {
+	return HealthStats{}
+}
+
 func (nullReverseDNS) GetStats() map[string]int64 {
 	return map[string]int64{
 		"lookups":           0,
@@ -82,6 +82,7 @@ func newDNSParser(layerType gopacket.LayerType, cfg *config.Config) *dnsParser {
 
 	stack := []gopacket.DecodingLayer{
 		&layers.Ethernet{},
+		&layers.Loopback{},
 		ipv4Payload,
 		ipv6Payload,
 		udpPayload,
@@ -203,12 +204,14 @@ func (p *dnsParser) parseAnswerInto(
 	}
 
 	pktInfo.rCode = uint8(dns.ResponseCode)
+	pktInfo.truncated = dns.TC
 	if dns.ResponseCode != 0 {
 		pktInfo.pktType = failedResponse
 		return nil
 	}
 
 	pktInfo.queryType = QueryType(question.Type)
+	pktInfo.minAnswerTTL, pktInfo.maxAnswerTTL = answerTTLs(dns.Answers)
 	alias := p.extractCNAME(question.Name, dns.Answers)
 	p.extractIPsInto(alias, dns.Answers, t)
 	inplaceASCIILower(question.Name)
@@ -245,6 +248,19 @@ func (*dnsParser) extractIPsInto(alias []byte, records []layers.DNSResourceRecor
 	}
 }
 
+// answerTTLs returns the extreme TTLs of records, which are 0 if there are none
+func answerTTLs(records []layers.DNSResourceRecord) (min uint32, max uint32) {
+	for i, record := range records {
+		if i == 0 || record.TTL < min {
+			min = record.TTL
+		}
+		if record.TTL > max {
+			max = record.TTL
+		}
+	}
+	return min, max
+}
+
 func (p *dnsParser) isWantedQueryType(checktype layers.DNSType) bool {
 	_, ok := p.recordedQueryTypes[checktype]
 	return ok
@@ -0,0 +1,156 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build windows || linux_bpf
+// +build windows linux_bpf
+
+package dns
+
+import (
+	"time"
+
+	"github.com/google/gopacket"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+)
+
+// Replayer feeds captured packets through the parser and the stats of the
+// snooper. As packets are replayed faster than they were captured, queries
+// are expired based on the capture timestamps rather than on a ticker.
+// A Replayer is not thread-safe.
+type Replayer struct {
+	parser          *dnsParser
+	cache           *reverseDNSCache
+	statKeeper      *dnsStatKeeper
+	translation     *translation
+	collectLocalDNS bool
+
+	lastExpiration time.Time
+	lastTs         time.Time
+
+	packets        int64
+	decodingErrors int64
+	truncatedPkts  int64
+	queries        int64
+	successes      int64
+	errors         int64
+}
+
+// NewReplayer returns a new Replayer for packets starting with the given
+// layer, such as layers.LayerTypeEthernet
+func NewReplayer(layerType gopacket.LayerType, cfg *config.Config) *Replayer {
+	var statKeeper *dnsStatKeeper
+	if cfg.CollectDNSStats {
+		statKeeper = newDNSStatkeeperWithoutExpiration(cfg.DNSTimeout, cfg.MaxDNSStats)
+	}
+	return &Replayer{
+		parser:          newDNSParser(layerType, cfg),
+		cache:           newReverseDNSCache(dnsCacheSize, dnsCacheExpirationPeriod),
+		statKeeper:      statKeeper,
+		translation:     new(translation),
+		collectLocalDNS: cfg.CollectLocalDNS,
+	}
+}
+
+// Process handles a packet captured at ts
+func (r *Replayer) Process(data []byte, ts time.Time) {
+	r.packets++
+	r.expire(ts)
+
+	t := r.translation
+	t.dns = nil
+	t.ips = make(map[util.Address]time.Time)
+	pktInfo := dnsPacketInfo{}
+
+	if err := r.parser.ParseInto(data, t, &pktInfo); err != nil {
+		switch err {
+		case errSkippedPayload:
+		case errTruncated:
+			r.truncatedPkts++
+		default:
+			if _, ok := err.(gopacket.UnsupportedLayerType); !ok {
+				r.decodingErrors++
+			}
+		}
+		return
+	}
+
+	if r.statKeeper != nil && (r.collectLocalDNS || !pktInfo.key.ServerIP.IsLoopback()) {
+		r.statKeeper.ProcessPacketInfo(pktInfo, ts)
+	}
+
+	if pktInfo.pktType == successfulResponse {
+		r.cache.Add(t)
+		r.successes++
+	} else if pktInfo.pktType == failedResponse {
+		r.errors++
+	} else {
+		r.queries++
+	}
+}
+
+// expire times out the queries which were sent before the DNS timeout, as
+// the ticker of the snooper does
+func (r *Replayer) expire(ts time.Time) {
+	if ts.After(r.lastTs) {
+		r.lastTs = ts
+	}
+	if r.statKeeper == nil {
+		return
+	}
+	if r.lastExpiration.IsZero() {
+		r.lastExpiration = ts
+		return
+	}
+	if ts.Sub(r.lastExpiration) < r.statKeeper.expirationPeriod {
+		return
+	}
+
+	r.statKeeper.removeExpiredStates(ts.Add(-r.statKeeper.expirationPeriod))
+	r.lastExpiration = ts
+}
+
+// GetDNSStats returns the stats of the queries replayed since the last call.
+// The queries which were still pending at the end of the capture are counted
+// as timeouts.
+func (r *Replayer) GetDNSStats() StatsByKeyByNameByType {
+	if r.statKeeper == nil {
+		return nil
+	}
+	r.statKeeper.removeExpiredStates(r.lastTs.Add(time.Nanosecond))
+	return r.statKeeper.GetAndResetAllStats()
+}
+
+// GetHealthStats returns the stats of the queries replayed since the last
+// call, by resolver and by failing domain
+func (r *Replayer) GetHealthStats() HealthStats {
+	if r.statKeeper == nil {
+		return HealthStats{}
+	}
+	return r.statKeeper.GetAndResetHealthStats()
+}
+
+// Resolve returns the names resolved by the replayed responses
+func (r *Replayer) Resolve(ips []util.Address) map[util.Address][]Hostname {
+	return r.cache.Get(ips)
+}
+
+// GetStats returns telemetry about the replayed packets
+func (r *Replayer) GetStats() map[string]int64 {
+	return map[string]int64{
+		"packets":           r.packets,
+		"decoding_errors":   r.decodingErrors,
+		"truncated_packets": r.truncatedPkts,
+		"queries":           r.queries,
+		"successes":         r.successes,
+		"errors":            r.errors,
+	}
+}
+
+// Close releases the resources of the Replayer
+func (r *Replayer) Close() {
+	r.cache.Close()
+}
@@ -127,6 +127,15 @@ func (s *socketFilterSnooper) GetDNSStats() StatsByKeyByNameByType {
 	return s.statKeeper.GetAndResetAllStats()
 }
 
+// GetHealthStats gets the stats by resolver and failing domain accumulated
+// since the last call
+func (s *socketFilterSnooper) GetHealthStats() HealthStats {
+	if s.statKeeper == nil {
+		return HealthStats{}
+	}
+	return s.statKeeper.GetAndResetHealthStats()
+}
+
 // GetStats returns stats for use with telemetry
 func (s *socketFilterSnooper) GetStats() map[string]int64 {
 	stats := s.cache.Stats()
@@ -9,11 +9,13 @@
 package dns
 
 import (
+	"sort"
 	"sync"
 	"time"
 
 	"go.uber.org/atomic"
 
+	"github.com/DataDog/datadog-agent/pkg/process/util"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
@@ -42,6 +44,20 @@ type dnsPacketInfo struct {
 	rCode         uint8    // responseCode
 	question      Hostname // only relevant for query packets
 	queryType     QueryType
+	truncated     bool   // only relevant for response packets
+	minAnswerTTL  uint32 // only relevant for successful responses
+	maxAnswerTTL  uint32 // only relevant for successful responses
+}
+
+// failureKey identifies the failed queries of a client for a domain
+type failureKey struct {
+	clientIP util.Address
+	domain   Hostname
+}
+
+type resolverState struct {
+	ResolverStats
+	answered bool // whether an answer TTL was recorded
 }
 
 type stateKey struct {
@@ -69,19 +85,16 @@ type dnsStatKeeper struct {
 	droppedStats     int
 	lastNumStats     *atomic.Int32
 	lastDroppedStats *atomic.Int32
+
+	// resolvers and failures are accumulated independently of stats, as
+	// they are read by another client
+	resolvers       map[util.Address]*resolverState
+	failures        map[failureKey]*FailureStats
+	droppedFailures uint32
 }
 
 func newDNSStatkeeper(timeout time.Duration, maxStats int) *dnsStatKeeper {
-	statsKeeper := &dnsStatKeeper{
-		stats:            make(StatsByKeyByNameByType),
-		state:            make(map[stateKey]stateValue),
-		expirationPeriod: timeout,
-		exit:             make(chan struct{}),
-		maxSize:          maxStateMapSize,
-		maxStats:         maxStats,
-		lastNumStats:     atomic.NewInt32(0),
-		lastDroppedStats: atomic.NewInt32(0),
-	}
+	statsKeeper := newDNSStatkeeperWithoutExpiration(timeout, maxStats)
 
 	ticker := time.NewTicker(statsKeeper.expirationPeriod)
 	go func() {
@@ -98,6 +111,23 @@ func newDNSStatkeeper(timeout time.Duration, maxStats int) *dnsStatKeeper {
 	return statsKeeper
 }
 
+// newDNSStatkeeperWithoutExpiration returns a dnsStatKeeper whose states must
+// be expired by the caller, such as when replaying captured packets
+func newDNSStatkeeperWithoutExpiration(timeout time.Duration, maxStats int) *dnsStatKeeper {
+	return &dnsStatKeeper{
+		stats:            make(StatsByKeyByNameByType),
+		state:            make(map[stateKey]stateValue),
+		expirationPeriod: timeout,
+		exit:             make(chan struct{}),
+		maxSize:          maxStateMapSize,
+		maxStats:         maxStats,
+		lastNumStats:     atomic.NewInt32(0),
+		lastDroppedStats: atomic.NewInt32(0),
+		resolvers:        make(map[util.Address]*resolverState),
+		failures:         make(map[failureKey]*FailureStats),
+	}
+}
+
 func microSecs(t time.Time) uint64 {
 	return uint64(t.UnixNano() / 1000)
 }
@@ -129,6 +159,7 @@ func (d *dnsStatKeeper) ProcessPacketInfo(info dnsPacketInfo, ts time.Time) {
 	d.deleteCount++
 
 	latency := microSecs(ts) - start.ts
+	d.recordResponse(info, start.question, latency)
 
 	allStats, ok := d.stats[info.key]
 	if !ok {
@@ -168,6 +199,115 @@ func (d *dnsStatKeeper) ProcessPacketInfo(info dnsPacketInfo, ts time.Time) {
 	d.stats[info.key] = allStats
 }
 
+// recordResponse adds a response to the health stats of its resolver, and to
+// the failures of its client if it failed
+func (d *dnsStatKeeper) recordResponse(info dnsPacketInfo, question Hostname, latency uint64) {
+	if latency > uint64(d.expirationPeriod.Microseconds()) {
+		d.recordTimeout(info.key, question)
+		return
+	}
+
+	resolver := d.getResolver(info.key.ServerIP)
+	if resolver == nil {
+		return
+	}
+	resolver.CountByRcode[RcodeName(uint32(info.rCode))]++
+	if info.truncated {
+		resolver.Truncated++
+	}
+
+	if info.pktType == successfulResponse {
+		resolver.SuccessLatencySum += latency
+		if info.maxAnswerTTL > 0 {
+			if !resolver.answered || info.minAnswerTTL < resolver.MinAnswerTTL {
+				resolver.MinAnswerTTL = info.minAnswerTTL
+			}
+			if info.maxAnswerTTL > resolver.MaxAnswerTTL {
+				resolver.MaxAnswerTTL = info.maxAnswerTTL
+			}
+			resolver.answered = true
+		}
+		return
+	}
+
+	resolver.FailureLatencySum += latency
+	if failure := d.getFailure(info.key.ClientIP, question); failure != nil {
+		failure.CountByRcode[RcodeName(uint32(info.rCode))]++
+	}
+}
+
+func (d *dnsStatKeeper) recordTimeout(key Key, question Hostname) {
+	if resolver := d.getResolver(key.ServerIP); resolver != nil {
+		resolver.Timeouts++
+	}
+	if failure := d.getFailure(key.ClientIP, question); failure != nil {
+		failure.Timeouts++
+	}
+}
+
+func (d *dnsStatKeeper) getResolver(ip util.Address) *resolverState {
+	resolver, ok := d.resolvers[ip]
+	if !ok {
+		if len(d.resolvers) >= d.maxStats {
+			return nil
+		}
+		resolver = &resolverState{
+			ResolverStats: ResolverStats{
+				Resolver:     ip.String(),
+				CountByRcode: make(map[string]uint32),
+			},
+		}
+		d.resolvers[ip] = resolver
+	}
+	return resolver
+}
+
+func (d *dnsStatKeeper) getFailure(clientIP util.Address, question Hostname) *FailureStats {
+	key := failureKey{clientIP: clientIP, domain: question}
+	failure, ok := d.failures[key]
+	if !ok {
+		if len(d.failures) >= d.maxStats {
+			d.droppedFailures++
+			return nil
+		}
+		failure = &FailureStats{
+			ClientIP:     clientIP.String(),
+			Domain:       ToString(question),
+			CountByRcode: make(map[string]uint32),
+		}
+		d.failures[key] = failure
+	}
+	return failure
+}
+
+// GetAndResetHealthStats returns the health stats accumulated since the last
+// call, with resolvers sorted by address and failures by decreasing count
+func (d *dnsStatKeeper) GetAndResetHealthStats() HealthStats {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	stats := HealthStats{
+		Resolvers:       make([]ResolverStats, 0, len(d.resolvers)),
+		Failures:        make([]FailureStats, 0, len(d.failures)),
+		DroppedFailures: d.droppedFailures,
+	}
+	for _, resolver := range d.resolvers {
+		stats.Resolvers = append(stats.Resolvers, resolver.ResolverStats)
+	}
+	for _, failure := range d.failures {
+		stats.Failures = append(stats.Failures, *failure)
+	}
+	sort.Slice(stats.Resolvers, func(i, j int) bool {
+		return stats.Resolvers[i].Resolver < stats.Resolvers[j].Resolver
+	})
+	stats.Failures = TopFailures(stats.Failures, len(stats.Failures))
+
+	d.resolvers = make(map[util.Address]*resolverState)
+	d.failures = make(map[failureKey]*FailureStats)
+	d.droppedFailures = 0
+	return stats
+}
+
 func (d *dnsStatKeeper) GetNumStats() (int32, int32) {
 	numStats := d.lastNumStats.Load()
 	droppedStats := d.lastDroppedStats.Load()
@@ -223,6 +363,7 @@ func (d *dnsStatKeeper) removeExpiredStates(earliestTs time.Time) {
 		if v.ts < threshold {
 			delete(d.state, k)
 			d.deleteCount++
+			d.recordTimeout(k.key, v.question)
 			// When we expire a state, we need to increment timeout count for that key:domain
 			allStats, ok := d.stats[k.key]
 			if !ok {
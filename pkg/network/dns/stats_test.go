@@ -107,6 +107,56 @@ func TestExpiredStateRemoval(t *testing.T) {
 	assert.Equal(t, uint32(1), stats[key][d][TypeA].Timeouts)
 }
 
+func TestHealthStats(t *testing.T) {
+	sk := newDNSStatkeeper(DNSTimeoutSecs*time.Second, 10000)
+	key := getSampleDNSKey()
+	otherKey := key
+	otherKey.ServerIP = util.AddressFromString("8.8.4.4")
+	good, bad := ToHostname("abc.com"), ToHostname("missing.abc.com")
+
+	now := time.Now()
+	exchange := func(id uint16, key Key, question Hostname, response dnsPacketInfo) {
+		sk.ProcessPacketInfo(dnsPacketInfo{transactionID: id, pktType: query, key: key, question: question, queryType: TypeA}, now)
+		response.transactionID = id
+		response.key = key
+		response.queryType = TypeA
+		sk.ProcessPacketInfo(response, now.Add(time.Millisecond))
+	}
+	exchange(1, key, good, dnsPacketInfo{pktType: successfulResponse, minAnswerTTL: 30, maxAnswerTTL: 300})
+	exchange(2, key, good, dnsPacketInfo{pktType: successfulResponse, minAnswerTTL: 10, maxAnswerTTL: 60, truncated: true})
+	exchange(3, key, bad, dnsPacketInfo{pktType: failedResponse, rCode: 3})
+	exchange(4, key, bad, dnsPacketInfo{pktType: failedResponse, rCode: 3})
+	exchange(5, otherKey, good, dnsPacketInfo{pktType: failedResponse, rCode: 2})
+	sk.ProcessPacketInfo(dnsPacketInfo{transactionID: 6, pktType: query, key: otherKey, question: good, queryType: TypeA}, now)
+	sk.removeExpiredStates(now.Add(time.Second))
+
+	stats := sk.GetAndResetHealthStats()
+	assert.Equal(t, []ResolverStats{
+		{
+			Resolver:          "8.8.4.4",
+			CountByRcode:      map[string]uint32{"SERVFAIL": 1},
+			Timeouts:          1,
+			FailureLatencySum: 1000,
+		},
+		{
+			Resolver:          "8.8.8.8",
+			CountByRcode:      map[string]uint32{"NOERROR": 2, "NXDOMAIN": 2},
+			Truncated:         1,
+			MinAnswerTTL:      10,
+			MaxAnswerTTL:      300,
+			SuccessLatencySum: 2000,
+			FailureLatencySum: 2000,
+		},
+	}, stats.Resolvers)
+	assert.Equal(t, []FailureStats{
+		{ClientIP: "1.1.1.1", Domain: "abc.com", CountByRcode: map[string]uint32{"SERVFAIL": 1}, Timeouts: 1},
+		{ClientIP: "1.1.1.1", Domain: "missing.abc.com", CountByRcode: map[string]uint32{"NXDOMAIN": 2}},
+	}, stats.Failures)
+
+	assert.Empty(t, sk.GetAndResetHealthStats().Resolvers)
+	assert.Len(t, TopFailures(stats.Failures, 1), 1)
+}
+
 func BenchmarkStats(b *testing.B) {
 	key := getSampleDNSKey()
 
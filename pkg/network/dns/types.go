@@ -67,6 +67,7 @@ type StatsByKeyByNameByType map[Key]map[Hostname]map[QueryType]Stats
 type ReverseDNS interface {
 	Resolve([]util.Address) map[util.Address][]Hostname
 	GetDNSStats() StatsByKeyByNameByType
+	GetHealthStats() HealthStats
 	GetStats() map[string]int64
 	Start() error
 	Close()
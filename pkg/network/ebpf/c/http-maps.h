@@ -24,6 +24,15 @@ BPF_PERCPU_ARRAY_MAP(http_batch_state, __u32, http_batch_state_t, 1)
 
 BPF_LRU_MAP(ssl_sock_by_ctx, void *, ssl_sock_t, 1)
 
+/* This map keeps track of the HTTP/2 connections seen by the TLS probes */
+BPF_LRU_MAP(http2_tls_conns, conn_tuple_t, http2_tls_conn_t, 1)
+
+/* This map is used to send the plaintext of HTTP/2 over TLS connections to userspace */
+BPF_PERF_EVENT_ARRAY_MAP(http2_tls_segments, __u32, 0)
+
+/* http2_tls_segment_t is too big for allocation on stack in eBPF function, we use an array as a heap allocator */
+BPF_PERCPU_ARRAY_MAP(http2_tls_segment_heap, __u32, http2_tls_segment_t, 1)
+
 BPF_LRU_MAP(ssl_read_args, u64, ssl_read_args_t, 1024)
 
 BPF_LRU_MAP(ssl_read_ex_args, u64, ssl_read_ex_args_t, 1024)
@@ -18,6 +18,12 @@
 // _________^
 #define HTTP_STATUS_OFFSET 9
 
+// This controls the number of bytes of plaintext sent to userspace in each HTTP/2 over TLS segment
+#define HTTP2_TLS_SEGMENT_SIZE 4096
+// This controls the number of segments sent for a single TLS read or write, the bytes beyond
+// being only accounted for, which is enough to follow connections as long as they are DATA frames
+#define HTTP2_TLS_MAX_SEGMENTS 4
+
 // This is needed to reduce code size on multiple copy opitmizations that were made in
 // the http eBPF program.
 _Static_assert((HTTP_BUFFER_SIZE % 8) == 0, "HTTP_BUFFER_SIZE must be a multiple of 8.");
@@ -116,6 +122,36 @@ typedef struct {
     __u32 fd;
 } ssl_sock_t;
 
+typedef enum
+{
+    HTTPS_READ,
+    HTTPS_WRITE
+} https_direction_t;
+
+// HTTP/2 connection seen by the TLS probes, whose plaintext is sent to userspace
+typedef struct {
+    // direction of the TLS call which returned the client preface, the plaintext
+    // going in this direction being sent by the client
+    __u8 client_direction;
+} http2_tls_conn_t;
+
+// Plaintext of a TLS read or write of an HTTP/2 connection, sent to userspace followed by len bytes of data
+typedef struct {
+    conn_tuple_t tup;
+    __u64 timestamp;
+    __u32 len;
+    // number of bytes following data which were read or written but not captured
+    __u32 skipped;
+    __u8 from_client;
+    // set when the connection is closed, in which case there is no data
+    __u8 closed;
+} http2_tls_segment_header_t;
+
+typedef struct {
+    http2_tls_segment_header_t header;
+    char data[HTTP2_TLS_SEGMENT_SIZE];
+} http2_tls_segment_t;
+
  #define LIB_PATH_MAX_SIZE 120
 
 typedef struct {
@@ -0,0 +1,144 @@
+#ifndef __HTTP2_TLS_H
+#define __HTTP2_TLS_H
+
+#include "bpf_helpers.h"
+#include "bpf_telemetry.h"
+#include "http-types.h"
+#include "http-maps.h"
+
+// HTTP/2 connections start with the client preface, see https://httpwg.org/specs/rfc9113.html#preface
+#define HTTP2_PREFACE "PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n"
+#define HTTP2_PREFACE_SIZE (sizeof(HTTP2_PREFACE) - 1)
+
+static __always_inline bool http2_tls_enabled() {
+    __u64 val = 0;
+    LOAD_CONSTANT("http2_tls_enabled", val);
+    return val == ENABLED;
+}
+
+static __always_inline bool is_http2_preface(const char *buf) {
+    const char preface[] = HTTP2_PREFACE;
+#pragma unroll
+    for (int i = 0; i < HTTP2_PREFACE_SIZE; i++) {
+        if (buf[i] != preface[i]) {
+            return false;
+        }
+    }
+    return true;
+}
+
+// http2_tls_send_segments sends len bytes of buffer to userspace, in up to HTTP2_TLS_MAX_SEGMENTS
+// segments. The bytes which can't be sent are accounted for in the skipped field of the last one.
+static __always_inline void http2_tls_send_segments(void *ctx, conn_tuple_t *t, char *buffer, size_t len, bool from_client) {
+    u32 key = 0;
+    http2_tls_segment_t *segment = bpf_map_lookup_elem(&http2_tls_segment_heap, &key);
+    if (segment == NULL) {
+        return;
+    }
+
+    __builtin_memcpy(&segment->header.tup, t, sizeof(conn_tuple_t));
+    segment->header.timestamp = bpf_ktime_get_ns();
+    segment->header.from_client = from_client;
+    segment->header.closed = 0;
+
+    u32 cpu = bpf_get_smp_processor_id();
+    size_t offset = 0;
+#pragma unroll
+    for (int i = 0; i < HTTP2_TLS_MAX_SEGMENTS; i++) {
+        if (offset >= len) {
+            return;
+        }
+
+        u32 size = HTTP2_TLS_SEGMENT_SIZE;
+        if (len - offset < HTTP2_TLS_SEGMENT_SIZE) {
+            size = len - offset;
+        }
+        // bound size explicitly for the verifier, which requires a non-zero size on older kernels
+        if (size == 0 || size > HTTP2_TLS_SEGMENT_SIZE) {
+            return;
+        }
+
+        if (bpf_probe_read_user_with_telemetry(segment->data, size, buffer + offset) < 0) {
+            break;
+        }
+        offset += size;
+
+        segment->header.len = size;
+        segment->header.skipped = 0;
+        bpf_perf_event_output(ctx, &http2_tls_segments, cpu, segment, sizeof(http2_tls_segment_header_t) + size);
+    }
+
+    if (offset >= len) {
+        return;
+    }
+
+    // userspace follows the connection as long as the bytes skipped are part of frames it ignores
+    segment->header.len = 0;
+    segment->header.skipped = len - offset;
+    bpf_perf_event_output(ctx, &http2_tls_segments, cpu, segment, sizeof(http2_tls_segment_header_t));
+}
+
+// http2_tls_process sends the plaintext of the TLS reads and writes of HTTP/2 connections to
+// userspace, where header blocks can be decoded. It returns true if the connection is an HTTP/2
+// one, which must not be processed as HTTP/1.
+static __always_inline bool http2_tls_process(void *ctx, conn_tuple_t *t, char *buffer, size_t len, https_direction_t direction) {
+    if (!http2_tls_enabled()) {
+        return false;
+    }
+
+    // the tuples of TLS connections don't hold the pid, which tells apart the two sides of the
+    // connections between two processes of the host
+    conn_tuple_t key = *t;
+    key.pid = bpf_get_current_pid_tgid() >> 32;
+
+    __u8 client_direction = 0;
+    http2_tls_conn_t *conn = bpf_map_lookup_elem(&http2_tls_conns, &key);
+    if (conn != NULL) {
+        client_direction = conn->client_direction;
+    } else {
+        // connections are only followed from their start, as decoding header blocks
+        // requires the state built from the previous ones
+        if (len < HTTP2_PREFACE_SIZE) {
+            return false;
+        }
+
+        char preface[HTTP2_PREFACE_SIZE];
+        if (bpf_probe_read_user_with_telemetry(preface, HTTP2_PREFACE_SIZE, buffer) < 0) {
+            return false;
+        }
+        if (!is_http2_preface(preface)) {
+            return false;
+        }
+
+        http2_tls_conn_t new_conn = { .client_direction = direction };
+        bpf_map_update_with_telemetry(http2_tls_conns, &key, &new_conn, BPF_ANY);
+        client_direction = direction;
+    }
+
+    http2_tls_send_segments(ctx, &key, buffer, len, direction == client_direction);
+    return true;
+}
+
+// http2_tls_finish notifies userspace of the end of an HTTP/2 connection
+static __always_inline void http2_tls_finish(void *ctx, conn_tuple_t *t) {
+    if (!http2_tls_enabled()) {
+        return;
+    }
+
+    conn_tuple_t key = *t;
+    key.pid = bpf_get_current_pid_tgid() >> 32;
+    if (bpf_map_lookup_elem(&http2_tls_conns, &key) == NULL) {
+        return;
+    }
+    bpf_map_delete_elem(&http2_tls_conns, &key);
+
+    http2_tls_segment_header_t header = { 0 };
+    __builtin_memcpy(&header.tup, &key, sizeof(conn_tuple_t));
+    header.timestamp = bpf_ktime_get_ns();
+    header.closed = 1;
+
+    u32 cpu = bpf_get_smp_processor_id();
+    bpf_perf_event_output(ctx, &http2_tls_segments, cpu, &header, sizeof(header));
+}
+
+#endif
@@ -6,6 +6,7 @@
 #include "http-maps.h"
 #include "http-maps.h"
 #include "http.h"
+#include "http2-tls.h"
 #include "port_range.h"
 #include "sockfd.h"
 #include "tags-types.h"
@@ -15,7 +16,11 @@
 static __always_inline int read_conn_tuple(conn_tuple_t* t, struct sock* skp, u64 pid_tgid, metadata_mask_t type);
 static __always_inline int http_process(http_transaction_t *http_stack, skb_info_t *skb_info, __u64 tags);
 
-static __always_inline void https_process(conn_tuple_t *t, void *buffer, size_t len, __u64 tags) {
+static __always_inline void https_process(void *ctx, conn_tuple_t *t, void *buffer, size_t len, https_direction_t direction, __u64 tags) {
+    if (http2_tls_process(ctx, t, buffer, len, direction)) {
+        return;
+    }
+
     http_transaction_t http;
     __builtin_memset(&http, 0, sizeof(http));
     __builtin_memcpy(&http.tup, t, sizeof(conn_tuple_t));
@@ -25,7 +30,9 @@ static __always_inline void https_process(conn_tuple_t *t, void *buffer, size_t
     http_process(&http, NULL, tags);
 }
 
-static __always_inline void https_finish(conn_tuple_t *t) {
+static __always_inline void https_finish(void *ctx, conn_tuple_t *t) {
+    http2_tls_finish(ctx, t);
+
     http_transaction_t http;
     __builtin_memset(&http, 0, sizeof(http));
     __builtin_memcpy(&http.tup, t, sizeof(conn_tuple_t));
@@ -187,7 +187,7 @@ int uretprobe__SSL_read(struct pt_regs *ctx) {
         goto cleanup;
     }
 
-    https_process(t, args->buf, len, LIBSSL);
+    https_process(ctx, t, args->buf, len, HTTPS_READ, LIBSSL);
 cleanup:
     bpf_map_delete_elem(&ssl_read_args, &pid_tgid);
     return 0;
@@ -223,7 +223,7 @@ int uretprobe__SSL_write(struct pt_regs* ctx) {
         goto cleanup;
     }
 
-    https_process(t, args->buf, write_len, LIBSSL);
+    https_process(ctx, t, args->buf, write_len, HTTPS_WRITE, LIBSSL);
 cleanup:
     bpf_map_delete_elem(&ssl_write_args, &pid_tgid);
     return 0;
@@ -274,7 +274,7 @@ int uretprobe__SSL_read_ex(struct pt_regs* ctx) {
         goto cleanup;
     }
 
-    https_process(conn_tuple, args->buf, bytes_count, LIBSSL);
+    https_process(ctx, conn_tuple, args->buf, bytes_count, HTTPS_READ, LIBSSL);
 cleanup:
     bpf_map_delete_elem(&ssl_read_ex_args, &pid_tgid);
     return 0;
@@ -325,7 +325,7 @@ int uretprobe__SSL_write_ex(struct pt_regs* ctx) {
         goto cleanup;
     }
 
-    https_process(conn_tuple, args->buf, bytes_count, LIBSSL);
+    https_process(ctx, conn_tuple, args->buf, bytes_count, HTTPS_WRITE, LIBSSL);
 cleanup:
     bpf_map_delete_elem(&ssl_write_ex_args, &pid_tgid);
     return 0;
@@ -341,7 +341,7 @@ int uprobe__SSL_shutdown(struct pt_regs *ctx) {
         return 0;
     }
 
-    https_finish(t);
+    https_finish(ctx, t);
     bpf_map_delete_elem(&ssl_sock_by_ctx, &ssl_ctx);
     return 0;
 }
@@ -445,7 +445,7 @@ int uretprobe__gnutls_record_recv(struct pt_regs *ctx) {
         goto cleanup;
     }
 
-    https_process(t, args->buf, read_len, LIBGNUTLS);
+    https_process(ctx, t, args->buf, read_len, HTTPS_READ, LIBGNUTLS);
 cleanup:
     bpf_map_delete_elem(&ssl_read_args, &pid_tgid);
     return 0;
@@ -482,13 +482,13 @@ int uretprobe__gnutls_record_send(struct pt_regs *ctx) {
         goto cleanup;
     }
 
-    https_process(t, args->buf, write_len, LIBGNUTLS);
+    https_process(ctx, t, args->buf, write_len, HTTPS_WRITE, LIBGNUTLS);
 cleanup:
     bpf_map_delete_elem(&ssl_write_args, &pid_tgid);
     return 0;
 }
 
-static __always_inline void gnutls_goodbye(void *ssl_session) {
+static __always_inline void gnutls_goodbye(struct pt_regs *ctx, void *ssl_session) {
     u64 pid_tgid = bpf_get_current_pid_tgid();
     log_debug("gnutls_goodbye: pid=%llu ctx=%llx\n", pid_tgid, ssl_session);
     conn_tuple_t *t = tup_from_ssl_ctx(ssl_session, pid_tgid);
@@ -496,7 +496,7 @@ static __always_inline void gnutls_goodbye(void *ssl_session) {
         return;
     }
 
-    https_finish(t);
+    https_finish(ctx, t);
     bpf_map_delete_elem(&ssl_sock_by_ctx, &ssl_session);
 }
 
@@ -504,7 +504,7 @@ static __always_inline void gnutls_goodbye(void *ssl_session) {
 SEC("uprobe/gnutls_bye")
 int uprobe__gnutls_bye(struct pt_regs *ctx) {
     void *ssl_session = (void *)PT_REGS_PARM1(ctx);
-    gnutls_goodbye(ssl_session);
+    gnutls_goodbye(ctx, ssl_session);
     return 0;
 }
 
@@ -512,7 +512,7 @@ int uprobe__gnutls_bye(struct pt_regs *ctx) {
 SEC("uprobe/gnutls_deinit")
 int uprobe__gnutls_deinit(struct pt_regs *ctx) {
     void *ssl_session = (void *)PT_REGS_PARM1(ctx);
-    gnutls_goodbye(ssl_session);
+    gnutls_goodbye(ctx, ssl_session);
     return 0;
 }
 
@@ -720,7 +720,7 @@ int uprobe__crypto_tls_Conn_Write__return(struct pt_regs *ctx) {
     }
 
     log_debug("[go-tls-write] processing %s\n", call_data_ptr->b_data);
-    https_process(t, (void*) call_data_ptr->b_data, call_data_ptr->b_len, GO);
+    https_process(ctx, t, (void*) call_data_ptr->b_data, call_data_ptr->b_len, HTTPS_WRITE, GO);
     return 0;
 }
 
@@ -809,7 +809,7 @@ int uprobe__crypto_tls_Conn_Read__return(struct pt_regs *ctx) {
     }
 
     log_debug("[go-tls-read] processing %s\n", call_data_ptr->b_data);
-    https_process(t, (void*) call_data_ptr->b_data, bytes_read, GO);
+    https_process(ctx, t, (void*) call_data_ptr->b_data, bytes_read, HTTPS_READ, GO);
     return 0;
 }
 
@@ -836,7 +836,7 @@ int uprobe__crypto_tls_Conn_Close(struct pt_regs *ctx) {
         return 1;
     }
 
-    https_finish(t);
+    https_finish(ctx, t);
 
     // Clear the element in the map since this connection is closed
     bpf_map_delete_elem(&conn_tup_by_tls_conn, &conn_pointer);
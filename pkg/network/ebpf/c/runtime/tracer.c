@@ -132,6 +132,7 @@ int kprobe__tcp_close(struct pt_regs *ctx) {
     sk = (struct sock *)PT_REGS_PARM1(ctx);
 
     // Should actually delete something only if the connection never got established
+    bool failed_connect = bpf_map_lookup_elem(&tcp_ongoing_connect_pid, &sk) != NULL;
     bpf_map_delete_elem(&tcp_ongoing_connect_pid, &sk);
 
     clear_sockfd_maps(sk);
@@ -143,6 +144,13 @@ int kprobe__tcp_close(struct pt_regs *ctx) {
     }
     log_debug("kprobe/tcp_close: netns: %u, sport: %u, dport: %u\n", t.netns, t.sport, t.dport);
 
+    if (failed_connect) {
+        // the SYN_SENT bit flags connections closed before being established,
+        // such as after a SYN timeout or a reset
+        tcp_stats_t stats = { .state_transitions = (1 << TCP_SYN_SENT) };
+        update_tcp_stats(&t, stats);
+    }
+
     cleanup_conn(&t);
     return 0;
 }
@@ -437,6 +445,14 @@ int kprobe__tcp_retransmit_skb(struct pt_regs *ctx) {
     return handle_retransmit(sk, segs);
 }
 
+SEC("kprobe/tcp_reset")
+int kprobe__tcp_reset(struct pt_regs *ctx) {
+    struct sock *sk = (struct sock *)PT_REGS_PARM1(ctx);
+    log_debug("kprobe/tcp_reset\n");
+
+    return handle_reset(sk);
+}
+
 SEC("kprobe/tcp_set_state")
 int kprobe__tcp_set_state(struct pt_regs *ctx) {
     u8 state = (u8)PT_REGS_PARM2(ctx);
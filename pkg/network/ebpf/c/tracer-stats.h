@@ -110,6 +110,10 @@ static __always_inline void update_tcp_stats(conn_tuple_t *t, tcp_stats_t stats)
         __sync_fetch_and_add(&val->retransmits, stats.retransmits);
     }
 
+    if (stats.resets > 0) {
+        __sync_fetch_and_add(&val->resets, stats.resets);
+    }
+
     if (stats.rtt > 0) {
         // For more information on the bit shift operations see:
         // https://elixir.bootlin.com/linux/v4.6/source/net/ipv4/tcp.c#L2686
@@ -145,4 +149,18 @@ static __always_inline int handle_retransmit(struct sock *sk, int segs) {
     return 0;
 }
 
+static __always_inline int handle_reset(struct sock *sk) {
+    conn_tuple_t t = {};
+    u64 zero = 0;
+
+    if (!read_conn_tuple(&t, sk, zero, CONN_TYPE_TCP)) {
+        return 0;
+    }
+
+    tcp_stats_t stats = { .resets = 1 };
+    update_tcp_stats(&t, stats);
+
+    return 0;
+}
+
 #endif // __TRACER_STATS_H
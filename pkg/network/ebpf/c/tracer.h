@@ -75,6 +75,8 @@ typedef struct {
     __u32 retransmits;
     __u32 rtt;
     __u32 rtt_var;
+    // Number of RST segments received
+    __u32 resets;
 
     // Bit mask containing all TCP state transitions tracked by our tracer
     __u16 state_transitions;
@@ -34,6 +34,7 @@ type TCPState uint8
 
 const (
 	Established TCPState = C.TCP_ESTABLISHED
+	SynSent     TCPState = C.TCP_SYN_SENT
 	Close       TCPState = C.TCP_CLOSE
 )
 
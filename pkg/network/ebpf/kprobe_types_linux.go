@@ -18,6 +18,7 @@ type TCPStats struct {
 	Retransmits       uint32
 	Rtt               uint32
 	Rtt_var           uint32
+	Resets            uint32
 	State_transitions uint16
 	Pad_cgo_0         [2]byte
 }
@@ -75,6 +76,7 @@ type TCPState uint8
 
 const (
 	Established TCPState = 0x1
+	SynSent     TCPState = 0x2
 	Close       TCPState = 0x7
 )
 
@@ -101,6 +101,9 @@ const (
 	// TCPRetransmitPre470 traces the return value for the tcp_retransmit_skb() system call on kernel version < 4.7
 	TCPRetransmitPre470 ProbeName = "kprobe/tcp_retransmit_skb/pre_4_7_0"
 
+	// TCPReset traces the tcp_reset() kernel function, called when a RST segment is received
+	TCPReset ProbeName = "kprobe/tcp_reset"
+
 	// InetCskAcceptReturn traces the return value for the inet_csk_accept syscall
 	InetCskAcceptReturn ProbeName = "kretprobe/inet_csk_accept"
 
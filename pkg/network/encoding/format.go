@@ -50,6 +50,7 @@ func FormatConnection(
 	conn network.ConnectionStats,
 	routes map[string]RouteIdx,
 	httpEncoder *httpEncoder,
+	protocolEncoder *protocolEncoder,
 	dnsFormatter *dnsFormatter,
 	ipc ipCache,
 	tagsSet *network.TagsSet,
@@ -83,6 +84,11 @@ func FormatConnection(
 	if httpStats != nil {
 		c.HttpAggregations, _ = proto.Marshal(httpStats)
 	}
+	if protocolStats := protocolEncoder.GetProtocolAggregations(conn); protocolStats != nil {
+		c.ProtocolAggregations, _ = proto.Marshal(protocolStats)
+	}
+
+	dynamicTags = protocolEncoder.AddDynamicTags(conn, dynamicTags)
 
 	conn.Tags |= staticTags
 	c.Tags, c.TagsChecksum = formatTags(tagsSet, conn, dynamicTags)
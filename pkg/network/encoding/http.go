@@ -35,24 +35,32 @@ type httpEncoder struct {
 // could incorporate that information in the `http.KeyTuple` struct.
 type aggregationWrapper struct {
 	*model.HTTPAggregations
-
-	// we keep track of the source and destination ports of the first
-	// `ConnectionStats` to claim this `HTTPAggregations` object
-	sport, dport uint16
+	connectionClaim
 }
 
 func (a *aggregationWrapper) ValueFor(c network.ConnectionStats) *model.HTTPAggregations {
-	if a == nil {
+	if a == nil || !a.claim(c) {
 		return nil
 	}
+	return a.HTTPAggregations
+}
 
+// connectionClaim keeps track of the `ConnectionStats` claiming an aggregation
+type connectionClaim struct {
+	// we keep track of the source and destination ports of the first
+	// `ConnectionStats` to claim the aggregation
+	sport, dport uint16
+}
+
+// claim returns true if c can be given the aggregation
+func (a *connectionClaim) claim(c network.ConnectionStats) bool {
 	if a.sport == 0 && a.dport == 0 {
 		// This is the first time a ConnectionStats claim this aggregation. In
 		// this case we return the value and save the source and destination
 		// ports
 		a.sport = c.SPort
 		a.dport = c.DPort
-		return a.HTTPAggregations
+		return true
 	}
 
 	if c.SPort == a.dport && c.DPort == a.sport {
@@ -61,17 +69,17 @@ func (a *aggregationWrapper) ValueFor(c network.ConnectionStats) *model.HTTPAggr
 		// same connection, which means both server and client are in the same host.
 		// In this particular case it is correct to have both connections
 		// (client:server and server:client) referencing the same HTTP data.
-		return a.HTTPAggregations
+		return true
 	}
 
-	// Return nil otherwise. This is to prevent multiple `ConnectionStats` with
+	// Return false otherwise. This is to prevent multiple `ConnectionStats` with
 	// exactly the same source and destination addresses but different PIDs to
 	// "bind" to the same HTTPAggregations object, which would result in a
 	// overcount problem. (Note that this is due to the fact that
 	// `http.KeyTuple` doesn't have a PID field.) This happens mostly in the
 	// context of pre-fork web servers, where multiple worker proceses share the
 	// same socket
-	return nil
+	return false
 }
 
 func newHTTPEncoder(payload *network.Connections) *httpEncoder {
@@ -145,6 +153,9 @@ func (e *httpEncoder) buildAggregations(payload *network.Connections) {
 			Method:                model.HTTPMethod(key.Method),
 			StatsByResponseStatus: e.getDataSlice(),
 		}
+		if key.Version == http.ProtocolVersion2 {
+			ms.Version = model.HTTPVersion_HTTP2
+		}
 
 		staticTags := e.staticTags[key.KeyTuple]
 		var dynamicTags map[string]struct{}
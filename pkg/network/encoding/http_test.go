@@ -107,6 +107,51 @@ func TestFormatHTTPStats(t *testing.T) {
 	assert.Equal(t, uint64((1<<(http.NumStatusClasses))-1), tags)
 }
 
+func TestFormatHTTPVersions(t *testing.T) {
+	var (
+		clientPort = uint16(52800)
+		serverPort = uint16(8080)
+		localhost  = util.AddressFromString("127.0.0.1")
+	)
+
+	var stats http.RequestStats
+	stats.AddRequest(200, 10, 0, nil)
+
+	http1Key := http.NewKey(localhost, localhost, clientPort, serverPort, "/", true, http.MethodGet)
+	http2Key := http1Key
+	http2Key.Version = http.ProtocolVersion2
+	otherKey := http.NewKey(localhost, localhost, clientPort, serverPort, "/other", true, http.MethodGet)
+
+	in := &network.Connections{
+		BufferedData: network.BufferedData{
+			Conns: []network.ConnectionStats{
+				{
+					Source: localhost,
+					Dest:   localhost,
+					SPort:  clientPort,
+					DPort:  serverPort,
+				},
+			},
+		},
+		HTTP: map[http.Key]*http.RequestStats{
+			http1Key: &stats,
+			http2Key: &stats,
+			otherKey: &stats,
+		},
+	}
+
+	aggregations, _, _ := newHTTPEncoder(in).GetHTTPAggregationsAndTags(in.Conns[0])
+	require.NotNil(t, aggregations)
+	require.Len(t, aggregations.EndpointAggregations, 3)
+
+	versions := make(map[model.HTTPVersion][]string)
+	for _, endpoint := range aggregations.EndpointAggregations {
+		versions[endpoint.Version] = append(versions[endpoint.Version], endpoint.Path)
+	}
+	assert.ElementsMatch(t, []string{"/", "/other"}, versions[model.HTTPVersion_HTTP1])
+	assert.ElementsMatch(t, []string{"/"}, versions[model.HTTPVersion_HTTP2])
+}
+
 func TestFormatHTTPStatsByPath(t *testing.T) {
 	var httpReqStats http.RequestStats
 	httpReqStats.AddRequest(100, 12.5, 0, nil)
@@ -0,0 +1,231 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package encoding
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	model "github.com/DataDog/agent-payload/v5/process"
+	"github.com/gogo/protobuf/proto"
+
+	"github.com/DataDog/datadog-agent/pkg/network"
+	"github.com/DataDog/datadog-agent/pkg/network/http"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols"
+	"github.com/DataDog/datadog-agent/pkg/trace/traceutil"
+)
+
+const (
+	// maxProtocolTagValueLength bounds the length of the operations and
+	// resources used as tags, which are read from the payloads
+	maxProtocolTagValueLength = 100
+
+	// maxResourceTagsPerConnection bounds the number of resources of a
+	// protocol used as tags of a connection, the stats of the others being
+	// encoded all the same
+	maxResourceTagsPerConnection = 10
+)
+
+// protocolEncoder encodes the stats of the protocols monitored beside HTTP
+// into the protocol aggregations of the connections, which are tagged with
+// the protocol and the operations and resources seen on them.
+type protocolEncoder struct {
+	aggregations map[http.KeyTuple]*protocolAggregationWrapper
+	tagsByTuple  map[http.KeyTuple]map[string]struct{}
+}
+
+// protocolAggregationWrapper guards model.ProtocolAggregations objects from
+// being claimed by several connections, see aggregationWrapper
+type protocolAggregationWrapper struct {
+	*model.ProtocolAggregations
+	connectionClaim
+}
+
+func (a *protocolAggregationWrapper) ValueFor(c network.ConnectionStats) *model.ProtocolAggregations {
+	if a == nil || !a.claim(c) {
+		return nil
+	}
+	return a.ProtocolAggregations
+}
+
+func newProtocolEncoder(payload *network.Connections) *protocolEncoder {
+	if len(payload.Protocols) == 0 {
+		return nil
+	}
+
+	encoder := &protocolEncoder{
+		aggregations: make(map[http.KeyTuple]*protocolAggregationWrapper),
+		tagsByTuple:  make(map[http.KeyTuple]map[string]struct{}),
+	}
+
+	// keys are sorted so that the resources used as tags don't change
+	// between payloads when there are more than maxResourceTagsPerConnection
+	keys := make([]protocols.Key, 0, len(payload.Protocols))
+	for key := range payload.Protocols {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Operation != keys[j].Operation {
+			return keys[i].Operation < keys[j].Operation
+		}
+		return keys[i].Resource < keys[j].Resource
+	})
+
+	resourcesByTuple := make(map[http.KeyTuple]map[protocols.ProtocolType]map[string]struct{})
+	for _, key := range keys {
+		aggregation, ok := encoder.aggregations[key.KeyTuple]
+		if !ok {
+			aggregation = &protocolAggregationWrapper{
+				ProtocolAggregations: &model.ProtocolAggregations{},
+			}
+			encoder.aggregations[key.KeyTuple] = aggregation
+		}
+		aggregation.Stats = append(
+			aggregation.Stats,
+			formatProtocolStats(key, payload.Protocols[key]),
+		)
+
+		tags, ok := encoder.tagsByTuple[key.KeyTuple]
+		if !ok {
+			tags = make(map[string]struct{})
+			encoder.tagsByTuple[key.KeyTuple] = tags
+			resourcesByTuple[key.KeyTuple] = make(map[protocols.ProtocolType]map[string]struct{})
+		}
+
+		resources, ok := resourcesByTuple[key.KeyTuple][key.Protocol]
+		if !ok {
+			resources = make(map[string]struct{})
+			resourcesByTuple[key.KeyTuple][key.Protocol] = resources
+		}
+		resource := key.Resource
+		if _, seen := resources[resource]; resource != "" && !seen {
+			if len(resources) >= maxResourceTagsPerConnection {
+				resource = ""
+			} else {
+				resources[resource] = struct{}{}
+			}
+		}
+
+		for _, tag := range protocolTags(key.Protocol, key.Operation, resource) {
+			tags[tag] = struct{}{}
+		}
+	}
+	return encoder
+}
+
+func formatProtocolStats(key protocols.Key, stats *protocols.RequestStats) *model.ProtocolStats {
+	// operations and resources are read from the payloads, and proto3
+	// strings must be valid UTF-8
+	ps := &model.ProtocolStats{
+		Protocol:  formatProtocolType(key.Protocol),
+		Operation: strings.ToValidUTF8(key.Operation, ""),
+		Resource:  strings.ToValidUTF8(key.Resource, ""),
+		Count:     uint32(stats.Count),
+	}
+
+	if stats.Latencies != nil {
+		ps.Latencies, _ = proto.Marshal(stats.Latencies.ToProto())
+	} else {
+		ps.FirstLatencySample = stats.FirstLatencySample
+	}
+
+	for code, count := range stats.ErrorsByCode {
+		ps.Errors = append(ps.Errors, &model.ProtocolError{Code: code, Count: uint32(count)})
+	}
+	sort.Slice(ps.Errors, func(i, j int) bool {
+		return ps.Errors[i].Code < ps.Errors[j].Code
+	})
+
+	return ps
+}
+
+func formatProtocolType(p protocols.ProtocolType) model.ProtocolType {
+	switch p {
+	case protocols.ProtocolKafka:
+		return model.ProtocolType_Kafka
+	case protocols.ProtocolPostgres:
+		return model.ProtocolType_Postgres
+	case protocols.ProtocolRedis:
+		return model.ProtocolType_Redis
+	default:
+		return model.ProtocolType_UnknownProtocol
+	}
+}
+
+// protocolTags returns the tags describing the transactions of an operation
+// on a resource, which is omitted when empty
+func protocolTags(protocol protocols.ProtocolType, operation, resource string) []string {
+	tags := []string{"protocol:" + protocol.String()}
+
+	switch protocol {
+	case protocols.ProtocolKafka:
+		tags = append(tags, protocolTag("kafka.operation", operation))
+		if resource != "" {
+			tags = append(tags, protocolTag("kafka.topic", resource))
+		}
+	case protocols.ProtocolPostgres:
+		tags = append(tags, protocolTag("postgres.operation", operation))
+		if resource != "" {
+			tags = append(tags, protocolTag("postgres.table", resource))
+		}
+	case protocols.ProtocolRedis:
+		tags = append(tags, protocolTag("redis.command", operation))
+	}
+	return tags
+}
+
+// protocolTag returns the tag name:value, with value truncated to
+// maxProtocolTagValueLength bytes and normalized
+func protocolTag(name, value string) string {
+	if len(value) > maxProtocolTagValueLength {
+		value = value[:maxProtocolTagValueLength]
+		for len(value) > 0 && !utf8.ValidString(value) {
+			value = value[:len(value)-1]
+		}
+	}
+	return traceutil.NormalizeTag(name + ":" + value)
+}
+
+// GetProtocolAggregations returns the protocol stats of c, or nil if there
+// are none or they are claimed by another connection
+func (e *protocolEncoder) GetProtocolAggregations(c network.ConnectionStats) *model.ProtocolAggregations {
+	if e == nil {
+		return nil
+	}
+
+	for _, key := range network.HTTPKeyTuplesFromConn(c) {
+		if value := e.aggregations[key].ValueFor(c); value != nil {
+			return value
+		}
+	}
+	return nil
+}
+
+// AddDynamicTags adds the tags of the protocols seen on c to dynamicTags,
+// which is copied rather than modified, and returns the result
+func (e *protocolEncoder) AddDynamicTags(c network.ConnectionStats, dynamicTags map[string]struct{}) map[string]struct{} {
+	if e == nil {
+		return dynamicTags
+	}
+
+	for _, key := range network.HTTPKeyTuplesFromConn(c) {
+		tags, ok := e.tagsByTuple[key]
+		if !ok {
+			continue
+		}
+
+		merged := make(map[string]struct{}, len(dynamicTags)+len(tags))
+		for tag := range dynamicTags {
+			merged[tag] = struct{}{}
+		}
+		for tag := range tags {
+			merged[tag] = struct{}{}
+		}
+		return merged
+	}
+	return dynamicTags
+}
@@ -0,0 +1,158 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package encoding
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	model "github.com/DataDog/agent-payload/v5/process"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/network"
+	"github.com/DataDog/datadog-agent/pkg/network/http"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+)
+
+func TestProtocolDynamicTags(t *testing.T) {
+	var (
+		client     = util.AddressFromString("10.0.0.1")
+		server     = util.AddressFromString("10.0.0.2")
+		clientPort = uint16(40000)
+		kafkaPort  = uint16(9092)
+	)
+
+	tuple := http.NewKeyTuple(client, server, clientPort, kafkaPort)
+	var stats protocols.RequestStats
+	stats.AddRequest(1000, "")
+
+	in := &network.Connections{
+		Protocols: map[protocols.Key]*protocols.RequestStats{
+			{KeyTuple: tuple, Protocol: protocols.ProtocolKafka, Operation: "produce", Resource: "orders"}: &stats,
+			{KeyTuple: tuple, Protocol: protocols.ProtocolKafka, Operation: "fetch", Resource: "orders"}:   &stats,
+		},
+	}
+	encoder := newProtocolEncoder(in)
+
+	httpTags := map[string]struct{}{"http.iis.app_pool:app": {}}
+
+	// the client side of the connection
+	clientConn := network.ConnectionStats{Source: client, Dest: server, SPort: clientPort, DPort: kafkaPort}
+	assert.Equal(t, map[string]struct{}{
+		"http.iis.app_pool:app":   {},
+		"protocol:kafka":          {},
+		"kafka.operation:produce": {},
+		"kafka.operation:fetch":   {},
+		"kafka.topic:orders":      {},
+	}, encoder.AddDynamicTags(clientConn, httpTags))
+	// tags coming from HTTP are not modified
+	assert.Len(t, httpTags, 1)
+
+	// the server side of the connection
+	serverConn := network.ConnectionStats{Source: server, Dest: client, SPort: kafkaPort, DPort: clientPort}
+	assert.Contains(t, encoder.AddDynamicTags(serverConn, nil), "kafka.topic:orders")
+
+	otherConn := network.ConnectionStats{Source: client, Dest: server, SPort: clientPort + 1, DPort: kafkaPort}
+	assert.Nil(t, encoder.AddDynamicTags(otherConn, nil))
+}
+
+func TestProtocolAggregations(t *testing.T) {
+	var (
+		client     = util.AddressFromString("10.0.0.1")
+		server     = util.AddressFromString("10.0.0.2")
+		clientPort = uint16(40000)
+		pgPort     = uint16(5432)
+	)
+
+	tuple := http.NewKeyTuple(client, server, clientPort, pgPort)
+	var selectStats, insertStats protocols.RequestStats
+	selectStats.AddRequest(1000, "")
+	selectStats.AddRequest(3000, "42P01")
+	insertStats.AddRequest(2000, "")
+
+	in := &network.Connections{
+		Protocols: map[protocols.Key]*protocols.RequestStats{
+			{KeyTuple: tuple, Protocol: protocols.ProtocolPostgres, Operation: "SELECT", Resource: "users"}: &selectStats,
+			{KeyTuple: tuple, Protocol: protocols.ProtocolPostgres, Operation: "INSERT", Resource: "users"}: &insertStats,
+		},
+	}
+	encoder := newProtocolEncoder(in)
+
+	conn := network.ConnectionStats{Source: client, Dest: server, SPort: clientPort, DPort: pgPort}
+	aggregations := encoder.GetProtocolAggregations(conn)
+	require.NotNil(t, aggregations)
+	require.Len(t, aggregations.Stats, 2)
+
+	insert := aggregations.Stats[0]
+	assert.Equal(t, model.ProtocolType_Postgres, insert.Protocol)
+	assert.Equal(t, "INSERT", insert.Operation)
+	assert.Equal(t, "users", insert.Resource)
+	assert.Equal(t, uint32(1), insert.Count)
+	assert.Equal(t, float64(2000), insert.FirstLatencySample)
+	assert.Nil(t, insert.Latencies)
+
+	sel := aggregations.Stats[1]
+	assert.Equal(t, "SELECT", sel.Operation)
+	assert.Equal(t, uint32(2), sel.Count)
+	assert.NotNil(t, sel.Latencies)
+	require.Len(t, sel.Errors, 1)
+	assert.Equal(t, "42P01", sel.Errors[0].Code)
+	assert.Equal(t, uint32(1), sel.Errors[0].Count)
+
+	// another connection with the same tuple doesn't claim the stats
+	otherConn := network.ConnectionStats{Source: client, Dest: server, SPort: clientPort, DPort: pgPort, Pid: 2}
+	assert.Nil(t, encoder.GetProtocolAggregations(otherConn))
+
+	// but the server side of the connection does
+	serverConn := network.ConnectionStats{Source: server, Dest: client, SPort: pgPort, DPort: clientPort}
+	assert.Equal(t, aggregations, encoder.GetProtocolAggregations(serverConn))
+}
+
+func TestProtocolTagsBounded(t *testing.T) {
+	var (
+		client     = util.AddressFromString("10.0.0.1")
+		server     = util.AddressFromString("10.0.0.2")
+		clientPort = uint16(40000)
+		kafkaPort  = uint16(9092)
+	)
+
+	tuple := http.NewKeyTuple(client, server, clientPort, kafkaPort)
+	var stats protocols.RequestStats
+	stats.AddRequest(1000, "")
+
+	in := &network.Connections{
+		Protocols: map[protocols.Key]*protocols.RequestStats{
+			{KeyTuple: tuple, Protocol: protocols.ProtocolRedis, Operation: "GET Weird Command\xff"}:                       &stats,
+			{KeyTuple: tuple, Protocol: protocols.ProtocolKafka, Operation: "produce", Resource: strings.Repeat("é", 100)}: &stats,
+		},
+	}
+	for i := 0; i < 2*maxResourceTagsPerConnection; i++ {
+		key := protocols.Key{KeyTuple: tuple, Protocol: protocols.ProtocolKafka, Operation: "fetch", Resource: fmt.Sprintf("topic-%02d", i)}
+		in.Protocols[key] = &stats
+	}
+	encoder := newProtocolEncoder(in)
+
+	conn := network.ConnectionStats{Source: client, Dest: server, SPort: clientPort, DPort: kafkaPort}
+	tags := encoder.AddDynamicTags(conn, nil)
+
+	var topics []string
+	for tag := range tags {
+		assert.LessOrEqual(t, len(tag), len("kafka.topic:")+maxProtocolTagValueLength, tag)
+		if strings.HasPrefix(tag, "kafka.topic:") {
+			topics = append(topics, tag)
+		}
+	}
+	assert.Len(t, topics, maxResourceTagsPerConnection)
+	assert.Contains(t, tags, "kafka.topic:topic-00")
+	assert.NotContains(t, tags, "kafka.topic:topic-19")
+	assert.Contains(t, tags, "redis.command:get_weird_command")
+
+	// the stats of all the topics are encoded
+	assert.Len(t, encoder.GetProtocolAggregations(conn).Stats, 2*maxResourceTagsPerConnection+2)
+}
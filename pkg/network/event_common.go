@@ -15,6 +15,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/network/dns"
 	"github.com/DataDog/datadog-agent/pkg/network/http"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols"
 	"github.com/DataDog/datadog-agent/pkg/process/util"
 )
 
@@ -123,6 +124,7 @@ type Connections struct {
 	CompilationTelemetryByAsset map[string]RuntimeCompilationTelemetry
 	HTTP                        map[http.Key]*http.RequestStats
 	DNSStats                    dns.StatsByKeyByNameByType
+	Protocols                   map[protocols.Key]*protocols.RequestStats
 }
 
 // ConnTelemetryType enumerates the connection telemetry gathered by the system-probe
@@ -204,6 +206,11 @@ type StatCounters struct {
 	//   are established with the same tuple between two agent checks;
 	TCPEstablished uint32
 	TCPClosed      uint32
+	// TCPFailedConnects is the number of outgoing TCP connections which were
+	// closed before being established, such as after a SYN timeout
+	TCPFailedConnects uint32
+	// Resets is the number of RST segments received
+	Resets uint32
 }
 
 // IsZero returns whether all the stat counter values are zeroes
@@ -437,12 +444,14 @@ func ConnectionSummary(c *ConnectionStats, names map[util.Address][]dns.Hostname
 
 	if c.Type == TCP {
 		str += fmt.Sprintf(
-			", %d retransmits (+%d), RTT %s (± %s), %d established (+%d), %d closed (+%d)",
+			", %d retransmits (+%d), %d resets (+%d), RTT %s (± %s), %d established (+%d), %d closed (+%d), %d failed connects (+%d)",
 			stc.Retransmits, c.Last.Retransmits,
+			stc.Resets, c.Last.Resets,
 			time.Duration(c.RTT)*time.Microsecond,
 			time.Duration(c.RTTVar)*time.Microsecond,
 			stc.TCPEstablished, c.Last.TCPEstablished,
 			stc.TCPClosed, c.Last.TCPClosed,
+			stc.TCPFailedConnects, c.Last.TCPFailedConnects,
 		)
 	}
 
@@ -512,7 +521,9 @@ func (s StatCounters) Sub(other StatCounters) (sc StatCounters, underflow bool)
 		s.SentBytes < other.SentBytes ||
 		s.SentPackets < other.SentPackets ||
 		(s.TCPClosed < other.TCPClosed && s.TCPClosed > 0) ||
-		(s.TCPEstablished < other.TCPEstablished && s.TCPEstablished > 0) {
+		(s.TCPEstablished < other.TCPEstablished && s.TCPEstablished > 0) ||
+		(s.TCPFailedConnects < other.TCPFailedConnects && s.TCPFailedConnects > 0) ||
+		(s.Resets < other.Resets && s.Resets > 0) {
 		return sc, true
 	}
 
@@ -532,6 +543,12 @@ func (s StatCounters) Sub(other StatCounters) (sc StatCounters, underflow bool)
 	if s.TCPClosed > 0 {
 		sc.TCPClosed = s.TCPClosed - other.TCPClosed
 	}
+	if s.TCPFailedConnects > 0 {
+		sc.TCPFailedConnects = s.TCPFailedConnects - other.TCPFailedConnects
+	}
+	if s.Resets > 0 {
+		sc.Resets = s.Resets - other.Resets
+	}
 
 	return sc, false
 }
@@ -546,6 +563,9 @@ func (s StatCounters) Add(other StatCounters) StatCounters {
 		SentPackets:    s.SentPackets + other.SentPackets,
 		TCPClosed:      s.TCPClosed + other.TCPClosed,
 		TCPEstablished: s.TCPEstablished + other.TCPEstablished,
+
+		TCPFailedConnects: s.TCPFailedConnects + other.TCPFailedConnects,
+		Resets:            s.Resets + other.Resets,
 	}
 }
 
@@ -575,5 +595,8 @@ func (s StatCounters) Max(other StatCounters) StatCounters {
 		SentPackets:    maxUint64(s.SentPackets, other.SentPackets),
 		TCPClosed:      maxUint32(s.TCPClosed, other.TCPClosed),
 		TCPEstablished: maxUint32(s.TCPEstablished, other.TCPEstablished),
+
+		TCPFailedConnects: maxUint32(s.TCPFailedConnects, other.TCPFailedConnects),
+		Resets:            maxUint32(s.Resets, other.Resets),
 	}
 }
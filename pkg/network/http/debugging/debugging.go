@@ -21,6 +21,7 @@ type RequestSummary struct {
 	DNS         string
 	Path        string
 	Method      string
+	Version     string
 	ByStatus    map[int]Stats
 	StaticTags  uint64
 	DynamicTags []string
@@ -58,6 +59,7 @@ func HTTP(stats map[http.Key]*http.RequestStats, dns map[util.Address][]dns.Host
 			DNS:      getDNS(dns, serverAddr),
 			Path:     k.Path.Content,
 			Method:   k.Method.String(),
+			Version:  k.Version.String(),
 			ByStatus: make(map[int]Stats),
 		}
 
@@ -0,0 +1,186 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package http
+
+import (
+	"os"
+	"sync"
+	"time"
+	"unsafe"
+
+	manager "github.com/DataDog/ebpf-manager"
+	"github.com/cilium/ebpf"
+
+	ddebpf "github.com/DataDog/datadog-agent/pkg/ebpf"
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	errtelemetry "github.com/DataDog/datadog-agent/pkg/network/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	http2TLSConnsMap    = "http2_tls_conns"
+	http2TLSSegmentsMap = "http2_tls_segments"
+)
+
+// TLSSegmentHandler handles the plaintext of the TLS reads and writes of
+// HTTP/2 connections, which are decoded in userspace
+type TLSSegmentHandler interface {
+	// HandleTLSSegment processes the plaintext of a TLS read or write made by
+	// process pid on the connection identified by tuple, followed by skipped
+	// bytes which were not captured
+	HandleTLSSegment(pid uint32, tuple KeyTuple, fromClient bool, payload []byte, skipped int, ts time.Time)
+	// CloseTLS forgets about the connection identified by tuple in process pid
+	CloseTLS(pid uint32, tuple KeyTuple)
+	// ExpireTLS forgets about the connections without activity since the
+	// given time, and returns how many were removed
+	ExpireTLS(before time.Time) int
+}
+
+// http2TLSProgram streams the plaintext of the TLS connections starting with
+// the HTTP/2 client preface to a TLSSegmentHandler. The TLS uprobes
+// themselves are attached by the sslProgram and the GoTLSProgram.
+type http2TLSProgram struct {
+	cfg         *config.Config
+	handler     TLSSegmentHandler
+	perfHandler *ddebpf.PerfHandler
+	wg          sync.WaitGroup
+}
+
+var _ subprogram = &http2TLSProgram{}
+
+func newHTTP2TLSProgram(c *config.Config, handler TLSSegmentHandler) *http2TLSProgram {
+	if handler == nil || !c.EnableHTTP2Monitoring || !c.EnableHTTPSMonitoring || !HTTPSSupported(c) {
+		return nil
+	}
+
+	return &http2TLSProgram{
+		cfg:         c,
+		handler:     handler,
+		perfHandler: ddebpf.NewPerfHandler(batchNotificationsChanSize),
+	}
+}
+
+func (p *http2TLSProgram) ConfigureManager(m *errtelemetry.Manager) {
+	if p == nil {
+		return
+	}
+
+	m.Maps = append(m.Maps, &manager.Map{Name: http2TLSConnsMap})
+	m.PerfMaps = append(m.PerfMaps, &manager.PerfMap{
+		Map: manager.Map{Name: http2TLSSegmentsMap},
+		PerfMapOptions: manager.PerfMapOptions{
+			PerfRingBufferSize: 64 * os.Getpagesize(),
+			Watermark:          1,
+			RecordHandler:      p.perfHandler.RecordHandler,
+			LostHandler:        p.perfHandler.LostHandler,
+			RecordGetter:       p.perfHandler.RecordGetter,
+		},
+	})
+}
+
+func (p *http2TLSProgram) ConfigureOptions(options *manager.Options) {
+	if p == nil {
+		return
+	}
+
+	options.MapSpecEditors[http2TLSConnsMap] = manager.MapSpecEditor{
+		Type:       ebpf.LRUHash,
+		MaxEntries: uint32(p.cfg.MaxTrackedConnections),
+		EditorFlag: manager.EditMaxEntries,
+	}
+	options.ConstantEditors = append(options.ConstantEditors, manager.ConstantEditor{
+		Name:  "http2_tls_enabled",
+		Value: uint64(1),
+	})
+}
+
+func (p *http2TLSProgram) GetAllUndefinedProbes() []manager.ProbeIdentificationPair {
+	return nil
+}
+
+func (p *http2TLSProgram) Start() {
+	if p == nil {
+		return
+	}
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+
+		ticker := time.NewTicker(p.cfg.HTTPMapCleanerInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case event, ok := <-p.perfHandler.DataChannel:
+				if !ok {
+					return
+				}
+				p.handleSegment(event.Data)
+				event.Done()
+			case lost, ok := <-p.perfHandler.LostChannel:
+				if !ok {
+					return
+				}
+				// the connections missing these segments are considered as
+				// broken as soon as they can't be followed anymore
+				log.Debugf("lost %d HTTP/2 TLS segments", lost)
+			case now := <-ticker.C:
+				p.handler.ExpireTLS(now.Add(-p.cfg.HTTPIdleConnectionTTL))
+			}
+		}
+	}()
+}
+
+func (p *http2TLSProgram) handleSegment(data []byte) {
+	if len(data) < int(unsafe.Sizeof(http2TLSSegmentHeader{})) {
+		return
+	}
+
+	header := *(*http2TLSSegmentHeader)(unsafe.Pointer(&data[0]))
+	tuple := KeyTuple{
+		SrcIPHigh: header.Tup.Saddr_h,
+		SrcIPLow:  header.Tup.Saddr_l,
+		SrcPort:   header.Tup.Sport,
+		DstIPHigh: header.Tup.Daddr_h,
+		DstIPLow:  header.Tup.Daddr_l,
+		DstPort:   header.Tup.Dport,
+	}
+
+	if header.Closed != 0 {
+		p.handler.CloseTLS(header.Tup.Pid, tuple)
+		return
+	}
+
+	payload := data[unsafe.Sizeof(header):]
+	if int(header.Len) < len(payload) {
+		// perf records are padded to 8 bytes
+		payload = payload[:header.Len]
+	}
+
+	p.handler.HandleTLSSegment(header.Tup.Pid, tuple, header.From_client != 0, payload, int(header.Skipped), ktimeToTime(header.Timestamp))
+}
+
+// ktimeToTime converts a time returned by bpf_ktime_get_ns() to a time.Time
+func ktimeToTime(ktime uint64) time.Time {
+	now, err := ddebpf.NowNanoseconds()
+	if err != nil {
+		return time.Now()
+	}
+	return time.Now().Add(time.Duration(int64(ktime) - now))
+}
+
+func (p *http2TLSProgram) Stop() {
+	if p == nil {
+		return
+	}
+
+	p.perfHandler.Stop()
+	p.wg.Wait()
+}
@@ -81,7 +81,7 @@ var tailCalls []manager.TailCallRoute = []manager.TailCallRoute{
 	},
 }
 
-func newEBPFProgram(c *config.Config, offsets []manager.ConstantEditor, sockFD *ebpf.Map, bpfTelemetry *errtelemetry.EBPFTelemetry) (*ebpfProgram, error) {
+func newEBPFProgram(c *config.Config, offsets []manager.ConstantEditor, sockFD *ebpf.Map, bpfTelemetry *errtelemetry.EBPFTelemetry, http2Handler TLSSegmentHandler) (*ebpfProgram, error) {
 	bc, err := getBytecode(c)
 	if err != nil {
 		return nil, err
@@ -151,6 +151,7 @@ func newEBPFProgram(c *config.Config, offsets []manager.ConstantEditor, sockFD *
 	ebpfSubprograms := []subprogram{
 		newGoTLSProgram(c),
 		newSSLProgram(c, sockFD),
+		newHTTP2TLSProgram(c, http2Handler),
 	}
 
 	program := &ebpfProgram{
@@ -0,0 +1,120 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package http2
+
+import (
+	"time"
+
+	"go.uber.org/atomic"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/network/http"
+)
+
+// Handler parses the HTTP/2 segments handed by a protocols.Dispatcher, as
+// well as the plaintext of TLS connections handed by the HTTP monitor, and
+// aggregates their transactions. TCP segments must be handled by a single
+// goroutine, and TLS segments by a single goroutine which may be another one,
+// while stats can be read concurrently.
+type Handler struct {
+	parser     *Parser
+	statKeeper *StatKeeper
+
+	// the TLS connections between two processes of the host are seen by both
+	// of them with the same tuple, so their state is kept by process
+	tlsParsers           map[uint32]*Parser
+	tlsBrokenConnections *atomic.Int64
+}
+
+// NewHandler returns a new Handler
+func NewHandler(c *config.Config) *Handler {
+	return &Handler{
+		parser:               NewParser(),
+		statKeeper:           NewStatKeeper(c),
+		tlsParsers:           make(map[uint32]*Parser),
+		tlsBrokenConnections: atomic.NewInt64(0),
+	}
+}
+
+// HandleSegment processes the payload of a TCP segment of the connection
+// identified by tuple, which is always (client, server)
+func (h *Handler) HandleSegment(tuple http.KeyTuple, fromClient bool, payload []byte, ts time.Time) {
+	h.statKeeper.Process(h.parser.Parse(tuple, fromClient, payload, ts))
+}
+
+// Expire forgets about the connections without activity since the given
+// time, and returns how many were removed
+func (h *Handler) Expire(before time.Time) int {
+	return h.parser.Expire(before)
+}
+
+// HandleTLSSegment processes the plaintext of a TLS read or write made by
+// process pid on the connection identified by tuple, followed by skipped
+// bytes which were not captured
+func (h *Handler) HandleTLSSegment(pid uint32, tuple http.KeyTuple, fromClient bool, payload []byte, skipped int, ts time.Time) {
+	parser, ok := h.tlsParsers[pid]
+	if !ok {
+		parser = newParser(h.tlsBrokenConnections)
+		h.tlsParsers[pid] = parser
+	}
+
+	if len(payload) > 0 {
+		h.statKeeper.Process(parser.Parse(tuple, fromClient, payload, ts))
+	}
+	parser.Skip(tuple, fromClient, skipped, ts)
+}
+
+// CloseTLS forgets about the TLS connection identified by tuple in process
+// pid
+func (h *Handler) CloseTLS(pid uint32, tuple http.KeyTuple) {
+	parser, ok := h.tlsParsers[pid]
+	if !ok {
+		return
+	}
+	parser.Close(tuple)
+	if len(parser.connections) == 0 {
+		delete(h.tlsParsers, pid)
+	}
+}
+
+// ExpireTLS forgets about the TLS connections without activity since the
+// given time, and returns how many were removed
+func (h *Handler) ExpireTLS(before time.Time) int {
+	expired := 0
+	for pid, parser := range h.tlsParsers {
+		expired += parser.Expire(before)
+		if len(parser.connections) == 0 {
+			delete(h.tlsParsers, pid)
+		}
+	}
+	return expired
+}
+
+// GetHTTPStats returns the stats aggregated since the last call
+func (h *Handler) GetHTTPStats() map[http.Key]*http.RequestStats {
+	if h == nil {
+		return nil
+	}
+	return h.statKeeper.GetAndResetAllStats()
+}
+
+// GetStats returns telemetry about the Handler
+func (h *Handler) GetStats() map[string]interface{} {
+	if h == nil {
+		return map[string]interface{}{
+			"enabled": false,
+		}
+	}
+
+	stats := map[string]interface{}{
+		"enabled":            true,
+		"broken_connections": h.parser.brokenConnections.Load() + h.tlsBrokenConnections.Load(),
+	}
+	for key, value := range h.statKeeper.GetStats() {
+		stats[key] = value
+	}
+	return stats
+}
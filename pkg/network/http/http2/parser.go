@@ -0,0 +1,542 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package http2 implements the monitoring of HTTP/2 traffic, including gRPC,
+// either in cleartext (h2c) or over TLS. Frames are parsed in userspace, where
+// header blocks can be decoded with HPACK, and transactions are aggregated
+// with the same keys and stats as HTTP/1 transactions.
+package http2
+
+import (
+	"encoding/binary"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/atomic"
+	"golang.org/x/net/http2/hpack"
+
+	"github.com/DataDog/datadog-agent/pkg/network/http"
+)
+
+// Frame types and flags, see https://httpwg.org/specs/rfc9113.html#FrameTypes
+const (
+	frameData         = 0x0
+	frameHeaders      = 0x1
+	frameRSTStream    = 0x3
+	frameSettings     = 0x4
+	framePushPromise  = 0x5
+	frameContinuation = 0x9
+
+	flagEndStream  = 0x1
+	flagAck        = 0x1
+	flagEndHeaders = 0x4
+	flagPadded     = 0x8
+	flagPriority   = 0x20
+
+	settingHeaderTableSize = 0x1
+
+	frameHeaderSize = 9
+)
+
+const (
+	// clientPreface starts every HTTP/2 connection
+	clientPreface = "PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n"
+
+	// initialHeaderTableSize is the size of the HPACK dynamic table until a
+	// peer asks for another size
+	initialHeaderTableSize = 4096
+
+	// header blocks bigger than this are considered as a loss of
+	// synchronization with the frame boundaries of a connection
+	maxHeaderBlockSize = 64 * 1024
+
+	// maxHeaderValueSize is the maximum size of a decoded header value
+	maxHeaderValueSize = 16 * 1024
+
+	// maxStreams is the maximum number of streams waiting for a response on a
+	// single connection
+	maxStreams = 1024
+
+	grpcContentType = "application/grpc"
+)
+
+// Transaction is a request and its response, exchanged on an HTTP/2 stream
+type Transaction struct {
+	http.KeyTuple
+	Method     http.Method
+	Path       string
+	StatusCode uint16
+
+	// GRPCStatus is the grpc-status trailer of gRPC responses, or -1
+	GRPCStatus int
+
+	// Latency is the time, in nanoseconds, between the request headers and
+	// the end of the response
+	Latency float64
+}
+
+// stream is a request waiting for the end of its response
+type stream struct {
+	method     string
+	path       string
+	grpc       bool
+	statusCode uint16
+	grpcStatus int
+	start      time.Time
+}
+
+// direction holds the state of the frames sent by one side of a connection
+type direction struct {
+	// decoder decodes the header blocks sent by this side, using the dynamic
+	// table built from the previous blocks
+	decoder *hpack.Decoder
+
+	// pending holds a frame header, or a frame whose payload is needed,
+	// which was split across segments
+	pending []byte
+
+	// number of bytes of the payload of an ignored frame still expected
+	skip int
+
+	// headerBlock accumulates the fragments of a header block sent over
+	// HEADERS (or PUSH_PROMISE) and CONTINUATION frames
+	headerBlock     []byte
+	headerStream    uint32
+	headerEndStream bool
+	pushPromise     bool
+}
+
+// connection holds the state of the parser for an HTTP/2 connection
+type connection struct {
+	client, server direction
+
+	// prefaceRemaining is the number of bytes of the client preface still
+	// expected
+	prefaceRemaining int
+
+	streams map[uint32]*stream
+
+	// connections are ignored once a header block can't be decoded, as the
+	// HPACK state of the connection is lost
+	broken bool
+
+	lastSeen time.Time
+}
+
+// Parser extracts transactions from the payloads of HTTP/2 connections.
+// Only connections whose start (the client preface) is seen are parsed, as
+// header blocks can't be decoded without the previous ones. A Parser is not
+// thread-safe.
+type Parser struct {
+	connections map[http.KeyTuple]*connection
+
+	// telemetry
+	brokenConnections *atomic.Int64
+}
+
+// NewParser returns a new Parser
+func NewParser() *Parser {
+	return newParser(atomic.NewInt64(0))
+}
+
+func newParser(brokenConnections *atomic.Int64) *Parser {
+	return &Parser{
+		connections:       make(map[http.KeyTuple]*connection),
+		brokenConnections: brokenConnections,
+	}
+}
+
+// Parse processes the payload of a TCP segment of the connection identified
+// by tuple, which is always (client, server), and returns the transactions it
+// completes
+func (p *Parser) Parse(tuple http.KeyTuple, fromClient bool, payload []byte, ts time.Time) []Transaction {
+	conn, ok := p.connections[tuple]
+	if !ok {
+		if !fromClient || !strings.HasPrefix(clientPreface, string(payload[:min(len(payload), len(clientPreface))])) {
+			return nil
+		}
+		conn = newConnection()
+		p.connections[tuple] = conn
+	}
+	conn.lastSeen = ts
+
+	if conn.broken {
+		return nil
+	}
+
+	if fromClient && conn.prefaceRemaining > 0 {
+		n := min(conn.prefaceRemaining, len(payload))
+		conn.prefaceRemaining -= n
+		payload = payload[n:]
+	}
+
+	d := &conn.server
+	if fromClient {
+		d = &conn.client
+	}
+
+	var transactions []Transaction
+	for len(payload) > 0 && !conn.broken {
+		if d.skip > 0 {
+			n := min(d.skip, len(payload))
+			d.skip -= n
+			payload = payload[n:]
+			continue
+		}
+
+		frame, rest, ok := d.nextFrame(payload)
+		payload = rest
+		if !ok {
+			if frame == nil {
+				// the frame is too big to be buffered
+				conn.broken = true
+			}
+			break
+		}
+
+		if tx, ok := conn.handleFrame(d, fromClient, frame, ts); ok {
+			tx.KeyTuple = tuple
+			transactions = append(transactions, tx)
+		}
+	}
+
+	if conn.broken {
+		conn.markBroken(p)
+	}
+
+	return transactions
+}
+
+// Skip accounts for n bytes of the connection identified by tuple which were
+// sent but not captured. The connection is still followed when these bytes
+// are part of the payload of a frame which is ignored, and is considered as
+// broken otherwise.
+func (p *Parser) Skip(tuple http.KeyTuple, fromClient bool, n int, ts time.Time) {
+	conn, ok := p.connections[tuple]
+	if !ok || conn.broken || n <= 0 {
+		return
+	}
+	conn.lastSeen = ts
+
+	d := &conn.server
+	if fromClient {
+		d = &conn.client
+	}
+
+	if (fromClient && conn.prefaceRemaining > 0) || len(d.pending) > 0 || n > d.skip {
+		conn.markBroken(p)
+		return
+	}
+	d.skip -= n
+}
+
+// Close forgets about the connection identified by tuple
+func (p *Parser) Close(tuple http.KeyTuple) {
+	delete(p.connections, tuple)
+}
+
+func newConnection() *connection {
+	return &connection{
+		client:           direction{decoder: newDecoder()},
+		server:           direction{decoder: newDecoder()},
+		prefaceRemaining: len(clientPreface),
+		streams:          make(map[uint32]*stream),
+	}
+}
+
+func newDecoder() *hpack.Decoder {
+	decoder := hpack.NewDecoder(initialHeaderTableSize, nil)
+	decoder.SetMaxStringLength(maxHeaderValueSize)
+	return decoder
+}
+
+func (c *connection) markBroken(p *Parser) {
+	if c.streams == nil {
+		// already accounted for
+		return
+	}
+	c.broken = true
+	c.streams = nil
+	c.client = direction{}
+	c.server = direction{}
+	p.brokenConnections.Inc()
+}
+
+// nextFrame returns the next frame of payload, when it is complete or when
+// its payload is not needed, and the rest of payload. It returns false when
+// payload ends in the middle of a frame, which is kept until the next segment.
+// A nil frame returned with false means that the frame can't be handled.
+func (d *direction) nextFrame(payload []byte) (frame []byte, rest []byte, ok bool) {
+	data := payload
+	if len(d.pending) > 0 {
+		data = append(d.pending, payload...)
+	}
+
+	if len(data) < frameHeaderSize {
+		d.pending = append(d.pending[:0], data...)
+		return []byte{}, nil, false
+	}
+
+	length := int(data[0])<<16 | int(data[1])<<8 | int(data[2])
+	frameType := data[3]
+
+	if !needsPayload(frameType) {
+		consumed := len(data) - len(payload)
+		d.pending = d.pending[:0]
+		header := data[:frameHeaderSize:frameHeaderSize]
+		if frameHeaderSize+length > len(data) {
+			d.skip = frameHeaderSize + length - len(data)
+			return header, nil, true
+		}
+		return header, payload[frameHeaderSize+length-consumed:], true
+	}
+
+	if length > maxHeaderBlockSize {
+		d.pending = d.pending[:0]
+		return nil, nil, false
+	}
+
+	if frameHeaderSize+length > len(data) {
+		d.pending = append(d.pending[:0], data...)
+		return []byte{}, nil, false
+	}
+
+	consumed := len(data) - len(payload)
+	frame = data[:frameHeaderSize+length]
+	if len(d.pending) > 0 {
+		// the frame is copied, as pending is reused
+		frame = append([]byte(nil), frame...)
+		d.pending = d.pending[:0]
+	}
+	return frame, payload[frameHeaderSize+length-consumed:], true
+}
+
+// needsPayload returns true for the frames whose payload is parsed
+func needsPayload(frameType byte) bool {
+	switch frameType {
+	case frameHeaders, frameContinuation, framePushPromise, frameSettings:
+		return true
+	}
+	return false
+}
+
+// handleFrame processes a frame sent by d. Frames which don't need their
+// payload are passed with their header only.
+func (c *connection) handleFrame(d *direction, fromClient bool, frame []byte, ts time.Time) (Transaction, bool) {
+	frameType := frame[3]
+	flags := frame[4]
+	streamID := binary.BigEndian.Uint32(frame[5:]) & 0x7fffffff
+	payload := frame[frameHeaderSize:]
+
+	if d.headerBlock != nil && frameType != frameContinuation {
+		// a header block must be followed by its CONTINUATION frames
+		c.broken = true
+		return Transaction{}, false
+	}
+
+	switch frameType {
+	case frameData:
+		if !fromClient && flags&flagEndStream != 0 {
+			return c.endStream(streamID, ts)
+		}
+	case frameRSTStream:
+		delete(c.streams, streamID)
+	case frameSettings:
+		if flags&flagAck == 0 {
+			c.handleSettings(fromClient, payload)
+		}
+	case frameHeaders:
+		var ok bool
+		if payload, ok = removePadding(flags, payload); !ok {
+			c.broken = true
+			return Transaction{}, false
+		}
+		if flags&flagPriority != 0 {
+			if len(payload) < 5 {
+				c.broken = true
+				return Transaction{}, false
+			}
+			payload = payload[5:]
+		}
+		d.headerStream = streamID
+		d.headerEndStream = flags&flagEndStream != 0
+		d.pushPromise = false
+		return c.appendHeaderBlock(d, fromClient, flags, payload, ts)
+	case framePushPromise:
+		var ok bool
+		if payload, ok = removePadding(flags, payload); !ok || len(payload) < 4 {
+			c.broken = true
+			return Transaction{}, false
+		}
+		d.headerStream = streamID
+		d.headerEndStream = false
+		d.pushPromise = true
+		return c.appendHeaderBlock(d, fromClient, flags, payload[4:], ts)
+	case frameContinuation:
+		if d.headerBlock == nil || streamID != d.headerStream {
+			c.broken = true
+			return Transaction{}, false
+		}
+		return c.appendHeaderBlock(d, fromClient, flags, payload, ts)
+	}
+
+	return Transaction{}, false
+}
+
+// handleSettings applies the header table size set by a peer, which bounds
+// the dynamic table of the headers sent to this peer
+func (c *connection) handleSettings(fromClient bool, payload []byte) {
+	for ; len(payload) >= 6; payload = payload[6:] {
+		if binary.BigEndian.Uint16(payload) != settingHeaderTableSize {
+			continue
+		}
+		size := binary.BigEndian.Uint32(payload[2:])
+		if fromClient {
+			c.server.decoder.SetAllowedMaxDynamicTableSize(size)
+		} else {
+			c.client.decoder.SetAllowedMaxDynamicTableSize(size)
+		}
+	}
+}
+
+func removePadding(flags byte, payload []byte) ([]byte, bool) {
+	if flags&flagPadded == 0 {
+		return payload, true
+	}
+	if len(payload) < 1 {
+		return nil, false
+	}
+	padding := int(payload[0])
+	if 1+padding > len(payload) {
+		return nil, false
+	}
+	return payload[1 : len(payload)-padding], true
+}
+
+func (c *connection) appendHeaderBlock(d *direction, fromClient bool, flags byte, fragment []byte, ts time.Time) (Transaction, bool) {
+	if len(d.headerBlock)+len(fragment) > maxHeaderBlockSize {
+		c.broken = true
+		return Transaction{}, false
+	}
+	if d.headerBlock == nil {
+		d.headerBlock = make([]byte, 0, len(fragment))
+	}
+	d.headerBlock = append(d.headerBlock, fragment...)
+	if flags&flagEndHeaders == 0 {
+		return Transaction{}, false
+	}
+
+	block := d.headerBlock
+	d.headerBlock = nil
+
+	// header blocks are always decoded, even when they are not needed, in
+	// order to keep the dynamic table up to date
+	fields, err := d.decoder.DecodeFull(block)
+	if err != nil {
+		c.broken = true
+		return Transaction{}, false
+	}
+	if d.pushPromise {
+		return Transaction{}, false
+	}
+
+	if fromClient {
+		c.handleRequestHeaders(d.headerStream, fields, ts)
+		return Transaction{}, false
+	}
+
+	c.handleResponseHeaders(d.headerStream, fields)
+	if d.headerEndStream {
+		return c.endStream(d.headerStream, ts)
+	}
+	return Transaction{}, false
+}
+
+func (c *connection) handleRequestHeaders(streamID uint32, fields []hpack.HeaderField, ts time.Time) {
+	if _, ok := c.streams[streamID]; ok {
+		// trailers of the request
+		return
+	}
+	if len(c.streams) >= maxStreams {
+		return
+	}
+
+	s := &stream{start: ts, grpcStatus: -1}
+	for _, f := range fields {
+		switch f.Name {
+		case ":method":
+			s.method = f.Value
+		case ":path":
+			s.path = f.Value
+		case "content-type":
+			s.grpc = strings.HasPrefix(f.Value, grpcContentType)
+		}
+	}
+	c.streams[streamID] = s
+}
+
+func (c *connection) handleResponseHeaders(streamID uint32, fields []hpack.HeaderField) {
+	s, ok := c.streams[streamID]
+	if !ok {
+		return
+	}
+
+	for _, f := range fields {
+		switch f.Name {
+		case ":status":
+			status, err := strconv.ParseUint(f.Value, 10, 16)
+			// informational responses precede the final one
+			if err == nil && status >= 200 {
+				s.statusCode = uint16(status)
+			}
+		case "grpc-status":
+			if status, err := strconv.Atoi(f.Value); err == nil && s.grpc {
+				s.grpcStatus = status
+			}
+		}
+	}
+}
+
+func (c *connection) endStream(streamID uint32, ts time.Time) (Transaction, bool) {
+	s, ok := c.streams[streamID]
+	if !ok {
+		return Transaction{}, false
+	}
+	delete(c.streams, streamID)
+
+	latency := ts.Sub(s.start)
+	if s.statusCode == 0 || latency < 0 {
+		return Transaction{}, false
+	}
+
+	return Transaction{
+		Method:     http.MethodFromString(s.method),
+		Path:       s.path,
+		StatusCode: s.statusCode,
+		GRPCStatus: s.grpcStatus,
+		Latency:    float64(latency.Nanoseconds()),
+	}, true
+}
+
+// Expire forgets about the connections without activity since the given
+// time, and returns how many were removed
+func (p *Parser) Expire(before time.Time) int {
+	expired := 0
+	for tuple, conn := range p.connections {
+		if conn.lastSeen.Before(before) {
+			delete(p.connections, tuple)
+			expired++
+		}
+	}
+	return expired
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
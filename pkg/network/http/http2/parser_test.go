@@ -0,0 +1,152 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package http2
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/network/http"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols/testutil"
+)
+
+func TestParsePcap(t *testing.T) {
+	handler := NewHandler(&config.Config{MaxHTTPStatsBuffered: 1000})
+	testutil.Replay(t, "testdata/h2c.pcap", map[uint16]protocols.SegmentHandler{50051: handler})
+
+	byPath := make(map[string]*http.RequestStats)
+	for key, stats := range handler.GetHTTPStats() {
+		assert.Equal(t, http.ProtocolVersion2, key.Version)
+		assert.True(t, key.Path.FullPath)
+		byPath[key.Method.String()+" "+key.Path.Content] = stats
+	}
+	// the cancelled request and the connection whose start wasn't captured
+	// are ignored
+	require.Len(t, byPath, 3)
+
+	// the second request relies on the dynamic table filled by the first one
+	hello := byPath["POST /helloworld.Greeter/SayHello"]
+	require.NotNil(t, hello)
+	require.NotNil(t, hello.Stats(200))
+	assert.Equal(t, 2, hello.Stats(200).Count)
+	assert.Equal(t, []string{"grpc.status_code:OK"}, hello.Stats(200).DynamicTags)
+
+	// header block split over a CONTINUATION frame, answered with trailers
+	// only, then retransmitted
+	goodbye := byPath["POST /helloworld.Greeter/SayGoodbye"]
+	require.NotNil(t, goodbye)
+	require.NotNil(t, goodbye.Stats(200))
+	assert.Equal(t, 1, goodbye.Stats(200).Count)
+	assert.Equal(t, []string{"grpc.status_code:NotFound"}, goodbye.Stats(200).DynamicTags)
+
+	// padded HEADERS frame with a priority, whose query is removed
+	users := byPath["GET /api/users"]
+	require.NotNil(t, users)
+	require.NotNil(t, users.Stats(400))
+	assert.Equal(t, 1, users.Stats(400).Count)
+	assert.Equal(t, float64(3*time.Millisecond), users.Stats(400).FirstLatencySample)
+	assert.Empty(t, users.Stats(400).DynamicTags)
+
+	assert.Equal(t, int64(0), handler.parser.brokenConnections.Load())
+	assert.Equal(t, 2, handler.Expire(time.Now()))
+}
+
+func TestInvalidHeaderBlock(t *testing.T) {
+	p := NewParser()
+	now := time.Now()
+	tuple := http.KeyTuple{SrcPort: 40000, DstPort: 50051}
+
+	// a HEADERS frame referencing an entry missing from the dynamic table
+	frame := []byte{0, 0, 1, frameHeaders, flagEndHeaders | flagEndStream, 0, 0, 0, 1, 0xbf}
+	assert.Empty(t, p.Parse(tuple, true, append([]byte(clientPreface), frame...), now))
+	assert.Equal(t, int64(1), p.brokenConnections.Load())
+
+	// the connection is ignored from then on
+	assert.Empty(t, p.Parse(tuple, false, frame, now))
+	assert.Equal(t, int64(1), p.brokenConnections.Load())
+	assert.Equal(t, 1, p.Expire(now.Add(time.Second)))
+}
+
+func TestSkip(t *testing.T) {
+	p := NewParser()
+	now := time.Now()
+	tuple := http.KeyTuple{SrcPort: 40000, DstPort: 443}
+
+	// requests and responses only made of fields of the static table
+	request := func(stream byte) []byte {
+		return []byte{0, 0, 2, frameHeaders, flagEndHeaders | flagEndStream, 0, 0, 0, stream, 0x82, 0x84}
+	}
+	response := func(stream byte, flags byte) []byte {
+		return []byte{0, 0, 1, frameHeaders, flagEndHeaders | flags, 0, 0, 0, stream, 0x88}
+	}
+
+	assert.Empty(t, p.Parse(tuple, true, append([]byte(clientPreface), request(1)...), now))
+	assert.Empty(t, p.Parse(tuple, false, response(1, 0), now))
+
+	// a DATA frame whose end wasn't captured
+	data := append([]byte{0, 0, 100, frameData, flagEndStream, 0, 0, 0, 1}, make([]byte, 10)...)
+	assert.Len(t, p.Parse(tuple, false, data, now.Add(time.Millisecond)), 1)
+	p.Skip(tuple, false, 90, now)
+
+	// the connection is still followed
+	assert.Empty(t, p.Parse(tuple, true, request(3), now))
+	assert.Len(t, p.Parse(tuple, false, response(3, flagEndStream), now.Add(time.Millisecond)), 1)
+	assert.Equal(t, int64(0), p.brokenConnections.Load())
+
+	// bytes missing from the frames which are parsed break the connection
+	assert.Empty(t, p.Parse(tuple, true, request(5)[:5], now))
+	p.Skip(tuple, true, 6, now)
+	assert.Equal(t, int64(1), p.brokenConnections.Load())
+	assert.Empty(t, p.Parse(tuple, false, response(5, flagEndStream), now))
+
+	p.Close(tuple)
+	assert.Equal(t, 0, p.Expire(now.Add(time.Second)))
+}
+
+func TestSkipBeyondFrame(t *testing.T) {
+	p := NewParser()
+	now := time.Now()
+	tuple := http.KeyTuple{SrcPort: 40000, DstPort: 443}
+
+	assert.Empty(t, p.Parse(tuple, true, []byte(clientPreface), now))
+	assert.Empty(t, p.Parse(tuple, false, []byte{0, 0, 10, frameData, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0}, now))
+
+	// the skipped bytes overlap the next frame, whose boundaries are lost
+	p.Skip(tuple, false, 20, now)
+	assert.Equal(t, int64(1), p.brokenConnections.Load())
+}
+
+func TestTLSConnectionsByProcess(t *testing.T) {
+	handler := NewHandler(&config.Config{MaxHTTPStatsBuffered: 1000})
+	now := time.Now()
+	tuple := http.KeyTuple{SrcPort: 40000, DstPort: 443}
+
+	request := []byte{0, 0, 2, frameHeaders, flagEndHeaders | flagEndStream, 0, 0, 0, 1, 0x82, 0x84}
+	response := []byte{0, 0, 1, frameHeaders, flagEndHeaders | flagEndStream, 0, 0, 0, 1, 0x88}
+
+	// the client and the server of a connection of the host see it with the
+	// same tuple
+	handler.HandleTLSSegment(1, tuple, true, append([]byte(clientPreface), request...), 0, now)
+	handler.HandleTLSSegment(2, tuple, true, append([]byte(clientPreface), request...), 0, now)
+	handler.HandleTLSSegment(2, tuple, false, response, 0, now.Add(time.Millisecond))
+	handler.HandleTLSSegment(1, tuple, false, response, 0, now.Add(time.Millisecond))
+
+	stats := handler.GetHTTPStats()
+	require.Len(t, stats, 1)
+	for _, stat := range stats {
+		assert.Equal(t, 2, stat.Stats(200).Count)
+	}
+	assert.Equal(t, int64(0), handler.GetStats()["broken_connections"])
+
+	handler.CloseTLS(1, tuple)
+	assert.Equal(t, 1, handler.ExpireTLS(now.Add(time.Second)))
+	assert.Empty(t, handler.tlsParsers)
+}
@@ -0,0 +1,204 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package http2
+
+import (
+	"strconv"
+	"sync"
+
+	"go.uber.org/atomic"
+	"google.golang.org/grpc/codes"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/network/http"
+)
+
+// grpcStatusTagPrefix prefixes the dynamic tag holding the status code of gRPC
+// responses, such as grpc.status_code:NotFound
+const grpcStatusTagPrefix = "grpc.status_code:"
+
+// StatKeeper aggregates HTTP/2 transactions by http.Key, so that their stats
+// can be merged with the ones of HTTP/1 transactions
+type StatKeeper struct {
+	mux        sync.Mutex
+	stats      map[http.Key]*http.RequestStats
+	maxEntries int
+
+	// replace rules for HTTP path
+	replaceRules []*config.ReplaceRule
+
+	// map containing interned path strings
+	// this is rotated with the stats map
+	interned map[string]string
+
+	// telemetry
+	processed *atomic.Int64
+	dropped   *atomic.Int64
+	rejected  *atomic.Int64
+	malformed *atomic.Int64
+}
+
+// NewStatKeeper returns a new StatKeeper
+func NewStatKeeper(c *config.Config) *StatKeeper {
+	return &StatKeeper{
+		stats:        make(map[http.Key]*http.RequestStats),
+		maxEntries:   c.MaxHTTPStatsBuffered,
+		replaceRules: c.HTTPReplaceRules,
+		interned:     make(map[string]string),
+		processed:    atomic.NewInt64(0),
+		dropped:      atomic.NewInt64(0),
+		rejected:     atomic.NewInt64(0),
+		malformed:    atomic.NewInt64(0),
+	}
+}
+
+// Process adds transactions to the stats
+func (s *StatKeeper) Process(transactions []Transaction) {
+	if len(transactions) == 0 {
+		return
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	for i := range transactions {
+		s.processed.Inc()
+		s.add(&transactions[i])
+	}
+}
+
+func (s *StatKeeper) add(tx *Transaction) {
+	if tx.Method == http.MethodUnknown || tx.Latency <= 0 {
+		s.malformed.Inc()
+		return
+	}
+
+	path, rejected := s.processPath(tx.Path)
+	if rejected {
+		return
+	}
+
+	key := http.Key{
+		KeyTuple: tx.KeyTuple,
+		Path: http.Path{
+			Content:  path,
+			FullPath: true,
+		},
+		Method:  tx.Method,
+		Version: http.ProtocolVersion2,
+	}
+	stats, ok := s.stats[key]
+	if !ok {
+		if len(s.stats) >= s.maxEntries {
+			s.dropped.Inc()
+			return
+		}
+		stats = new(http.RequestStats)
+		s.stats[key] = stats
+	}
+
+	statusClass := int(tx.StatusCode) / 100 * 100
+	stats.AddRequest(statusClass, tx.Latency, 0, grpcStatusTags(stats, statusClass, tx.GRPCStatus))
+}
+
+// grpcStatusTags returns the dynamic tags to add to stats for a gRPC status,
+// which are only added once to each status class
+func grpcStatusTags(stats *http.RequestStats, statusClass int, grpcStatus int) []string {
+	if grpcStatus < 0 {
+		return nil
+	}
+
+	// codes outside of the ones defined by gRPC are reported as unknown, as
+	// gRPC clients do, to bound the number of tag values
+	code := codes.Unknown
+	if grpcStatus <= int(codes.Unauthenticated) {
+		code = codes.Code(grpcStatus)
+	}
+	tag := grpcStatusTagPrefix + code.String()
+	if stat := stats.Stats(statusClass); stat != nil {
+		for _, t := range stat.DynamicTags {
+			if t == tag {
+				return nil
+			}
+		}
+	}
+	return []string{tag}
+}
+
+// processPath removes the query from path and applies the replace rules to
+// it. It returns true if the path must be rejected.
+func (s *StatKeeper) processPath(path string) (string, bool) {
+	for i := 0; i < len(path); i++ {
+		if path[i] == '?' {
+			path = path[:i]
+			break
+		}
+	}
+
+	b := []byte(path)
+	match := false
+	for _, r := range s.replaceRules {
+		if r.Re.Match(b) {
+			if r.Repl == "" {
+				// this is a "drop" rule
+				s.rejected.Inc()
+				return "", true
+			}
+
+			b = r.Re.ReplaceAll(b, []byte(r.Repl))
+			match = true
+		}
+	}
+
+	// paths matching a user rule are not checked, as for HTTP/1
+	if !match && !isPrintable(b) {
+		s.malformed.Inc()
+		return "", true
+	}
+	return s.intern(b), false
+}
+
+func isPrintable(path []byte) bool {
+	if len(path) == 0 {
+		return false
+	}
+	for _, r := range path {
+		if !strconv.IsPrint(rune(r)) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *StatKeeper) intern(b []byte) string {
+	v, ok := s.interned[string(b)]
+	if !ok {
+		v = string(b)
+		s.interned[v] = v
+	}
+	return v
+}
+
+// GetAndResetAllStats returns the stats aggregated since the last call
+func (s *StatKeeper) GetAndResetAllStats() map[http.Key]*http.RequestStats {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	ret := s.stats // No deep copy needed since `s.stats` gets reset
+	s.stats = make(map[http.Key]*http.RequestStats)
+	s.interned = make(map[string]string)
+	return ret
+}
+
+// GetStats returns telemetry about the StatKeeper
+func (s *StatKeeper) GetStats() map[string]int64 {
+	return map[string]int64{
+		"processed": s.processed.Load(),
+		"dropped":   s.dropped.Load(),
+		"rejected":  s.rejected.Load(),
+		"malformed": s.malformed.Load(),
+	}
+}
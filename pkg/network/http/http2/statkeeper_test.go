@@ -0,0 +1,66 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package http2
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/network/http"
+)
+
+func TestStatKeeperReplaceRules(t *testing.T) {
+	s := NewStatKeeper(&config.Config{
+		MaxHTTPStatsBuffered: 1000,
+		HTTPReplaceRules: []*config.ReplaceRule{
+			{Re: regexp.MustCompile(`^/users/\d+`), Repl: "/users/?"},
+			{Re: regexp.MustCompile(`^/health`), Repl: ""},
+		},
+	})
+
+	tx := func(path string, status uint16) Transaction {
+		return Transaction{Method: http.MethodGet, Path: path, StatusCode: status, GRPCStatus: -1, Latency: 1000}
+	}
+	s.Process([]Transaction{
+		tx("/users/1?verbose=1", 200),
+		tx("/users/2", 500),
+		tx("/health", 200),
+		tx("/bad\x01path", 200),
+		{Method: http.MethodUnknown, Path: "/", StatusCode: 200, Latency: 1000},
+	})
+
+	stats := s.GetAndResetAllStats()
+	require.Len(t, stats, 1)
+	for key, stat := range stats {
+		assert.Equal(t, "/users/?", key.Path.Content)
+		assert.Equal(t, http.ProtocolVersion2, key.Version)
+		assert.Equal(t, 1, stat.Stats(200).Count)
+		assert.Equal(t, 1, stat.Stats(500).Count)
+	}
+
+	telemetry := s.GetStats()
+	assert.Equal(t, int64(5), telemetry["processed"])
+	assert.Equal(t, int64(1), telemetry["rejected"])
+	assert.Equal(t, int64(2), telemetry["malformed"])
+	assert.Empty(t, s.GetAndResetAllStats())
+}
+
+func TestGRPCStatusTags(t *testing.T) {
+	var stats http.RequestStats
+	assert.Equal(t, []string{"grpc.status_code:NotFound"}, grpcStatusTags(&stats, 200, 5))
+	assert.Equal(t, []string{"grpc.status_code:Unauthenticated"}, grpcStatusTags(&stats, 200, 16))
+	// codes unknown to gRPC don't leak into tag values
+	assert.Equal(t, []string{"grpc.status_code:Unknown"}, grpcStatusTags(&stats, 200, 17))
+	assert.Equal(t, []string{"grpc.status_code:Unknown"}, grpcStatusTags(&stats, 200, 1<<30))
+	assert.Nil(t, grpcStatusTags(&stats, 200, -1))
+
+	stats.AddRequest(200, 1000, 0, grpcStatusTags(&stats, 200, 99))
+	assert.Nil(t, grpcStatusTags(&stats, 200, 2))
+}
@@ -61,6 +61,48 @@ func (m Method) String() string {
 	}
 }
 
+// ProtocolVersion is the version of the HTTP protocol of a group of transactions
+type ProtocolVersion uint8
+
+const (
+	// ProtocolVersion1 represents HTTP/1.0 and HTTP/1.1, and is the zero value
+	ProtocolVersion1 ProtocolVersion = iota
+	// ProtocolVersion2 represents HTTP/2
+	ProtocolVersion2
+)
+
+// String returns a string representing the HTTP protocol version
+func (v ProtocolVersion) String() string {
+	switch v {
+	case ProtocolVersion2:
+		return "HTTP/2"
+	default:
+		return "HTTP/1.x"
+	}
+}
+
+// MethodFromString returns the Method represented by s, or MethodUnknown
+func MethodFromString(s string) Method {
+	switch s {
+	case "GET":
+		return MethodGet
+	case "POST":
+		return MethodPost
+	case "PUT":
+		return MethodPut
+	case "DELETE":
+		return MethodDelete
+	case "HEAD":
+		return MethodHead
+	case "OPTIONS":
+		return MethodOptions
+	case "PATCH":
+		return MethodPatch
+	default:
+		return MethodUnknown
+	}
+}
+
 // Path represents the HTTP path
 type Path struct {
 	Content  string
@@ -85,7 +127,8 @@ type Key struct {
 	// this field order is intentional to help the GC pointer tracking
 	Path Path
 	KeyTuple
-	Method Method
+	Method  Method
+	Version ProtocolVersion
 }
 
 // NewKey generates a new Key
@@ -20,6 +20,8 @@ type httpBatchState C.http_batch_state_t
 type sslSock C.ssl_sock_t
 type sslReadArgs C.ssl_read_args_t
 
+type http2TLSSegmentHeader C.http2_tls_segment_header_t
+
 type ebpfHttpTx C.http_transaction_t
 type httpBatch C.http_batch_t
 type httpBatchKey C.http_batch_key_t
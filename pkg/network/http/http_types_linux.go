@@ -28,6 +28,16 @@ type sslReadArgs struct {
 	Buf *byte
 }
 
+type http2TLSSegmentHeader struct {
+	Tup         httpConnTuple
+	Timestamp   uint64
+	Len         uint32
+	Skipped     uint32
+	From_client uint8
+	Closed      uint8
+	Pad_cgo_0   [6]byte
+}
+
 type ebpfHttpTx struct {
 	Tup                  httpConnTuple
 	Request_started      uint64
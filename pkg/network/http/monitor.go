@@ -53,9 +53,10 @@ type Monitor struct {
 	stopped       bool
 }
 
-// NewMonitor returns a new Monitor instance
-func NewMonitor(c *config.Config, offsets []manager.ConstantEditor, sockFD *ebpf.Map, bpfTelemetry *errtelemetry.EBPFTelemetry) (*Monitor, error) {
-	mgr, err := newEBPFProgram(c, offsets, sockFD, bpfTelemetry)
+// NewMonitor returns a new Monitor instance. The plaintext of the HTTP/2
+// connections monitored over TLS is handed to http2Handler, which can be nil.
+func NewMonitor(c *config.Config, offsets []manager.ConstantEditor, sockFD *ebpf.Map, bpfTelemetry *errtelemetry.EBPFTelemetry, http2Handler TLSSegmentHandler) (*Monitor, error) {
+	mgr, err := newEBPFProgram(c, offsets, sockFD, bpfTelemetry, http2Handler)
 	if err != nil {
 		return nil, fmt.Errorf("error setting up http ebpf program: %s", err)
 	}
@@ -0,0 +1,208 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package http
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+)
+
+// the constants below mirror the ones of the socket filter program
+const (
+	// httpsPort is the port of the encrypted traffic, which is only
+	// processed for connection teardowns
+	httpsPort = 443
+
+	// httpStatusOffset is the offset of the status code in a response
+	httpStatusOffset = 9
+
+	// ephemeralRangeBegin and ephemeralRangeEnd bound the ports considered
+	// as client ports when normalizing tuples
+	ephemeralRangeBegin = 32768
+	ephemeralRangeEnd   = 60999
+
+	// connTypeTCP and connV6 are the flags of the tuple metadata
+	connTypeTCP = 1
+	connV6      = 1 << 1
+)
+
+var requestPrefixes = []struct {
+	prefix []byte
+	method Method
+}{
+	{[]byte("GET /"), MethodGet},
+	{[]byte("POST /"), MethodPost},
+	{[]byte("PUT /"), MethodPut},
+	{[]byte("DELETE /"), MethodDelete},
+	{[]byte("HEAD /"), MethodHead},
+	{[]byte("OPTIONS /"), MethodOptions},
+	{[]byte("OPTIONS *"), MethodOptions},
+	{[]byte("PATCH /"), MethodPatch},
+}
+
+// Replayer emulates the HTTP socket filter program on captured TCP
+// segments, so that captures go through the same aggregation as the
+// transactions read from eBPF. Unlike DNS packets, which are parsed by the
+// same code when replayed, the classification of HTTP segments is done in
+// C by the program: the Replayer re-implements it and is an approximation,
+// kept from drifting by TestReplayerMatchesSocketFilter, which runs the same
+// exchanges through both. Encrypted traffic, which is monitored with
+// uprobes, can't be replayed. A Replayer is not thread-safe.
+type Replayer struct {
+	inFlight   map[httpConnTuple]*ebpfHttpTx
+	enqueued   []httpTX
+	statKeeper *httpStatKeeper
+	telemetry  *telemetry
+}
+
+// NewReplayer returns a new Replayer
+func NewReplayer(c *config.Config) (*Replayer, error) {
+	telemetry, err := newTelemetry()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Replayer{
+		inFlight:   make(map[httpConnTuple]*ebpfHttpTx),
+		statKeeper: newHTTPStatkeeper(c, telemetry),
+		telemetry:  telemetry,
+	}, nil
+}
+
+// Process handles a TCP segment captured at ts, closing being true for the
+// segments with the FIN or RST flag
+func (r *Replayer) Process(src, dst util.Address, sport, dport uint16, seq uint32, closing bool, payload []byte, ts time.Time) {
+	if len(payload) == 0 || sport == httpsPort || dport == httpsPort {
+		if !closing {
+			return
+		}
+		payload = nil
+	}
+
+	stack := &ebpfHttpTx{Owned_by_src_port: sport}
+	stack.Tup = newConnTuple(src, dst, sport, dport)
+	copy(stack.Request_fragment[:], payload)
+
+	isRequest, isResponse, method := parseData(stack.Request_fragment[:])
+	tx, ok := r.inFlight[stack.Tup]
+	if !ok {
+		if !isRequest && !isResponse {
+			return
+		}
+		tx = new(ebpfHttpTx)
+		*tx = *stack
+		r.inFlight[stack.Tup] = tx
+	}
+
+	if seq != 0 && tx.Tcp_seq == seq {
+		// the segment was seen before, which happens with localhost traffic
+		return
+	}
+
+	if (isRequest && tx.Request_started != 0) || (isResponse && tx.Response_status_code != 0) {
+		r.enqueue(tx)
+		*tx = *stack
+	}
+
+	now := uint64(ts.UnixNano())
+	if isRequest {
+		tx.Request_method = uint8(method)
+		tx.Request_started = now
+		tx.Response_last_seen = 0
+		tx.Response_status_code = 0
+		tx.Request_fragment = stack.Request_fragment
+		tx.Tcp_seq = seq
+	} else if isResponse && len(payload) > httpStatusOffset+2 {
+		buffer := stack.Request_fragment[httpStatusOffset:]
+		tx.Response_status_code = uint16(buffer[0]-'0')*100 + uint16(buffer[1]-'0')*10 + uint16(buffer[2]-'0')
+		tx.Tcp_seq = seq
+	}
+
+	if tx.Response_status_code != 0 {
+		tx.Response_last_seen = now
+	}
+
+	if closing && tx.Owned_by_src_port == sport {
+		r.enqueue(tx)
+		delete(r.inFlight, stack.Tup)
+	}
+}
+
+func (r *Replayer) enqueue(tx *ebpfHttpTx) {
+	enqueued := new(ebpfHttpTx)
+	*enqueued = *tx
+	r.enqueued = append(r.enqueued, enqueued)
+}
+
+// GetHTTPStats returns the stats of the transactions replayed since the last
+// call, including the ones of connections which weren't closed
+func (r *Replayer) GetHTTPStats() map[Key]*RequestStats {
+	for tuple, tx := range r.inFlight {
+		r.enqueue(tx)
+		delete(r.inFlight, tuple)
+	}
+
+	r.telemetry.aggregate(r.enqueued, nil)
+	r.statKeeper.Process(r.enqueued)
+	r.enqueued = nil
+	return r.statKeeper.GetAndResetAllStats()
+}
+
+// GetStats returns telemetry about the replayed transactions
+func (r *Replayer) GetStats() map[string]interface{} {
+	return r.telemetry.report()
+}
+
+// parseData detects requests and responses like the socket filter does
+func parseData(p []byte) (isRequest bool, isResponse bool, method Method) {
+	if bytes.HasPrefix(p, []byte("HTTP")) {
+		return false, true, MethodUnknown
+	}
+	for _, r := range requestPrefixes {
+		if bytes.HasPrefix(p, r.prefix) {
+			return true, false, r.method
+		}
+	}
+	return false, false, MethodUnknown
+}
+
+// newConnTuple returns the tuple of a segment, normalized to the (client,
+// server) format based on the port range heuristic
+func newConnTuple(src, dst util.Address, sport, dport uint16) httpConnTuple {
+	if !isEphemeralPort(sport) || isEphemeralPort(dport) {
+		if (!isEphemeralPort(sport) && isEphemeralPort(dport)) || dport > sport {
+			src, dst = dst, src
+			sport, dport = dport, sport
+		}
+	}
+
+	metadata := uint32(connTypeTCP)
+	if src.Is6() {
+		metadata |= connV6
+	}
+
+	srcLow, srcHigh := util.ToLowHigh(src)
+	dstLow, dstHigh := util.ToLowHigh(dst)
+	return httpConnTuple{
+		Saddr_h:  srcHigh,
+		Saddr_l:  srcLow,
+		Daddr_h:  dstHigh,
+		Daddr_l:  dstLow,
+		Sport:    sport,
+		Dport:    dport,
+		Metadata: metadata,
+	}
+}
+
+func isEphemeralPort(port uint16) bool {
+	return port >= ephemeralRangeBegin && port <= ephemeralRangeEnd
+}
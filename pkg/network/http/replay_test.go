@@ -0,0 +1,138 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package http
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/network/http/testutil"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+)
+
+// replayFixtures are exchanged both over loopback, for the socket filter
+// program to monitor them, and through the Replayer, which emulates the
+// program and must not drift from it
+var replayFixtures = []struct {
+	request  string
+	response string
+}{
+	{
+		request:  "GET /fixture/get HTTP/1.1\r\nHost: localhost\r\n\r\n",
+		response: "HTTP/1.1 200 OK\r\nContent-Length: 0\r\n\r\n",
+	},
+	{
+		request:  "POST /fixture/post HTTP/1.1\r\nHost: localhost\r\nContent-Length: 2\r\n\r\n{}",
+		response: "HTTP/1.1 404 Not Found\r\nContent-Length: 0\r\n\r\n",
+	},
+	{
+		request:  "DELETE /fixture/delete?id=1 HTTP/1.1\r\nHost: localhost\r\n\r\n",
+		response: "HTTP/1.1 503 Service Unavailable\r\nContent-Length: 0\r\n\r\n",
+	},
+	{
+		request:  "OPTIONS * HTTP/1.1\r\nHost: localhost\r\n\r\n",
+		response: "HTTP/1.1 204 No Content\r\n\r\n",
+	},
+	{
+		// methods are case-sensitive
+		request:  "get /fixture/lowercase HTTP/1.1\r\nHost: localhost\r\n\r\n",
+		response: "HTTP/1.1 200 OK\r\nContent-Length: 0\r\n\r\n",
+	},
+	{
+		// methods the program doesn't know about aren't monitored
+		request:  "PROPFIND /fixture/propfind HTTP/1.1\r\nHost: localhost\r\n\r\n",
+		response: "HTTP/1.1 207 Multi-Status\r\nContent-Length: 0\r\n\r\n",
+	},
+}
+
+func TestReplayerMatchesSocketFilter(t *testing.T) {
+	skipTestIfKernelNotSupported(t)
+
+	responses := make(map[string]string, len(replayFixtures))
+	for _, f := range replayFixtures {
+		responses[f.request] = f.response
+	}
+
+	serverAddr := "127.0.0.1:8083"
+	server := testutil.NewTCPServer(serverAddr, func(c net.Conn) {
+		defer c.Close()
+		buf := make([]byte, 1024)
+		n, err := c.Read(buf)
+		if err != nil {
+			return
+		}
+		c.Write([]byte(responses[string(buf[:n])])) //nolint:errcheck
+	})
+	done := make(chan struct{})
+	require.NoError(t, server.Run(done))
+	defer close(done)
+
+	monitor, err := NewMonitor(config.New(), nil, nil, nil, nil)
+	require.NoError(t, err)
+	require.NoError(t, monitor.Start())
+	defer monitor.Stop()
+
+	replayer, err := NewReplayer(config.New())
+	require.NoError(t, err)
+
+	localhost, serverPort := util.AddressFromString("127.0.0.1"), uint16(8083)
+	ts := time.Now()
+	for i, f := range replayFixtures {
+		c, err := net.Dial("tcp", serverAddr)
+		require.NoError(t, err)
+		_, err = c.Write([]byte(f.request))
+		require.NoError(t, err)
+		_, err = io.ReadAll(c)
+		require.NoError(t, err)
+		clientPort := uint16(c.LocalAddr().(*net.TCPAddr).Port)
+		c.Close()
+
+		seq := uint32(i * 3)
+		ts = ts.Add(time.Second)
+		replayer.Process(localhost, localhost, clientPort, serverPort, seq+1, false, []byte(f.request), ts)
+		replayer.Process(localhost, localhost, serverPort, clientPort, seq+2, false, []byte(f.response), ts.Add(time.Millisecond))
+		replayer.Process(localhost, localhost, clientPort, serverPort, seq+3, true, nil, ts.Add(2*time.Millisecond))
+	}
+
+	expected := summarizeTransactions(replayer.GetHTTPStats())
+	require.NotEmpty(t, expected)
+
+	monitored := make(map[string]int)
+	assert.Eventually(t, func() bool {
+		for summary, count := range summarizeTransactions(monitor.GetHTTPStats()) {
+			monitored[summary] += count
+		}
+		return assert.ObjectsAreEqual(expected, monitored)
+	}, 3*time.Second, 100*time.Millisecond)
+	assert.Equal(t, expected, monitored)
+}
+
+// summarizeTransactions counts the transactions of stats by method, path and
+// status class, leaving out the tuples which differ between the monitored
+// and the replayed connections
+func summarizeTransactions(stats map[Key]*RequestStats) map[string]int {
+	summaries := make(map[string]int)
+	for key, stat := range stats {
+		for class := 100; class <= 500; class += 100 {
+			if !stat.HasStats(class) {
+				continue
+			}
+			summary := fmt.Sprintf("%s %s %d", key.Method, key.Path.Content, class)
+			summaries[summary] += stat.Stats(class).Count
+		}
+	}
+	return summaries
+}
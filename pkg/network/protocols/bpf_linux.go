@@ -0,0 +1,132 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package protocols
+
+import (
+	"fmt"
+	"math"
+
+	"golang.org/x/net/bpf"
+)
+
+const (
+	labelIPv4    = "ipv4"
+	labelCapture = "capture"
+	labelDrop    = "drop"
+)
+
+// filterBuilder assembles classic BPF programs, resolving the targets of
+// jumps once all the instructions are known
+type filterBuilder struct {
+	instructions []bpf.Instruction
+	labels       map[string]int
+	jumps        map[int][2]string
+}
+
+func (b *filterBuilder) add(i bpf.Instruction) {
+	b.instructions = append(b.instructions, i)
+}
+
+// jumpIf adds a conditional jump to the given labels, an empty label meaning
+// the next instruction
+func (b *filterBuilder) jumpIf(cond bpf.JumpTest, val uint32, labelTrue, labelFalse string) {
+	b.jumps[len(b.instructions)] = [2]string{labelTrue, labelFalse}
+	b.add(bpf.JumpIf{Cond: cond, Val: val})
+}
+
+func (b *filterBuilder) label(name string) {
+	b.labels[name] = len(b.instructions)
+}
+
+func (b *filterBuilder) skip(from int, label string) (uint8, error) {
+	if label == "" {
+		return 0, nil
+	}
+	to, ok := b.labels[label]
+	if !ok {
+		return 0, fmt.Errorf("unknown label %s", label)
+	}
+	skip := to - from - 1
+	if skip < 0 || skip > math.MaxUint8 {
+		return 0, fmt.Errorf("jump to %s out of range", label)
+	}
+	return uint8(skip), nil
+}
+
+func (b *filterBuilder) assemble() ([]bpf.RawInstruction, error) {
+	for idx, labels := range b.jumps {
+		jump := b.instructions[idx].(bpf.JumpIf)
+		var err error
+		if jump.SkipTrue, err = b.skip(idx, labels[0]); err != nil {
+			return nil, err
+		}
+		if jump.SkipFalse, err = b.skip(idx, labels[1]); err != nil {
+			return nil, err
+		}
+		b.instructions[idx] = jump
+	}
+	return bpf.Assemble(b.instructions)
+}
+
+// matchPorts adds the instructions capturing the packet if the port loaded
+// in the accumulator is one of ports
+func (b *filterBuilder) matchPorts(ports []uint16) {
+	for _, port := range ports {
+		b.jumpIf(bpf.JumpEqual, uint32(port), labelCapture, "")
+	}
+}
+
+// generateBPFFilter returns a classic BPF filter capturing the TCP segments
+// sent from or to one of ports, over IPv4 or IPv6 (without extension headers)
+func generateBPFFilter(ports []uint16) ([]bpf.RawInstruction, error) {
+	b := &filterBuilder{
+		labels: make(map[string]int),
+		jumps:  make(map[int][2]string),
+	}
+
+	// load Ethertype
+	b.add(bpf.LoadAbsolute{Size: 2, Off: 12})
+	b.jumpIf(bpf.JumpEqual, 0x86dd, "", labelIPv4)
+
+	// IPv6: check that the next header is TCP, then load the ports
+	b.add(bpf.LoadAbsolute{Size: 1, Off: 20})
+	b.jumpIf(bpf.JumpEqual, 0x6, "", labelDrop)
+	b.add(bpf.LoadAbsolute{Size: 2, Off: 54})
+	b.matchPorts(ports)
+	b.add(bpf.LoadAbsolute{Size: 2, Off: 56})
+	b.matchPorts(ports)
+	b.add(bpf.Jump{Skip: 0})
+	// the unconditional jump above is resolved below, as its offset can be
+	// bigger than what conditional jumps allow
+	ipv6DropJump := len(b.instructions) - 1
+
+	b.label(labelIPv4)
+	b.jumpIf(bpf.JumpEqual, 0x800, "", labelDrop)
+	// check that the protocol is TCP
+	b.add(bpf.LoadAbsolute{Size: 1, Off: 23})
+	b.jumpIf(bpf.JumpEqual, 0x6, "", labelDrop)
+	// drop fragments, which don't start with a TCP header
+	b.add(bpf.LoadAbsolute{Size: 2, Off: 20})
+	b.jumpIf(bpf.JumpBitsSet, 0x1fff, labelDrop, "")
+	// x = IP header length
+	b.add(bpf.LoadMemShift{Off: 14})
+	b.add(bpf.LoadIndirect{Size: 2, Off: 14})
+	b.matchPorts(ports)
+	b.add(bpf.LoadIndirect{Size: 2, Off: 16})
+	b.matchPorts(ports)
+
+	b.label(labelDrop)
+	b.add(bpf.RetConstant{Val: 0})
+	b.label(labelCapture)
+	b.add(bpf.RetConstant{Val: 262144})
+
+	b.instructions[ipv6DropJump] = bpf.Jump{Skip: uint32(b.labels[labelDrop] - ipv6DropJump - 1)}
+
+	return b.assemble()
+}
@@ -0,0 +1,85 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package protocols
+
+import (
+	"net"
+	"testing"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/bpf"
+)
+
+func TestGenerateBPFFilter(t *testing.T) {
+	raw, err := generateBPFFilter([]uint16{5432, 6379})
+	require.NoError(t, err)
+
+	instructions, allDecoded := bpf.Disassemble(raw)
+	require.True(t, allDecoded)
+	vm, err := bpf.NewVM(instructions)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name     string
+		ipv6     bool
+		udp      bool
+		src, dst uint16
+		captured bool
+	}{
+		{name: "ipv4 request", src: 40000, dst: 6379, captured: true},
+		{name: "ipv4 response", src: 5432, dst: 40000, captured: true},
+		{name: "ipv4 other port", src: 40000, dst: 9092},
+		{name: "ipv4 udp", udp: true, src: 40000, dst: 6379},
+		{name: "ipv6 request", ipv6: true, src: 40000, dst: 5432, captured: true},
+		{name: "ipv6 response", ipv6: true, src: 6379, dst: 40000, captured: true},
+		{name: "ipv6 other port", ipv6: true, src: 40000, dst: 80},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			n, err := vm.Run(buildPacket(t, tc.ipv6, tc.udp, tc.src, tc.dst))
+			require.NoError(t, err)
+			assert.Equal(t, tc.captured, n > 0)
+		})
+	}
+}
+
+func buildPacket(t *testing.T, ipv6, udp bool, src, dst uint16) []byte {
+	eth := &layers.Ethernet{
+		SrcMAC:       net.HardwareAddr{0, 0, 0, 0, 0, 1},
+		DstMAC:       net.HardwareAddr{0, 0, 0, 0, 0, 2},
+		EthernetType: layers.EthernetTypeIPv4,
+	}
+	protocol := layers.IPProtocolTCP
+	if udp {
+		protocol = layers.IPProtocolUDP
+	}
+
+	var network gopacket.NetworkLayer
+	if ipv6 {
+		eth.EthernetType = layers.EthernetTypeIPv6
+		network = &layers.IPv6{Version: 6, NextHeader: protocol, HopLimit: 64, SrcIP: net.ParseIP("::1"), DstIP: net.ParseIP("::2")}
+	} else {
+		network = &layers.IPv4{Version: 4, IHL: 5, TTL: 64, Protocol: protocol, SrcIP: net.IPv4(10, 0, 0, 1), DstIP: net.IPv4(10, 0, 0, 2)}
+	}
+
+	var transport gopacket.SerializableLayer
+	if udp {
+		transport = &layers.UDP{SrcPort: layers.UDPPort(src), DstPort: layers.UDPPort(dst)}
+	} else {
+		transport = &layers.TCP{SrcPort: layers.TCPPort(src), DstPort: layers.TCPPort(dst), PSH: true, ACK: true}
+	}
+
+	buf := gopacket.NewSerializeBuffer()
+	err := gopacket.SerializeLayers(buf, gopacket.SerializeOptions{FixLengths: true},
+		eth, network.(gopacket.SerializableLayer), transport, gopacket.Payload("payload"))
+	require.NoError(t, err)
+	return buf.Bytes()
+}
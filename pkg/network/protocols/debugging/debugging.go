@@ -0,0 +1,90 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package debugging provides a debug-friendly representation of the stats of
+// the protocols monitored beside HTTP.
+package debugging
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/network/dns"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+)
+
+// TransactionSummary represents a (debug-friendly) aggregated view of the
+// transactions matching a (client, server, protocol, operation, resource)
+// tuple
+type TransactionSummary struct {
+	Client             Address
+	Server             Address
+	DNS                string
+	Protocol           string
+	Operation          string
+	Resource           string
+	Count              int
+	ErrorsByCode       map[string]int
+	FirstLatencySample float64
+	LatencyP50         float64
+	LatencyP99         float64
+}
+
+// Address represents represents a IP:Port
+type Address struct {
+	IP   string
+	Port uint16
+}
+
+// Protocols returns a debug-friendly representation of map[protocols.Key]*protocols.RequestStats
+func Protocols(stats map[protocols.Key]*protocols.RequestStats, dnsData map[util.Address][]dns.Hostname) []TransactionSummary {
+	all := make([]TransactionSummary, 0, len(stats))
+	for k, v := range stats {
+		clientAddr := formatIP(k.SrcIPLow, k.SrcIPHigh)
+		serverAddr := formatIP(k.DstIPLow, k.DstIPHigh)
+
+		debug := TransactionSummary{
+			Client: Address{
+				IP:   clientAddr.String(),
+				Port: k.SrcPort,
+			},
+			Server: Address{
+				IP:   serverAddr.String(),
+				Port: k.DstPort,
+			},
+			DNS:                getDNS(dnsData, serverAddr),
+			Protocol:           k.Protocol.String(),
+			Operation:          k.Operation,
+			Resource:           k.Resource,
+			Count:              v.Count,
+			ErrorsByCode:       v.ErrorsByCode,
+			FirstLatencySample: v.FirstLatencySample,
+		}
+		if v.Latencies != nil {
+			debug.LatencyP50, _ = v.Latencies.GetValueAtQuantile(0.5)
+			debug.LatencyP99, _ = v.Latencies.GetValueAtQuantile(0.99)
+		}
+
+		all = append(all, debug)
+	}
+
+	return all
+}
+
+func formatIP(low, high uint64) util.Address {
+	// as with HTTP, the address family is unknown, and addresses are assumed
+	// to be IPv6 only if higher order bits are set
+	if high > 0 || (low>>32) > 0 {
+		return util.V6Address(low, high)
+	}
+
+	return util.V4Address(uint32(low))
+}
+
+func getDNS(dnsData map[util.Address][]dns.Hostname, addr util.Address) string {
+	if names := dnsData[addr]; len(names) > 0 {
+		return dns.ToString(names[0])
+	}
+
+	return ""
+}
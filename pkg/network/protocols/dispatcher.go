@@ -0,0 +1,229 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package protocols
+
+import (
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"go.uber.org/atomic"
+
+	"github.com/DataDog/datadog-agent/pkg/network/http"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+)
+
+// segmentTracker keeps the next sequence number expected from one side of a
+// connection
+type segmentTracker struct {
+	next     uint32
+	lastSeen time.Time
+}
+
+// SegmentHandler handles the TCP payloads of the connections of a protocol
+type SegmentHandler interface {
+	// HandleSegment processes the payload of a TCP segment of the connection
+	// identified by tuple, which is always (client, server)
+	HandleSegment(tuple http.KeyTuple, fromClient bool, payload []byte, ts time.Time)
+
+	// Expire forgets about the connections without activity since the given
+	// time, and returns how many were removed
+	Expire(before time.Time) int
+}
+
+// parserHandler is a SegmentHandler adding the transactions returned by a
+// Parser to a StatKeeper
+type parserHandler struct {
+	Parser
+	statKeeper *StatKeeper
+}
+
+func (h *parserHandler) HandleSegment(tuple http.KeyTuple, fromClient bool, payload []byte, ts time.Time) {
+	h.statKeeper.Process(h.Parse(tuple, fromClient, payload, ts))
+}
+
+// Dispatcher decodes raw packets, and hands their TCP payloads to the handler
+// of the protocol served on their destination port (requests) or source port
+// (responses). A Dispatcher is not thread-safe.
+type Dispatcher struct {
+	decoder *gopacket.DecodingLayerParser
+	decoded []gopacket.LayerType
+	ipv4    *layers.IPv4
+	ipv6    *layers.IPv6
+	tcp     *layers.TCP
+
+	handlers   map[uint16]SegmentHandler
+	statKeeper *StatKeeper
+
+	// segments are tracked by (sender, receiver) tuple, in order to skip
+	// retransmissions, as well as loopback traffic which is captured twice
+	segments map[http.KeyTuple]*segmentTracker
+
+	// telemetry
+	decodingErrors *atomic.Int64
+	duplicates     *atomic.Int64
+}
+
+// NewDispatcher returns a Dispatcher for packets starting with a layer of the
+// given type, and handing the payloads sent to or from each port of parsers
+// to the associated Parser. The transactions returned by the parsers are
+// added to statKeeper.
+func NewDispatcher(layerType gopacket.LayerType, parsers map[uint16]Parser, statKeeper *StatKeeper) *Dispatcher {
+	d := NewSegmentDispatcher(layerType, ParserHandlers(parsers, statKeeper))
+	d.statKeeper = statKeeper
+	return d
+}
+
+// ParserHandlers returns the SegmentHandlers adding the transactions returned
+// by each Parser of parsers to statKeeper
+func ParserHandlers(parsers map[uint16]Parser, statKeeper *StatKeeper) map[uint16]SegmentHandler {
+	// parsers used for several ports share their handler, so that they are
+	// only expired once
+	byParser := make(map[Parser]SegmentHandler, len(parsers))
+	handlers := make(map[uint16]SegmentHandler, len(parsers))
+	for port, parser := range parsers {
+		handler, ok := byParser[parser]
+		if !ok {
+			handler = &parserHandler{Parser: parser, statKeeper: statKeeper}
+			byParser[parser] = handler
+		}
+		handlers[port] = handler
+	}
+	return handlers
+}
+
+// NewSegmentDispatcher returns a Dispatcher for packets starting with a layer
+// of the given type, and handing the payloads sent to or from each port of
+// handlers to the associated SegmentHandler
+func NewSegmentDispatcher(layerType gopacket.LayerType, handlers map[uint16]SegmentHandler) *Dispatcher {
+	d := &Dispatcher{
+		ipv4:           &layers.IPv4{},
+		ipv6:           &layers.IPv6{},
+		tcp:            &layers.TCP{},
+		handlers:       handlers,
+		segments:       make(map[http.KeyTuple]*segmentTracker),
+		decodingErrors: atomic.NewInt64(0),
+		duplicates:     atomic.NewInt64(0),
+	}
+
+	d.decoder = gopacket.NewDecodingLayerParser(layerType,
+		&layers.Ethernet{},
+		&layers.Loopback{},
+		d.ipv4,
+		d.ipv6,
+		d.tcp,
+	)
+	d.decoder.IgnoreUnsupported = true
+
+	return d
+}
+
+// Dispatch processes a raw packet captured at ts
+func (d *Dispatcher) Dispatch(data []byte, ts time.Time) {
+	if err := d.decoder.DecodeLayers(data, &d.decoded); err != nil {
+		d.decodingErrors.Inc()
+		return
+	}
+
+	var src, dst util.Address
+	isIP, isTCP := false, false
+	for _, layer := range d.decoded {
+		switch layer {
+		case layers.LayerTypeIPv4:
+			src = util.AddressFromNetIP(d.ipv4.SrcIP)
+			dst = util.AddressFromNetIP(d.ipv4.DstIP)
+			isIP = true
+		case layers.LayerTypeIPv6:
+			src = util.AddressFromNetIP(d.ipv6.SrcIP)
+			dst = util.AddressFromNetIP(d.ipv6.DstIP)
+			isIP = true
+		case layers.LayerTypeTCP:
+			isTCP = true
+		}
+	}
+
+	payload := d.tcp.Payload
+	if !isIP || !isTCP || len(payload) == 0 {
+		return
+	}
+
+	sport, dport := uint16(d.tcp.SrcPort), uint16(d.tcp.DstPort)
+	var (
+		handler    SegmentHandler
+		tuple      http.KeyTuple
+		fromClient bool
+	)
+	if h, ok := d.handlers[dport]; ok {
+		handler, fromClient = h, true
+		tuple = http.NewKeyTuple(src, dst, sport, dport)
+	} else if h, ok := d.handlers[sport]; ok {
+		handler, fromClient = h, false
+		tuple = http.NewKeyTuple(dst, src, dport, sport)
+	} else {
+		return
+	}
+
+	if d.isDuplicate(http.NewKeyTuple(src, dst, sport, dport), d.tcp.Seq, len(payload), ts) {
+		d.duplicates.Inc()
+		return
+	}
+
+	handler.HandleSegment(tuple, fromClient, payload, ts)
+}
+
+func (d *Dispatcher) isDuplicate(directional http.KeyTuple, seq uint32, length int, ts time.Time) bool {
+	end := seq + uint32(length)
+
+	tracker, ok := d.segments[directional]
+	if !ok {
+		d.segments[directional] = &segmentTracker{next: end, lastSeen: ts}
+		return false
+	}
+
+	tracker.lastSeen = ts
+
+	// sequence numbers wrap around, so they are compared through the sign
+	// of their difference
+	if int32(end-tracker.next) <= 0 {
+		return true
+	}
+
+	tracker.next = end
+	return false
+}
+
+// Expire forgets about the connections without activity since the given
+// time, and returns how many were removed from the handlers
+func (d *Dispatcher) Expire(before time.Time) int {
+	for key, tracker := range d.segments {
+		if tracker.lastSeen.Before(before) {
+			delete(d.segments, key)
+		}
+	}
+
+	expired := 0
+	seen := make(map[SegmentHandler]struct{}, len(d.handlers))
+	for _, handler := range d.handlers {
+		if _, ok := seen[handler]; ok {
+			continue
+		}
+		seen[handler] = struct{}{}
+		expired += handler.Expire(before)
+	}
+
+	return expired
+}
+
+// GetStats returns telemetry about the Dispatcher
+func (d *Dispatcher) GetStats() map[string]int64 {
+	stats := map[string]int64{}
+	if d.statKeeper != nil {
+		stats = d.statKeeper.GetStats()
+	}
+	stats["decoding_errors"] = d.decodingErrors.Load()
+	stats["duplicate_segments"] = d.duplicates.Load()
+	return stats
+}
@@ -0,0 +1,311 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package kafka implements a parser of the Kafka protocol, keeping track of
+// produce and fetch requests by topic.
+package kafka
+
+import (
+	"encoding/binary"
+	"strconv"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/network/http"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols"
+)
+
+const (
+	// apiKeyProduce and apiKeyFetch are the only API keys for which
+	// transactions are reported, see https://kafka.apache.org/protocol#protocol_api_keys
+	apiKeyProduce int16 = 0
+	apiKeyFetch   int16 = 1
+
+	// first versions using the flexible encoding (compact types and tagged
+	// fields) for each API
+	produceFlexibleVersion = 9
+	fetchFlexibleVersion   = 12
+	// first version of fetch identifying topics by UUID instead of name
+	fetchTopicIDVersion = 13
+
+	// messages bigger than this are considered as a loss of
+	// synchronization with the message boundaries of a connection
+	maxMessageSize = 100 * 1024 * 1024
+
+	// maxInFlight is the maximum number of requests waiting for a response
+	// on a single connection
+	maxInFlight = 1024
+)
+
+// request is a produce or fetch request waiting for its response
+type request struct {
+	apiKey  int16
+	version int16
+	topic   string
+	ts      time.Time
+}
+
+// connection holds the state of the parser for a Kafka connection
+type connection struct {
+	inFlight map[int32]request
+
+	// number of bytes of the messages being received which are still
+	// expected in later segments, from the client and from the server
+	clientRemaining int
+	serverRemaining int
+
+	lastSeen time.Time
+}
+
+type parser struct {
+	connections map[http.KeyTuple]*connection
+}
+
+var _ protocols.Parser = &parser{}
+
+// NewParser returns a parser of Kafka connections. Responses are matched
+// with requests through their correlation ID.
+func NewParser() protocols.Parser {
+	return &parser{
+		connections: make(map[http.KeyTuple]*connection),
+	}
+}
+
+func (p *parser) Protocol() protocols.ProtocolType {
+	return protocols.ProtocolKafka
+}
+
+func (p *parser) Parse(tuple http.KeyTuple, fromClient bool, payload []byte, ts time.Time) []protocols.Transaction {
+	conn, ok := p.connections[tuple]
+	if !ok {
+		conn = &connection{
+			inFlight: make(map[int32]request),
+		}
+		p.connections[tuple] = conn
+	}
+	conn.lastSeen = ts
+
+	remaining := &conn.serverRemaining
+	if fromClient {
+		remaining = &conn.clientRemaining
+	}
+
+	// skip the end of a message started in a previous segment
+	if *remaining >= len(payload) {
+		*remaining -= len(payload)
+		return nil
+	}
+	payload = payload[*remaining:]
+	*remaining = 0
+
+	var transactions []protocols.Transaction
+	for len(payload) >= 4 {
+		size := int(int32(binary.BigEndian.Uint32(payload)))
+		if size <= 0 || size > maxMessageSize {
+			// not the start of a message, wait for the next one
+			return transactions
+		}
+
+		message := payload[4:]
+		if len(message) > size {
+			message = message[:size]
+		}
+
+		if fromClient {
+			p.parseRequest(conn, message, ts)
+		} else if tx, ok := p.parseResponse(conn, message, ts); ok {
+			tx.KeyTuple = tuple
+			transactions = append(transactions, tx)
+		}
+
+		if 4+size > len(payload) {
+			*remaining = 4 + size - len(payload)
+			break
+		}
+		payload = payload[4+size:]
+	}
+
+	return transactions
+}
+
+func (p *parser) parseRequest(conn *connection, message []byte, ts time.Time) {
+	r := &reader{data: message}
+	apiKey := r.int16()
+	version := r.int16()
+	correlationID := r.int32()
+	if r.err != nil || (apiKey != apiKeyProduce && apiKey != apiKeyFetch) {
+		return
+	}
+	if len(conn.inFlight) >= maxInFlight {
+		return
+	}
+
+	flexible := isFlexible(apiKey, version)
+
+	r.string(false) // client_id is never compact
+	if flexible {
+		r.taggedFields()
+	}
+
+	var topic string
+	switch apiKey {
+	case apiKeyProduce:
+		if version >= 3 {
+			r.string(flexible) // transactional_id
+		}
+		r.int16() // acks
+		r.int32() // timeout_ms
+		if r.arrayLength(flexible) > 0 {
+			topic = r.string(flexible)
+		}
+	case apiKeyFetch:
+		r.int32() // replica_id
+		r.int32() // max_wait_ms
+		r.int32() // min_bytes
+		if version >= 3 {
+			r.int32() // max_bytes
+		}
+		if version >= 4 {
+			r.int8() // isolation_level
+		}
+		if version >= 7 {
+			r.int32() // session_id
+			r.int32() // session_epoch
+		}
+		if version < fetchTopicIDVersion && r.arrayLength(flexible) > 0 {
+			topic = r.string(flexible)
+		}
+	}
+
+	// the topic is not known when it is not in the first segment of the
+	// request, and the request is still tracked
+	conn.inFlight[correlationID] = request{
+		apiKey:  apiKey,
+		version: version,
+		topic:   topic,
+		ts:      ts,
+	}
+}
+
+func (p *parser) parseResponse(conn *connection, message []byte, ts time.Time) (protocols.Transaction, bool) {
+	r := &reader{data: message}
+	correlationID := r.int32()
+	if r.err != nil {
+		return protocols.Transaction{}, false
+	}
+
+	req, ok := conn.inFlight[correlationID]
+	if !ok {
+		return protocols.Transaction{}, false
+	}
+	delete(conn.inFlight, correlationID)
+
+	latency := ts.Sub(req.ts)
+	if latency < 0 {
+		return protocols.Transaction{}, false
+	}
+
+	flexible := isFlexible(req.apiKey, req.version)
+	if flexible {
+		r.taggedFields()
+	}
+
+	var errorCode int16
+	switch req.apiKey {
+	case apiKeyProduce:
+		errorCode = produceResponseError(r, flexible)
+	case apiKeyFetch:
+		errorCode = fetchResponseError(r, req.version, flexible)
+	}
+
+	operation := "produce"
+	if req.apiKey == apiKeyFetch {
+		operation = "fetch"
+	}
+
+	tx := protocols.Transaction{
+		Key: protocols.Key{
+			Protocol:  protocols.ProtocolKafka,
+			Operation: operation,
+			Resource:  req.topic,
+		},
+		Latency: float64(latency.Nanoseconds()),
+	}
+	if errorCode != 0 {
+		tx.ErrorCode = strconv.Itoa(int(errorCode))
+	}
+
+	return tx, true
+}
+
+// produceResponseError returns the error code of the first partition of the
+// first topic of a produce response
+func produceResponseError(r *reader, flexible bool) int16 {
+	if r.arrayLength(flexible) <= 0 {
+		return 0
+	}
+	r.string(flexible) // name
+	if r.arrayLength(flexible) <= 0 {
+		return 0
+	}
+	r.int32() // index
+	errorCode := r.int16()
+	if r.err != nil {
+		return 0
+	}
+	return errorCode
+}
+
+// fetchResponseError returns the top-level error code of a fetch response,
+// or the error code of its first partition
+func fetchResponseError(r *reader, version int16, flexible bool) int16 {
+	if version >= 1 {
+		r.int32() // throttle_time_ms
+	}
+	if version >= 7 {
+		errorCode := r.int16()
+		if r.err == nil && errorCode != 0 {
+			return errorCode
+		}
+		r.int32() // session_id
+	}
+	if r.arrayLength(flexible) <= 0 {
+		return 0
+	}
+	if version >= fetchTopicIDVersion {
+		r.skip(16) // topic_id
+	} else {
+		r.string(flexible) // topic
+	}
+	if r.arrayLength(flexible) <= 0 {
+		return 0
+	}
+	r.int32() // partition_index
+	errorCode := r.int16()
+	if r.err != nil {
+		return 0
+	}
+	return errorCode
+}
+
+func isFlexible(apiKey, version int16) bool {
+	switch apiKey {
+	case apiKeyProduce:
+		return version >= produceFlexibleVersion
+	case apiKeyFetch:
+		return version >= fetchFlexibleVersion
+	}
+	return false
+}
+
+func (p *parser) Expire(before time.Time) int {
+	expired := 0
+	for tuple, conn := range p.connections {
+		if conn.lastSeen.Before(before) {
+			delete(p.connections, tuple)
+			expired++
+		}
+	}
+	return expired
+}
@@ -0,0 +1,61 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package kafka
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/network/protocols"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols/testutil"
+)
+
+func TestParsePcap(t *testing.T) {
+	stats := testutil.ReplayPcap(t, "testdata/kafka.pcap", 9092, NewParser())
+	require.Len(t, stats, 3)
+
+	for key := range stats {
+		assert.Equal(t, protocols.ProtocolKafka, key.Protocol)
+		assert.Equal(t, uint16(40000), key.SrcPort)
+		assert.Equal(t, uint16(9092), key.DstPort)
+	}
+
+	byOperation := testutil.StatsByOperation(stats)
+
+	produce := byOperation[[2]string{"produce", "orders"}]
+	require.NotNil(t, produce)
+	assert.Equal(t, 1, produce.Count)
+	assert.Equal(t, float64(2*time.Millisecond), produce.FirstLatencySample)
+	assert.Empty(t, produce.ErrorsByCode)
+
+	// the response is split across two segments, and the second one must
+	// not be mistaken for a new message
+	fetch := byOperation[[2]string{"fetch", "payments"}]
+	require.NotNil(t, fetch)
+	assert.Equal(t, 1, fetch.Count)
+	assert.Equal(t, float64(5*time.Millisecond), fetch.FirstLatencySample)
+	assert.Equal(t, map[string]int{"3": 1}, fetch.ErrorsByCode)
+
+	// flexible version
+	flexible := byOperation[[2]string{"produce", "events"}]
+	require.NotNil(t, flexible)
+	assert.Equal(t, 1, flexible.Count)
+	assert.Equal(t, float64(time.Millisecond), flexible.FirstLatencySample)
+}
+
+func TestExpire(t *testing.T) {
+	p := NewParser()
+	now := time.Now()
+
+	request := []byte{0, 0, 0, 8, 0, 0, 0, 7, 0, 0, 0, 1}
+	p.Parse(protocols.Key{}.KeyTuple, true, request, now)
+
+	assert.Equal(t, 0, p.Expire(now.Add(-time.Second)))
+	assert.Equal(t, 1, p.Expire(now.Add(time.Second)))
+}
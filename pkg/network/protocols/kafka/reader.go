@@ -0,0 +1,118 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package kafka
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+var errTruncated = errors.New("truncated kafka message")
+
+// reader decodes the primitive types of the Kafka protocol, see
+// https://kafka.apache.org/protocol#protocol_types. Once a read fails, all
+// the following reads fail too.
+type reader struct {
+	data []byte
+	err  error
+}
+
+func (r *reader) skip(n int) {
+	if r.err != nil {
+		return
+	}
+	if n < 0 || len(r.data) < n {
+		r.err = errTruncated
+		return
+	}
+	r.data = r.data[n:]
+}
+
+func (r *reader) int8() int8 {
+	if r.err != nil || len(r.data) < 1 {
+		r.err = errTruncated
+		return 0
+	}
+	v := int8(r.data[0])
+	r.data = r.data[1:]
+	return v
+}
+
+func (r *reader) int16() int16 {
+	if r.err != nil || len(r.data) < 2 {
+		r.err = errTruncated
+		return 0
+	}
+	v := int16(binary.BigEndian.Uint16(r.data))
+	r.data = r.data[2:]
+	return v
+}
+
+func (r *reader) int32() int32 {
+	if r.err != nil || len(r.data) < 4 {
+		r.err = errTruncated
+		return 0
+	}
+	v := int32(binary.BigEndian.Uint32(r.data))
+	r.data = r.data[4:]
+	return v
+}
+
+func (r *reader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(r.data)
+	if n <= 0 {
+		r.err = errTruncated
+		return 0
+	}
+	r.data = r.data[n:]
+	return v
+}
+
+func (r *reader) bytes(n int) []byte {
+	if r.err != nil || n < 0 || len(r.data) < n {
+		r.err = errTruncated
+		return nil
+	}
+	v := r.data[:n]
+	r.data = r.data[n:]
+	return v
+}
+
+// string reads a STRING or NULLABLE_STRING, or their COMPACT variants
+func (r *reader) string(compact bool) string {
+	var n int
+	if compact {
+		// compact strings are prefixed by their length plus one, zero
+		// being null
+		n = int(r.uvarint()) - 1
+	} else {
+		n = int(r.int16())
+	}
+	if n <= 0 {
+		return ""
+	}
+	return string(r.bytes(n))
+}
+
+// arrayLength reads the length of an ARRAY or COMPACT_ARRAY
+func (r *reader) arrayLength(compact bool) int {
+	if compact {
+		return int(r.uvarint()) - 1
+	}
+	return int(r.int32())
+}
+
+// taggedFields skips the tagged fields of flexible versions
+func (r *reader) taggedFields() {
+	count := r.uvarint()
+	for i := uint64(0); i < count && r.err == nil; i++ {
+		r.uvarint() // tag
+		r.skip(int(r.uvarint()))
+	}
+}
@@ -0,0 +1,194 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package protocols
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/vishvananda/netns"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	filterpkg "github.com/DataDog/datadog-agent/pkg/network/filter"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// connections without traffic for this long are forgotten by the parsers
+	connectionIdleTTL = 2 * time.Minute
+	expirationPeriod  = 30 * time.Second
+)
+
+// Monitor captures the traffic of the monitored protocols with a raw socket,
+// and keeps stats about their transactions. Unlike HTTP, which is parsed in
+// eBPF, payloads are parsed in userspace: the kernel only filters segments by
+// port.
+type Monitor struct {
+	source     *filterpkg.AFPacketSource
+	dispatcher *Dispatcher
+	statKeeper *StatKeeper
+
+	lastExpiration time.Time
+
+	exit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewMonitor returns a Monitor handing the traffic sent to or from each port
+// of parsers to the associated Parser, and the traffic of each port of
+// handlers to the associated SegmentHandler. It returns nil if there is
+// nothing to monitor.
+func NewMonitor(cfg *config.Config, parsers map[uint16]Parser, handlers map[uint16]SegmentHandler) (*Monitor, error) {
+	if len(parsers) == 0 && len(handlers) == 0 {
+		return nil, nil
+	}
+
+	statKeeper := NewStatKeeper(cfg.MaxProtocolStatsBuffered)
+	allHandlers := ParserHandlers(parsers, statKeeper)
+	for port, handler := range handlers {
+		if _, ok := allHandlers[port]; ok {
+			return nil, fmt.Errorf("port %d is used by several protocols", port)
+		}
+		allHandlers[port] = handler
+	}
+
+	ports := make([]uint16, 0, len(allHandlers))
+	for port := range allHandlers {
+		ports = append(ports, port)
+	}
+	bpfFilter, err := generateBPFFilter(ports)
+	if err != nil {
+		return nil, fmt.Errorf("error creating bpf classic filter: %w", err)
+	}
+
+	// Create the RAW_SOCKET inside the root network namespace
+	var (
+		source *filterpkg.AFPacketSource
+		srcErr error
+		ns     netns.NsHandle
+	)
+	if ns, err = cfg.GetRootNetNs(); err != nil {
+		return nil, err
+	}
+	defer ns.Close()
+
+	err = util.WithNS(cfg.ProcRoot, ns, func() error {
+		source, srcErr = filterpkg.NewPacketSource(nil, bpfFilter)
+		return srcErr
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dispatcher := NewSegmentDispatcher(source.PacketType(), allHandlers)
+	dispatcher.statKeeper = statKeeper
+	return &Monitor{
+		source:         source,
+		dispatcher:     dispatcher,
+		statKeeper:     statKeeper,
+		lastExpiration: time.Now(),
+		exit:           make(chan struct{}),
+	}, nil
+}
+
+// Start starts consuming packets
+func (m *Monitor) Start() {
+	if m == nil {
+		return
+	}
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		m.pollPackets()
+	}()
+}
+
+func (m *Monitor) pollPackets() {
+	for {
+		err := m.source.VisitPackets(m.exit, m.processPacket)
+		if err != nil {
+			log.Warnf("error reading packet: %s", err)
+		}
+
+		select {
+		case <-m.exit:
+			return
+		default:
+		}
+
+		// VisitPackets also returns when no packet was received for a while,
+		// which must not prevent the expiration of idle connections
+		m.expire(time.Now())
+
+		if err != nil {
+			// Sleep briefly and try again
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+}
+
+// processPacket hands a packet to the dispatcher, which is only used by the
+// goroutine reading packets
+func (m *Monitor) processPacket(data []byte, ts time.Time) error {
+	m.dispatcher.Dispatch(data, ts)
+	m.expire(ts)
+	return nil
+}
+
+func (m *Monitor) expire(now time.Time) {
+	if now.Sub(m.lastExpiration) < expirationPeriod {
+		return
+	}
+	if expired := m.dispatcher.Expire(now.Add(-connectionIdleTTL)); expired > 0 {
+		log.Debugf("expired %d idle protocol connections", expired)
+	}
+	m.lastExpiration = now
+}
+
+// GetProtocolStats returns the stats aggregated since the last call
+func (m *Monitor) GetProtocolStats() map[Key]*RequestStats {
+	if m == nil {
+		return nil
+	}
+	return m.statKeeper.GetAndResetAllStats()
+}
+
+// GetStats returns telemetry about the Monitor
+func (m *Monitor) GetStats() map[string]interface{} {
+	if m == nil {
+		return map[string]interface{}{
+			"enabled": false,
+		}
+	}
+
+	stats := map[string]interface{}{
+		"enabled": true,
+	}
+	for key, value := range m.source.Stats() {
+		stats[key] = value
+	}
+	for key, value := range m.dispatcher.GetStats() {
+		stats[key] = value
+	}
+	return stats
+}
+
+// Stop stops the Monitor
+func (m *Monitor) Stop() {
+	if m == nil {
+		return
+	}
+
+	close(m.exit)
+	m.wg.Wait()
+	m.source.Close()
+}
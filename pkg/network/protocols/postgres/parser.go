@@ -0,0 +1,340 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package postgres implements a parser of the PostgreSQL frontend/backend
+// protocol, keeping track of the latency of queries by query type and table.
+package postgres
+
+import (
+	"bytes"
+	"encoding/binary"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/network/http"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols"
+)
+
+// Message types, see https://www.postgresql.org/docs/current/protocol-message-formats.html
+const (
+	// sent by the client
+	msgQuery = 'Q'
+	msgParse = 'P'
+	msgBind  = 'B'
+	msgSync  = 'S'
+
+	// sent by the server
+	msgErrorResponse  = 'E'
+	msgReadyForQuery  = 'Z'
+	msgSSLAccepted    = 'S'
+	msgSSLNotAccepted = 'N'
+
+	// errorFieldCode is the field of an ErrorResponse holding the SQLSTATE
+	// code of the error
+	errorFieldCode = 'C'
+
+	// unknownErrorCode replaces the invalid SQLSTATE codes
+	unknownErrorCode = "UNKNOWN"
+
+	// request codes of the untyped messages asking for encryption
+	sslRequestCode    = 80877103
+	gssEncRequestCode = 80877104
+
+	// messages bigger than this are considered as a loss of
+	// synchronization with the message boundaries of a connection
+	maxMessageSize = 100 * 1024 * 1024
+
+	// maxInFlight is the maximum number of queries waiting for a response
+	// on a single connection
+	maxInFlight = 1024
+
+	// maxStatements is the maximum number of prepared statements tracked on
+	// a single connection
+	maxStatements = 512
+)
+
+// query is a query waiting for the server to be ready for the next one
+type query struct {
+	operation string
+	table     string
+	errorCode string
+	ts        time.Time
+}
+
+// connection holds the state of the parser for a PostgreSQL connection
+type connection struct {
+	// queries sent, in order
+	inFlight []query
+
+	// extended is the query being sent with the extended protocol, until the
+	// client sends a Sync message
+	extended *query
+
+	// statements are the prepared statements of the connection, by name
+	statements map[string]query
+
+	// the client asked for encryption, and the connection is ignored once
+	// the server accepts it
+	encryptionRequested bool
+	encrypted           bool
+
+	// number of bytes of the messages being received which are still
+	// expected in later segments, from the client and from the server
+	clientRemaining int
+	serverRemaining int
+
+	lastSeen time.Time
+}
+
+type parser struct {
+	connections map[http.KeyTuple]*connection
+}
+
+var _ protocols.Parser = &parser{}
+
+// NewParser returns a parser of PostgreSQL connections. A transaction lasts
+// from a query (or the first message of an extended query) until the server
+// is ready for the next one.
+func NewParser() protocols.Parser {
+	return &parser{
+		connections: make(map[http.KeyTuple]*connection),
+	}
+}
+
+func (p *parser) Protocol() protocols.ProtocolType {
+	return protocols.ProtocolPostgres
+}
+
+func (p *parser) Parse(tuple http.KeyTuple, fromClient bool, payload []byte, ts time.Time) []protocols.Transaction {
+	conn, ok := p.connections[tuple]
+	if !ok {
+		conn = &connection{
+			statements: make(map[string]query),
+		}
+		p.connections[tuple] = conn
+	}
+	conn.lastSeen = ts
+
+	if conn.encrypted {
+		return nil
+	}
+
+	remaining := &conn.serverRemaining
+	if fromClient {
+		remaining = &conn.clientRemaining
+	}
+
+	// skip the end of a message started in a previous segment
+	if *remaining >= len(payload) {
+		*remaining -= len(payload)
+		return nil
+	}
+	payload = payload[*remaining:]
+	*remaining = 0
+
+	// the server answers to encryption requests with a single byte
+	if !fromClient && conn.encryptionRequested {
+		conn.encryptionRequested = false
+		switch payload[0] {
+		case msgSSLAccepted:
+			conn.encrypted = true
+			return nil
+		case msgSSLNotAccepted:
+			payload = payload[1:]
+		}
+	}
+
+	var transactions []protocols.Transaction
+	for len(payload) >= 5 {
+		msgType := payload[0]
+		headerSize := 1
+		if fromClient && msgType == 0 {
+			// the untyped messages sent by clients at the start of a
+			// connection begin with their length, whose first byte is
+			// always zero
+			headerSize = 0
+		}
+
+		size := int(int32(binary.BigEndian.Uint32(payload[headerSize:])))
+		if size < 4 || size > maxMessageSize {
+			// not the start of a message, wait for the next one
+			return transactions
+		}
+
+		body := payload[headerSize+4:]
+		if len(body) > size-4 {
+			body = body[:size-4]
+		}
+
+		switch {
+		case headerSize == 0:
+			p.handleUntyped(conn, body)
+		case fromClient:
+			p.handleClientMessage(conn, msgType, body, ts)
+		default:
+			if tx, ok := p.handleServerMessage(conn, msgType, body, ts); ok {
+				tx.KeyTuple = tuple
+				transactions = append(transactions, tx)
+			}
+		}
+
+		if headerSize+size > len(payload) {
+			*remaining = headerSize + size - len(payload)
+			break
+		}
+		payload = payload[headerSize+size:]
+	}
+
+	return transactions
+}
+
+func (p *parser) handleUntyped(conn *connection, body []byte) {
+	if len(body) < 4 {
+		return
+	}
+
+	switch binary.BigEndian.Uint32(body) {
+	case sslRequestCode, gssEncRequestCode:
+		conn.encryptionRequested = true
+	}
+}
+
+func (p *parser) handleClientMessage(conn *connection, msgType byte, body []byte, ts time.Time) {
+	switch msgType {
+	case msgQuery:
+		operation, table := parseQuery(cString(body))
+		conn.push(query{operation: operation, table: table, ts: ts})
+	case msgParse:
+		name := cString(body)
+		if len(name)+1 > len(body) {
+			return
+		}
+		operation, table := parseQuery(cString(body[len(name)+1:]))
+		q := query{operation: operation, table: table}
+		if _, ok := conn.statements[name]; ok || len(conn.statements) < maxStatements {
+			conn.statements[name] = q
+		}
+		conn.startExtended(q, ts)
+	case msgBind:
+		portal := cString(body)
+		if len(portal)+1 > len(body) {
+			return
+		}
+		q, ok := conn.statements[cString(body[len(portal)+1:])]
+		if !ok {
+			q = query{operation: unknownOperation}
+		}
+		conn.startExtended(q, ts)
+	case msgSync:
+		if conn.extended != nil {
+			conn.push(*conn.extended)
+			conn.extended = nil
+		}
+	}
+}
+
+func (p *parser) handleServerMessage(conn *connection, msgType byte, body []byte, ts time.Time) (protocols.Transaction, bool) {
+	if len(conn.inFlight) == 0 {
+		return protocols.Transaction{}, false
+	}
+
+	switch msgType {
+	case msgErrorResponse:
+		if conn.inFlight[0].errorCode == "" {
+			conn.inFlight[0].errorCode = errorCode(body)
+		}
+	case msgReadyForQuery:
+		q := conn.inFlight[0]
+		conn.inFlight = conn.inFlight[1:]
+
+		latency := ts.Sub(q.ts)
+		if latency < 0 {
+			return protocols.Transaction{}, false
+		}
+
+		return protocols.Transaction{
+			Key: protocols.Key{
+				Protocol:  protocols.ProtocolPostgres,
+				Operation: q.operation,
+				Resource:  q.table,
+			},
+			Latency:   float64(latency.Nanoseconds()),
+			ErrorCode: q.errorCode,
+		}, true
+	}
+
+	return protocols.Transaction{}, false
+}
+
+func (c *connection) push(q query) {
+	if len(c.inFlight) < maxInFlight {
+		c.inFlight = append(c.inFlight, q)
+	}
+}
+
+// startExtended records the query being sent with the extended protocol,
+// the first message sent for it giving its start time
+func (c *connection) startExtended(q query, ts time.Time) {
+	if c.extended == nil {
+		q.ts = ts
+		c.extended = &q
+		return
+	}
+	if c.extended.operation == unknownOperation {
+		c.extended.operation, c.extended.table = q.operation, q.table
+	}
+}
+
+// errorCode returns the SQLSTATE code of an ErrorResponse message, or
+// unknownErrorCode if it is invalid
+func errorCode(body []byte) string {
+	for len(body) > 1 {
+		field := body[0]
+		value := cString(body[1:])
+		if field == errorFieldCode {
+			if !isSQLState(value) {
+				return unknownErrorCode
+			}
+			return value
+		}
+		if 1+len(value)+1 > len(body) {
+			break
+		}
+		body = body[1+len(value)+1:]
+	}
+	return ""
+}
+
+// isSQLState returns true if code is made of five digits or uppercase letters
+func isSQLState(code string) bool {
+	if len(code) != 5 {
+		return false
+	}
+	for i := 0; i < len(code); i++ {
+		if (code[i] < '0' || code[i] > '9') && (code[i] < 'A' || code[i] > 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// cString returns the null-terminated string at the start of data, or all of
+// data if it is truncated
+func cString(data []byte) string {
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		return string(data[:i])
+	}
+	return string(data)
+}
+
+func (p *parser) Expire(before time.Time) int {
+	expired := 0
+	for tuple, conn := range p.connections {
+		if conn.lastSeen.Before(before) {
+			delete(p.connections, tuple)
+			expired++
+		}
+	}
+	return expired
+}
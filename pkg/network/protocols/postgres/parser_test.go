@@ -0,0 +1,64 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package postgres
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/network/protocols"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols/testutil"
+)
+
+func TestParsePcap(t *testing.T) {
+	stats := testutil.ReplayPcap(t, "testdata/postgres.pcap", 5432, NewParser())
+	require.Len(t, stats, 3)
+
+	for key := range stats {
+		assert.Equal(t, protocols.ProtocolPostgres, key.Protocol)
+	}
+
+	byOperation := testutil.StatsByOperation(stats)
+
+	selectStats := byOperation[[2]string{"SELECT", "users"}]
+	require.NotNil(t, selectStats)
+	assert.Equal(t, 1, selectStats.Count)
+	assert.Equal(t, float64(3*time.Millisecond), selectStats.FirstLatencySample)
+
+	insert := byOperation[[2]string{"INSERT", "public.orders"}]
+	require.NotNil(t, insert)
+	assert.Equal(t, map[string]int{"23505": 1}, insert.ErrorsByCode)
+
+	// the prepared statement is executed twice
+	update := byOperation[[2]string{"UPDATE", "accounts"}]
+	require.NotNil(t, update)
+	assert.Equal(t, 2, update.Count)
+	assert.Empty(t, update.ErrorsByCode)
+}
+
+func TestEncryptedConnection(t *testing.T) {
+	p := NewParser()
+	tuple := protocols.Key{}.KeyTuple
+	now := time.Now()
+
+	p.Parse(tuple, true, []byte{0, 0, 0, 8, 0x04, 0xd2, 0x16, 0x2f}, now)
+	p.Parse(tuple, false, []byte{'S'}, now)
+
+	// encrypted traffic looking like a query and its response is ignored
+	p.Parse(tuple, true, []byte{'Q', 0, 0, 0, 5, 0}, now)
+	assert.Empty(t, p.Parse(tuple, false, []byte{'Z', 0, 0, 0, 5, 'I'}, now.Add(time.Millisecond)))
+}
+
+func TestErrorCode(t *testing.T) {
+	assert.Equal(t, "42P01", errorCode([]byte("SERROR\x00C42P01\x00Mrelation does not exist\x00\x00")))
+	assert.Equal(t, unknownErrorCode, errorCode([]byte("SERROR\x00C"+strings.Repeat("A", 100)+"\x00\x00")))
+	assert.Equal(t, unknownErrorCode, errorCode([]byte("C42p01\x00")))
+	assert.Equal(t, "", errorCode([]byte("SERROR\x00\x00")))
+}
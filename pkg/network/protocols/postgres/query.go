@@ -0,0 +1,136 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package postgres
+
+import (
+	"strings"
+)
+
+const (
+	unknownOperation = "UNKNOWN"
+
+	// only the start of queries is looked at to find their operation and
+	// table
+	maxQueryPrefix = 4096
+)
+
+// parseQuery returns the type of a query (its first keyword, such as SELECT
+// or INSERT), and the table it operates on when it can be found. This is not
+// a SQL parser: the table of a SELECT or DELETE is the first one following
+// FROM, which ignores joins and subqueries.
+func parseQuery(q string) (operation, table string) {
+	if len(q) > maxQueryPrefix {
+		q = q[:maxQueryPrefix]
+	}
+
+	tokens := tokenize(q)
+	if len(tokens) == 0 {
+		return unknownOperation, ""
+	}
+
+	operation = strings.ToUpper(tokens[0])
+	if !isKeyword(operation) {
+		return unknownOperation, ""
+	}
+
+	var tableIdx int
+	switch operation {
+	case "SELECT", "DELETE":
+		tableIdx = indexAfter(tokens, "FROM")
+	case "INSERT":
+		tableIdx = indexAfter(tokens, "INTO")
+	case "UPDATE", "COPY":
+		tableIdx = 1
+	case "TRUNCATE":
+		tableIdx = 1
+		if tableIdx < len(tokens) && strings.EqualFold(tokens[tableIdx], "TABLE") {
+			tableIdx++
+		}
+	default:
+		return operation, ""
+	}
+
+	if tableIdx > 0 && tableIdx < len(tokens) && strings.EqualFold(tokens[tableIdx], "ONLY") {
+		tableIdx++
+	}
+	if tableIdx <= 0 || tableIdx >= len(tokens) {
+		return operation, ""
+	}
+
+	return operation, normalizeTable(tokens[tableIdx])
+}
+
+// tokenize splits a query in words, commas, semicolons and parentheses
+// being separators. Comments are skipped.
+func tokenize(q string) []string {
+	var tokens []string
+	for len(q) > 0 {
+		switch {
+		case strings.HasPrefix(q, "--"):
+			end := strings.IndexByte(q, '\n')
+			if end < 0 {
+				return tokens
+			}
+			q = q[end+1:]
+		case strings.HasPrefix(q, "/*"):
+			end := strings.Index(q, "*/")
+			if end < 0 {
+				return tokens
+			}
+			q = q[end+2:]
+		case isSeparator(q[0]):
+			q = q[1:]
+		default:
+			end := 0
+			for end < len(q) && !isSeparator(q[end]) {
+				end++
+			}
+			tokens = append(tokens, q[:end])
+			q = q[end:]
+		}
+	}
+	return tokens
+}
+
+func isSeparator(c byte) bool {
+	switch c {
+	case ' ', '\t', '\n', '\r', ',', ';', '(', ')':
+		return true
+	}
+	return false
+}
+
+func isKeyword(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 'A' || s[i] > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+func indexAfter(tokens []string, keyword string) int {
+	for i, token := range tokens {
+		if strings.EqualFold(token, keyword) {
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// normalizeTable lowercases unquoted identifiers, and removes quotes from
+// quoted ones, as PostgreSQL does
+func normalizeTable(table string) string {
+	parts := strings.Split(table, ".")
+	for i, part := range parts {
+		if len(part) >= 2 && part[0] == '"' && part[len(part)-1] == '"' {
+			parts[i] = part[1 : len(part)-1]
+		} else {
+			parts[i] = strings.ToLower(part)
+		}
+	}
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,38 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package postgres
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseQuery(t *testing.T) {
+	for _, tc := range []struct {
+		query     string
+		operation string
+		table     string
+	}{
+		{query: "SELECT id, name FROM users WHERE id = $1", operation: "SELECT", table: "users"},
+		{query: "select * from Public.Users;", operation: "SELECT", table: "public.users"},
+		{query: `SELECT * FROM "Orders" o JOIN items i ON o.id = i.order_id`, operation: "SELECT", table: "Orders"},
+		{query: "SELECT 1", operation: "SELECT", table: ""},
+		{query: "-- comment\n/* other comment */ INSERT INTO orders(id) VALUES (1)", operation: "INSERT", table: "orders"},
+		{query: "UPDATE ONLY accounts SET balance = 0", operation: "UPDATE", table: "accounts"},
+		{query: "DELETE FROM sessions WHERE expired", operation: "DELETE", table: "sessions"},
+		{query: "TRUNCATE TABLE logs", operation: "TRUNCATE", table: "logs"},
+		{query: "BEGIN", operation: "BEGIN", table: ""},
+		{query: "", operation: unknownOperation, table: ""},
+		{query: "\x01\x02", operation: unknownOperation, table: ""},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			operation, table := parseQuery(tc.query)
+			assert.Equal(t, tc.operation, operation)
+			assert.Equal(t, tc.table, table)
+		})
+	}
+}
@@ -0,0 +1,147 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package redis implements a parser of the Redis serialization protocol
+// (RESP), keeping track of the latency of commands.
+package redis
+
+import (
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/network/http"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols"
+)
+
+const (
+	unknownCommand = "UNKNOWN"
+
+	// longer commands are considered invalid
+	maxCommandLength = 32
+
+	// maxInFlight is the maximum number of commands waiting for a response
+	// on a single connection
+	maxInFlight = 1024
+)
+
+// command is a command waiting for its response
+type command struct {
+	name string
+	ts   time.Time
+}
+
+// connection holds the state of the parser for a Redis connection
+type connection struct {
+	requests  scanner
+	responses scanner
+
+	// commands sent, in order, as Redis answers them in order
+	inFlight []command
+
+	// connections used for pub/sub or MONITOR receive messages which don't
+	// answer commands, and are ignored
+	ignored bool
+
+	lastSeen time.Time
+}
+
+type parser struct {
+	connections map[http.KeyTuple]*connection
+}
+
+var _ protocols.Parser = &parser{}
+
+// NewParser returns a parser of Redis connections. Pipelined commands are
+// supported, but not connections switching to pub/sub.
+func NewParser() protocols.Parser {
+	return &parser{
+		connections: make(map[http.KeyTuple]*connection),
+	}
+}
+
+func (p *parser) Protocol() protocols.ProtocolType {
+	return protocols.ProtocolRedis
+}
+
+func (p *parser) Parse(tuple http.KeyTuple, fromClient bool, payload []byte, ts time.Time) []protocols.Transaction {
+	conn, ok := p.connections[tuple]
+	if !ok {
+		conn = &connection{}
+		p.connections[tuple] = conn
+	}
+	conn.lastSeen = ts
+
+	if conn.ignored {
+		return nil
+	}
+
+	if fromClient {
+		conn.requests.feed(payload, func(v value) {
+			name := normalizeCommand(v.command)
+			switch name {
+			case "SUBSCRIBE", "PSUBSCRIBE", "SSUBSCRIBE", "MONITOR":
+				conn.ignored = true
+				conn.inFlight = nil
+				return
+			}
+			if !conn.ignored && len(conn.inFlight) < maxInFlight {
+				conn.inFlight = append(conn.inFlight, command{name: name, ts: ts})
+			}
+		})
+		return nil
+	}
+
+	var transactions []protocols.Transaction
+	conn.responses.feed(payload, func(v value) {
+		if len(conn.inFlight) == 0 {
+			return
+		}
+		cmd := conn.inFlight[0]
+		conn.inFlight = conn.inFlight[1:]
+
+		latency := ts.Sub(cmd.ts)
+		if latency < 0 {
+			return
+		}
+
+		tx := protocols.Transaction{
+			Key: protocols.Key{
+				KeyTuple:  tuple,
+				Protocol:  protocols.ProtocolRedis,
+				Operation: cmd.name,
+			},
+			Latency: float64(latency.Nanoseconds()),
+		}
+		if v.isError() {
+			tx.ErrorCode = v.errorPrefix
+		}
+		transactions = append(transactions, tx)
+	})
+
+	return transactions
+}
+
+func normalizeCommand(name string) string {
+	if name == "" || len(name) > maxCommandLength {
+		return unknownCommand
+	}
+	for i := 0; i < len(name); i++ {
+		if !isLetter(name[i]) && name[i] != '_' && name[i] != '.' {
+			return unknownCommand
+		}
+	}
+	return strings.ToUpper(name)
+}
+
+func (p *parser) Expire(before time.Time) int {
+	expired := 0
+	for tuple, conn := range p.connections {
+		if conn.lastSeen.Before(before) {
+			delete(p.connections, tuple)
+			expired++
+		}
+	}
+	return expired
+}
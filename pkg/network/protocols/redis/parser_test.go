@@ -0,0 +1,55 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/network/protocols"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols/testutil"
+)
+
+func TestParsePcap(t *testing.T) {
+	stats := testutil.ReplayPcap(t, "testdata/redis.pcap", 6379, NewParser())
+
+	byOperation := testutil.StatsByOperation(stats)
+	require.Len(t, byOperation, 5)
+
+	// the second SET has a value split across two segments
+	set := byOperation[[2]string{"SET", ""}]
+	require.NotNil(t, set)
+	assert.Equal(t, 2, set.Count)
+
+	// pipelined commands, sent in a retransmitted segment
+	get := byOperation[[2]string{"GET", ""}]
+	require.NotNil(t, get)
+	assert.Equal(t, 1, get.Count)
+	assert.Equal(t, float64(2*time.Millisecond), get.FirstLatencySample)
+
+	incr := byOperation[[2]string{"INCR", ""}]
+	require.NotNil(t, incr)
+	assert.Equal(t, map[string]int{"ERR": 1}, incr.ErrorsByCode)
+
+	// inline command
+	assert.NotNil(t, byOperation[[2]string{"PING", ""}])
+
+	// array response
+	assert.NotNil(t, byOperation[[2]string{"LRANGE", ""}])
+}
+
+func TestPubSubIgnored(t *testing.T) {
+	p := NewParser()
+	tuple := protocols.Key{}.KeyTuple
+	now := time.Now()
+
+	p.Parse(tuple, true, []byte("*2\r\n$9\r\nSUBSCRIBE\r\n$4\r\nnews\r\n"), now)
+	assert.Empty(t, p.Parse(tuple, false, []byte("*3\r\n$9\r\nsubscribe\r\n$4\r\nnews\r\n:1\r\n"), now))
+	assert.Empty(t, p.Parse(tuple, false, []byte("*3\r\n$7\r\nmessage\r\n$4\r\nnews\r\n$5\r\nhello\r\n"), now))
+}
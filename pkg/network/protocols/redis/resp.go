@@ -0,0 +1,220 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package redis
+
+import (
+	"bytes"
+	"strconv"
+)
+
+const (
+	// maxBulkLength is the largest bulk string accepted by Redis, see the
+	// proto-max-bulk-len setting
+	maxBulkLength = 512 * 1024 * 1024
+
+	// maxAggregateLength is the largest number of elements of an aggregate
+	// accepted by Redis
+	maxAggregateLength = 1024 * 1024 * 1024
+
+	// maxAggregateDepth is the maximum nesting of aggregates, deeper values
+	// being considered invalid
+	maxAggregateDepth = 32
+
+	// longer error prefixes are considered invalid
+	maxErrorPrefixLength = 32
+
+	unknownErrorPrefix = "UNKNOWN"
+)
+
+// value describes a top-level RESP value, that is a whole request or
+// response, see https://redis.io/docs/reference/protocol-spec/
+type value struct {
+	// kind is the first byte of the value, giving its type
+	kind byte
+
+	// command is the first element of an array, which is the command of a
+	// request
+	command string
+
+	// errorPrefix is the first word of an error, such as ERR or WRONGTYPE, or
+	// unknownErrorPrefix if it isn't an uppercase word
+	errorPrefix string
+
+	// elements is the number of elements of the value, if it is an array
+	elements int
+}
+
+// isError returns true if the value is a simple or a blob error
+func (v value) isError() bool {
+	return v.kind == '-' || v.kind == '!'
+}
+
+// scanner reads RESP values incrementally, as they are received over a
+// connection, without buffering them. Values whose headers are split across
+// segments can't be read, and cause the scanner to skip data until the start
+// of the next segment.
+type scanner struct {
+	current value
+
+	// elements still expected for each aggregate being read, innermost last
+	pending []int
+
+	// bytes of a bulk string, including its CRLF, still expected
+	bulkRemaining int
+}
+
+// feed processes data, calling done for each top-level value read
+func (s *scanner) feed(data []byte, done func(value)) {
+	for len(data) > 0 {
+		if s.bulkRemaining > 0 {
+			if s.bulkRemaining > len(data) {
+				s.bulkRemaining -= len(data)
+				return
+			}
+			data = data[s.bulkRemaining:]
+			s.bulkRemaining = 0
+			s.elementDone(done)
+			continue
+		}
+
+		end := bytes.Index(data, []byte("\r\n"))
+		if end <= 0 {
+			s.reset()
+			return
+		}
+		line, rest := data[:end], data[end+2:]
+		data = rest
+
+		topLevel := len(s.pending) == 0
+		if topLevel {
+			s.current = value{kind: line[0]}
+		}
+
+		switch line[0] {
+		case '+', ':', ',', '#', '_', '(':
+			s.elementDone(done)
+		case '-':
+			if topLevel {
+				s.current.errorPrefix = errorPrefix(line[1:])
+			}
+			s.elementDone(done)
+		case '$', '!', '=':
+			n, err := strconv.Atoi(string(line[1:]))
+			if err != nil {
+				s.reset()
+				return
+			}
+			if n < -1 || n > maxBulkLength {
+				s.reset()
+				return
+			}
+			if n == -1 {
+				// null bulk string
+				s.elementDone(done)
+				continue
+			}
+			if n+2 > len(data) {
+				s.bulkRemaining = n + 2 - len(data)
+				return
+			}
+			s.bulkString(data[:n], topLevel)
+			data = data[n+2:]
+			s.elementDone(done)
+		case '*', '~', '>', '%', '|':
+			n, err := strconv.Atoi(string(line[1:]))
+			if err != nil {
+				s.reset()
+				return
+			}
+			if n < -1 || n > maxAggregateLength {
+				s.reset()
+				return
+			}
+			if line[0] == '%' || line[0] == '|' {
+				n *= 2
+			}
+			if topLevel {
+				s.current.elements = n
+			}
+			if n <= 0 {
+				s.elementDone(done)
+				continue
+			}
+			if len(s.pending) == maxAggregateDepth {
+				s.reset()
+				return
+			}
+			s.pending = append(s.pending, n)
+		default:
+			if !topLevel || !isLetter(line[0]) {
+				s.reset()
+				return
+			}
+			// inline commands are sent as a line of space-separated
+			// arguments
+			s.current.command = firstWord(line)
+			s.elementDone(done)
+		}
+	}
+}
+
+// bulkString records the content of bulk strings describing the current value
+func (s *scanner) bulkString(content []byte, topLevel bool) {
+	switch {
+	case topLevel && s.current.kind == '!':
+		s.current.errorPrefix = errorPrefix(content)
+	case len(s.pending) == 1 && s.pending[0] == s.current.elements:
+		// first element of a top-level array
+		s.current.command = string(content)
+	}
+}
+
+// elementDone accounts for the end of a value, which can complete the
+// aggregates it is part of
+func (s *scanner) elementDone(done func(value)) {
+	for len(s.pending) > 0 {
+		last := len(s.pending) - 1
+		s.pending[last]--
+		if s.pending[last] > 0 {
+			return
+		}
+		s.pending = s.pending[:last]
+	}
+	done(s.current)
+}
+
+func (s *scanner) reset() {
+	s.pending = s.pending[:0]
+	s.bulkRemaining = 0
+}
+
+func firstWord(data []byte) string {
+	if i := bytes.IndexByte(data, ' '); i >= 0 {
+		data = data[:i]
+	}
+	return string(data)
+}
+
+// errorPrefix returns the first word of an error, which is, by convention, an
+// uppercase word giving its type
+func errorPrefix(data []byte) string {
+	if i := bytes.IndexByte(data, ' '); i >= 0 {
+		data = data[:i]
+	}
+	if len(data) == 0 || len(data) > maxErrorPrefixLength {
+		return unknownErrorPrefix
+	}
+	for _, c := range data {
+		if c < 'A' || c > 'Z' {
+			return unknownErrorPrefix
+		}
+	}
+	return string(data)
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
@@ -0,0 +1,81 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package redis
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScanner(t *testing.T) {
+	var values []value
+	s := &scanner{}
+	done := func(v value) { values = append(values, v) }
+
+	// nested aggregates, null values and RESP3 types
+	s.feed([]byte("*2\r\n*2\r\n$1\r\na\r\n$-1\r\n%1\r\n+key\r\n:1\r\n"), done)
+	s.feed([]byte("_\r\n!21\r\nSYNTAX invalid syntax\r\n"), done)
+	// a bulk string split across three segments
+	s.feed([]byte("$10\r\n01234"), done)
+	s.feed([]byte("56"), done)
+	s.feed([]byte("789\r\n-WRONGTYPE Operation against a key\r\n"), done)
+
+	assert.Equal(t, []value{
+		{kind: '*', elements: 2, command: ""},
+		{kind: '_'},
+		{kind: '!', errorPrefix: "SYNTAX"},
+		{kind: '$'},
+		{kind: '-', errorPrefix: "WRONGTYPE"},
+	}, values)
+}
+
+func TestScannerOutOfRangeLengths(t *testing.T) {
+	for _, data := range []string{
+		"$9223372036854775807\r\nabc",
+		"$536870913\r\nabc",
+		"$-2\r\nabc",
+		"%9223372036854775807\r\n+key\r\n",
+		"*-5\r\n+OK\r\n",
+	} {
+		var values []value
+		s := &scanner{}
+		assert.NotPanics(t, func() {
+			s.feed([]byte(data), func(v value) { values = append(values, v) })
+		}, data)
+		assert.Empty(t, values, data)
+		assert.Zero(t, s.bulkRemaining, data)
+	}
+}
+
+func TestScannerErrorPrefixes(t *testing.T) {
+	var values []value
+	s := &scanner{}
+	done := func(v value) { values = append(values, v) }
+
+	s.feed([]byte("-ERR unknown command\r\n-"+strings.Repeat("A", maxErrorPrefixLength+1)+" too long\r\n"), done)
+	s.feed([]byte("-lowercase error\r\n- leading space\r\n!11\r\nMOVED 1 a:1\r\n"), done)
+
+	var prefixes []string
+	for _, v := range values {
+		prefixes = append(prefixes, v.errorPrefix)
+	}
+	assert.Equal(t, []string{"ERR", unknownErrorPrefix, unknownErrorPrefix, unknownErrorPrefix, "MOVED"}, prefixes)
+}
+
+func TestScannerMaxAggregateDepth(t *testing.T) {
+	var values []value
+	s := &scanner{}
+	done := func(v value) { values = append(values, v) }
+
+	s.feed([]byte(strings.Repeat("*1\r\n", maxAggregateDepth)+"+OK\r\n"), done)
+	assert.Len(t, values, 1)
+
+	s.feed([]byte(strings.Repeat("*1\r\n", maxAggregateDepth+1)+"+OK\r\n"), done)
+	assert.Len(t, values, 1)
+	assert.Empty(t, s.pending)
+}
@@ -0,0 +1,79 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package protocols
+
+import (
+	"sync"
+
+	"go.uber.org/atomic"
+)
+
+// StatKeeper aggregates transactions by Key
+type StatKeeper struct {
+	mux        sync.Mutex
+	stats      map[Key]*RequestStats
+	maxEntries int
+
+	// telemetry
+	processed *atomic.Int64
+	dropped   *atomic.Int64
+}
+
+// NewStatKeeper returns a StatKeeper buffering stats for at most maxEntries
+// keys between two calls to GetAndResetAllStats
+func NewStatKeeper(maxEntries int) *StatKeeper {
+	return &StatKeeper{
+		stats:      make(map[Key]*RequestStats),
+		maxEntries: maxEntries,
+		processed:  atomic.NewInt64(0),
+		dropped:    atomic.NewInt64(0),
+	}
+}
+
+// Process adds transactions to the stats
+func (s *StatKeeper) Process(transactions []Transaction) {
+	if len(transactions) == 0 {
+		return
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	for i := range transactions {
+		tx := &transactions[i]
+		s.processed.Inc()
+
+		stats, ok := s.stats[tx.Key]
+		if !ok {
+			if len(s.stats) >= s.maxEntries {
+				s.dropped.Inc()
+				continue
+			}
+			stats = new(RequestStats)
+			s.stats[tx.Key] = stats
+		}
+
+		stats.AddRequest(tx.Latency, tx.ErrorCode)
+	}
+}
+
+// GetAndResetAllStats returns the stats aggregated since the last call
+func (s *StatKeeper) GetAndResetAllStats() map[Key]*RequestStats {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	ret := s.stats // No deep copy needed since `s.stats` gets reset
+	s.stats = make(map[Key]*RequestStats)
+	return ret
+}
+
+// GetStats returns telemetry about the StatKeeper
+func (s *StatKeeper) GetStats() map[string]int64 {
+	return map[string]int64{
+		"processed": s.processed.Load(),
+		"dropped":   s.dropped.Load(),
+	}
+}
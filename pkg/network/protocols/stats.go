@@ -0,0 +1,106 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package protocols
+
+import (
+	"github.com/DataDog/sketches-go/ddsketch"
+
+	"github.com/DataDog/datadog-agent/pkg/network/http"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// RequestStats stores stats for the transactions of a Key
+type RequestStats struct {
+	// this field order is intentional to help the GC pointer tracking
+	Latencies *ddsketch.DDSketch
+
+	// ErrorsByCode counts the transactions that failed, by error code
+	ErrorsByCode map[string]int
+
+	// Count is the number of transactions, successful or not. As with HTTP,
+	// the count is kept apart from the sketch, which can discard values.
+	Count int
+
+	// FirstLatencySample holds the latency of the first transaction, to avoid
+	// creating sketches for keys with a single transaction
+	FirstLatencySample float64
+}
+
+// AddRequest adds a transaction to the stats
+func (r *RequestStats) AddRequest(latency float64, errorCode string) {
+	if errorCode != "" {
+		if r.ErrorsByCode == nil {
+			r.ErrorsByCode = make(map[string]int)
+		}
+		r.ErrorsByCode[errorCode]++
+	}
+
+	r.addSample(latency)
+}
+
+func (r *RequestStats) addSample(latency float64) {
+	r.Count++
+	if r.Count == 1 {
+		r.FirstLatencySample = latency
+		return
+	}
+
+	if r.Latencies == nil {
+		if !r.initSketch() {
+			return
+		}
+		r.addLatency(r.FirstLatencySample)
+	}
+
+	r.addLatency(latency)
+}
+
+// CombineWith merges the data in 2 RequestStats objects
+// newStats is kept as it is, while the method receiver gets mutated
+func (r *RequestStats) CombineWith(newStats *RequestStats) {
+	for code, count := range newStats.ErrorsByCode {
+		if r.ErrorsByCode == nil {
+			r.ErrorsByCode = make(map[string]int)
+		}
+		r.ErrorsByCode[code] += count
+	}
+
+	switch {
+	case newStats.Count == 0:
+		return
+	case newStats.Count == 1:
+		r.addSample(newStats.FirstLatencySample)
+		return
+	case r.Count == 0:
+		r.Latencies = newStats.Latencies.Copy()
+		r.Count = newStats.Count
+		return
+	}
+
+	if r.Latencies == nil {
+		r.Latencies = newStats.Latencies.Copy()
+		r.addLatency(r.FirstLatencySample)
+	} else if err := r.Latencies.MergeWith(newStats.Latencies); err != nil {
+		log.Debugf("error merging transaction latencies: %v", err)
+	}
+	r.Count += newStats.Count
+}
+
+func (r *RequestStats) initSketch() bool {
+	var err error
+	r.Latencies, err = ddsketch.NewDefaultDDSketch(http.RelativeAccuracy)
+	if err != nil {
+		log.Debugf("error recording transaction latency: could not create new ddsketch: %v", err)
+		return false
+	}
+	return true
+}
+
+func (r *RequestStats) addLatency(latency float64) {
+	if err := r.Latencies.Add(latency); err != nil {
+		log.Debugf("could not add transaction latency to ddsketch: %v", err)
+	}
+}
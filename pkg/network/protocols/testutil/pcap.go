@@ -0,0 +1,77 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package testutil provides helpers to test protocol parsers.
+package testutil
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/network/protocols"
+)
+
+// ReplayPcap parses the packets of a pcap file with parser, handling the
+// packets sent to or from port as traffic of its protocol, and returns the
+// aggregated stats.
+func ReplayPcap(t *testing.T, path string, port uint16, parser protocols.Parser) map[protocols.Key]*protocols.RequestStats {
+	statKeeper := protocols.NewStatKeeper(1000)
+	Replay(t, path, protocols.ParserHandlers(map[uint16]protocols.Parser{port: parser}, statKeeper))
+	return statKeeper.GetAndResetAllStats()
+}
+
+// Replay hands the TCP payloads of the packets of a pcap file to handlers,
+// and returns the telemetry of the dispatcher
+func Replay(t *testing.T, path string, handlers map[uint16]protocols.SegmentHandler) map[string]int64 {
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	r, err := pcapgo.NewReader(f)
+	require.NoError(t, err)
+
+	dispatcher := protocols.NewSegmentDispatcher(firstLayerType(t, r.LinkType()), handlers)
+
+	for {
+		data, ci, err := r.ReadPacketData()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+
+		dispatcher.Dispatch(data, ci.Timestamp)
+	}
+
+	stats := dispatcher.GetStats()
+	require.Zero(t, stats["decoding_errors"])
+	return stats
+}
+
+func firstLayerType(t *testing.T, linkType layers.LinkType) gopacket.LayerType {
+	switch linkType {
+	case layers.LinkTypeEthernet:
+		return layers.LayerTypeEthernet
+	case layers.LinkTypeNull, layers.LinkTypeLoop:
+		return layers.LayerTypeLoopback
+	}
+	require.FailNow(t, "unsupported link type", linkType.String())
+	return gopacket.LayerTypeZero
+}
+
+// StatsByOperation indexes stats by operation and resource, as all the
+// transactions of a pcap file usually come from the same connection
+func StatsByOperation(stats map[protocols.Key]*protocols.RequestStats) map[[2]string]*protocols.RequestStats {
+	byOperation := make(map[[2]string]*protocols.RequestStats, len(stats))
+	for key, s := range stats {
+		byOperation[[2]string{key.Operation, key.Resource}] = s
+	}
+	return byOperation
+}
@@ -0,0 +1,85 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package protocols implements the monitoring of application protocols other
+// than HTTP by Universal Service Monitoring. Each protocol has a parser
+// extracting transactions from the TCP payloads of its connections, and the
+// resulting transactions are aggregated by connection, operation and
+// resource.
+package protocols
+
+import (
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/network/http"
+)
+
+// ProtocolType is an application protocol monitored by Universal Service
+// Monitoring
+type ProtocolType uint8
+
+const (
+	// ProtocolUnknown represents an unknown protocol
+	ProtocolUnknown ProtocolType = iota
+	// ProtocolKafka represents the Kafka protocol
+	ProtocolKafka
+	// ProtocolPostgres represents the PostgreSQL frontend/backend protocol
+	ProtocolPostgres
+	// ProtocolRedis represents the Redis serialization protocol (RESP)
+	ProtocolRedis
+)
+
+// String returns the name of the protocol
+func (p ProtocolType) String() string {
+	switch p {
+	case ProtocolKafka:
+		return "kafka"
+	case ProtocolPostgres:
+		return "postgres"
+	case ProtocolRedis:
+		return "redis"
+	default:
+		return "unknown"
+	}
+}
+
+// Key is an identifier for a group of transactions. Like for HTTP, the
+// KeyTuple is always (client, server).
+type Key struct {
+	// this field order is intentional to help the GC pointer tracking
+	Operation string
+	Resource  string
+	http.KeyTuple
+	Protocol ProtocolType
+}
+
+// Transaction is a request and its response, as extracted by a Parser
+type Transaction struct {
+	Key
+
+	// Latency is the time elapsed between the request and its response, in
+	// nanoseconds
+	Latency float64
+
+	// ErrorCode is the protocol specific error returned by the server, it is
+	// empty when the request succeeded
+	ErrorCode string
+}
+
+// Parser extracts transactions from the TCP payloads exchanged over the
+// connections of a protocol. Parsers are not thread-safe.
+type Parser interface {
+	// Protocol returns the protocol handled by the parser
+	Protocol() ProtocolType
+
+	// Parse processes the payload of a TCP segment, sent by the client of the
+	// connection when fromClient is true, and returns the transactions it
+	// completes. The payload can't be referenced after the call.
+	Parse(tuple http.KeyTuple, fromClient bool, payload []byte, ts time.Time) []Transaction
+
+	// Expire forgets about the connections without activity since the given
+	// time, and returns how many were removed
+	Expire(before time.Time) int
+}
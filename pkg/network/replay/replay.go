@@ -0,0 +1,262 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+// Package replay feeds packet captures through the DNS and HTTP parsers of
+// the network tracer, to reproduce issues from captures and to test the
+// parsers against them.
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/network/dns"
+	dnsdebugging "github.com/DataDog/datadog-agent/pkg/network/dns/debugging"
+	"github.com/DataDog/datadog-agent/pkg/network/http"
+	httpdebugging "github.com/DataDog/datadog-agent/pkg/network/http/debugging"
+	"github.com/DataDog/datadog-agent/pkg/network/http/http2"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+)
+
+// pcapngMagic is the type of the section header block starting pcapng files
+var pcapngMagic = []byte{0x0a, 0x0d, 0x0d, 0x0a}
+
+// expirationPeriod is the capture time after which the HTTP/2 connections
+// without activity are forgotten, as done by the protocol monitor
+const expirationPeriod = 2 * time.Minute
+
+// Result holds the stats computed from a capture, in the format of the
+// debug endpoints of the network tracer
+type Result struct {
+	DNS       []dnsdebugging.RequestSummary  `json:"dns"`
+	DNSHealth dns.HealthStats                `json:"dns_health"`
+	HTTP      []httpdebugging.RequestSummary `json:"http"`
+	Telemetry map[string]interface{}         `json:"telemetry"`
+}
+
+type packetReader interface {
+	ReadPacketData() ([]byte, gopacket.CaptureInfo, error)
+}
+
+// File replays the packets of a pcap or pcapng file with the parsers enabled
+// by cfg. HTTPS traffic is encrypted in captures, so it can't be replayed.
+func File(cfg *config.Config, path string) (*Result, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	reader, linkType, err := newReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", path, err)
+	}
+	layerType, err := firstLayerType(linkType)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := newReplayer(cfg, layerType)
+	if err != nil {
+		return nil, err
+	}
+	defer r.close()
+
+	for {
+		data, ci, err := reader.ReadPacketData()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not read %s: %w", path, err)
+		}
+
+		r.process(data, ci.Timestamp)
+	}
+
+	return r.result(), nil
+}
+
+func newReader(f io.Reader) (packetReader, layers.LinkType, error) {
+	buffered := bufio.NewReader(f)
+	magic, err := buffered.Peek(len(pcapngMagic))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if bytes.Equal(magic, pcapngMagic) {
+		r, err := pcapgo.NewNgReader(buffered, pcapgo.DefaultNgReaderOptions)
+		if err != nil {
+			return nil, 0, err
+		}
+		return r, r.LinkType(), nil
+	}
+
+	r, err := pcapgo.NewReader(buffered)
+	if err != nil {
+		return nil, 0, err
+	}
+	return r, r.LinkType(), nil
+}
+
+func firstLayerType(linkType layers.LinkType) (gopacket.LayerType, error) {
+	switch linkType {
+	case layers.LinkTypeEthernet:
+		return layers.LayerTypeEthernet, nil
+	case layers.LinkTypeNull, layers.LinkTypeLoop:
+		return layers.LayerTypeLoopback, nil
+	}
+	return gopacket.LayerTypeZero, fmt.Errorf("unsupported link type %s", linkType)
+}
+
+type replayer struct {
+	dns *dns.Replayer
+
+	decoder *gopacket.DecodingLayerParser
+	decoded []gopacket.LayerType
+	ipv4    *layers.IPv4
+	ipv6    *layers.IPv6
+	tcp     *layers.TCP
+
+	http            *http.Replayer
+	http2Handler    *http2.Handler
+	http2Dispatcher *protocols.Dispatcher
+	lastExpiration  time.Time
+}
+
+func newReplayer(cfg *config.Config, layerType gopacket.LayerType) (*replayer, error) {
+	r := &replayer{
+		dns:  dns.NewReplayer(layerType, cfg),
+		ipv4: &layers.IPv4{},
+		ipv6: &layers.IPv6{},
+		tcp:  &layers.TCP{},
+	}
+
+	r.decoder = gopacket.NewDecodingLayerParser(layerType,
+		&layers.Ethernet{},
+		&layers.Loopback{},
+		r.ipv4,
+		r.ipv6,
+		r.tcp,
+	)
+	r.decoder.IgnoreUnsupported = true
+
+	if cfg.EnableHTTPMonitoring {
+		var err error
+		if r.http, err = http.NewReplayer(cfg); err != nil {
+			r.close()
+			return nil, err
+		}
+	}
+
+	if cfg.EnableHTTP2Monitoring && len(cfg.HTTP2Ports) > 0 {
+		r.http2Handler = http2.NewHandler(cfg)
+		handlers := make(map[uint16]protocols.SegmentHandler, len(cfg.HTTP2Ports))
+		for _, port := range cfg.HTTP2Ports {
+			handlers[port] = r.http2Handler
+		}
+		r.http2Dispatcher = protocols.NewSegmentDispatcher(layerType, handlers)
+	}
+
+	return r, nil
+}
+
+func (r *replayer) process(data []byte, ts time.Time) {
+	r.dns.Process(data, ts)
+
+	if r.http2Dispatcher != nil {
+		if r.lastExpiration.IsZero() {
+			r.lastExpiration = ts
+		} else if ts.Sub(r.lastExpiration) >= expirationPeriod {
+			r.http2Dispatcher.Expire(ts.Add(-expirationPeriod))
+			r.lastExpiration = ts
+		}
+		r.http2Dispatcher.Dispatch(data, ts)
+	}
+
+	if r.http == nil {
+		return
+	}
+	if err := r.decoder.DecodeLayers(data, &r.decoded); err != nil {
+		return
+	}
+
+	var src, dst util.Address
+	isIP, isTCP := false, false
+	for _, layer := range r.decoded {
+		switch layer {
+		case layers.LayerTypeIPv4:
+			src = util.AddressFromNetIP(r.ipv4.SrcIP)
+			dst = util.AddressFromNetIP(r.ipv4.DstIP)
+			isIP = true
+		case layers.LayerTypeIPv6:
+			src = util.AddressFromNetIP(r.ipv6.SrcIP)
+			dst = util.AddressFromNetIP(r.ipv6.DstIP)
+			isIP = true
+		case layers.LayerTypeTCP:
+			isTCP = true
+		}
+	}
+	if !isIP || !isTCP {
+		return
+	}
+
+	r.http.Process(src, dst, uint16(r.tcp.SrcPort), uint16(r.tcp.DstPort), r.tcp.Seq, r.tcp.FIN || r.tcp.RST, r.tcp.Payload, ts)
+}
+
+func (r *replayer) result() *Result {
+	httpStats := make(map[http.Key]*http.RequestStats)
+	telemetry := map[string]interface{}{
+		"dns": r.dns.GetStats(),
+	}
+	if r.http != nil {
+		for key, s := range r.http.GetHTTPStats() {
+			httpStats[key] = s
+		}
+		telemetry["http"] = r.http.GetStats()
+	}
+	if r.http2Handler != nil {
+		for key, s := range r.http2Handler.GetHTTPStats() {
+			httpStats[key] = s
+		}
+		telemetry["http2"] = r.http2Handler.GetStats()
+	}
+
+	servers := make([]util.Address, 0, len(httpStats))
+	for key := range httpStats {
+		servers = append(servers, serverAddress(key))
+	}
+
+	return &Result{
+		DNS:       dnsdebugging.DNS(r.dns.GetDNSStats()),
+		DNSHealth: r.dns.GetHealthStats(),
+		HTTP:      httpdebugging.HTTP(httpStats, r.dns.Resolve(servers)),
+		Telemetry: telemetry,
+	}
+}
+
+func serverAddress(key http.Key) util.Address {
+	if key.DstIPHigh > 0 || (key.DstIPLow>>32) > 0 {
+		return util.V6Address(key.DstIPLow, key.DstIPHigh)
+	}
+	return util.V4Address(uint32(key.DstIPLow))
+}
+
+func (r *replayer) close() {
+	r.dns.Close()
+}
@@ -0,0 +1,76 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package replay
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	dnsdebugging "github.com/DataDog/datadog-agent/pkg/network/dns/debugging"
+	httpdebugging "github.com/DataDog/datadog-agent/pkg/network/http/debugging"
+)
+
+// the captures hold the resolution of api.example.com, followed by two HTTP
+// transactions with it, a failed and an unanswered resolution, and a request
+// to port 443
+func TestReplayFile(t *testing.T) {
+	cfg := config.New()
+	cfg.CollectDNSStats = true
+	cfg.CollectDNSDomains = true
+	cfg.EnableHTTPMonitoring = true
+
+	for _, path := range []string{"testdata/dns_http.pcap", "testdata/dns_http.pcapng"} {
+		t.Run(path, func(t *testing.T) {
+			result, err := File(cfg, path)
+			require.NoError(t, err)
+
+			dnsByDomain := make(map[string]dnsdebugging.RequestSummary)
+			for _, s := range result.DNS {
+				dnsByDomain[s.Domain] = s
+			}
+			require.Len(t, dnsByDomain, 3)
+			assert.Equal(t, map[string]uint32{"NOERROR": 1}, dnsByDomain["api.example.com"].Stats.CountByRcode)
+			assert.Equal(t, uint64(2000), dnsByDomain["api.example.com"].Stats.SuccessLatencySum)
+			assert.Equal(t, map[string]uint32{"NXDOMAIN": 1}, dnsByDomain["missing.example.com"].Stats.CountByRcode)
+			assert.Equal(t, uint32(1), dnsByDomain["slow.example.com"].Stats.Timeouts)
+
+			require.Len(t, result.DNSHealth.Resolvers, 1)
+			assert.Equal(t, "10.0.0.53", result.DNSHealth.Resolvers[0].Resolver)
+			assert.Len(t, result.DNSHealth.Failures, 2)
+
+			httpByPath := make(map[string]httpdebugging.RequestSummary)
+			for _, s := range result.HTTP {
+				httpByPath[s.Path] = s
+			}
+			require.Len(t, httpByPath, 2)
+
+			get := httpByPath["/users/1"]
+			assert.Equal(t, "GET", get.Method)
+			assert.Equal(t, "api.example.com", get.DNS)
+			assert.Equal(t, httpdebugging.Address{IP: "10.0.0.2", Port: 8080}, get.Server)
+			require.Contains(t, get.ByStatus, 200)
+			assert.InDelta(t, 5e6, get.ByStatus[200].FirstLatencySample, 5e4)
+
+			post := httpByPath["/users"]
+			assert.Equal(t, "POST", post.Method)
+			require.Contains(t, post.ByStatus, 400)
+			// as in the socket filter, the FIN sent 1ms after the response is
+			// seen as part of it
+			assert.InDelta(t, 11e6, post.ByStatus[400].FirstLatencySample, 1e5)
+		})
+	}
+}
+
+func TestReplayUnknownFormat(t *testing.T) {
+	_, err := File(config.New(), "replay_test.go")
+	assert.Error(t, err)
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/network/dns"
 	"github.com/DataDog/datadog-agent/pkg/network/http"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols"
 	"github.com/DataDog/datadog-agent/pkg/process/util"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
@@ -67,6 +68,10 @@ type State interface {
 	// StoreClosedConnections stores a batch of closed connections
 	StoreClosedConnections(connections []ConnectionStats)
 
+	// StoreProtocolStats stores the latest stats of the protocols monitored
+	// beside HTTP, returned in the next Delta of every client
+	StoreProtocolStats(stats map[protocols.Key]*protocols.RequestStats)
+
 	// GetStats returns a map of statistics about the current network state
 	GetStats() map[string]interface{}
 
@@ -77,18 +82,20 @@ type State interface {
 // Delta represents a delta of network data compared to the last call to State.
 type Delta struct {
 	BufferedData
-	HTTP     map[http.Key]*http.RequestStats
-	DNSStats dns.StatsByKeyByNameByType
+	HTTP      map[http.Key]*http.RequestStats
+	DNSStats  dns.StatsByKeyByNameByType
+	Protocols map[protocols.Key]*protocols.RequestStats
 }
 
 type telemetry struct {
-	closedConnDropped  int64
-	connDropped        int64
-	statsUnderflows    int64
-	timeSyncCollisions int64
-	dnsStatsDropped    int64
-	httpStatsDropped   int64
-	dnsPidCollisions   int64
+	closedConnDropped    int64
+	connDropped          int64
+	statsUnderflows      int64
+	timeSyncCollisions   int64
+	dnsStatsDropped      int64
+	httpStatsDropped     int64
+	protocolStatsDropped int64
+	dnsPidCollisions     int64
 }
 
 const minClosedCapacity = 1024
@@ -104,6 +111,7 @@ type client struct {
 	// maps by dns key the domain (string) to stats structure
 	dnsStats        dns.StatsByKeyByNameByType
 	httpStatsDelta  map[http.Key]*http.RequestStats
+	protocolStats   map[protocols.Key]*protocols.RequestStats
 	lastTelemetries map[ConnTelemetryType]int64
 }
 
@@ -117,6 +125,7 @@ func (c *client) Reset(active map[string]*ConnectionStats) {
 	c.closedConnectionsKeys = make(map[string]int)
 	c.dnsStats = make(dns.StatsByKeyByNameByType)
 	c.httpStatsDelta = make(map[http.Key]*http.RequestStats)
+	c.protocolStats = make(map[protocols.Key]*protocols.RequestStats)
 
 	// XXX: we should change the way we clean this map once
 	// https://github.com/golang/go/issues/20135 is solved
@@ -229,8 +238,9 @@ func (ns *networkState) GetDelta(
 			Conns:  conns,
 			buffer: clientBuffer,
 		},
-		HTTP:     client.httpStatsDelta,
-		DNSStats: client.dnsStats,
+		HTTP:      client.httpStatsDelta,
+		DNSStats:  client.dnsStats,
+		Protocols: client.protocolStats,
 	}
 }
 
@@ -465,6 +475,40 @@ func (ns *networkState) storeHTTPStats(allStats map[http.Key]*http.RequestStats)
 	}
 }
 
+// StoreProtocolStats stores the latest stats of the protocols monitored
+// beside HTTP for all clients. They are bounded by the same limit as HTTP
+// stats.
+func (ns *networkState) StoreProtocolStats(allStats map[protocols.Key]*protocols.RequestStats) {
+	if len(allStats) == 0 {
+		return
+	}
+
+	ns.Lock()
+	defer ns.Unlock()
+
+	for key, stats := range allStats {
+		for _, client := range ns.clients {
+			prevStats, ok := client.protocolStats[key]
+			if !ok && len(client.protocolStats) >= ns.maxHTTPStats {
+				ns.telemetry.protocolStatsDropped++
+				continue
+			}
+
+			if prevStats != nil {
+				prevStats.CombineWith(stats)
+			} else if len(ns.clients) == 1 {
+				client.protocolStats[key] = stats
+			} else {
+				// stats are combined in place later on, so each client needs
+				// its own copy
+				copied := &protocols.RequestStats{}
+				copied.CombineWith(stats)
+				client.protocolStats[key] = copied
+			}
+		}
+	}
+}
+
 func (ns *networkState) getClient(clientID string) *client {
 	if c, ok := ns.clients[clientID]; ok {
 		return c
@@ -477,6 +521,7 @@ func (ns *networkState) getClient(clientID string) *client {
 		closedConnectionsKeys: make(map[string]int),
 		dnsStats:              dns.StatsByKeyByNameByType{},
 		httpStatsDelta:        map[http.Key]*http.RequestStats{},
+		protocolStats:         map[protocols.Key]*protocols.RequestStats{},
 		lastTelemetries:       make(map[ConnTelemetryType]int64),
 	}
 	ns.clients[clientID] = c
@@ -625,13 +670,14 @@ func (ns *networkState) GetStats() map[string]interface{} {
 	return map[string]interface{}{
 		"clients": clientInfo,
 		"telemetry": map[string]int64{
-			"stats_underflows":     ns.telemetry.statsUnderflows,
-			"closed_conn_dropped":  ns.telemetry.closedConnDropped,
-			"conn_dropped":         ns.telemetry.connDropped,
-			"time_sync_collisions": ns.telemetry.timeSyncCollisions,
-			"dns_stats_dropped":    ns.telemetry.dnsStatsDropped,
-			"http_stats_dropped":   ns.telemetry.httpStatsDropped,
-			"dns_pid_collisions":   ns.telemetry.dnsPidCollisions,
+			"stats_underflows":       ns.telemetry.statsUnderflows,
+			"closed_conn_dropped":    ns.telemetry.closedConnDropped,
+			"conn_dropped":           ns.telemetry.connDropped,
+			"time_sync_collisions":   ns.telemetry.timeSyncCollisions,
+			"dns_stats_dropped":      ns.telemetry.dnsStatsDropped,
+			"http_stats_dropped":     ns.telemetry.httpStatsDropped,
+			"protocol_stats_dropped": ns.telemetry.protocolStatsDropped,
+			"dns_pid_collisions":     ns.telemetry.dnsPidCollisions,
 		},
 		"current_time":       time.Now().Unix(),
 		"latest_bpf_time_ns": ns.latestTimeEpoch,
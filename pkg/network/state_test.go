@@ -20,6 +20,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/network/dns"
 	"github.com/DataDog/datadog-agent/pkg/network/http"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols"
 	"github.com/DataDog/datadog-agent/pkg/process/util"
 )
 
@@ -1419,6 +1420,34 @@ func TestHTTPStats(t *testing.T) {
 	assert.Len(t, delta.HTTP, 0)
 }
 
+func TestProtocolStats(t *testing.T) {
+	tuple := http.NewKeyTuple(util.AddressFromString("1.1.1.1"), util.AddressFromString("0.0.0.0"), 1000, 6379)
+	key := protocols.Key{KeyTuple: tuple, Protocol: protocols.ProtocolRedis, Operation: "GET"}
+
+	getStats := func() map[protocols.Key]*protocols.RequestStats {
+		var rs protocols.RequestStats
+		rs.AddRequest(1000, "")
+		return map[protocols.Key]*protocols.RequestStats{key: &rs}
+	}
+
+	state := newDefaultState()
+	state.RegisterClient("client1")
+	state.RegisterClient("client2")
+
+	state.StoreProtocolStats(getStats())
+	state.StoreProtocolStats(getStats())
+
+	delta := state.GetDelta("client1", latestEpochTime(), nil, nil, nil)
+	require.Len(t, delta.Protocols, 1)
+	assert.Equal(t, 2, delta.Protocols[key].Count)
+
+	// Verify stats have been flushed for the first client only
+	assert.Len(t, state.GetDelta("client1", latestEpochTime(), nil, nil, nil).Protocols, 0)
+	delta = state.GetDelta("client2", latestEpochTime(), nil, nil, nil)
+	require.Len(t, delta.Protocols, 1)
+	assert.Equal(t, 2, delta.Protocols[key].Count)
+}
+
 func TestHTTPStatsWithMultipleClients(t *testing.T) {
 	c := ConnectionStats{
 		Source: util.AddressFromString("1.1.1.1"),
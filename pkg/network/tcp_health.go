@@ -0,0 +1,97 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package network
+
+import (
+	"sort"
+
+	"github.com/DataDog/datadog-agent/pkg/network/dns"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+)
+
+// TCPHealthStats summarizes the health of the outgoing TCP connections seen
+// by a client since its previous request, by process and destination
+type TCPHealthStats struct {
+	Destinations []TCPDestinationStats `json:"destinations"`
+}
+
+// TCPDestinationStats holds the stats of the TCP connections of a process to
+// a destination
+type TCPDestinationStats struct {
+	Pid      uint32 `json:"pid"`
+	Dest     string `json:"dest"`
+	DestPort uint16 `json:"dest_port"`
+	// Hostname is the domain Dest was resolved from, if known
+	Hostname string `json:"hostname,omitempty"`
+
+	Connections    uint32 `json:"connections"`
+	Established    uint32 `json:"established"`
+	FailedConnects uint32 `json:"failed_connects"`
+	Resets         uint32 `json:"resets"`
+	Retransmits    uint32 `json:"retransmits"`
+	SentPackets    uint64 `json:"sent_packets"`
+
+	// RTTs holds the smoothed round-trip time, in microseconds, of each
+	// connection for which it is known
+	RTTs []uint32 `json:"rtts"`
+}
+
+type tcpDestinationKey struct {
+	pid      uint32
+	dest     util.Address
+	destPort uint16
+}
+
+// TCPHealth aggregates the outgoing TCP connections of conns by process and
+// destination. Connections to the loopback interface are left out.
+func TCPHealth(conns *Connections) TCPHealthStats {
+	byDest := make(map[tcpDestinationKey]*TCPDestinationStats)
+	for _, c := range conns.Conns {
+		if c.Type != TCP || c.Direction != OUTGOING || c.Dest.IsLoopback() {
+			continue
+		}
+
+		key := tcpDestinationKey{pid: c.Pid, dest: c.Dest, destPort: c.DPort}
+		stats, ok := byDest[key]
+		if !ok {
+			stats = &TCPDestinationStats{
+				Pid:      c.Pid,
+				Dest:     c.Dest.String(),
+				DestPort: c.DPort,
+			}
+			if names := conns.DNS[c.Dest]; len(names) > 0 {
+				stats.Hostname = dns.ToString(names[0])
+			}
+			byDest[key] = stats
+		}
+
+		stats.Connections++
+		stats.Established += c.Last.TCPEstablished
+		stats.FailedConnects += c.Last.TCPFailedConnects
+		stats.Resets += c.Last.Resets
+		stats.Retransmits += c.Last.Retransmits
+		stats.SentPackets += c.Last.SentPackets
+		if c.RTT > 0 {
+			stats.RTTs = append(stats.RTTs, c.RTT)
+		}
+	}
+
+	health := TCPHealthStats{Destinations: make([]TCPDestinationStats, 0, len(byDest))}
+	for _, stats := range byDest {
+		health.Destinations = append(health.Destinations, *stats)
+	}
+	sort.Slice(health.Destinations, func(i, j int) bool {
+		di, dj := health.Destinations[i], health.Destinations[j]
+		if di.Pid != dj.Pid {
+			return di.Pid < dj.Pid
+		}
+		if di.Dest != dj.Dest {
+			return di.Dest < dj.Dest
+		}
+		return di.DestPort < dj.DestPort
+	})
+	return health
+}
@@ -0,0 +1,66 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package network
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/network/dns"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+)
+
+func TestTCPHealth(t *testing.T) {
+	server := util.AddressFromString("10.0.0.2")
+	outgoing := func(sport uint16, rtt uint32, last StatCounters) ConnectionStats {
+		return ConnectionStats{
+			Pid:       42,
+			Type:      TCP,
+			Direction: OUTGOING,
+			Source:    util.AddressFromString("10.0.0.1"),
+			Dest:      server,
+			SPort:     sport,
+			DPort:     5432,
+			RTT:       rtt,
+			Last:      last,
+		}
+	}
+
+	conns := &Connections{
+		BufferedData: BufferedData{
+			Conns: []ConnectionStats{
+				outgoing(40000, 1200, StatCounters{SentPackets: 10, Retransmits: 2, TCPEstablished: 1}),
+				outgoing(40001, 800, StatCounters{SentPackets: 5, Resets: 1, TCPEstablished: 1, TCPClosed: 1}),
+				outgoing(40002, 0, StatCounters{TCPFailedConnects: 1, TCPClosed: 1}),
+				// incoming connections, loopback destinations and UDP are left out
+				{Pid: 42, Type: TCP, Direction: INCOMING, Dest: server, DPort: 40003},
+				{Pid: 42, Type: TCP, Direction: OUTGOING, Dest: util.AddressFromString("127.0.0.1"), DPort: 8080},
+				{Pid: 42, Type: UDP, Direction: OUTGOING, Dest: server, DPort: 53},
+			},
+		},
+		DNS: map[util.Address][]dns.Hostname{
+			server: {dns.ToHostname("db.example.com")},
+		},
+	}
+
+	health := TCPHealth(conns)
+	require.Len(t, health.Destinations, 1)
+	assert.Equal(t, TCPDestinationStats{
+		Pid:            42,
+		Dest:           "10.0.0.2",
+		DestPort:       5432,
+		Hostname:       "db.example.com",
+		Connections:    3,
+		Established:    2,
+		FailedConnects: 1,
+		Resets:         1,
+		Retransmits:    2,
+		SentPackets:    15,
+		RTTs:           []uint32{1200, 800},
+	}, health.Destinations[0])
+}
@@ -459,8 +459,10 @@ func updateTCPStats(conn *network.ConnectionStats, cookie uint32, tcpStats *nete
 
 	m, _ := conn.Monotonic.Get(cookie)
 	m.Retransmits = tcpStats.Retransmits
+	m.Resets = tcpStats.Resets
 	m.TCPEstablished = uint32(tcpStats.State_transitions >> netebpf.Established & 1)
 	m.TCPClosed = uint32(tcpStats.State_transitions >> netebpf.Close & 1)
+	m.TCPFailedConnects = uint32(tcpStats.State_transitions >> netebpf.SynSent & 1)
 	conn.Monotonic.Put(cookie, m)
 	conn.RTT = tcpStats.Rtt
 	conn.RTTVar = tcpStats.Rtt_var
@@ -483,6 +485,7 @@ func (t *kprobeTracer) getTCPStats(stats *netebpf.TCPStats, tuple *netebpf.ConnT
 		if _, reported := seen[*tuple]; reported {
 			t.pidCollisions.Inc()
 			stats.Retransmits = 0
+			stats.Resets = 0
 			stats.State_transitions = 0
 		} else {
 			seen[*tuple] = struct{}{}
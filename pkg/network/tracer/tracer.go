@@ -33,7 +33,12 @@ import (
 	netebpf "github.com/DataDog/datadog-agent/pkg/network/ebpf"
 	"github.com/DataDog/datadog-agent/pkg/network/ebpf/probes"
 	"github.com/DataDog/datadog-agent/pkg/network/http"
+	"github.com/DataDog/datadog-agent/pkg/network/http/http2"
 	"github.com/DataDog/datadog-agent/pkg/network/netlink"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols/kafka"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols/postgres"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols/redis"
 	errtelemetry "github.com/DataDog/datadog-agent/pkg/network/telemetry"
 	"github.com/DataDog/datadog-agent/pkg/network/tracer/connection"
 	"github.com/DataDog/datadog-agent/pkg/network/tracer/connection/kprobe"
@@ -48,13 +53,15 @@ const defaultUDPConnTimeoutNanoSeconds = uint64(time.Duration(120) * time.Second
 
 // Tracer implements the functionality of the network tracer
 type Tracer struct {
-	config       *config.Config
-	state        network.State
-	conntracker  netlink.Conntracker
-	reverseDNS   dns.ReverseDNS
-	httpMonitor  *http.Monitor
-	ebpfTracer   connection.Tracer
-	bpfTelemetry *errtelemetry.EBPFTelemetry
+	config          *config.Config
+	state           network.State
+	conntracker     netlink.Conntracker
+	reverseDNS      dns.ReverseDNS
+	httpMonitor     *http.Monitor
+	protocolMonitor *protocols.Monitor
+	http2Handler    *http2.Handler
+	ebpfTracer      connection.Tracer
+	bpfTelemetry    *errtelemetry.EBPFTelemetry
 
 	// Telemetry
 	skippedConns *atomic.Int64 `stats:""`
@@ -171,11 +178,16 @@ func NewTracer(config *config.Config) (*Tracer, error) {
 		log.Info("gateway lookup enabled")
 	}
 
+	var http2Handler *http2.Handler
+	if config.EnableHTTP2Monitoring {
+		http2Handler = http2.NewHandler(config)
+	}
+
 	tr := &Tracer{
 		config:                     config,
 		state:                      state,
 		reverseDNS:                 newReverseDNS(config),
-		httpMonitor:                newHTTPMonitor(config, ebpfTracer, bpfTelemetry, constantEditors),
+		httpMonitor:                newHTTPMonitor(config, ebpfTracer, bpfTelemetry, constantEditors, http2Handler),
 		activeBuffer:               network.NewConnectionBuffer(512, 256),
 		conntracker:                conntracker,
 		sourceExcludes:             network.ParseConnectionFilters(config.ExcludedSourceConnections),
@@ -192,7 +204,9 @@ func NewTracer(config *config.Config) (*Tracer, error) {
 		connStatsMapSize: atomic.NewInt64(0),
 		lastCheck:        atomic.NewInt64(0),
 		bpfTelemetry:     bpfTelemetry,
+		http2Handler:     http2Handler,
 	}
+	tr.protocolMonitor = newProtocolMonitor(config, http2Handler)
 
 	err = ebpfTracer.Start(tr.storeClosedConnections)
 	if err != nil {
@@ -337,6 +351,7 @@ func (t *Tracer) Stop() {
 	t.reverseDNS.Close()
 	t.ebpfTracer.Stop()
 	t.httpMonitor.Stop()
+	t.protocolMonitor.Stop()
 	t.conntracker.Close()
 }
 
@@ -353,7 +368,8 @@ func (t *Tracer) GetActiveConnections(clientID string) (*network.Connections, er
 	}
 	active := t.activeBuffer.Connections()
 
-	delta := t.state.GetDelta(clientID, latestTime, active, t.reverseDNS.GetDNSStats(), t.httpMonitor.GetHTTPStats())
+	t.state.StoreProtocolStats(t.protocolMonitor.GetProtocolStats())
+	delta := t.state.GetDelta(clientID, latestTime, active, t.reverseDNS.GetDNSStats(), t.getHTTPStats())
 	t.activeBuffer.Reset()
 
 	ips := make([]util.Address, 0, len(delta.Conns)*2)
@@ -370,6 +386,7 @@ func (t *Tracer) GetActiveConnections(clientID string) (*network.Connections, er
 		DNS:                         names,
 		DNSStats:                    delta.DNSStats,
 		HTTP:                        delta.HTTP,
+		Protocols:                   delta.Protocols,
 		ConnTelemetry:               ctm,
 		CompilationTelemetryByAsset: rctm,
 	}, nil
@@ -381,6 +398,12 @@ func (t *Tracer) RegisterClient(clientID string) error {
 	return nil
 }
 
+// GetDNSHealthStats returns the DNS stats by resolver and failing domain
+// accumulated since the last call
+func (t *Tracer) GetDNSHealthStats() dns.HealthStats {
+	return t.reverseDNS.GetHealthStats()
+}
+
 func (t *Tracer) getConnTelemetry(mapSize int) map[network.ConnTelemetryType]int64 {
 	kprobeStats := ddebpf.GetProbeTotals()
 	tm := map[network.ConnTelemetryType]int64{
@@ -596,6 +619,7 @@ const (
 	epbfStats
 	gatewayLookupStats
 	httpStats
+	protocolStats
 	kprobesStats
 	stateStats
 	tracerStats
@@ -609,6 +633,7 @@ var allStats = []statsComp{
 	epbfStats,
 	gatewayLookupStats,
 	httpStats,
+	protocolStats,
 	kprobesStats,
 	stateStats,
 	tracerStats,
@@ -638,6 +663,9 @@ func (t *Tracer) getStats(comps ...statsComp) (map[string]interface{}, error) {
 			ret["gateway_lookup"] = t.gwLookup.GetStats()
 		case httpStats:
 			ret["http"] = t.httpMonitor.GetStats()
+		case protocolStats:
+			ret["protocols"] = t.protocolMonitor.GetStats()
+			ret["http2"] = t.http2Handler.GetStats()
 		case kprobesStats:
 			ret["kprobes"] = ddebpf.GetProbeStats()
 		case stateStats:
@@ -793,7 +821,7 @@ func (t *Tracer) DebugHostConntrack(ctx context.Context) (interface{}, error) {
 	}, nil
 }
 
-func newHTTPMonitor(c *config.Config, tracer connection.Tracer, bpfTelemetry *errtelemetry.EBPFTelemetry, offsets []manager.ConstantEditor) *http.Monitor {
+func newHTTPMonitor(c *config.Config, tracer connection.Tracer, bpfTelemetry *errtelemetry.EBPFTelemetry, offsets []manager.ConstantEditor, http2Handler *http2.Handler) *http.Monitor {
 	if !c.EnableHTTPMonitoring {
 		return nil
 	}
@@ -801,7 +829,14 @@ func newHTTPMonitor(c *config.Config, tracer connection.Tracer, bpfTelemetry *er
 	// Shared with the HTTP program
 	sockFDMap := tracer.GetMap(string(probes.SockByPidFDMap))
 
-	monitor, err := http.NewMonitor(c, offsets, sockFDMap, bpfTelemetry)
+	// HTTP/2 connections monitored over TLS are handed to the same handler
+	// as the cleartext ones
+	var tlsHandler http.TLSSegmentHandler
+	if http2Handler != nil {
+		tlsHandler = http2Handler
+	}
+
+	monitor, err := http.NewMonitor(c, offsets, sockFDMap, bpfTelemetry, tlsHandler)
 	if err != nil {
 		log.Errorf("could not instantiate http monitor: %s", err)
 		return nil
@@ -821,3 +856,68 @@ func newHTTPMonitor(c *config.Config, tracer connection.Tracer, bpfTelemetry *er
 	log.Info("http monitoring enabled")
 	return monitor
 }
+
+// getHTTPStats returns the stats of the HTTP/1 transactions monitored in eBPF,
+// merged with the ones of the HTTP/2 transactions, which have distinct keys
+func (t *Tracer) getHTTPStats() map[http.Key]*http.RequestStats {
+	stats := t.httpMonitor.GetHTTPStats()
+	http2Stats := t.http2Handler.GetHTTPStats()
+	if len(http2Stats) == 0 {
+		return stats
+	}
+	if stats == nil {
+		return http2Stats
+	}
+	for key, s := range http2Stats {
+		stats[key] = s
+	}
+	return stats
+}
+
+func newProtocolMonitor(c *config.Config, http2Handler *http2.Handler) *protocols.Monitor {
+	parsers := make(map[uint16]protocols.Parser)
+	addParser := func(enabled bool, ports []uint16, parser protocols.Parser) {
+		if !enabled {
+			return
+		}
+		for _, port := range ports {
+			if _, ok := parsers[port]; ok {
+				log.Warnf("port %d is used by several monitored protocols, only %s is monitored", port, parsers[port].Protocol())
+				continue
+			}
+			parsers[port] = parser
+		}
+	}
+	addParser(c.EnableKafkaMonitoring, c.KafkaPorts, kafka.NewParser())
+	addParser(c.EnablePostgresMonitoring, c.PostgresPorts, postgres.NewParser())
+	addParser(c.EnableRedisMonitoring, c.RedisPorts, redis.NewParser())
+
+	handlers := make(map[uint16]protocols.SegmentHandler)
+	if http2Handler != nil {
+		for _, port := range c.HTTP2Ports {
+			if _, ok := parsers[port]; ok {
+				log.Warnf("port %d is used by several monitored protocols, only %s is monitored", port, parsers[port].Protocol())
+				continue
+			}
+			handlers[port] = http2Handler
+		}
+	}
+
+	monitor, err := protocols.NewMonitor(c, parsers, handlers)
+	if err != nil {
+		log.Errorf("could not enable protocol monitoring: %s", err)
+		return nil
+	}
+	if monitor == nil {
+		return nil
+	}
+
+	monitor.Start()
+	for port, parser := range parsers {
+		log.Infof("%s monitoring enabled on port %d", parser.Protocol(), port)
+	}
+	for port := range handlers {
+		log.Infof("http2 monitoring enabled on port %d", port)
+	}
+	return monitor
+}
@@ -163,6 +163,12 @@ func (t *Tracer) RegisterClient(clientID string) error {
 	return nil
 }
 
+// GetDNSHealthStats returns the DNS stats by resolver and failing domain
+// accumulated since the last call
+func (t *Tracer) GetDNSHealthStats() dns.HealthStats {
+	return t.reverseDNS.GetHealthStats()
+}
+
 func (t *Tracer) getConnTelemetry() map[network.ConnTelemetryType]int64 {
 	tm := map[network.ConnTelemetryType]int64{}
 
@@ -38,6 +38,7 @@ type OrchestratorConfig struct {
 	KubeClusterName                string
 	IsScrubbingEnabled             bool
 	Scrubber                       *redact.DataScrubber
+	Redactor                       *redact.Redactor
 	OrchestratorEndpoints          []apicfg.Endpoint
 	MaxPerMessage                  int
 	MaxWeightPerMessageBytes       int
@@ -90,6 +91,19 @@ func (oc *OrchestratorConfig) Load() error {
 		oc.Scrubber.AddCustomSensitiveWords(config.Datadog.GetStringSlice(k))
 	}
 
+	// Redaction rules for the annotations, labels and fields of any collected resource
+	if k := key(orchestratorNS, "redaction_rules"); config.Datadog.IsSet(k) {
+		var rules []redact.RedactionRule
+		if err := config.Datadog.UnmarshalKey(k, &rules); err != nil {
+			return fmt.Errorf("error parsing %s: %s", k, err)
+		}
+		redactor, err := redact.NewRedactor(rules)
+		if err != nil {
+			return fmt.Errorf("error parsing %s: %s", k, err)
+		}
+		oc.Redactor = redactor
+	}
+
 	// The maximum number of resources per message and the maximum message size.
 	// Note: Only change if the defaults are causing issues.
 	setBoundedConfigIntValue(key(orchestratorNS, "max_per_message"), maxMessageBatch, func(v int) { oc.MaxPerMessage = v })
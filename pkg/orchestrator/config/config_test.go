@@ -179,6 +179,32 @@ func (suite *YamlConfigTestSuite) TestEnvConfigSensitiveWords() {
 	}
 }
 
+func (suite *YamlConfigTestSuite) TestRedactionRules() {
+	suite.config.Set("orchestrator_explorer.redaction_rules", []interface{}{
+		map[string]interface{}{
+			"kinds":           []interface{}{"Deployment"},
+			"annotation_keys": []interface{}{"vault.hashicorp.com/*"},
+			"json_paths":      []interface{}{".spec.template.metadata.annotations"},
+		},
+	})
+
+	orchestratorCfg := NewDefaultOrchestratorConfig()
+	err := orchestratorCfg.Load()
+	suite.NoError(err)
+	suite.True(orchestratorCfg.Redactor.HasMetadataRules("Deployment"))
+	suite.False(orchestratorCfg.Redactor.HasMetadataRules("Pod"))
+}
+
+func (suite *YamlConfigTestSuite) TestInvalidRedactionRules() {
+	suite.config.Set("orchestrator_explorer.redaction_rules", []interface{}{
+		map[string]interface{}{"label_values": []interface{}{"("}},
+	})
+
+	orchestratorCfg := NewDefaultOrchestratorConfig()
+	err := orchestratorCfg.Load()
+	suite.EqualError(err, "error parsing orchestrator_explorer.redaction_rules: redaction rule 0: invalid label value regex \"(\": error parsing regexp: missing closing ): `(`")
+}
+
 func (suite *YamlConfigTestSuite) TestNoEnvConfigArgsScrubbing() {
 
 	orchestratorCfg := NewDefaultOrchestratorConfig()
@@ -0,0 +1,301 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// dataFieldNames are the fields of ConfigMap-like resources holding data keys
+var dataFieldNames = []string{"data", "stringData", "binaryData"}
+
+// RedactionRule declares the fields of the collected Kubernetes resources to redact
+type RedactionRule struct {
+	// Kinds restricts the rule to resources of these kinds, e.g. "Deployment", the rule applies to all kinds if empty
+	Kinds []string `mapstructure:"kinds" json:"kinds"`
+	// AnnotationKeys are globs matching the keys of the annotations to redact
+	AnnotationKeys []string `mapstructure:"annotation_keys" json:"annotation_keys"`
+	// LabelValues are regexes matching the label values to redact
+	LabelValues []string `mapstructure:"label_values" json:"label_values"`
+	// DataFields are globs matching the keys of the data, stringData and binaryData fields to redact
+	DataFields []string `mapstructure:"data_fields" json:"data_fields"`
+	// JSONPaths are paths of the fields to redact, e.g. "{.spec.auth[*].token}"
+	JSONPaths []string `mapstructure:"json_paths" json:"json_paths"`
+}
+
+type compiledRule struct {
+	kinds          map[string]struct{}
+	annotationKeys []*regexp.Regexp
+	labelValues    []*regexp.Regexp
+	dataFields     []*regexp.Regexp
+	jsonPaths      [][]pathSegment
+}
+
+// Redactor redacts the fields of Kubernetes resources matching a set of RedactionRules
+type Redactor struct {
+	rules []compiledRule
+}
+
+// NewRedactor compiles the redaction rules into a Redactor
+func NewRedactor(rules []RedactionRule) (*Redactor, error) {
+	r := &Redactor{rules: make([]compiledRule, 0, len(rules))}
+	for i, rule := range rules {
+		compiled := compiledRule{}
+		if len(rule.Kinds) > 0 {
+			compiled.kinds = make(map[string]struct{}, len(rule.Kinds))
+			for _, kind := range rule.Kinds {
+				compiled.kinds[strings.ToLower(kind)] = struct{}{}
+			}
+		}
+		for _, glob := range rule.AnnotationKeys {
+			compiled.annotationKeys = append(compiled.annotationKeys, compileGlob(glob))
+		}
+		for _, pattern := range rule.LabelValues {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("redaction rule %d: invalid label value regex %q: %v", i, pattern, err)
+			}
+			compiled.labelValues = append(compiled.labelValues, re)
+		}
+		for _, glob := range rule.DataFields {
+			compiled.dataFields = append(compiled.dataFields, compileGlob(glob))
+		}
+		for _, path := range rule.JSONPaths {
+			segments, err := parsePath(path)
+			if err != nil {
+				return nil, fmt.Errorf("redaction rule %d: invalid JSONPath %q: %v", i, path, err)
+			}
+			compiled.jsonPaths = append(compiled.jsonPaths, segments)
+		}
+		r.rules = append(r.rules, compiled)
+	}
+	return r, nil
+}
+
+// rulesFor returns the rules applying to a resource kind
+func (r *Redactor) rulesFor(kind string) []compiledRule {
+	if r == nil {
+		return nil
+	}
+	var rules []compiledRule
+	kind = strings.ToLower(kind)
+	for _, rule := range r.rules {
+		if rule.kinds != nil {
+			if _, found := rule.kinds[kind]; !found {
+				continue
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// HasMetadataRules returns whether annotations or labels of a resource kind are redacted
+func (r *Redactor) HasMetadataRules(kind string) bool {
+	for _, rule := range r.rulesFor(kind) {
+		if len(rule.annotationKeys) > 0 || len(rule.labelValues) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactMetadata redacts the annotations and labels of a resource matching the rules
+func (r *Redactor) RedactMetadata(kind string, obj metav1.Object) {
+	for _, rule := range r.rulesFor(kind) {
+		annotations := obj.GetAnnotations()
+		for key := range annotations {
+			if matchAny(rule.annotationKeys, key) {
+				annotations[key] = redactedValue
+			}
+		}
+		labels := obj.GetLabels()
+		for key, value := range labels {
+			if matchAny(rule.labelValues, value) {
+				labels[key] = redactedValue
+			}
+		}
+	}
+}
+
+// RedactManifest redacts the data fields and JSONPaths matching the rules in the
+// JSON manifest of a resource. The manifest is returned as is if nothing was redacted.
+func (r *Redactor) RedactManifest(kind string, manifest []byte) ([]byte, error) {
+	var rules []compiledRule
+	for _, rule := range r.rulesFor(kind) {
+		if len(rule.dataFields) > 0 || len(rule.jsonPaths) > 0 {
+			rules = append(rules, rule)
+		}
+	}
+	if len(rules) == 0 {
+		return manifest, nil
+	}
+
+	// Numbers are kept as is to not lose precision on integers
+	decoder := json.NewDecoder(bytes.NewReader(manifest))
+	decoder.UseNumber()
+	var content interface{}
+	if err := decoder.Decode(&content); err != nil {
+		return nil, fmt.Errorf("unable to decode manifest: %v", err)
+	}
+
+	changed := false
+	for _, rule := range rules {
+		if len(rule.dataFields) > 0 {
+			if object, ok := content.(map[string]interface{}); ok {
+				for _, fieldName := range dataFieldNames {
+					data, ok := object[fieldName].(map[string]interface{})
+					if !ok {
+						continue
+					}
+					for key := range data {
+						if matchAny(rule.dataFields, key) {
+							data[key] = redactedValue
+							changed = true
+						}
+					}
+				}
+			}
+		}
+		for _, segments := range rule.jsonPaths {
+			if redactPath(content, segments) {
+				changed = true
+			}
+		}
+	}
+
+	if !changed {
+		return manifest, nil
+	}
+	return json.Marshal(content)
+}
+
+// compileGlob compiles a glob, where * matches any sequence of characters and ? any character
+func compileGlob(glob string) *regexp.Regexp {
+	pattern := regexp.QuoteMeta(glob)
+	pattern = strings.ReplaceAll(pattern, `\*`, ".*")
+	pattern = strings.ReplaceAll(pattern, `\?`, ".")
+	return regexp.MustCompile("^" + pattern + "$")
+}
+
+func matchAny(patterns []*regexp.Regexp, s string) bool {
+	for _, pattern := range patterns {
+		if pattern.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// pathSegment is a step of a JSONPath: a map key, an array index or a wildcard
+type pathSegment struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// parsePath parses the subset of JSONPath made of child and index selectors,
+// e.g. "{.spec.containers[0].args}", "$.data['tls.key']" or ".spec.auth[*].token"
+func parsePath(path string) ([]pathSegment, error) {
+	p := strings.TrimSpace(path)
+	if strings.HasPrefix(p, "{") && strings.HasSuffix(p, "}") {
+		p = p[1 : len(p)-1]
+	}
+	p = strings.TrimPrefix(p, "$")
+	if p != "" && p[0] != '.' && p[0] != '[' {
+		p = "." + p
+	}
+
+	var segments []pathSegment
+	for len(p) > 0 {
+		switch p[0] {
+		case '.':
+			end := strings.IndexAny(p[1:], ".[")
+			if end == -1 {
+				end = len(p) - 1
+			}
+			name := p[1 : end+1]
+			if name == "" {
+				return nil, fmt.Errorf("empty field name")
+			}
+			segments = append(segments, pathSegment{key: name, wildcard: name == "*"})
+			p = p[end+1:]
+		case '[':
+			end := strings.IndexByte(p, ']')
+			if end == -1 {
+				return nil, fmt.Errorf("missing closing bracket")
+			}
+			selector := p[1:end]
+			switch {
+			case selector == "*":
+				segments = append(segments, pathSegment{wildcard: true})
+			case len(selector) >= 2 && (selector[0] == '\'' || selector[0] == '"') && selector[len(selector)-1] == selector[0]:
+				segments = append(segments, pathSegment{key: selector[1 : len(selector)-1]})
+			default:
+				index, err := strconv.Atoi(selector)
+				if err != nil || index < 0 {
+					return nil, fmt.Errorf("unsupported selector [%s]", selector)
+				}
+				segments = append(segments, pathSegment{index: index, isIndex: true})
+			}
+			p = p[end+1:]
+		default:
+			return nil, fmt.Errorf("unexpected character %q", p[0])
+		}
+	}
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("the root object can't be redacted")
+	}
+	return segments, nil
+}
+
+// redactPath redacts the fields of a decoded JSON value matching the path segments
+func redactPath(value interface{}, segments []pathSegment) bool {
+	segment, last := segments[0], len(segments) == 1
+	changed := false
+	visit := func(child interface{}, set func(interface{})) {
+		if child == nil {
+			return
+		}
+		if last {
+			set(redactedValue)
+			changed = true
+			return
+		}
+		if redactPath(child, segments[1:]) {
+			changed = true
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if segment.isIndex {
+			return false
+		}
+		for key, child := range v {
+			if segment.wildcard || key == segment.key {
+				key := key
+				visit(child, func(redacted interface{}) { v[key] = redacted })
+			}
+		}
+	case []interface{}:
+		for i, child := range v {
+			if segment.wildcard || (segment.isIndex && i == segment.index) {
+				i := i
+				visit(child, func(redacted interface{}) { v[i] = redacted })
+			}
+		}
+	}
+	return changed
+}
@@ -0,0 +1,126 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package redact
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRedactMetadata(t *testing.T) {
+	redactor, err := NewRedactor([]RedactionRule{
+		{
+			AnnotationKeys: []string{"kubectl.kubernetes.io/last-applied-configuration", "vault.hashicorp.com/*"},
+		},
+		{
+			Kinds:       []string{"deployment"},
+			LabelValues: []string{"^sk_live_"},
+		},
+	})
+	require.NoError(t, err)
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				"kubectl.kubernetes.io/last-applied-configuration": `{"password":"foo"}`,
+				"vault.hashicorp.com/agent-inject-secret-db":       "database/creds/db",
+				"deployment.kubernetes.io/revision":                "3",
+			},
+			Labels: map[string]string{
+				"app":   "checkout",
+				"token": "sk_live_1234",
+			},
+		},
+	}
+	assert.True(t, redactor.HasMetadataRules("Deployment"))
+	redactor.RedactMetadata("Deployment", deployment)
+	assert.Equal(t, map[string]string{
+		"kubectl.kubernetes.io/last-applied-configuration": redactedValue,
+		"vault.hashicorp.com/agent-inject-secret-db":       redactedValue,
+		"deployment.kubernetes.io/revision":                "3",
+	}, deployment.Annotations)
+	assert.Equal(t, map[string]string{
+		"app":   "checkout",
+		"token": redactedValue,
+	}, deployment.Labels)
+
+	// the label rule only applies to deployments
+	labels := map[string]string{"token": "sk_live_1234"}
+	redactor.RedactMetadata("StatefulSet", &metav1.ObjectMeta{Labels: labels})
+	assert.Equal(t, "sk_live_1234", labels["token"])
+
+	// no rule
+	var nilRedactor *Redactor
+	assert.False(t, nilRedactor.HasMetadataRules("Deployment"))
+}
+
+func TestRedactManifest(t *testing.T) {
+	redactor, err := NewRedactor([]RedactionRule{
+		{
+			Kinds:      []string{"ConfigMap"},
+			DataFields: []string{"*.key", "password"},
+		},
+		{
+			JSONPaths: []string{"{.spec.auth[*].token}", "$.spec['connection.string']", ".spec.replicas", ".spec.missing.field"},
+		},
+	})
+	require.NoError(t, err)
+
+	configMap := []byte(`{"kind":"ConfigMap","data":{"tls.key":"abc","password":"def","config.yaml":"foo: bar"},"binaryData":{"ca.key":"Zm9v"}}`)
+	redacted, err := redactor.RedactManifest("ConfigMap", configMap)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"kind":"ConfigMap","data":{"tls.key":"********","password":"********","config.yaml":"foo: bar"},"binaryData":{"ca.key":"********"}}`, string(redacted))
+
+	custom := []byte(`{"kind":"Database","metadata":{"generation":9007199254740993},"spec":{"auth":[{"user":"foo","token":"abc"},{"user":"bar","token":"def"}],"connection.string":"postgres://foo:bar@db","replicas":3}}`)
+	redacted, err = redactor.RedactManifest("Database", custom)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"kind":"Database","metadata":{"generation":9007199254740993},"spec":{"auth":[{"user":"foo","token":"********"},{"user":"bar","token":"********"}],"connection.string":"********","replicas":"********"}}`, string(redacted))
+	assert.Contains(t, string(redacted), "9007199254740993")
+
+	// the manifest is kept as is when nothing matches
+	unchanged := []byte(`{"kind":"Service","spec":{"type":"ClusterIP"}}`)
+	redacted, err = redactor.RedactManifest("Service", unchanged)
+	assert.NoError(t, err)
+	assert.Equal(t, unchanged, redacted)
+
+	_, err = redactor.RedactManifest("Database", []byte("not json"))
+	assert.Error(t, err)
+}
+
+func TestNewRedactorInvalidRules(t *testing.T) {
+	_, err := NewRedactor([]RedactionRule{{LabelValues: []string{"("}}})
+	assert.EqualError(t, err, "redaction rule 0: invalid label value regex \"(\": error parsing regexp: missing closing ): `(`")
+
+	_, err = NewRedactor([]RedactionRule{{}, {JSONPaths: []string{".spec.auth[?(@.user)]"}}})
+	assert.EqualError(t, err, `redaction rule 1: invalid JSONPath ".spec.auth[?(@.user)]": unsupported selector [?(@.user)]`)
+
+	_, err = NewRedactor([]RedactionRule{{JSONPaths: []string{"$"}}})
+	assert.EqualError(t, err, `redaction rule 0: invalid JSONPath "$": the root object can't be redacted`)
+}
+
+func Test_parsePath(t *testing.T) {
+	segments, err := parsePath("{.spec.containers[0].env[*]['value']}")
+	assert.NoError(t, err)
+	assert.Equal(t, []pathSegment{
+		{key: "spec"},
+		{key: "containers"},
+		{index: 0, isIndex: true},
+		{key: "env"},
+		{wildcard: true},
+		{key: "value"},
+	}, segments)
+
+	segments, err = parsePath("spec.*")
+	assert.NoError(t, err)
+	assert.Equal(t, []pathSegment{{key: "spec"}, {key: "*", wildcard: true}}, segments)
+
+	_, err = parsePath(".spec[0")
+	assert.EqualError(t, err, "missing closing bracket")
+}
@@ -61,6 +61,9 @@ type ProcessCheck struct {
 
 	maxBatchSize  int
 	maxBatchBytes int
+
+	// processGroups reports the process group metrics, it is nil if disabled
+	processGroups *processGroupAggregator
 }
 
 // Init initializes the singleton ProcessCheck.
@@ -79,6 +82,8 @@ func (p *ProcessCheck) Init(_ *config.AgentConfig, info *model.SystemInfo) {
 
 	p.maxBatchSize = getMaxBatchSize()
 	p.maxBatchBytes = getMaxBatchBytes()
+
+	p.processGroups = newProcessGroupAggregator()
 }
 
 // Name returns the name of the ProcessCheck.
@@ -172,6 +177,9 @@ func (p *ProcessCheck) run(cfg *config.AgentConfig, groupID int32, collectRealTi
 
 	connsByPID := Connections.getLastConnectionsByPID()
 	procsByCtr := fmtProcesses(cfg, procs, p.lastProcs, pidToCid, cpuTimes[0], p.lastCPUTime, p.lastRun, connsByPID)
+	if p.processGroups != nil {
+		p.processGroups.report(procsByCtr, procs, connsByPID)
+	}
 	messages, totalProcs, totalContainers := createProcCtrMessages(procsByCtr, containers, cfg, p.maxBatchSize, p.maxBatchBytes, p.sysInfo, groupID, p.networkID)
 
 	// Store the last state for comparison on the next run.
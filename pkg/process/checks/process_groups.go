@@ -0,0 +1,247 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	model "github.com/DataDog/agent-payload/v5/process"
+
+	ddconfig "github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/process/procutil"
+	"github.com/DataDog/datadog-agent/pkg/process/statsd"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	processGroupTagName = "process_group"
+
+	// maxTagLength is the maximum length of a tag, longer tags being truncated
+	maxTagLength = 200
+
+	// maxProcessGroupNameLength is the maximum length of a group name built from a command line,
+	// so that it fits in a tag along with the tag name and its separator
+	maxProcessGroupNameLength = maxTagLength - len(processGroupTagName) - 1
+
+	// maxCmdlineProcessGroups is the maximum number of groups built from command lines
+	// reported per run, the groups with the most instances being kept
+	maxCmdlineProcessGroups = 100
+)
+
+// volatileArgRegex matches the standalone numbers, UUIDs and hexadecimal ids of a command
+// line, like worker ids, ports and hashes. Hexadecimal ids are only replaced if they
+// contain a digit, to keep words like "deadbeef".
+var volatileArgRegex = regexp.MustCompile(`\b(?:[0-9a-fA-F]+-){4}[0-9a-fA-F]+\b|\b[0-9a-fA-F]{8,}\b|\b[0-9]+\b`)
+
+// ProcessGroupConfig is a user-defined process group, made of the processes whose
+// command line matches a regex
+type ProcessGroupConfig struct {
+	Name    string `mapstructure:"name"`
+	Pattern string `mapstructure:"pattern"`
+}
+
+type processGroupMatcher struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+// processGroupAggregator groups processes into process groups and reports their
+// aggregated stats as metrics, to monitor worker pools as a unit
+type processGroupAggregator struct {
+	groups         []processGroupMatcher
+	groupByCmdline bool
+	// warnedTooManyGroups is set once the limit of groups built from command lines is reported
+	warnedTooManyGroups bool
+}
+
+// processGroupStats holds the aggregated stats of the processes of a group
+type processGroupStats struct {
+	instances      int
+	cpuPct         float64
+	rss            uint64
+	readBytesRate  float64
+	writeBytesRate float64
+	openFDs        int64
+	threads        int64
+	// connections is only reported when the connections of the processes are known
+	connections int
+}
+
+// newProcessGroupAggregator returns a processGroupAggregator configured from the agent
+// config, or nil if the process groups are disabled
+func newProcessGroupAggregator() *processGroupAggregator {
+	if !ddconfig.Datadog.GetBool("process_config.process_groups.enabled") {
+		return nil
+	}
+
+	var groups []ProcessGroupConfig
+	if err := ddconfig.Datadog.UnmarshalKey("process_config.process_groups.groups", &groups); err != nil {
+		log.Errorf("Unable to parse process_config.process_groups.groups, ignoring user-defined process groups: %s", err)
+	}
+
+	a := &processGroupAggregator{
+		groupByCmdline: ddconfig.Datadog.GetBool("process_config.process_groups.group_by_cmdline"),
+	}
+	for _, group := range groups {
+		if group.Name == "" {
+			log.Warnf("Ignoring process group without name, pattern: %q", group.Pattern)
+			continue
+		}
+		pattern, err := regexp.Compile(group.Pattern)
+		if err != nil {
+			log.Warnf("Ignoring process group %q with invalid pattern %q: %s", group.Name, group.Pattern, err)
+			continue
+		}
+		a.groups = append(a.groups, processGroupMatcher{name: group.Name, pattern: pattern})
+	}
+	return a
+}
+
+// groupName returns the group of a process command line, processes matching no
+// user-defined group are grouped by normalized command line if enabled.
+// fromCmdline is true for the groups built from command lines.
+func (a *processGroupAggregator) groupName(cmdline []string) (name string, fromCmdline bool) {
+	rawCmdline := strings.Join(cmdline, " ")
+	for _, group := range a.groups {
+		if group.pattern.MatchString(rawCmdline) {
+			return group.name, false
+		}
+	}
+	if a.groupByCmdline {
+		return normalizeCmdline(cmdline), true
+	}
+	return "", false
+}
+
+// normalizeCmdline returns the executable name followed by the arguments, with the
+// numbers and ids changing across the instances of a worker pool replaced by "*"
+func normalizeCmdline(cmdline []string) string {
+	if len(cmdline) == 0 {
+		return ""
+	}
+	args := strings.Fields(strings.Join(cmdline, " "))
+	if len(args) == 0 {
+		return ""
+	}
+	args[0] = filepath.Base(args[0])
+	normalized := volatileArgRegex.ReplaceAllStringFunc(strings.Join(args, " "), func(arg string) string {
+		if strings.ContainsAny(arg, "0123456789") {
+			return "*"
+		}
+		return arg
+	})
+	normalized = strings.ToValidUTF8(normalized, "")
+	if len(normalized) > maxProcessGroupNameLength {
+		// truncate on a rune boundary
+		end := maxProcessGroupNameLength
+		for end > 0 && !utf8.RuneStart(normalized[end]) {
+			end--
+		}
+		normalized = normalized[:end]
+	}
+	return normalized
+}
+
+// aggregate sums the stats of the processes by group. procs provides the stats missing
+// from the process models, and connsByPID the connections collected by system-probe,
+// it is nil when they are not available.
+func (a *processGroupAggregator) aggregate(
+	procsByCtr map[string][]*model.Process,
+	procs map[int32]*procutil.Process,
+	connsByPID map[int32][]*model.Connection,
+) map[string]*processGroupStats {
+	statsByGroup := make(map[string]*processGroupStats)
+	var cmdlineGroups []string
+	for _, ctrProcs := range procsByCtr {
+		for _, proc := range ctrProcs {
+			if proc.Command == nil {
+				continue
+			}
+			name, fromCmdline := a.groupName(proc.Command.Args)
+			if name == "" {
+				continue
+			}
+			stats, found := statsByGroup[name]
+			if !found {
+				stats = &processGroupStats{}
+				statsByGroup[name] = stats
+				if fromCmdline {
+					cmdlineGroups = append(cmdlineGroups, name)
+				}
+			}
+
+			stats.instances++
+			if proc.Cpu != nil {
+				stats.cpuPct += float64(proc.Cpu.TotalPct)
+			}
+			if proc.Memory != nil {
+				stats.rss += proc.Memory.Rss
+			}
+			// Negative counts and rates mean the stats could not be read
+			if proc.OpenFdCount > 0 {
+				stats.openFDs += int64(proc.OpenFdCount)
+			}
+			if io := proc.IoStat; io != nil {
+				if io.ReadBytesRate > 0 {
+					stats.readBytesRate += float64(io.ReadBytesRate)
+				}
+				if io.WriteBytesRate > 0 {
+					stats.writeBytesRate += float64(io.WriteBytesRate)
+				}
+			}
+			if fp, found := procs[proc.Pid]; found && fp.Stats != nil {
+				stats.threads += int64(fp.Stats.NumThreads)
+			}
+			stats.connections += len(connsByPID[proc.Pid])
+		}
+	}
+
+	if len(cmdlineGroups) > maxCmdlineProcessGroups {
+		// keep the same groups across runs, rather than the first ones found
+		sort.Slice(cmdlineGroups, func(i, j int) bool {
+			si, sj := statsByGroup[cmdlineGroups[i]], statsByGroup[cmdlineGroups[j]]
+			if si.instances != sj.instances {
+				return si.instances > sj.instances
+			}
+			return cmdlineGroups[i] < cmdlineGroups[j]
+		})
+		for _, name := range cmdlineGroups[maxCmdlineProcessGroups:] {
+			delete(statsByGroup, name)
+		}
+		if !a.warnedTooManyGroups {
+			log.Warnf("Found %d process groups built from command lines, only reporting the %d with the most instances. Consider defining process groups in process_config.process_groups.groups instead.", len(cmdlineGroups), maxCmdlineProcessGroups)
+			a.warnedTooManyGroups = true
+		}
+	}
+	return statsByGroup
+}
+
+// report aggregates the processes by group and sends the process group metrics
+func (a *processGroupAggregator) report(
+	procsByCtr map[string][]*model.Process,
+	procs map[int32]*procutil.Process,
+	connsByPID map[int32][]*model.Connection,
+) {
+	statsByGroup := a.aggregate(procsByCtr, procs, connsByPID)
+	for name, stats := range statsByGroup {
+		tags := []string{processGroupTagName + ":" + name}
+		statsd.Client.Gauge("process.group.instances", float64(stats.instances), tags, 1)           //nolint:errcheck
+		statsd.Client.Gauge("process.group.cpu.pct", stats.cpuPct, tags, 1)                         //nolint:errcheck
+		statsd.Client.Gauge("process.group.mem.rss", float64(stats.rss), tags, 1)                   //nolint:errcheck
+		statsd.Client.Gauge("process.group.io.read_bytes_rate", stats.readBytesRate, tags, 1)       //nolint:errcheck
+		statsd.Client.Gauge("process.group.io.write_bytes_rate", stats.writeBytesRate, tags, 1)     //nolint:errcheck
+		statsd.Client.Gauge("process.group.open_file_descriptors", float64(stats.openFDs), tags, 1) //nolint:errcheck
+		statsd.Client.Gauge("process.group.threads", float64(stats.threads), tags, 1)               //nolint:errcheck
+		if connsByPID != nil {
+			statsd.Client.Gauge("process.group.connections", float64(stats.connections), tags, 1) //nolint:errcheck
+		}
+	}
+	log.Debugf("Reported %d process groups", len(statsByGroup))
+}
@@ -0,0 +1,149 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	model "github.com/DataDog/agent-payload/v5/process"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ddconfig "github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/process/procutil"
+)
+
+func makeGroupedProcess(pid int32, cmdline string, cpuPct float32, rss uint64, openFds int32, readRate float32) *model.Process {
+	return &model.Process{
+		Pid:         pid,
+		Command:     &model.Command{Args: strings.Split(cmdline, " ")},
+		Cpu:         &model.CPUStat{TotalPct: cpuPct},
+		Memory:      &model.MemoryStat{Rss: rss},
+		OpenFdCount: openFds,
+		IoStat:      &model.IOStat{ReadBytesRate: readRate, WriteBytesRate: -1},
+	}
+}
+
+func TestNewProcessGroupAggregator(t *testing.T) {
+	cfg := ddconfig.Mock(t)
+	assert.Nil(t, newProcessGroupAggregator())
+
+	cfg.Set("process_config.process_groups.enabled", true)
+	cfg.Set("process_config.process_groups.groups", []interface{}{
+		map[string]interface{}{"name": "gunicorn", "pattern": "^gunicorn: worker"},
+		map[string]interface{}{"name": "invalid", "pattern": "("},
+		map[string]interface{}{"pattern": "sidekiq"},
+	})
+	a := newProcessGroupAggregator()
+	require.NotNil(t, a)
+	assert.False(t, a.groupByCmdline)
+	require.Len(t, a.groups, 1)
+	assert.Equal(t, "gunicorn", a.groups[0].name)
+}
+
+func TestNormalizeCmdline(t *testing.T) {
+	for _, tc := range []struct {
+		cmdline  []string
+		expected string
+	}{
+		{[]string{"/usr/bin/python3", "manage.py", "runworker", "--id", "12"}, "python3 manage.py runworker --id *"},
+		{[]string{"sidekiq 6.5.1 app [0 of 10 busy]"}, "sidekiq *.*.* app [* of * busy]"},
+		{[]string{"nginx: worker process"}, "nginx: worker process"},
+		{[]string{"java", "-Xmx1024m", "-Dport=8080"}, "java -Xmx1024m -Dport=*"},
+		{nil, ""},
+		{[]string{"celery", "worker", "--hostname=3f2a9c1be07d", "--queue=default"}, "celery worker --hostname=* --queue=default"},
+		{[]string{"runner", "--job", "123e4567-e89b-12d3-a456-426614174000"}, "runner --job *"},
+		{[]string{"cat", "deadbeef"}, "cat deadbeef"},
+		{[]string{strings.Repeat("a", 300)}, strings.Repeat("a", maxProcessGroupNameLength)},
+		// truncated on a rune boundary
+		{[]string{strings.Repeat("a", maxProcessGroupNameLength-1) + "é"}, strings.Repeat("a", maxProcessGroupNameLength-1)},
+		{[]string{"invalid\xffutf8"}, "invalidutf8"},
+	} {
+		assert.Equal(t, tc.expected, normalizeCmdline(tc.cmdline))
+	}
+
+	// the longest names fit in a tag
+	assert.Len(t, processGroupTagName+":"+normalizeCmdline([]string{strings.Repeat("a", 300)}), maxTagLength)
+}
+
+func TestProcessGroupAggregate(t *testing.T) {
+	a := &processGroupAggregator{
+		groups: []processGroupMatcher{{name: "gunicorn", pattern: regexp.MustCompile("^gunicorn: worker")}},
+	}
+	procsByCtr := map[string][]*model.Process{
+		emptyCtrID: {
+			makeGroupedProcess(1, "gunicorn: worker [app]", 10, 100, 5, 1000),
+			makeGroupedProcess(2, "gunicorn: worker [app]", 20, 200, -1, -1),
+			makeGroupedProcess(3, "sidekiq 6.5.1 app [0 of 10 busy]", 5, 50, 3, 10),
+		},
+		"ctr1": {
+			makeGroupedProcess(4, "gunicorn: worker [app]", 30, 300, 7, 500),
+			makeGroupedProcess(5, "sidekiq 6.5.1 app [3 of 10 busy]", 15, 150, 4, 20),
+		},
+	}
+	procs := map[int32]*procutil.Process{
+		1: {Pid: 1, Stats: &procutil.Stats{NumThreads: 2}},
+		2: {Pid: 2, Stats: &procutil.Stats{NumThreads: 3}},
+		4: {Pid: 4, Stats: &procutil.Stats{NumThreads: 4}},
+	}
+	connsByPID := map[int32][]*model.Connection{
+		1: {{Pid: 1}, {Pid: 1}},
+		4: {{Pid: 4}},
+	}
+
+	// only the user-defined groups
+	stats := a.aggregate(procsByCtr, procs, connsByPID)
+	assert.Equal(t, map[string]*processGroupStats{
+		"gunicorn": {
+			instances:     3,
+			cpuPct:        60,
+			rss:           600,
+			readBytesRate: 1500,
+			openFDs:       12,
+			threads:       9,
+			connections:   3,
+		},
+	}, stats)
+
+	// group the other processes by command line
+	a.groupByCmdline = true
+	stats = a.aggregate(procsByCtr, procs, nil)
+	require.Len(t, stats, 2)
+	assert.Equal(t, &processGroupStats{
+		instances:     2,
+		cpuPct:        20,
+		rss:           200,
+		readBytesRate: 30,
+		openFDs:       7,
+	}, stats["sidekiq *.*.* app [* of * busy]"])
+	assert.Equal(t, 0, stats["gunicorn"].connections)
+}
+
+func TestProcessGroupAggregateLimit(t *testing.T) {
+	a := &processGroupAggregator{
+		groups:         []processGroupMatcher{{name: "gunicorn", pattern: regexp.MustCompile("^gunicorn: worker")}},
+		groupByCmdline: true,
+	}
+	var procs []*model.Process
+	for i := 0; i < maxCmdlineProcessGroups+10; i++ {
+		procs = append(procs, makeGroupedProcess(int32(i), fmt.Sprintf("worker-%c%c", 'a'+i/26, 'a'+i%26), 1, 1, 1, 1))
+	}
+	// the groups with the most instances are kept
+	procs = append(procs, makeGroupedProcess(1000, "worker-zz", 1, 1, 1, 1))
+	procs = append(procs, makeGroupedProcess(1001, "worker-zz", 1, 1, 1, 1))
+	procs = append(procs, makeGroupedProcess(1002, "gunicorn: worker [app]", 1, 1, 1, 1))
+
+	stats := a.aggregate(map[string][]*model.Process{emptyCtrID: procs}, nil, nil)
+	assert.Len(t, stats, maxCmdlineProcessGroups+1)
+	assert.Contains(t, stats, "gunicorn")
+	assert.Equal(t, 2, stats["worker-zz"].instances)
+	assert.Contains(t, stats, "worker-aa")
+	assert.NotContains(t, stats, "worker-ef")
+	assert.True(t, a.warnedTooManyGroups)
+}
@@ -0,0 +1,52 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package net
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	sysconfig "github.com/DataDog/datadog-agent/cmd/system-probe/config"
+	"github.com/DataDog/datadog-agent/pkg/network/dns"
+)
+
+const (
+	dnsHealthURL = "http://unix/" + string(sysconfig.NetworkTracerModule) + "/dns/health"
+)
+
+// GetDNSHealthStats returns the DNS stats by resolver and failing domain
+// accumulated by system-probe since the last call
+func (r *RemoteSysProbeUtil) GetDNSHealthStats() (*dns.HealthStats, error) {
+	req, err := http.NewRequest("GET", dnsHealthURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("dns health request failed: socket %s, url %s, status code: %d", r.path, dnsHealthURL, resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &dns.HealthStats{}
+	if err := json.Unmarshal(body, stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
@@ -0,0 +1,53 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package net
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	sysconfig "github.com/DataDog/datadog-agent/cmd/system-probe/config"
+	"github.com/DataDog/datadog-agent/pkg/network"
+)
+
+const (
+	tcpHealthURL = "http://unix/" + string(sysconfig.NetworkTracerModule) + "/tcp/health"
+)
+
+// GetTCPHealthStats returns the stats of the outgoing TCP connections seen
+// by system-probe since the previous call with the same client ID, by process
+// and destination
+func (r *RemoteSysProbeUtil) GetTCPHealthStats(clientID string) (*network.TCPHealthStats, error) {
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s?client_id=%s", tcpHealthURL, clientID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("tcp health request failed: socket %s, url %s, status code: %d", r.path, tcpHealthURL, resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &network.TCPHealthStats{}
+	if err := json.Unmarshal(body, stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
@@ -0,0 +1,104 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+// workloadmetaSources are the sources streamed when a request doesn't filter
+// by source.
+var workloadmetaSources = []workloadmeta.Source{
+	workloadmeta.SourceRuntime,
+	workloadmeta.SourceNodeOrchestrator,
+	workloadmeta.SourceClusterOrchestrator,
+	workloadmeta.SourceHost,
+}
+
+// Pb2WorkloadmetaFilters helper to convert a protobuf workloadmeta filter to
+// one native filter per requested source, so that events can be attributed
+// to the source that reported them.
+func Pb2WorkloadmetaFilters(filter *pb.WorkloadmetaFilter) (map[workloadmeta.Source]*workloadmeta.Filter, error) {
+	eventType, err := Pb2WorkloadmetaEventType(filter.GetEventType())
+	if err != nil {
+		return nil, err
+	}
+
+	kinds := make([]workloadmeta.Kind, 0, len(filter.GetKinds()))
+	for _, kind := range filter.GetKinds() {
+		kinds = append(kinds, workloadmeta.Kind(kind))
+	}
+
+	sources := workloadmetaSources
+	if len(filter.GetSources()) > 0 {
+		sources = make([]workloadmeta.Source, 0, len(filter.GetSources()))
+		for _, source := range filter.GetSources() {
+			sources = append(sources, workloadmeta.Source(source))
+		}
+	}
+
+	filters := make(map[workloadmeta.Source]*workloadmeta.Filter, len(sources))
+	for _, source := range sources {
+		if source == workloadmeta.SourceAll {
+			return nil, status.Errorf(codes.InvalidArgument, "empty source in filter")
+		}
+
+		filters[source] = workloadmeta.NewFilter(kinds, source, eventType)
+	}
+
+	return filters, nil
+}
+
+// Pb2WorkloadmetaEventType helper to convert a protobuf event type to its
+// native representation.
+func Pb2WorkloadmetaEventType(eventType pb.WorkloadmetaEventType) (workloadmeta.EventType, error) {
+	switch eventType {
+	case pb.WorkloadmetaEventType_EVENT_TYPE_ALL:
+		return workloadmeta.EventTypeAll, nil
+	case pb.WorkloadmetaEventType_EVENT_TYPE_SET:
+		return workloadmeta.EventTypeSet, nil
+	case pb.WorkloadmetaEventType_EVENT_TYPE_UNSET:
+		return workloadmeta.EventTypeUnset, nil
+	}
+
+	return 0, status.Errorf(codes.InvalidArgument, "invalid event type %q", eventType)
+}
+
+// Workloadmeta2PbEvent helper to convert a native workloadmeta event reported
+// by source to its protobuf representation. Only the allowed environment
+// variables of the entity are kept, see workloadmeta.RedactEntity.
+func Workloadmeta2PbEvent(source workloadmeta.Source, event workloadmeta.Event) (*pb.WorkloadmetaEvent, error) {
+	var eventType pb.WorkloadmetaEventType
+	switch event.Type {
+	case workloadmeta.EventTypeSet:
+		eventType = pb.WorkloadmetaEventType_EVENT_TYPE_SET
+	case workloadmeta.EventTypeUnset:
+		eventType = pb.WorkloadmetaEventType_EVENT_TYPE_UNSET
+	default:
+		return nil, fmt.Errorf("invalid event type %d", event.Type)
+	}
+
+	id := event.Entity.GetID()
+	entity, err := json.Marshal(workloadmeta.RedactEntity(event.Entity))
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode entity %s: %w", id.ID, err)
+	}
+
+	return &pb.WorkloadmetaEvent{
+		Type:   eventType,
+		Kind:   string(id.Kind),
+		Id:     id.ID,
+		Source: string(source),
+		Entity: entity,
+	}, nil
+}
@@ -0,0 +1,42 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package utils
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+func TestWorkloadmeta2PbEventRedactsEnvVars(t *testing.T) {
+	event, err := Workloadmeta2PbEvent(workloadmeta.SourceRuntime, workloadmeta.Event{
+		Type: workloadmeta.EventTypeSet,
+		Entity: &workloadmeta.Container{
+			EntityID: workloadmeta.EntityID{
+				Kind: workloadmeta.KindContainer,
+				ID:   "ctr-id",
+			},
+			EnvVars: map[string]string{
+				"DD_SERVICE":  "web",
+				"DB_PASSWORD": "secret",
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, pb.WorkloadmetaEventType_EVENT_TYPE_SET, event.Type)
+	assert.Equal(t, "ctr-id", event.Id)
+	assert.NotContains(t, string(event.Entity), "secret")
+
+	container := workloadmeta.Container{}
+	require.NoError(t, json.Unmarshal(event.Entity, &container))
+	assert.Equal(t, map[string]string{"DD_SERVICE": "web"}, container.EnvVars)
+}
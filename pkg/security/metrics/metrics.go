@@ -44,6 +44,15 @@ var (
 	// Tags: rule_id
 	MetricRateLimiterAllow = newRuntimeMetric(".rules.rate_limiter.allow")
 
+	// Rule suppression metrics
+
+	// MetricRulesSuppressed is the name of the metric used to count the amount of events suppressed by a rule suppression
+	// Tags: rule_id, suppression_id
+	MetricRulesSuppressed = newRuntimeMetric(".rules.suppressed")
+	// MetricRulesThrottled is the name of the metric used to count the amount of events throttled by a rule throttle
+	// Tags: rule_id
+	MetricRulesThrottled = newRuntimeMetric(".rules.throttled")
+
 	// Syscall monitoring metrics
 
 	// MetricSyscalls is the name of the metric used to count each syscall executed on the host
@@ -571,6 +571,33 @@ func (m *Module) sendStats() {
 	if err := m.apiServer.SendStats(); err != nil {
 		seclog.Debugf("failed to send api server stats: %s", err)
 	}
+	if err := m.sendSuppressionStats(); err != nil {
+		seclog.Debugf("failed to send rule suppression stats: %s", err)
+	}
+}
+
+// sendSuppressionStats sends the number of events suppressed or throttled by rule
+func (m *Module) sendSuppressionStats() error {
+	ruleSet := m.GetRuleSet()
+	if ruleSet == nil {
+		return nil
+	}
+
+	for ruleID, stats := range ruleSet.GetSuppressionStats() {
+		for suppressionID, count := range stats.Suppressed {
+			tags := []string{"rule_id:" + ruleID, "suppression_id:" + suppressionID}
+			if err := m.statsdClient.Count(metrics.MetricRulesSuppressed, count, tags, 1.0); err != nil {
+				return err
+			}
+		}
+		if stats.Throttled > 0 {
+			tags := []string{"rule_id:" + ruleID}
+			if err := m.statsdClient.Count(metrics.MetricRulesThrottled, stats.Throttled, tags, 1.0); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
 func (m *Module) statsSender() {
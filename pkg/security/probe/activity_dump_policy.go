@@ -0,0 +1,263 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package probe
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/security/utils"
+)
+
+// DefaultPolicyPathPrefixDepth is the default number of directories kept in the
+// opened path prefixes of a generated policy
+const DefaultPolicyPathPrefixDepth = 3
+
+// WorkloadAllowList holds the behaviour observed for a workload in one or
+// more activity dumps
+//msgp:ignore WorkloadAllowList
+type WorkloadAllowList struct {
+	// Name identifies the workload, usually the container image name
+	Name string
+	// Selector is the SECL expression matching the events of the workload
+	Selector string
+
+	Binaries     map[string]bool
+	PathPrefixes map[string]bool
+	DNSNames     map[string]bool
+}
+
+// newWorkloadAllowList returns an empty allow list for a workload
+func newWorkloadAllowList(name, selector string) *WorkloadAllowList {
+	return &WorkloadAllowList{
+		Name:         name,
+		Selector:     selector,
+		Binaries:     make(map[string]bool),
+		PathPrefixes: make(map[string]bool),
+		DNSNames:     make(map[string]bool),
+	}
+}
+
+// getWorkloadSelector returns the name of the workload of the dump, and the
+// SECL expression matching its events
+func (ad *ActivityDump) getWorkloadSelector() (string, string, error) {
+	if imageName := utils.GetTagValue("image_name", ad.Tags); imageName != "" {
+		return imageName, fmt.Sprintf(`container.tags == "image_name:%s"`, imageName), nil
+	}
+	if ad.DumpMetadata.ContainerID != "" {
+		return ad.DumpMetadata.ContainerID, fmt.Sprintf(`container.id == "%s"`, ad.DumpMetadata.ContainerID), nil
+	}
+	if ad.DumpMetadata.Comm != "" {
+		return ad.DumpMetadata.Comm, fmt.Sprintf(`process.ancestors.comm == "%s"`, ad.DumpMetadata.Comm), nil
+	}
+	return "", "", fmt.Errorf("activity dump %s doesn't have any image name, container ID or comm", ad.DumpMetadata.Name)
+}
+
+// pathPrefix returns the pattern matching the directory of a file, truncated
+// to depth directories
+func pathPrefix(filePath string, depth int) string {
+	dir := path.Dir(filePath)
+	if dir == "/" || dir == "." {
+		return filePath
+	}
+
+	elems := strings.Split(strings.TrimPrefix(dir, "/"), "/")
+	if depth > 0 && len(elems) > depth {
+		elems = elems[:depth]
+	}
+	return "/" + strings.Join(elems, "/") + "/*"
+}
+
+func (al *WorkloadAllowList) addFiles(file *FileActivityNode, depth int) {
+	if file.File != nil && file.Open != nil && file.File.PathnameStr != "" {
+		al.PathPrefixes[pathPrefix(file.File.PathnameStr, depth)] = true
+	}
+
+	for _, child := range file.Children {
+		al.addFiles(child, depth)
+	}
+}
+
+func (al *WorkloadAllowList) addProcessNode(node *ProcessActivityNode, depth int) {
+	if node.Process.FileEvent.PathnameStr != "" {
+		al.Binaries[node.Process.FileEvent.PathnameStr] = true
+	}
+
+	for _, file := range node.Files {
+		al.addFiles(file, depth)
+	}
+
+	for _, dns := range node.DNSNames {
+		for _, req := range dns.Requests {
+			if req.Name != "" {
+				al.DNSNames[req.Name] = true
+			}
+		}
+	}
+
+	for _, child := range node.Children {
+		al.addProcessNode(child, depth)
+	}
+}
+
+// GenerateWorkloadAllowList returns the binaries executed, the opened path
+// prefixes and the DNS names queried in the activity dump. depth is the
+// number of directories kept in the path prefixes.
+func (ad *ActivityDump) GenerateWorkloadAllowList(depth int) (*WorkloadAllowList, error) {
+	ad.Lock()
+	defer ad.Unlock()
+
+	name, selector, err := ad.getWorkloadSelector()
+	if err != nil {
+		return nil, err
+	}
+
+	al := newWorkloadAllowList(name, selector)
+	for _, node := range ad.ProcessActivityTree {
+		al.addProcessNode(node, depth)
+	}
+	return al, nil
+}
+
+// Merge adds the entries of another allow list of the same workload
+func (al *WorkloadAllowList) Merge(other *WorkloadAllowList) {
+	for binary := range other.Binaries {
+		al.Binaries[binary] = true
+	}
+	for prefix := range other.PathPrefixes {
+		al.PathPrefixes[prefix] = true
+	}
+	for name := range other.DNSNames {
+		al.DNSNames[name] = true
+	}
+}
+
+// generatedPolicy is the serialized form of a generated policy, loadable as a
+// rules.PolicyDef
+type generatedPolicy struct {
+	Version string                 `yaml:"version,omitempty"`
+	Macros  []generatedPolicyMacro `yaml:"macros,omitempty"`
+	Rules   []generatedPolicyRule  `yaml:"rules"`
+}
+
+type generatedPolicyMacro struct {
+	ID     string   `yaml:"id"`
+	Values []string `yaml:"values"`
+}
+
+type generatedPolicyRule struct {
+	ID          string            `yaml:"id"`
+	Description string            `yaml:"description,omitempty"`
+	Expression  string            `yaml:"expression"`
+	Tags        map[string]string `yaml:"tags,omitempty"`
+}
+
+// policyID returns a string usable in rule and macro IDs
+func policyID(s string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// seclList returns the SECL array of the values, quoted as patterns when
+// pattern is set
+func seclList(values []string, pattern bool) string {
+	elems := make([]string, 0, len(values))
+	for _, value := range values {
+		quoted, _ := json.Marshal(value)
+		if pattern && strings.Contains(value, "*") {
+			elems = append(elems, "~"+string(quoted))
+		} else {
+			elems = append(elems, string(quoted))
+		}
+	}
+	return "[" + strings.Join(elems, ", ") + "]"
+}
+
+// GenerateLeastPrivilegePolicy returns a policy reporting the activity of
+// each workload outside of its allow list: the execution of other binaries,
+// the opening of files outside of the observed path prefixes and the
+// resolution of other DNS names. The allow lists of the same workload are
+// merged.
+func GenerateLeastPrivilegePolicy(allowLists []*WorkloadAllowList, ruleIDPrefix string) ([]byte, error) {
+	merged := make(map[string]*WorkloadAllowList)
+	var names []string
+	for _, al := range allowLists {
+		existing, found := merged[al.Name]
+		if !found {
+			existing = newWorkloadAllowList(al.Name, al.Selector)
+			merged[al.Name] = existing
+			names = append(names, al.Name)
+		}
+		existing.Merge(al)
+	}
+	sort.Strings(names)
+
+	var policy generatedPolicy
+	for _, name := range names {
+		al := merged[name]
+		id := policyID(ruleIDPrefix + "_" + name)
+		tags := map[string]string{"workload": name}
+
+		if binaries := sortedKeys(al.Binaries); len(binaries) > 0 {
+			macroID := id + "_binaries"
+			policy.Macros = append(policy.Macros, generatedPolicyMacro{ID: macroID, Values: binaries})
+			policy.Rules = append(policy.Rules, generatedPolicyRule{
+				ID:          id + "_unexpected_exec",
+				Description: fmt.Sprintf("Execution of a binary not observed in %s", name),
+				Expression:  fmt.Sprintf("exec.file.path not in %s && %s", macroID, al.Selector),
+				Tags:        tags,
+			})
+		}
+
+		if prefixes := sortedKeys(al.PathPrefixes); len(prefixes) > 0 {
+			policy.Rules = append(policy.Rules, generatedPolicyRule{
+				ID:          id + "_unexpected_open",
+				Description: fmt.Sprintf("Opening of a file outside of the paths observed in %s", name),
+				Expression:  fmt.Sprintf("open.file.path not in %s && %s", seclList(prefixes, true), al.Selector),
+				Tags:        tags,
+			})
+		}
+
+		if dnsNames := sortedKeys(al.DNSNames); len(dnsNames) > 0 {
+			macroID := id + "_dns_names"
+			policy.Macros = append(policy.Macros, generatedPolicyMacro{ID: macroID, Values: dnsNames})
+			policy.Rules = append(policy.Rules, generatedPolicyRule{
+				ID:          id + "_unexpected_dns",
+				Description: fmt.Sprintf("Resolution of a DNS name not observed in %s", name),
+				Expression:  fmt.Sprintf("dns.question.name not in %s && %s", macroID, al.Selector),
+				Tags:        tags,
+			})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := yaml.NewEncoder(&buf).Encode(&policy); err != nil {
+		return nil, fmt.Errorf("couldn't encode policy: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,117 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package probe
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/model"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/rules"
+)
+
+func newTestProcessNode(path string, files []string, dnsNames []string, children ...*ProcessActivityNode) *ProcessActivityNode {
+	node := &ProcessActivityNode{
+		Files:    make(map[string]*FileActivityNode),
+		DNSNames: make(map[string]*DNSNode),
+		Children: children,
+	}
+	node.Process.FileEvent.PathnameStr = path
+
+	for _, file := range files {
+		node.Files[file] = &FileActivityNode{
+			Name: file,
+			File: &model.FileEvent{PathnameStr: file},
+			Open: &OpenNode{},
+		}
+	}
+	for _, name := range dnsNames {
+		node.DNSNames[name] = &DNSNode{Requests: []model.DNSEvent{{Name: name}}}
+	}
+	return node
+}
+
+func TestPathPrefix(t *testing.T) {
+	assert.Equal(t, "/etc/*", pathPrefix("/etc/passwd", 3))
+	assert.Equal(t, "/usr/lib/x86_64-linux-gnu/*", pathPrefix("/usr/lib/x86_64-linux-gnu/libc.so.6", 3))
+	assert.Equal(t, "/usr/lib/*", pathPrefix("/usr/lib/x86_64-linux-gnu/libc.so.6", 2))
+	assert.Equal(t, "/app", pathPrefix("/app", 3))
+}
+
+func TestGenerateLeastPrivilegePolicy(t *testing.T) {
+	ad := NewEmptyActivityDump()
+	ad.Tags = []string{"image_name:nginx", "image_tag:1.23"}
+	ad.ProcessActivityTree = []*ProcessActivityNode{
+		newTestProcessNode("/usr/sbin/nginx", []string{"/etc/nginx/nginx.conf", "/var/log/nginx/access.log"}, []string{"example.com"},
+			newTestProcessNode("/bin/sh", []string{"/usr/lib/x86_64-linux-gnu/libc.so.6"}, nil),
+		),
+	}
+
+	allowList, err := ad.GenerateWorkloadAllowList(DefaultPolicyPathPrefixDepth)
+	assert.NoError(t, err)
+	assert.Equal(t, "nginx", allowList.Name)
+	assert.Equal(t, map[string]bool{"/usr/sbin/nginx": true, "/bin/sh": true}, allowList.Binaries)
+	assert.Equal(t, map[string]bool{"/etc/nginx/*": true, "/var/log/nginx/*": true, "/usr/lib/x86_64-linux-gnu/*": true}, allowList.PathPrefixes)
+	assert.Equal(t, map[string]bool{"example.com": true}, allowList.DNSNames)
+
+	raw, err := GenerateLeastPrivilegePolicy([]*WorkloadAllowList{allowList}, "lp")
+	assert.NoError(t, err)
+
+	policy, err := rules.LoadPolicy("generated.policy", "test", bytes.NewReader(raw), nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, policy.Macros, 2)
+	assert.Len(t, policy.Rules, 3)
+	assert.Equal(t, rules.RuleID("lp_nginx_unexpected_exec"), policy.Rules[0].ID)
+	assert.Equal(t, `exec.file.path not in lp_nginx_binaries && container.tags == "image_name:nginx"`, policy.Rules[0].Expression)
+	assert.Equal(t, `open.file.path not in [~"/etc/nginx/*", ~"/usr/lib/x86_64-linux-gnu/*", ~"/var/log/nginx/*"] && container.tags == "image_name:nginx"`, policy.Rules[1].Expression)
+
+	var opts rules.Opts
+	opts.WithEventTypeEnabled(map[eval.EventType]bool{"*": true})
+
+	var evalOpts eval.Opts
+	evalOpts.WithConstants(model.SECLConstants)
+
+	m := &model.Model{}
+	rs := rules.NewRuleSet(m, m.NewEvent, &opts, &evalOpts, &eval.MacroStore{})
+
+	// the policy is loadable by the provider of the policies directory
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "generated.policy"), raw, 0644))
+	provider, err := rules.NewPoliciesDirProvider(dir, false)
+	assert.NoError(t, err)
+	loader := rules.NewPolicyLoader(provider)
+	assert.Nil(t, rs.LoadPolicies(loader, rules.PolicyLoaderOpts{}).ErrorOrNil())
+}
+
+func TestGenerateLeastPrivilegePolicyMerge(t *testing.T) {
+	first := newWorkloadAllowList("redis", `container.tags == "image_name:redis"`)
+	first.Binaries["/usr/local/bin/redis-server"] = true
+
+	second := newWorkloadAllowList("redis", `container.tags == "image_name:redis"`)
+	second.Binaries["/bin/sh"] = true
+
+	raw, err := GenerateLeastPrivilegePolicy([]*WorkloadAllowList{first, second}, "lp")
+	assert.NoError(t, err)
+
+	policy, err := rules.LoadPolicy("generated.policy", "test", bytes.NewReader(raw), nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, policy.Rules, 1)
+	assert.Equal(t, []string{"/bin/sh", "/usr/local/bin/redis-server"}, policy.Macros[0].Values)
+}
+
+func TestGenerateWorkloadAllowListNoSelector(t *testing.T) {
+	ad := NewEmptyActivityDump()
+	_, err := ad.GenerateWorkloadAllowList(DefaultPolicyPathPrefixDepth)
+	assert.Error(t, err)
+}
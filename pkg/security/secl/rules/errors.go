@@ -106,3 +106,13 @@ type ErrRuleLoad struct {
 func (e ErrRuleLoad) Error() string {
 	return fmt.Sprintf("rule `%s` definition error: %s", e.Definition.ID, e.Err)
 }
+
+// ErrSuppressionLoad is on suppression definition error
+type ErrSuppressionLoad struct {
+	Definition *SuppressionDefinition
+	Err        error
+}
+
+func (e ErrSuppressionLoad) Error() string {
+	return fmt.Sprintf("suppression `%s` definition error: %s", e.Definition.ID, e.Err)
+}
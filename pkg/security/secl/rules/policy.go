@@ -17,19 +17,21 @@ import (
 
 // PolicyDef represents a policy file definition
 type PolicyDef struct {
-	Version string             `yaml:"version"`
-	Rules   []*RuleDefinition  `yaml:"rules"`
-	Macros  []*MacroDefinition `yaml:"macros"`
+	Version      string                   `yaml:"version"`
+	Rules        []*RuleDefinition        `yaml:"rules"`
+	Macros       []*MacroDefinition       `yaml:"macros"`
+	Suppressions []*SuppressionDefinition `yaml:"suppressions"`
 }
 
 // Policy represents a policy file which is composed of a list of rules and macros
 type Policy struct {
-	Name        string
-	Source      string
-	Version     string
-	Rules       []*RuleDefinition
-	Macros      []*MacroDefinition
-	RuleSkipped []*RuleDefinition
+	Name         string
+	Source       string
+	Version      string
+	Rules        []*RuleDefinition
+	Macros       []*MacroDefinition
+	Suppressions []*SuppressionDefinition
+	RuleSkipped  []*RuleDefinition
 }
 
 // AddMacro add a macro to the policy
@@ -37,6 +39,12 @@ func (p *Policy) AddMacro(def *MacroDefinition) {
 	p.Macros = append(p.Macros, def)
 }
 
+// AddSuppression add a suppression to the policy
+func (p *Policy) AddSuppression(def *SuppressionDefinition) {
+	def.Policy = p
+	p.Suppressions = append(p.Suppressions, def)
+}
+
 // AddRule add a rule to the policy
 func (p *Policy) AddRule(def *RuleDefinition) {
 	def.Policy = p
@@ -129,6 +137,19 @@ RULES:
 		policy.AddRule(ruleDef)
 	}
 
+	for _, suppressionDef := range def.Suppressions {
+		if suppressionDef.ID == "" {
+			errs = multierror.Append(errs, &ErrSuppressionLoad{Definition: suppressionDef, Err: errors.New("no ID defined for suppression")})
+			continue
+		}
+		if !validators.CheckRuleID(suppressionDef.ID) {
+			errs = multierror.Append(errs, &ErrSuppressionLoad{Definition: suppressionDef, Err: fmt.Errorf("ID does not match pattern `%s`", validators.RuleIDPattern)})
+			continue
+		}
+
+		policy.AddSuppression(suppressionDef)
+	}
+
 	cleanupRuleSkipped(policy)
 
 	return policy, errs.ErrorOrNil()
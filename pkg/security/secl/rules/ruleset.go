@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"reflect"
 	"sync"
+	"time"
 
 	"github.com/hashicorp/go-multierror"
 	"github.com/spf13/cast"
@@ -68,15 +69,16 @@ type RuleID = string
 
 // RuleDefinition holds the definition of a rule
 type RuleDefinition struct {
-	ID                     RuleID             `yaml:"id"`
-	Version                string             `yaml:"version"`
-	Expression             string             `yaml:"expression"`
-	Description            string             `yaml:"description"`
-	Tags                   map[string]string  `yaml:"tags"`
-	AgentVersionConstraint string             `yaml:"agent_version"`
-	Disabled               bool               `yaml:"disabled"`
-	Combine                CombinePolicy      `yaml:"combine"`
-	Actions                []ActionDefinition `yaml:"actions"`
+	ID                     RuleID              `yaml:"id"`
+	Version                string              `yaml:"version"`
+	Expression             string              `yaml:"expression"`
+	Description            string              `yaml:"description"`
+	Tags                   map[string]string   `yaml:"tags"`
+	AgentVersionConstraint string              `yaml:"agent_version"`
+	Disabled               bool                `yaml:"disabled"`
+	Combine                CombinePolicy       `yaml:"combine"`
+	Actions                []ActionDefinition  `yaml:"actions"`
+	Throttle               *ThrottleDefinition `yaml:"throttle"`
 	Policy                 *Policy
 }
 
@@ -172,6 +174,13 @@ type RuleSet struct {
 	fields []string
 	logger log.Logger
 	pool   *eval.ContextPool
+
+	// suppressions and throttlers prevent matches from being notified
+	suppressions         map[RuleID][]*Suppression
+	throttlers           map[RuleID]*throttler
+	suppressionStatsLock sync.Mutex
+	suppressionStats     map[RuleID]*SuppressionStats
+	now                  func() time.Time
 }
 
 func (rs *RuleSet) replCtx() eval.ReplacementContext {
@@ -352,6 +361,14 @@ func (rs *RuleSet) AddRule(ruleDef *RuleDefinition) (*eval.Rule, error) {
 	// Merge the fields of the new rule with the existing list of fields of the ruleset
 	rs.AddFields(rule.GetEvaluator().GetFields())
 
+	if ruleDef.Throttle != nil {
+		throttler, err := newThrottler(ruleDef.Throttle, rs.model)
+		if err != nil {
+			return nil, &ErrRuleLoad{Definition: ruleDef, Err: err}
+		}
+		rs.throttlers[ruleDef.ID] = throttler
+	}
+
 	rs.rules[ruleDef.ID] = rule
 
 	// Generate evaluator for fields that are used in variables
@@ -547,7 +564,9 @@ func (rs *RuleSet) Evaluate(event eval.Event) bool {
 				rs.logger.Tracef("Rule `%s` matches with event `%s`\n", rule.ID, event)
 			}
 
-			rs.NotifyRuleMatch(rule, event)
+			if rs.shouldNotify(ctx, rule) {
+				rs.NotifyRuleMatch(rule, event)
+			}
 			result = true
 
 			if err := rs.runRuleActions(ctx, rule); err != nil {
@@ -625,11 +644,13 @@ func (rs *RuleSet) generatePartials() error {
 // LoadPolicies loads policies from the provided policy loader
 func (rs *RuleSet) LoadPolicies(loader *PolicyLoader, opts PolicyLoaderOpts) *multierror.Error {
 	var (
-		errs       *multierror.Error
-		allRules   []*RuleDefinition
-		allMacros  []*MacroDefinition
-		macroIndex = make(map[string]*MacroDefinition)
-		ruleIndex  = make(map[string]*RuleDefinition)
+		errs             *multierror.Error
+		allRules         []*RuleDefinition
+		allMacros        []*MacroDefinition
+		allSuppressions  []*SuppressionDefinition
+		macroIndex       = make(map[string]*MacroDefinition)
+		ruleIndex        = make(map[string]*RuleDefinition)
+		suppressionIndex = make(map[string]*SuppressionDefinition)
 	)
 
 	policies, err := loader.LoadPolicies(opts)
@@ -660,6 +681,15 @@ func (rs *RuleSet) LoadPolicies(loader *PolicyLoader, opts PolicyLoaderOpts) *mu
 				allRules = append(allRules, rule)
 			}
 		}
+
+		for _, suppression := range policy.Suppressions {
+			if suppressionIndex[suppression.ID] != nil {
+				errs = multierror.Append(errs, &ErrSuppressionLoad{Definition: suppression, Err: ErrDefinitionIDConflict})
+			} else {
+				suppressionIndex[suppression.ID] = suppression
+				allSuppressions = append(allSuppressions, suppression)
+			}
+		}
 	}
 
 	// Add the macros to the ruleset and generate macros evaluators
@@ -776,6 +806,11 @@ func (rs *RuleSet) LoadPolicies(loader *PolicyLoader, opts PolicyLoaderOpts) *mu
 		errs = multierror.Append(errs, err)
 	}
 
+	// Attach the suppressions to the rules
+	if err := rs.AddSuppressions(allSuppressions); err.ErrorOrNil() != nil {
+		errs = multierror.Append(errs, err)
+	}
+
 	return errs
 }
 
@@ -795,5 +830,9 @@ func NewRuleSet(model eval.Model, eventCtor func() eval.Event, opts *Opts, evalO
 		pool:             eval.NewContextPool(),
 		fieldEvaluators:  make(map[string]eval.Evaluator),
 		scopedVariables:  make(map[Scope]VariableProvider),
+		suppressions:     make(map[RuleID][]*Suppression),
+		throttlers:       make(map[RuleID]*throttler),
+		suppressionStats: make(map[RuleID]*SuppressionStats),
+		now:              time.Now,
 	}
 }
@@ -0,0 +1,261 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/golang-lru/simplelru"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
+)
+
+const (
+	// maxThrottleKeys is the number of keys for which a throttled rule
+	// remembers the last time it fired. Least recently fired keys are
+	// forgotten first, which makes them fire again.
+	maxThrottleKeys = 4096
+)
+
+// SuppressionID represents the ID of a suppression
+type SuppressionID = string
+
+// SuppressionDefinition holds the definition of a suppression. The events
+// matching both one of the rules and the expression of a suppression, during
+// its time window, are not notified. The expression can use any field, such
+// as `process.ancestors.file.path` for a process tree, `container.tags` for
+// a container image, or a path glob.
+type SuppressionDefinition struct {
+	ID          SuppressionID `yaml:"id"`
+	Description string        `yaml:"description"`
+	Rules       []RuleID      `yaml:"rules"`
+	Expression  string        `yaml:"expression"`
+	// From and Until bound the time window of the suppression, a zero value
+	// meaning that the window isn't bounded
+	From   time.Time `yaml:"from"`
+	Until  time.Time `yaml:"until"`
+	Policy *Policy   `yaml:"-"`
+}
+
+// Check returns an error if the suppression is invalid
+func (sd *SuppressionDefinition) Check() error {
+	if len(sd.Rules) == 0 {
+		return errors.New("no rule defined")
+	}
+	if sd.Expression == "" {
+		return errors.New("no expression defined")
+	}
+	if !sd.From.IsZero() && !sd.Until.IsZero() && !sd.Until.After(sd.From) {
+		return errors.New("`until` must be after `from`")
+	}
+	return nil
+}
+
+// isActive returns whether now is in the time window of the suppression
+func (sd *SuppressionDefinition) isActive(now time.Time) bool {
+	return (sd.From.IsZero() || !now.Before(sd.From)) && (sd.Until.IsZero() || now.Before(sd.Until))
+}
+
+// ThrottleDefinition describes the throttling of a rule, which then fires at
+// most once per window for each distinct value of the given fields
+type ThrottleDefinition struct {
+	Fields []eval.Field  `yaml:"fields"`
+	Window time.Duration `yaml:"window"`
+}
+
+// Suppression describes a suppression of a ruleset
+type Suppression struct {
+	*eval.Rule
+	Definition *SuppressionDefinition
+}
+
+// throttler keeps the last time a rule fired for each key
+type throttler struct {
+	sync.Mutex
+	window     time.Duration
+	evaluators []eval.Evaluator
+	lastFired  *simplelru.LRU
+}
+
+func newThrottler(def *ThrottleDefinition, model eval.Model) (*throttler, error) {
+	if def.Window <= 0 {
+		return nil, errors.New("throttle window must be positive")
+	}
+
+	lastFired, err := simplelru.NewLRU(maxThrottleKeys, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &throttler{
+		window:    def.Window,
+		lastFired: lastFired,
+	}
+
+	for _, field := range def.Fields {
+		evaluator, err := model.GetEvaluator(field, "")
+		if err != nil {
+			return nil, fmt.Errorf("invalid throttle field `%s`: %w", field, err)
+		}
+		t.evaluators = append(t.evaluators, evaluator)
+	}
+
+	return t, nil
+}
+
+// allow returns whether the rule can fire for the key of the event
+func (t *throttler) allow(ctx *eval.Context, now time.Time) bool {
+	values := make([]string, 0, len(t.evaluators))
+	for _, evaluator := range t.evaluators {
+		values = append(values, fmt.Sprintf("%v", evaluator.Eval(ctx)))
+	}
+	key := strings.Join(values, "\x00")
+
+	t.Lock()
+	defer t.Unlock()
+
+	if last, ok := t.lastFired.Get(key); ok && now.Sub(last.(time.Time)) < t.window {
+		return false
+	}
+	t.lastFired.Add(key, now)
+	return true
+}
+
+// SuppressionStats describes the events which matched a rule, but weren't
+// notified
+type SuppressionStats struct {
+	// Suppressed holds the number of suppressed events by suppression
+	Suppressed map[SuppressionID]int64
+	Throttled  int64
+}
+
+// AddSuppressions compiles the suppressions and attaches them to their rules
+func (rs *RuleSet) AddSuppressions(suppressions []*SuppressionDefinition) *multierror.Error {
+	var result *multierror.Error
+
+	for _, suppressionDef := range suppressions {
+		if _, err := rs.AddSuppression(suppressionDef); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+
+	return result
+}
+
+// AddSuppression compiles a suppression and attaches it to its rules
+func (rs *RuleSet) AddSuppression(suppressionDef *SuppressionDefinition) (*Suppression, error) {
+	if err := suppressionDef.Check(); err != nil {
+		return nil, &ErrSuppressionLoad{Definition: suppressionDef, Err: err}
+	}
+
+	suppression := &Suppression{
+		Rule: &eval.Rule{
+			ID:         suppressionDef.ID,
+			Expression: suppressionDef.Expression,
+		},
+		Definition: suppressionDef,
+	}
+
+	if err := suppression.Parse(); err != nil {
+		return nil, &ErrSuppressionLoad{Definition: suppressionDef, Err: fmt.Errorf("syntax error: %w", err)}
+	}
+
+	if err := suppression.GenEvaluator(rs.model, rs.replCtx()); err != nil {
+		return nil, &ErrSuppressionLoad{Definition: suppressionDef, Err: err}
+	}
+
+	eventTypes, err := suppression.GetEventTypes()
+	if err != nil {
+		return nil, &ErrSuppressionLoad{Definition: suppressionDef, Err: err}
+	}
+
+	for _, id := range suppressionDef.Rules {
+		rule, exists := rs.rules[id]
+		if !exists {
+			return nil, &ErrSuppressionLoad{Definition: suppressionDef, Err: fmt.Errorf("unknown rule `%s`", id)}
+		}
+
+		// the fields specific to an event type are only set for the events of
+		// this type
+		if len(eventTypes) > 0 {
+			ruleEventType, err := GetRuleEventType(rule.Rule)
+			if err != nil {
+				return nil, &ErrSuppressionLoad{Definition: suppressionDef, Err: err}
+			}
+
+			for _, eventType := range eventTypes {
+				if eventType != ruleEventType {
+					return nil, &ErrSuppressionLoad{Definition: suppressionDef, Err: fmt.Errorf("event type `%s` doesn't match the one of rule `%s`", eventType, id)}
+				}
+			}
+		}
+	}
+
+	for _, id := range suppressionDef.Rules {
+		rs.suppressions[id] = append(rs.suppressions[id], suppression)
+	}
+
+	return suppression, nil
+}
+
+// shouldNotify returns whether a match of the rule has to be notified to the
+// listeners, and counts the ones which aren't
+func (rs *RuleSet) shouldNotify(ctx *eval.Context, rule *Rule) bool {
+	suppressions, throttler := rs.suppressions[rule.ID], rs.throttlers[rule.ID]
+	if len(suppressions) == 0 && throttler == nil {
+		return true
+	}
+
+	now := rs.now()
+	for _, suppression := range suppressions {
+		if suppression.Definition.isActive(now) && suppression.GetEvaluator().Eval(ctx) {
+			rs.countSuppressed(rule.ID, suppression.ID)
+			return false
+		}
+	}
+
+	if throttler != nil && !throttler.allow(ctx, now) {
+		rs.countSuppressed(rule.ID, "")
+		return false
+	}
+
+	return true
+}
+
+// countSuppressed counts an event suppressed by the given suppression, or
+// throttled if no suppression is given
+func (rs *RuleSet) countSuppressed(ruleID RuleID, suppressionID SuppressionID) {
+	rs.suppressionStatsLock.Lock()
+	defer rs.suppressionStatsLock.Unlock()
+
+	stats, exists := rs.suppressionStats[ruleID]
+	if !exists {
+		stats = &SuppressionStats{Suppressed: make(map[SuppressionID]int64)}
+		rs.suppressionStats[ruleID] = stats
+	}
+
+	if suppressionID == "" {
+		stats.Throttled++
+	} else {
+		stats.Suppressed[suppressionID]++
+	}
+}
+
+// GetSuppressionStats returns the events suppressed or throttled since the
+// last call, by rule
+func (rs *RuleSet) GetSuppressionStats() map[RuleID]*SuppressionStats {
+	rs.suppressionStatsLock.Lock()
+	defer rs.suppressionStatsLock.Unlock()
+
+	stats := rs.suppressionStats
+	rs.suppressionStats = make(map[RuleID]*SuppressionStats)
+	return stats
+}
@@ -0,0 +1,183 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package rules
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
+)
+
+type testMatchListener struct {
+	matches int
+}
+
+func (l *testMatchListener) RuleMatch(rule *Rule, event eval.Event) {
+	l.matches++
+}
+
+func (l *testMatchListener) EventDiscarderFound(rs *RuleSet, event eval.Event, field eval.Field, eventType eval.EventType) {
+}
+
+func newOpenEvent(filename, processName string) *testEvent {
+	return &testEvent{
+		kind: "open",
+		open: testOpen{
+			filename: filename,
+		},
+		process: testProcess{
+			name: processName,
+		},
+	}
+}
+
+func TestSuppression(t *testing.T) {
+	now := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	testPolicy := &PolicyDef{
+		Rules: []*RuleDefinition{{
+			ID:         "test_rule",
+			Expression: `open.filename =~ "/etc/*"`,
+		}},
+		Suppressions: []*SuppressionDefinition{
+			{
+				ID:         "backup",
+				Rules:      []RuleID{"test_rule"},
+				Expression: `process.name == "backup"`,
+			},
+			{
+				ID:         "maintenance",
+				Rules:      []RuleID{"test_rule"},
+				Expression: `open.filename =~ "/etc/apt*"`,
+				From:       now.Add(-time.Hour),
+				Until:      now.Add(time.Hour),
+			},
+		},
+	}
+
+	rs, err := loadPolicy(t, testPolicy, PolicyLoaderOpts{})
+	assert.Nil(t, err.ErrorOrNil())
+	rs.now = func() time.Time { return now }
+
+	listener := &testMatchListener{}
+	rs.AddListener(listener)
+
+	assert.True(t, rs.Evaluate(newOpenEvent("/etc/passwd", "backup")))
+	assert.Equal(t, 0, listener.matches)
+
+	assert.True(t, rs.Evaluate(newOpenEvent("/etc/apt.conf", "apt")))
+	assert.Equal(t, 0, listener.matches)
+
+	assert.True(t, rs.Evaluate(newOpenEvent("/etc/passwd", "cat")))
+	assert.Equal(t, 1, listener.matches)
+
+	// out of the time window of the maintenance
+	rs.now = func() time.Time { return now.Add(2 * time.Hour) }
+	assert.True(t, rs.Evaluate(newOpenEvent("/etc/apt.conf", "apt")))
+	assert.Equal(t, 2, listener.matches)
+
+	stats := rs.GetSuppressionStats()
+	assert.Equal(t, map[SuppressionID]int64{"backup": 1, "maintenance": 1}, stats["test_rule"].Suppressed)
+	assert.Empty(t, rs.GetSuppressionStats())
+}
+
+func TestSuppressionErrors(t *testing.T) {
+	testPolicy := &PolicyDef{
+		Rules: []*RuleDefinition{{
+			ID:         "test_rule",
+			Expression: `open.filename =~ "/etc/*"`,
+		}},
+		Suppressions: []*SuppressionDefinition{
+			{
+				ID:         "unknown_rule",
+				Rules:      []RuleID{"unknown"},
+				Expression: `process.name == "backup"`,
+			},
+			{
+				ID:         "event_type",
+				Rules:      []RuleID{"test_rule"},
+				Expression: `mkdir.filename == "/etc/foo"`,
+			},
+			{
+				ID:    "no_expression",
+				Rules: []RuleID{"test_rule"},
+			},
+		},
+	}
+
+	rs, err := loadPolicy(t, testPolicy, PolicyLoaderOpts{})
+	assert.NotNil(t, err)
+	assert.Len(t, err.Errors, 3)
+	assert.ErrorContains(t, err.Errors[0], "suppression `unknown_rule` definition error: unknown rule `unknown`")
+	assert.ErrorContains(t, err.Errors[1], "suppression `event_type` definition error: event type `mkdir` doesn't match")
+	assert.ErrorContains(t, err.Errors[2], "suppression `no_expression` definition error: no expression defined")
+
+	assert.Contains(t, rs.rules, "test_rule")
+	assert.Empty(t, rs.suppressions)
+}
+
+func TestThrottle(t *testing.T) {
+	now := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	testPolicy := &PolicyDef{
+		Rules: []*RuleDefinition{{
+			ID:         "test_rule",
+			Expression: `open.filename =~ "/etc/*"`,
+			Throttle: &ThrottleDefinition{
+				Fields: []eval.Field{"process.name"},
+				Window: 5 * time.Minute,
+			},
+		}},
+	}
+
+	rs, err := loadPolicy(t, testPolicy, PolicyLoaderOpts{})
+	assert.Nil(t, err.ErrorOrNil())
+	rs.now = func() time.Time { return now }
+
+	listener := &testMatchListener{}
+	rs.AddListener(listener)
+
+	rs.Evaluate(newOpenEvent("/etc/passwd", "cat"))
+	rs.Evaluate(newOpenEvent("/etc/shadow", "cat"))
+	rs.Evaluate(newOpenEvent("/etc/passwd", "vim"))
+	assert.Equal(t, 2, listener.matches)
+
+	rs.now = func() time.Time { return now.Add(5 * time.Minute) }
+	rs.Evaluate(newOpenEvent("/etc/passwd", "cat"))
+	assert.Equal(t, 3, listener.matches)
+
+	stats := rs.GetSuppressionStats()
+	assert.Equal(t, int64(1), stats["test_rule"].Throttled)
+}
+
+func TestThrottleErrors(t *testing.T) {
+	testPolicy := &PolicyDef{
+		Rules: []*RuleDefinition{
+			{
+				ID:         "invalid_field",
+				Expression: `open.filename =~ "/etc/*"`,
+				Throttle: &ThrottleDefinition{
+					Fields: []eval.Field{"process.unknown"},
+					Window: time.Minute,
+				},
+			},
+			{
+				ID:         "no_window",
+				Expression: `open.filename =~ "/etc/*"`,
+				Throttle:   &ThrottleDefinition{},
+			},
+		},
+	}
+
+	_, err := loadPolicy(t, testPolicy, PolicyLoaderOpts{})
+	assert.NotNil(t, err)
+	assert.Len(t, err.Errors, 2)
+	assert.ErrorContains(t, err.Errors[0], "invalid throttle field `process.unknown`")
+	assert.ErrorContains(t, err.Errors[1], "throttle window must be positive")
+}
@@ -0,0 +1,236 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
+)
+
+// TestEvent describes a synthetic event, the fields being set through the
+// SetFieldValue method of the event. The values of array fields are given
+// as lists.
+type TestEvent struct {
+	Type   eval.EventType         `yaml:"type"`
+	Fields map[string]interface{} `yaml:"fields"`
+}
+
+// TestCase describes the rules expected to match, or not to match, at least
+// one of a sequence of events
+type TestCase struct {
+	Name    string       `yaml:"name"`
+	Events  []*TestEvent `yaml:"events"`
+	Match   []RuleID     `yaml:"match"`
+	NoMatch []RuleID     `yaml:"no_match"`
+}
+
+// TestSuite holds the test cases of a set of rules
+type TestSuite struct {
+	Name  string      `yaml:"-"`
+	Tests []*TestCase `yaml:"tests"`
+}
+
+// LoadTestSuite reads a test suite from a YAML reader
+func LoadTestSuite(name string, reader io.Reader) (*TestSuite, error) {
+	var suite TestSuite
+
+	decoder := yaml.NewDecoder(reader)
+	if err := decoder.Decode(&suite); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("test suite `%s` is empty", name)
+		}
+		return nil, fmt.Errorf("failed to parse test suite `%s`: %w", name, err)
+	}
+	suite.Name = name
+
+	for i, test := range suite.Tests {
+		if test.Name == "" {
+			return nil, fmt.Errorf("test %d of suite `%s` has no name", i, name)
+		}
+		if len(test.Events) == 0 {
+			return nil, fmt.Errorf("test `%s` of suite `%s` has no event", test.Name, name)
+		}
+		if len(test.Match) == 0 && len(test.NoMatch) == 0 {
+			return nil, fmt.Errorf("test `%s` of suite `%s` has no expectation", test.Name, name)
+		}
+	}
+
+	return &suite, nil
+}
+
+// LoadTestSuiteFromFile reads a test suite from a YAML file
+func LoadTestSuiteFromFile(filename string) (*TestSuite, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return LoadTestSuite(filename, f)
+}
+
+// TestEventFactory returns a new event of the given type
+type TestEventFactory func(eventType eval.EventType) (eval.Event, error)
+
+// TestCaseReport describes the result of a test case
+type TestCaseReport struct {
+	Suite             string
+	Name              string
+	Succeeded         bool
+	Matched           []RuleID
+	MissingMatches    []RuleID `json:",omitempty"`
+	UnexpectedMatches []RuleID `json:",omitempty"`
+	Error             string   `json:",omitempty"`
+}
+
+// TestCoverage describes the rules for which at least one test case expects
+// a match, and got it
+type TestCoverage struct {
+	Rules          int
+	CoveredRules   int
+	Percent        float64
+	UncoveredRules []RuleID
+}
+
+// TestReport describes the result of test suites
+type TestReport struct {
+	Succeeded bool
+	Failures  int
+	Tests     []*TestCaseReport
+	Coverage  TestCoverage
+}
+
+// testListener records the rules matched by the events of a test case
+type testListener struct {
+	matched map[RuleID]bool
+}
+
+func (l *testListener) RuleMatch(rule *Rule, event eval.Event) {
+	l.matched[rule.ID] = true
+}
+
+func (l *testListener) EventDiscarderFound(rs *RuleSet, event eval.Event, field eval.Field, eventType eval.EventType) {
+}
+
+// RunTestSuites evaluates the events of the test cases of suites, and checks
+// the rules they matched against the expectations. The rule set should be
+// dedicated to the tests, as the variables set by rule actions are kept from
+// one test case to the next.
+func (rs *RuleSet) RunTestSuites(suites []*TestSuite, newEvent TestEventFactory) *TestReport {
+	listener := &testListener{}
+	rs.AddListener(listener)
+
+	report := &TestReport{Succeeded: true}
+	covered := make(map[RuleID]bool)
+
+	for _, suite := range suites {
+		for _, test := range suite.Tests {
+			listener.matched = make(map[RuleID]bool)
+
+			testReport := rs.runTestCase(test, listener, newEvent)
+			testReport.Suite = suite.Name
+			if testReport.Succeeded {
+				for _, id := range test.Match {
+					covered[id] = true
+				}
+			} else {
+				report.Succeeded = false
+				report.Failures++
+			}
+
+			report.Tests = append(report.Tests, testReport)
+		}
+	}
+
+	report.Coverage.Rules = len(rs.rules)
+	for id := range rs.rules {
+		if covered[id] {
+			report.Coverage.CoveredRules++
+		} else {
+			report.Coverage.UncoveredRules = append(report.Coverage.UncoveredRules, id)
+		}
+	}
+	sort.Strings(report.Coverage.UncoveredRules)
+	if report.Coverage.Rules > 0 {
+		report.Coverage.Percent = 100 * float64(report.Coverage.CoveredRules) / float64(report.Coverage.Rules)
+	}
+
+	return report
+}
+
+func (rs *RuleSet) runTestCase(test *TestCase, listener *testListener, newEvent TestEventFactory) *TestCaseReport {
+	report := &TestCaseReport{Name: test.Name}
+
+	for _, ids := range [][]RuleID{test.Match, test.NoMatch} {
+		for _, id := range ids {
+			if _, exists := rs.rules[id]; !exists {
+				report.Error = fmt.Sprintf("unknown rule `%s`", id)
+				return report
+			}
+		}
+	}
+
+	for i, testEvent := range test.Events {
+		event, err := newEvent(testEvent.Type)
+		if err != nil {
+			report.Error = fmt.Sprintf("event %d: %s", i, err)
+			return report
+		}
+
+		for field, value := range testEvent.Fields {
+			if err := setTestFieldValue(event, field, value); err != nil {
+				report.Error = fmt.Sprintf("event %d: failed to set field `%s`: %s", i, field, err)
+				return report
+			}
+		}
+
+		rs.Evaluate(event)
+	}
+
+	for id := range listener.matched {
+		report.Matched = append(report.Matched, id)
+	}
+	sort.Strings(report.Matched)
+
+	for _, id := range test.Match {
+		if !listener.matched[id] {
+			report.MissingMatches = append(report.MissingMatches, id)
+		}
+	}
+	for _, id := range test.NoMatch {
+		if listener.matched[id] {
+			report.UnexpectedMatches = append(report.UnexpectedMatches, id)
+		}
+	}
+
+	report.Succeeded = len(report.MissingMatches) == 0 && len(report.UnexpectedMatches) == 0
+	return report
+}
+
+// setTestFieldValue sets the value of a field, the elements of a list being
+// set one by one as it's how array fields are appended to
+func setTestFieldValue(event eval.Event, field eval.Field, value interface{}) error {
+	switch value := value.(type) {
+	case []interface{}:
+		for _, v := range value {
+			if err := setTestFieldValue(event, field, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	case nil:
+		return fmt.Errorf("no value")
+	}
+
+	return event.SetFieldValue(field, value)
+}
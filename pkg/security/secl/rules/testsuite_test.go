@@ -0,0 +1,117 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package rules
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
+)
+
+const testSuiteYAML = `
+tests:
+  - name: open in sbin
+    events:
+      - type: open
+        fields:
+          open.filename: /sbin/foo
+          process.uid: 1000
+    match: [ID0]
+    no_match: [ID1]
+  - name: root open
+    events:
+      - type: open
+        fields:
+          open.filename: /sbin/foo
+          process.uid: 0
+    no_match: [ID0]
+  - name: wrong expectation
+    events:
+      - type: open
+        fields:
+          open.filename: /etc/foo
+          process.uid: 1000
+    match: [ID0]
+`
+
+func newTestEvent(eventType eval.EventType) (eval.Event, error) {
+	return &testEvent{kind: eventType}, nil
+}
+
+func TestRunTestSuites(t *testing.T) {
+	rs := newRuleSet()
+	addRuleExpr(t, rs,
+		`open.filename =~ "/sbin/*" && process.uid != 0`,
+		`mkdir.filename =~ "/sbin/*"`,
+	)
+
+	suite, err := LoadTestSuite("suite", strings.NewReader(testSuiteYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := rs.RunTestSuites([]*TestSuite{suite}, newTestEvent)
+	assert.False(t, report.Succeeded)
+	assert.Equal(t, 1, report.Failures)
+	assert.Len(t, report.Tests, 3)
+
+	assert.True(t, report.Tests[0].Succeeded)
+	assert.Equal(t, "suite", report.Tests[0].Suite)
+	assert.Equal(t, []RuleID{"ID0"}, report.Tests[0].Matched)
+	assert.True(t, report.Tests[1].Succeeded)
+	assert.Empty(t, report.Tests[1].Matched)
+	assert.False(t, report.Tests[2].Succeeded)
+	assert.Equal(t, []RuleID{"ID0"}, report.Tests[2].MissingMatches)
+
+	assert.Equal(t, TestCoverage{
+		Rules:          2,
+		CoveredRules:   1,
+		Percent:        50,
+		UncoveredRules: []RuleID{"ID1"},
+	}, report.Coverage)
+}
+
+func TestRunTestSuitesErrors(t *testing.T) {
+	rs := newRuleSet()
+	addRuleExpr(t, rs, `open.filename == "/etc/passwd"`)
+
+	suite := &TestSuite{
+		Tests: []*TestCase{
+			{
+				Name:   "unknown rule",
+				Events: []*TestEvent{{Type: "open"}},
+				Match:  []RuleID{"unknown"},
+			},
+			{
+				Name:   "unknown field",
+				Events: []*TestEvent{{Type: "open", Fields: map[string]interface{}{"open.unknown": "foo"}}},
+				Match:  []RuleID{"ID0"},
+			},
+		},
+	}
+
+	report := rs.RunTestSuites([]*TestSuite{suite}, newTestEvent)
+	assert.False(t, report.Succeeded)
+	assert.Equal(t, 2, report.Failures)
+	assert.Contains(t, report.Tests[0].Error, "unknown rule")
+	assert.Contains(t, report.Tests[1].Error, "open.unknown")
+}
+
+func TestLoadTestSuite(t *testing.T) {
+	_, err := LoadTestSuite("suite", strings.NewReader(""))
+	assert.Error(t, err)
+
+	_, err = LoadTestSuite("suite", strings.NewReader(`
+tests:
+  - name: no expectation
+    events:
+      - type: open
+`))
+	assert.ErrorContains(t, err, "no expectation")
+}
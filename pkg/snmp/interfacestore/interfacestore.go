@@ -0,0 +1,88 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+// Package interfacestore shares the interfaces of the devices monitored by the SNMP
+// check with the other network devices components, like the NetFlow collector
+package interfacestore
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultTTL is the duration the interfaces of a device are kept after their last
+// update, it's large enough to cover a few runs of slow SNMP checks
+const DefaultTTL = 1 * time.Hour
+
+var timeNow = time.Now
+
+// DefaultStore is the store filled by the SNMP check
+var DefaultStore = NewStore(DefaultTTL)
+
+// Interface contains the details of a device interface
+type Interface struct {
+	Index int32
+	Name  string
+	Alias string
+	// Speed is the bandwidth of the interface in bits per second, 0 when unknown
+	Speed uint64
+}
+
+type deviceInterfaces struct {
+	interfaces map[int32]Interface
+	updatedAt  time.Time
+}
+
+// Store holds the interfaces of devices, by namespace and IP address
+type Store struct {
+	mu      sync.RWMutex
+	devices map[string]deviceInterfaces
+	ttl     time.Duration
+}
+
+// NewStore returns a new Store, devices not updated for ttl are ignored and removed
+func NewStore(ttl time.Duration) *Store {
+	return &Store{
+		devices: make(map[string]deviceInterfaces),
+		ttl:     ttl,
+	}
+}
+
+func deviceKey(namespace string, ipAddress string) string {
+	return namespace + ":" + ipAddress
+}
+
+// SetDeviceInterfaces replaces the interfaces of a device
+func (s *Store) SetDeviceInterfaces(namespace string, ipAddress string, interfaces []Interface) {
+	byIndex := make(map[int32]Interface, len(interfaces))
+	for _, iface := range interfaces {
+		byIndex[iface.Index] = iface
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := timeNow()
+	s.devices[deviceKey(namespace, ipAddress)] = deviceInterfaces{
+		interfaces: byIndex,
+		updatedAt:  now,
+	}
+	for key, device := range s.devices {
+		if now.Sub(device.updatedAt) > s.ttl {
+			delete(s.devices, key)
+		}
+	}
+}
+
+// GetInterface returns the interface of a device by its index (ifIndex)
+func (s *Store) GetInterface(namespace string, ipAddress string, index int32) (Interface, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	device, ok := s.devices[deviceKey(namespace, ipAddress)]
+	if !ok || timeNow().Sub(device.updatedAt) > s.ttl {
+		return Interface{}, false
+	}
+	iface, ok := device.interfaces[index]
+	return iface, ok
+}
@@ -0,0 +1,49 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package interfacestore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStore(t *testing.T) {
+	now := time.Now()
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	store := NewStore(time.Minute)
+	store.SetDeviceInterfaces("default", "10.0.0.1", []Interface{
+		{Index: 1, Name: "Gi0/1", Alias: "uplink-to-core", Speed: 1000000000},
+		{Index: 2, Name: "Gi0/2"},
+	})
+
+	iface, ok := store.GetInterface("default", "10.0.0.1", 1)
+	assert.True(t, ok)
+	assert.Equal(t, Interface{Index: 1, Name: "Gi0/1", Alias: "uplink-to-core", Speed: 1000000000}, iface)
+
+	_, ok = store.GetInterface("default", "10.0.0.1", 3)
+	assert.False(t, ok)
+	_, ok = store.GetInterface("other", "10.0.0.1", 1)
+	assert.False(t, ok)
+
+	// interfaces are replaced
+	store.SetDeviceInterfaces("default", "10.0.0.1", []Interface{{Index: 3, Name: "Gi0/3"}})
+	_, ok = store.GetInterface("default", "10.0.0.1", 1)
+	assert.False(t, ok)
+	iface, ok = store.GetInterface("default", "10.0.0.1", 3)
+	assert.True(t, ok)
+	assert.Equal(t, "Gi0/3", iface.Name)
+
+	// expired devices are ignored, then removed on the next update
+	now = now.Add(2 * time.Minute)
+	_, ok = store.GetInterface("default", "10.0.0.1", 3)
+	assert.False(t, ok)
+	store.SetDeviceInterfaces("default", "10.0.0.2", nil)
+	assert.Len(t, store.devices, 1)
+}
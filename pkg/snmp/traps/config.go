@@ -35,14 +35,15 @@ type UserV3 struct {
 // Config contains configuration for SNMP trap listeners.
 // YAML field tags provided for test marshalling purposes.
 type Config struct {
-	Enabled               bool     `mapstructure:"enabled" yaml:"enabled"`
-	Port                  uint16   `mapstructure:"port" yaml:"port"`
-	Users                 []UserV3 `mapstructure:"users" yaml:"users"`
-	CommunityStrings      []string `mapstructure:"community_strings" yaml:"community_strings"`
-	BindHost              string   `mapstructure:"bind_host" yaml:"bind_host"`
-	StopTimeout           int      `mapstructure:"stop_timeout" yaml:"stop_timeout"`
-	Namespace             string   `mapstructure:"namespace" yaml:"namespace"`
-	authoritativeEngineID string   `mapstructure:"-" yaml:"-"`
+	Enabled               bool         `mapstructure:"enabled" yaml:"enabled"`
+	Port                  uint16       `mapstructure:"port" yaml:"port"`
+	Users                 []UserV3     `mapstructure:"users" yaml:"users"`
+	CommunityStrings      []string     `mapstructure:"community_strings" yaml:"community_strings"`
+	BindHost              string       `mapstructure:"bind_host" yaml:"bind_host"`
+	StopTimeout           int          `mapstructure:"stop_timeout" yaml:"stop_timeout"`
+	Namespace             string       `mapstructure:"namespace" yaml:"namespace"`
+	Metrics               []MetricRule `mapstructure:"metrics" yaml:"metrics"`
+	authoritativeEngineID string       `mapstructure:"-" yaml:"-"`
 }
 
 // ReadConfig builds and returns configuration from Agent configuration.
@@ -95,6 +96,12 @@ func ReadConfig(agentHostname string) (*Config, error) {
 		return nil, fmt.Errorf("unable to load config: %w", err)
 	}
 
+	for i := range c.Metrics {
+		if err := c.Metrics[i].validate(); err != nil {
+			return nil, fmt.Errorf("invalid trap metric rule: %w", err)
+		}
+	}
+
 	return &c, nil
 }
 
@@ -150,3 +150,32 @@ func TestNamespaceSetBothGloballyAndLocally(t *testing.T) {
 
 	assert.Equal(t, "bar", config.Namespace)
 }
+
+func TestMetricsConfig(t *testing.T) {
+	Configure(t, Config{
+		Metrics: []MetricRule{{
+			TrapOID: ".1.3.6.1.6.3.1.1.5.3",
+			Count:   "link_down",
+			Tags:    []VarbindTag{{OID: ".1.3.6.1.2.1.2.2.1.1", Tag: "interface_index"}},
+			Gauges:  []VarbindGauge{{OID: "1.3.6.1.2.1.2.2.1.8", Metric: "link_down.oper_status"}},
+		}},
+	})
+
+	config, err := ReadConfig("")
+	assert.NoError(t, err)
+	assert.Equal(t, []MetricRule{{
+		TrapOID: "1.3.6.1.6.3.1.1.5.3",
+		Count:   "link_down",
+		Tags:    []VarbindTag{{OID: "1.3.6.1.2.1.2.2.1.1", Tag: "interface_index"}},
+		Gauges:  []VarbindGauge{{OID: "1.3.6.1.2.1.2.2.1.8", Metric: "link_down.oper_status"}},
+	}}, config.Metrics)
+}
+
+func TestInvalidMetricsConfig(t *testing.T) {
+	Configure(t, Config{
+		Metrics: []MetricRule{{TrapOID: "linkDown"}},
+	})
+
+	_, err := ReadConfig("")
+	assert.ErrorContains(t, err, "invalid trap OID `linkDown`")
+}
@@ -91,9 +91,14 @@ func (f JSONFormatter) FormatPacket(packet *SnmpPacket) ([]byte, error) {
 
 // GetTags returns a list of tags associated to an SNMP trap packet.
 func (f JSONFormatter) getTags(packet *SnmpPacket) []string {
+	return getPacketTags(packet, f.namespace)
+}
+
+// getPacketTags returns the tags of the device and the SNMP version of a packet
+func getPacketTags(packet *SnmpPacket, namespace string) []string {
 	return []string{
 		"snmp_version:" + formatVersion(packet.Content),
-		"device_namespace:" + f.namespace,
+		"device_namespace:" + namespace,
 		"snmp_device:" + packet.Addr.IP.String(),
 	}
 }
@@ -104,14 +109,7 @@ func (f JSONFormatter) formatV1Trap(packet *gosnmp.SnmpPacket) map[string]interf
 	enterpriseOid := NormalizeOID(packet.Enterprise)
 	genericTrap := packet.GenericTrap
 	specificTrap := packet.SpecificTrap
-	var trapOID string
-	if genericTrap == 6 {
-		// Vendor-specific trap
-		trapOID = fmt.Sprintf("%s.0.%d", enterpriseOid, specificTrap)
-	} else {
-		// Generic trap
-		trapOID = fmt.Sprintf("%s.%d", genericTrapOid, genericTrap+1)
-	}
+	trapOID := getV1TrapOID(packet)
 	data["snmpTrapOID"] = trapOID
 	trapMetadata, err := f.oidResolver.GetTrapMetadata(trapOID)
 	if err != nil {
@@ -131,6 +129,35 @@ func (f JSONFormatter) formatV1Trap(packet *gosnmp.SnmpPacket) map[string]interf
 	return data
 }
 
+// getV1TrapOID returns the OID of an SNMPv1 trap, from its generic or
+// specific trap type
+func getV1TrapOID(packet *gosnmp.SnmpPacket) string {
+	if packet.GenericTrap == 6 {
+		// Vendor-specific trap
+		return fmt.Sprintf("%s.0.%d", NormalizeOID(packet.Enterprise), packet.SpecificTrap)
+	}
+	// Generic trap
+	return fmt.Sprintf("%s.%d", genericTrapOid, packet.GenericTrap+1)
+}
+
+// getTrapOIDAndVariables returns the OID of a trap and its additional
+// variables, sysUpTime and snmpTrapOID being excluded for SNMPv2 and v3 traps
+func getTrapOIDAndVariables(packet *gosnmp.SnmpPacket) (string, []gosnmp.SnmpPDU, error) {
+	if packet.Version == gosnmp.Version1 {
+		return getV1TrapOID(packet), packet.Variables, nil
+	}
+
+	if len(packet.Variables) < 2 {
+		return "", nil, fmt.Errorf("expected at least 2 variables, got %d", len(packet.Variables))
+	}
+
+	trapOID, err := parseSnmpTrapOID(packet.Variables[1])
+	if err != nil {
+		return "", nil, err
+	}
+	return trapOID, packet.Variables[2:], nil
+}
+
 func (f JSONFormatter) formatTrap(packet *gosnmp.SnmpPacket) (map[string]interface{}, error) {
 	/*
 		An SNMP v2 or v3 trap packet consists in the following variables (PDUs):
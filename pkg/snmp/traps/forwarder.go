@@ -18,15 +18,18 @@ import (
 type TrapForwarder struct {
 	trapsIn   PacketsChannel
 	formatter Formatter
+	metrics   *TrapMetrics
 	sender    aggregator.Sender
 	stopChan  chan struct{}
 }
 
-// NewTrapForwarder creates a simple TrapForwarder instance
-func NewTrapForwarder(formatter Formatter, sender aggregator.Sender, packets PacketsChannel) (*TrapForwarder, error) {
+// NewTrapForwarder creates a simple TrapForwarder instance. metrics is optional,
+// traps being only forwarded as events when it's nil.
+func NewTrapForwarder(formatter Formatter, metrics *TrapMetrics, sender aggregator.Sender, packets PacketsChannel) (*TrapForwarder, error) {
 	return &TrapForwarder{
 		trapsIn:   packets,
 		formatter: formatter,
+		metrics:   metrics,
 		sender:    sender,
 		stopChan:  make(chan struct{}),
 	}, nil
@@ -56,6 +59,10 @@ func (tf *TrapForwarder) run() {
 }
 
 func (tf *TrapForwarder) sendTrap(packet *SnmpPacket) {
+	if tf.metrics != nil {
+		tf.metrics.SendMetrics(packet, tf.sender)
+	}
+
 	data, err := tf.formatter.FormatPacket(packet)
 	if err != nil {
 		log.Errorf("failed to format packet: %s", err)
@@ -44,7 +44,7 @@ func createForwarder(t *testing.T) (forwarder *TrapForwarder, err error) {
 	packetsIn := make(PacketsChannel)
 	mockSender := mocksender.NewMockSender("snmp-traps-listener")
 	mockSender.SetupAcceptAll()
-	forwarder, err = NewTrapForwarder(&DummyFormatter{}, mockSender, packetsIn)
+	forwarder, err = NewTrapForwarder(&DummyFormatter{}, nil, mockSender, packetsIn)
 	if err != nil {
 		return nil, err
 	}
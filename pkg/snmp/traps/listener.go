@@ -6,8 +6,10 @@
 package traps
 
 import (
+	"errors"
 	"fmt"
 	"net"
+	"sync/atomic"
 	"time"
 
 	"github.com/gosnmp/gosnmp"
@@ -15,76 +17,176 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/trace/log"
 )
 
-// TrapListener opens an UDP socket and put all received traps in a channel
+// usmStatsUnknownEngineIDs is the OID of the counter reported to the senders
+// of v3 packets with an unknown engine ID, see RFC 3414
+const usmStatsUnknownEngineIDs = ".1.3.6.1.6.3.15.1.1.4.0"
+
+// TrapListener opens an UDP socket and put all received traps in a channel.
+// It reads the socket itself rather than using gosnmp.TrapListener, which
+// acknowledges every INFORM request once its handler returns: the ones with
+// invalid credentials must not be acknowledged.
 type TrapListener struct {
-	config        Config
-	packets       PacketsChannel
-	listener      *gosnmp.TrapListener
-	errorsChannel chan error
-	errorLogger   *log.ThrottledLogger
+	config      Config
+	packets     PacketsChannel
+	params      *gosnmp.GoSNMP
+	conn        *net.UDPConn
+	stopped     chan struct{}
+	errorLogger *log.ThrottledLogger
+
+	// number of v3 packets received with an unknown engine ID
+	unknownEngineIDs uint32
 }
 
 // NewTrapListener creates a simple TrapListener instance but does not start it
 func NewTrapListener(config Config, packets PacketsChannel) (*TrapListener, error) {
-	var err error
-	gosnmpListener := gosnmp.NewTrapListener()
-	gosnmpListener.Params, err = config.BuildSNMPParams()
+	params, err := config.BuildSNMPParams()
 	if err != nil {
 		return nil, err
 	}
-	errorsChan := make(chan error, 1)
-	trapListener := &TrapListener{
-		config:        config,
-		packets:       packets,
-		listener:      gosnmpListener,
-		errorsChannel: errorsChan,
-		errorLogger:   log.NewThrottled(5, 10*time.Second),
-	}
-
-	gosnmpListener.OnNewTrap = trapListener.receiveTrap
-	return trapListener, nil
+	return &TrapListener{
+		config:      config,
+		packets:     packets,
+		params:      params,
+		stopped:     make(chan struct{}),
+		errorLogger: log.NewThrottled(5, 10*time.Second),
+	}, nil
 }
 
 // Start the TrapListener instance. Need to be manually Stopped
 func (t *TrapListener) Start() error {
 	log.Infof("Start listening for traps on %s", t.config.Addr())
+	addr, err := net.ResolveUDPAddr("udp", t.config.Addr())
+	if err != nil {
+		return fmt.Errorf("error happened when listening for SNMP Traps: %s", err)
+	}
+	t.conn, err = net.ListenUDP("udp", addr)
+	if err != nil {
+		return fmt.Errorf("error happened when listening for SNMP Traps: %s", err)
+	}
 	go t.run()
-	return t.blockUntilReady()
+	return nil
 }
 
 func (t *TrapListener) run() {
-	err := t.listener.Listen(t.config.Addr()) // blocking call
-	if err != nil {
-		t.errorsChannel <- err
-	}
-
-}
+	defer close(t.stopped)
 
-func (t *TrapListener) blockUntilReady() error {
-	select {
-	// Wait for listener to be started and listening to traps.
-	// See: https://godoc.org/github.com/gosnmp/gosnmp#TrapListener.Listening
-	case <-t.listener.Listening():
-		return nil
-	// If the listener failed to start (eg because it couldn't bind to a socket),
-	// we'll get an error here.
-	case err := <-t.errorsChannel:
-		return fmt.Errorf("error happened when listening for SNMP Traps: %s", err)
+	var buf [4096]byte
+	for {
+		n, remote, err := t.conn.ReadFromUDP(buf[:])
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			t.errorLogger.Warn("Error reading traps on listener %s: %s", t.config.Addr(), err)
+			continue
+		}
+		t.handleMessage(buf[:n], remote)
 	}
 }
 
 // Stop the current TrapListener instance
 func (t *TrapListener) Stop() {
-	t.listener.Close()
+	t.conn.Close()
+	<-t.stopped
 }
 
-func (t *TrapListener) receiveTrap(p *gosnmp.SnmpPacket, u *net.UDPAddr) {
+// handleMessage decodes and validates a message, and forwards it. INFORM
+// requests are only acknowledged once validated.
+func (t *TrapListener) handleMessage(msg []byte, remote *net.UDPAddr) {
+	p, err := t.params.UnmarshalTrap(msg, false)
+	if err != nil {
+		t.errorLogger.Warn("Invalid trap from %s on listener %s: %s", remote.String(), t.config.Addr(), err)
+		return
+	}
+
+	if t.hasUnknownEngineID(p) {
+		t.reportUnknownEngineID(p, remote)
+		return
+	}
+
 	if err := validatePacket(p, t.config); err != nil {
-		t.errorLogger.Warn("Invalid credentials from %s on listener %s, dropping traps", u.String(), t.config.Addr())
+		t.errorLogger.Warn("Invalid credentials from %s on listener %s, dropping traps", remote.String(), t.config.Addr())
 		trapsPacketsAuthErrors.Add(1)
 		return
 	}
+
+	t.receiveTrap(p, remote)
+
+	if p.PDUType == gosnmp.InformRequest {
+		t.acknowledge(p, remote)
+	}
+}
+
+func (t *TrapListener) receiveTrap(p *gosnmp.SnmpPacket, u *net.UDPAddr) {
 	log.Debugf("Packet received from %s on listener %s", u.String(), t.config.Addr())
 	trapsPackets.Add(1)
+	if p.PDUType == gosnmp.InformRequest {
+		trapsInforms.Add(1)
+	}
 	t.packets <- &SnmpPacket{Content: p, Addr: u, Timestamp: time.Now().UnixMilli()}
 }
+
+// acknowledge sends the response to an INFORM request, which holds the same
+// variables as the request
+func (t *TrapListener) acknowledge(p *gosnmp.SnmpPacket, u *net.UDPAddr) {
+	response := *p
+	response.PDUType = gosnmp.GetResponse
+	response.Error = gosnmp.NoError
+	response.ErrorIndex = 0
+	t.send(&response, u)
+}
+
+// hasUnknownEngineID returns true if p is a v3 packet whose engine ID isn't
+// the one of the listener and can't be valid, which happens when the sender
+// is discovering it
+func (t *TrapListener) hasUnknownEngineID(p *gosnmp.SnmpPacket) bool {
+	if p.Version != gosnmp.Version3 || p.SecurityModel != gosnmp.UserSecurityModel || t.params.SecurityModel != gosnmp.UserSecurityModel {
+		return false
+	}
+	securityParams, ok := t.params.SecurityParameters.(*gosnmp.UsmSecurityParameters)
+	if !ok {
+		return false
+	}
+	packetSecurityParams, ok := p.SecurityParameters.(*gosnmp.UsmSecurityParameters)
+	if !ok {
+		return false
+	}
+
+	// SnmpEngineID is an OCTET STRING of 5 to 32 bytes, see RFC 3411
+	engineID := packetSecurityParams.AuthoritativeEngineID
+	return engineID != securityParams.AuthoritativeEngineID && (len(engineID) < 5 || len(engineID) > 32)
+}
+
+// reportUnknownEngineID reports the engine ID of the listener to the sender
+// of p, see RFC 3414 section 3.2.3
+func (t *TrapListener) reportUnknownEngineID(p *gosnmp.SnmpPacket, u *net.UDPAddr) {
+	count := atomic.AddUint32(&t.unknownEngineIDs, 1)
+
+	securityParams, ok := p.SecurityParameters.Copy().(*gosnmp.UsmSecurityParameters)
+	if !ok {
+		return
+	}
+	securityParams.AuthoritativeEngineID = t.params.SecurityParameters.(*gosnmp.UsmSecurityParameters).AuthoritativeEngineID
+
+	// the sender can't authenticate the report without the engine ID, which
+	// is sent noAuthNoPriv, and reports are never reportable
+	report := *p
+	report.PDUType = gosnmp.Report
+	report.MsgFlags = gosnmp.NoAuthNoPriv
+	report.SecurityParameters = securityParams
+	report.Variables = []gosnmp.SnmpPDU{
+		{Name: usmStatsUnknownEngineIDs, Value: int(count), Type: gosnmp.Integer},
+	}
+	t.send(&report, u)
+}
+
+func (t *TrapListener) send(p *gosnmp.SnmpPacket, u *net.UDPAddr) {
+	blob, err := p.MarshalMsg()
+	if err != nil {
+		t.errorLogger.Warn("Unable to encode the response to %s on listener %s: %s", u.String(), t.config.Addr(), err)
+		return
+	}
+	if _, err := t.conn.WriteTo(blob, u); err != nil {
+		t.errorLogger.Warn("Unable to send the response to %s on listener %s: %s", u.String(), t.config.Addr(), err)
+	}
+}
@@ -6,6 +6,8 @@
 package traps
 
 import (
+	"net"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -121,6 +123,122 @@ func TestServerV3BadCredentials(t *testing.T) {
 	assertNoPacketReceived(t, trapListener)
 }
 
+func TestServerV2Inform(t *testing.T) {
+	config := Config{Port: serverPort, CommunityStrings: []string{"public"}}
+	Configure(t, config)
+
+	packetOutChan := make(PacketsChannel, 1)
+	trapListener, err := startSNMPTrapListener(config, packetOutChan)
+	require.NoError(t, err)
+	defer trapListener.Stop()
+
+	params, err := config.BuildSNMPParams()
+	require.NoError(t, err)
+	params.Community = "public"
+
+	response, err := sendTestInform(t, params)
+	require.NoError(t, err)
+	assert.Equal(t, gosnmp.GetResponse, response.PDUType)
+	assert.Equal(t, gosnmp.NoError, response.Error)
+
+	packet := receivePacket(t, trapListener)
+	require.NotNil(t, packet)
+	assert.Equal(t, gosnmp.InformRequest, packet.Content.PDUType)
+	assertIsValidV2Packet(t, packet, config)
+	assertVariables(t, packet)
+}
+
+func TestServerV2InformBadCredentials(t *testing.T) {
+	config := Config{Port: serverPort, CommunityStrings: []string{"public"}}
+	Configure(t, config)
+
+	packetOutChan := make(PacketsChannel, 1)
+	trapListener, err := startSNMPTrapListener(config, packetOutChan)
+	require.NoError(t, err)
+	defer trapListener.Stop()
+
+	params, err := config.BuildSNMPParams()
+	require.NoError(t, err)
+	params.Community = "wrong-community"
+
+	_, err = sendTestInform(t, params)
+	assert.Error(t, err)
+	assertNoPacketReceived(t, trapListener)
+}
+
+func TestServerV3Inform(t *testing.T) {
+	userV3 := UserV3{Username: "user", AuthKey: "password", AuthProtocol: "sha", PrivKey: "password", PrivProtocol: "aes"}
+	config := Config{Port: serverPort, Users: []UserV3{userV3}, authoritativeEngineID: "foobarbaz"}
+	Configure(t, config)
+
+	packetOutChan := make(PacketsChannel, 1)
+	trapListener, err := startSNMPTrapListener(config, packetOutChan)
+	require.NoError(t, err)
+	defer trapListener.Stop()
+
+	params, err := config.BuildSNMPParams()
+	require.NoError(t, err)
+
+	response, err := sendTestInform(t, params)
+	require.NoError(t, err)
+	assert.Equal(t, gosnmp.GetResponse, response.PDUType)
+
+	packet := receivePacket(t, trapListener)
+	require.NotNil(t, packet)
+	assert.Equal(t, gosnmp.InformRequest, packet.Content.PDUType)
+	assertVariables(t, packet)
+}
+
+func TestServerV3UnknownEngineIDReport(t *testing.T) {
+	userV3 := UserV3{Username: "user", AuthKey: "password", AuthProtocol: "sha", PrivKey: "password", PrivProtocol: "aes"}
+	config := Config{Port: serverPort, Users: []UserV3{userV3}, authoritativeEngineID: "foobarbaz"}
+	Configure(t, config)
+
+	trapListener, err := startSNMPTrapListener(config, make(PacketsChannel, 1))
+	require.NoError(t, err)
+	defer trapListener.Stop()
+
+	sender, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	defer sender.Close()
+
+	inform := &gosnmp.SnmpPacket{
+		Version:       gosnmp.Version3,
+		MsgFlags:      gosnmp.AuthPriv | gosnmp.Reportable,
+		SecurityModel: gosnmp.UserSecurityModel,
+		SecurityParameters: &gosnmp.UsmSecurityParameters{
+			UserName:                 "user",
+			AuthenticationProtocol:   gosnmp.SHA,
+			AuthenticationPassphrase: "password",
+			PrivacyProtocol:          gosnmp.AES,
+			PrivacyPassphrase:        "password",
+		},
+		PDUType:   gosnmp.InformRequest,
+		MsgID:     1,
+		RequestID: 1,
+	}
+	trapListener.reportUnknownEngineID(inform, sender.LocalAddr().(*net.UDPAddr))
+
+	buf := make([]byte, 4096)
+	require.NoError(t, sender.SetReadDeadline(time.Now().Add(3*time.Second)))
+	n, err := sender.Read(buf)
+	require.NoError(t, err)
+
+	decoder := &gosnmp.GoSNMP{
+		Version:            gosnmp.Version3,
+		SecurityModel:      gosnmp.UserSecurityModel,
+		SecurityParameters: &gosnmp.UsmSecurityParameters{UserName: "user"},
+		Logger:             gosnmp.NewLogger(nil),
+	}
+	report, err := decoder.SnmpDecodePacket(buf[:n])
+	require.NoError(t, err)
+	assert.Equal(t, gosnmp.Report, report.PDUType)
+	assert.Equal(t, gosnmp.NoAuthNoPriv, report.MsgFlags)
+	assert.Equal(t, "foobarbaz", report.SecurityParameters.(*gosnmp.UsmSecurityParameters).AuthoritativeEngineID)
+	require.Len(t, report.Variables, 1)
+	assert.Equal(t, usmStatsUnknownEngineIDs, report.Variables[0].Name)
+}
+
 // receivePacket waits for a received trap packet and returns it.
 func receivePacket(t *testing.T, listener *TrapListener) *SnmpPacket {
 	select {
@@ -140,3 +258,29 @@ func assertNoPacketReceived(t *testing.T, listener *TrapListener) {
 		break
 	}
 }
+
+func TestServerV3InformEngineIDDiscovery(t *testing.T) {
+	userV3 := UserV3{Username: "user", AuthKey: "password", AuthProtocol: "sha", PrivKey: "password", PrivProtocol: "aes"}
+	config := Config{Port: serverPort, Users: []UserV3{userV3}, authoritativeEngineID: "foobarbaz"}
+	Configure(t, config)
+
+	packetOutChan := make(PacketsChannel, 1)
+	trapListener, err := startSNMPTrapListener(config, packetOutChan)
+	require.NoError(t, err)
+	defer trapListener.Stop()
+
+	params, err := config.BuildSNMPParams()
+	require.NoError(t, err)
+	// the sender discovers the engine ID of the listener
+	params.SecurityParameters.(*gosnmp.UsmSecurityParameters).AuthoritativeEngineID = ""
+
+	response, err := sendTestInform(t, params)
+	require.NoError(t, err)
+	assert.Equal(t, gosnmp.GetResponse, response.PDUType)
+	assert.Equal(t, uint32(1), atomic.LoadUint32(&trapListener.unknownEngineIDs))
+
+	packet := receivePacket(t, trapListener)
+	require.NotNil(t, packet)
+	assert.Equal(t, gosnmp.InformRequest, packet.Content.PDUType)
+	assertVariables(t, packet)
+}
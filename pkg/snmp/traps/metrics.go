@@ -0,0 +1,200 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package traps
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gosnmp/gosnmp"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	trapMetricPrefix       = "snmp.traps."
+	defaultTrapCountMetric = "received"
+)
+
+// VarbindTag describes a tag whose value is read from a trap variable
+type VarbindTag struct {
+	OID string `mapstructure:"oid" yaml:"oid"`
+	Tag string `mapstructure:"tag" yaml:"tag"`
+}
+
+// VarbindGauge describes a gauge whose value is read from a trap variable
+type VarbindGauge struct {
+	OID    string `mapstructure:"oid" yaml:"oid"`
+	Metric string `mapstructure:"metric" yaml:"metric"`
+}
+
+// MetricRule describes the metrics generated from the traps of an OID. Each
+// trap is counted in `snmp.traps.<count>`, and the values of its gauge
+// variables are sent as `snmp.traps.<metric>`, tagged by the device, the trap
+// and the tag variables.
+type MetricRule struct {
+	TrapOID string         `mapstructure:"trap_oid" yaml:"trap_oid"`
+	Count   string         `mapstructure:"count" yaml:"count"`
+	Gauges  []VarbindGauge `mapstructure:"gauges" yaml:"gauges"`
+	Tags    []VarbindTag   `mapstructure:"tags" yaml:"tags"`
+}
+
+// validate normalizes the OIDs of the rule, and returns an error if the rule is invalid
+func (r *MetricRule) validate() error {
+	r.TrapOID = NormalizeOID(r.TrapOID)
+	if r.TrapOID == "" || !IsValidOID(r.TrapOID) {
+		return fmt.Errorf("invalid trap OID `%s`", r.TrapOID)
+	}
+	if r.Count == "" {
+		r.Count = defaultTrapCountMetric
+	}
+	for i := range r.Gauges {
+		gauge := &r.Gauges[i]
+		gauge.OID = NormalizeOID(gauge.OID)
+		if gauge.OID == "" || !IsValidOID(gauge.OID) {
+			return fmt.Errorf("invalid gauge OID `%s` for trap %s", gauge.OID, r.TrapOID)
+		}
+		if gauge.Metric == "" {
+			return fmt.Errorf("missing metric name of gauge %s for trap %s", gauge.OID, r.TrapOID)
+		}
+	}
+	for i := range r.Tags {
+		tag := &r.Tags[i]
+		tag.OID = NormalizeOID(tag.OID)
+		if tag.OID == "" || !IsValidOID(tag.OID) {
+			return fmt.Errorf("invalid tag OID `%s` for trap %s", tag.OID, r.TrapOID)
+		}
+		if tag.Tag == "" {
+			return fmt.Errorf("missing tag name of %s for trap %s", tag.OID, r.TrapOID)
+		}
+	}
+	return nil
+}
+
+// matchesOID returns whether a variable OID is the given OID, or one of its instances
+func matchesOID(varOID, oid string) bool {
+	return varOID == oid || strings.HasPrefix(varOID, oid+".")
+}
+
+// TrapMetrics converts traps to metrics according to metric rules
+type TrapMetrics struct {
+	rules       map[string][]MetricRule
+	oidResolver OIDResolver
+	namespace   string
+}
+
+// NewTrapMetrics creates a TrapMetrics instance from validated rules. The OID
+// resolver is used to name the traps, and to map the enum values of tags.
+func NewTrapMetrics(rules []MetricRule, oidResolver OIDResolver, namespace string) *TrapMetrics {
+	rulesByOID := make(map[string][]MetricRule)
+	for _, rule := range rules {
+		rulesByOID[rule.TrapOID] = append(rulesByOID[rule.TrapOID], rule)
+	}
+	return &TrapMetrics{
+		rules:       rulesByOID,
+		oidResolver: oidResolver,
+		namespace:   namespace,
+	}
+}
+
+// SendMetrics sends the metrics of the rules matching a trap
+func (m *TrapMetrics) SendMetrics(packet *SnmpPacket, sender aggregator.Sender) {
+	if len(m.rules) == 0 {
+		return
+	}
+
+	trapOID, variables, err := getTrapOIDAndVariables(packet.Content)
+	if err != nil {
+		log.Debugf("unable to get trap OID: %s", err)
+		return
+	}
+
+	rules := m.rules[trapOID]
+	if len(rules) == 0 {
+		return
+	}
+
+	baseTags := append(getPacketTags(packet, m.namespace), "snmp_trap_oid:"+trapOID)
+	if m.oidResolver != nil {
+		if trapMetadata, err := m.oidResolver.GetTrapMetadata(trapOID); err == nil {
+			baseTags = append(baseTags, "snmp_trap_name:"+trapMetadata.Name)
+		}
+	}
+
+	for _, rule := range rules {
+		tags := append([]string{}, baseTags...)
+		for _, tag := range rule.Tags {
+			for _, variable := range variables {
+				if matchesOID(NormalizeOID(variable.Name), tag.OID) {
+					tags = append(tags, tag.Tag+":"+m.formatTagValue(trapOID, variable))
+					break
+				}
+			}
+		}
+
+		sender.Count(trapMetricPrefix+rule.Count, 1, "", tags)
+
+		for _, gauge := range rule.Gauges {
+			for _, variable := range variables {
+				if !matchesOID(NormalizeOID(variable.Name), gauge.OID) {
+					continue
+				}
+				value, err := variableToFloat(variable)
+				if err != nil {
+					log.Debugf("unable to send gauge %s of trap %s: %s", gauge.Metric, trapOID, err)
+					break
+				}
+				sender.Gauge(trapMetricPrefix+gauge.Metric, value, "", tags)
+				break
+			}
+		}
+	}
+	sender.Commit()
+}
+
+// formatTagValue returns the value of a variable, mapped to its enum name
+// when the variable is known by the OID resolver
+func (m *TrapMetrics) formatTagValue(trapOID string, variable gosnmp.SnmpPDU) string {
+	if m.oidResolver != nil {
+		varMetadata, err := m.oidResolver.GetVariableMetadata(trapOID, NormalizeOID(variable.Name))
+		if err == nil && len(varMetadata.Enumeration) > 0 {
+			if i, ok := variable.Value.(int); ok {
+				if value, ok := varMetadata.Enumeration[i]; ok {
+					return value
+				}
+			}
+		}
+	}
+	return fmt.Sprint(formatValue(variable))
+}
+
+// variableToFloat returns the numeric value of a variable
+func variableToFloat(variable gosnmp.SnmpPDU) (float64, error) {
+	switch value := variable.Value.(type) {
+	case int:
+		return float64(value), nil
+	case uint:
+		return float64(value), nil
+	case int64:
+		return float64(value), nil
+	case uint32:
+		return float64(value), nil
+	case uint64:
+		return float64(value), nil
+	case float32:
+		return float64(value), nil
+	case float64:
+		return value, nil
+	case string:
+		return strconv.ParseFloat(value, 64)
+	case []byte:
+		return strconv.ParseFloat(string(value), 64)
+	default:
+		return 0, fmt.Errorf("value of type %T is not numeric", variable.Value)
+	}
+}
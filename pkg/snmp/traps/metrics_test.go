@@ -0,0 +1,129 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package traps
+
+import (
+	"testing"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+)
+
+var linkDownResolver = &MockedResolver{
+	content: trapDBFileContent{
+		Traps: TrapSpec{
+			"1.3.6.1.6.3.1.1.5.3": {Name: "linkDown", MIBName: "IF-MIB"},
+		},
+		Variables: variableSpec{
+			"1.3.6.1.2.1.2.2.1.8": {Name: "ifOperStatus", Enumeration: map[int]string{1: "up", 2: "down"}},
+		},
+	},
+}
+
+func TestTrapMetrics(t *testing.T) {
+	rules := []MetricRule{{
+		TrapOID: ".1.3.6.1.6.3.1.1.5.3",
+		Count:   "link_down",
+		Tags: []VarbindTag{
+			{OID: "1.3.6.1.2.1.2.2.1.1", Tag: "interface_index"},
+			{OID: "1.3.6.1.2.1.2.2.1.8", Tag: "oper_status"},
+		},
+		Gauges: []VarbindGauge{
+			{OID: "1.3.6.1.2.1.2.2.1.7", Metric: "link_down.admin_status"},
+		},
+	}}
+	for i := range rules {
+		require.NoError(t, rules[i].validate())
+	}
+
+	sender := mocksender.NewMockSender("snmp-traps-metrics")
+	sender.SetupAcceptAll()
+
+	packet := makeSnmpPacket(LinkDownv1GenericTrap)
+	packet.Content.Version = gosnmp.Version1
+
+	metrics := NewTrapMetrics(rules, linkDownResolver, "default")
+	metrics.SendMetrics(packet, sender)
+
+	expectedTags := []string{
+		"snmp_version:1",
+		"device_namespace:default",
+		"snmp_device:1.1.1.1",
+		"snmp_trap_oid:1.3.6.1.6.3.1.1.5.3",
+		"snmp_trap_name:linkDown",
+		"interface_index:2",
+		"oper_status:down",
+	}
+	sender.AssertMetric(t, "Count", "snmp.traps.link_down", 1, "", expectedTags)
+	sender.AssertMetric(t, "Gauge", "snmp.traps.link_down.admin_status", 1, "", expectedTags)
+	sender.AssertNumberOfCalls(t, "Commit", 1)
+}
+
+func TestTrapMetricsV2Instances(t *testing.T) {
+	rules := []MetricRule{{
+		TrapOID: "1.3.6.1.4.1.8072.2.3.0.1",
+		Tags: []VarbindTag{
+			{OID: "1.3.6.1.4.1.8072.2.3.2.2", Tag: "heartbeat_name"},
+		},
+		Gauges: []VarbindGauge{
+			{OID: "1.3.6.1.4.1.8072.2.3.2", Metric: "heartbeat.rate"},
+		},
+	}}
+	for i := range rules {
+		require.NoError(t, rules[i].validate())
+	}
+
+	sender := mocksender.NewMockSender("snmp-traps-metrics")
+	sender.SetupAcceptAll()
+
+	packet := makeSnmpPacket(NetSNMPExampleHeartbeatNotification)
+	packet.Content.Version = gosnmp.Version3
+
+	metrics := NewTrapMetrics(rules, NoOpOIDResolver{}, "default")
+	metrics.SendMetrics(packet, sender)
+
+	expectedTags := []string{
+		"snmp_version:3",
+		"device_namespace:default",
+		"snmp_device:1.1.1.1",
+		"snmp_trap_oid:1.3.6.1.4.1.8072.2.3.0.1",
+		"heartbeat_name:test",
+	}
+	sender.AssertMetric(t, "Count", "snmp.traps.received", 1, "", expectedTags)
+	// the first variable under the gauge OID is used
+	sender.AssertMetric(t, "Gauge", "snmp.traps.heartbeat.rate", 1024, "", expectedTags)
+}
+
+func TestTrapMetricsNoMatchingRule(t *testing.T) {
+	rules := []MetricRule{{TrapOID: "1.3.6.1.6.3.1.1.5.4"}}
+	for i := range rules {
+		require.NoError(t, rules[i].validate())
+	}
+
+	sender := mocksender.NewMockSender("snmp-traps-metrics")
+	sender.SetupAcceptAll()
+
+	metrics := NewTrapMetrics(rules, NoOpOIDResolver{}, "default")
+	metrics.SendMetrics(makeSnmpPacket(NetSNMPExampleHeartbeatNotification), sender)
+
+	sender.AssertNotCalled(t, "Count", "snmp.traps.received", float64(1), "", mock.Anything)
+	sender.AssertNumberOfCalls(t, "Commit", 0)
+}
+
+func TestMetricRuleValidation(t *testing.T) {
+	for _, rule := range []MetricRule{
+		{TrapOID: "1.3.6.foo"},
+		{TrapOID: "1.3.6.1", Gauges: []VarbindGauge{{OID: "1.3.6.1.2"}}},
+		{TrapOID: "1.3.6.1", Gauges: []VarbindGauge{{OID: "1..3", Metric: "foo"}}},
+		{TrapOID: "1.3.6.1", Tags: []VarbindTag{{OID: "1.3.6.1.2"}}},
+	} {
+		assert.Error(t, rule.validate(), "rule %+v", rule)
+	}
+}
@@ -57,7 +57,8 @@ func StartServer(agentHostname string, demux aggregator.Demultiplexer) error {
 	if err != nil {
 		return err
 	}
-	server, err := NewTrapServer(*config, formatter, sender)
+	metrics := NewTrapMetrics(config.Metrics, oidResolver, config.Namespace)
+	server, err := NewTrapServer(*config, formatter, metrics, sender)
 	serverInstance = server
 	startError = err
 	return err
@@ -78,7 +79,7 @@ func IsRunning() bool {
 }
 
 // NewTrapServer configures and returns a running SNMP traps server.
-func NewTrapServer(config Config, formatter Formatter, aggregator aggregator.Sender) (*TrapServer, error) {
+func NewTrapServer(config Config, formatter Formatter, metrics *TrapMetrics, aggregator aggregator.Sender) (*TrapServer, error) {
 	packets := make(PacketsChannel, packetsChanSize)
 
 	listener, err := startSNMPTrapListener(config, packets)
@@ -86,7 +87,7 @@ func NewTrapServer(config Config, formatter Formatter, aggregator aggregator.Sen
 		return nil, err
 	}
 
-	trapForwarder, err := startSNMPTrapForwarder(formatter, aggregator, packets)
+	trapForwarder, err := startSNMPTrapForwarder(formatter, metrics, aggregator, packets)
 	if err != nil {
 		return nil, fmt.Errorf("unable to start trapForwarder: %w. Will not listen for SNMP traps", err)
 	}
@@ -99,8 +100,8 @@ func NewTrapServer(config Config, formatter Formatter, aggregator aggregator.Sen
 	return server, nil
 }
 
-func startSNMPTrapForwarder(formatter Formatter, aggregator aggregator.Sender, packets PacketsChannel) (*TrapForwarder, error) {
-	trapForwarder, err := NewTrapForwarder(formatter, aggregator, packets)
+func startSNMPTrapForwarder(formatter Formatter, metrics *TrapMetrics, aggregator aggregator.Sender, packets PacketsChannel) (*TrapForwarder, error) {
+	trapForwarder, err := NewTrapForwarder(formatter, metrics, aggregator, packets)
 	if err != nil {
 		return nil, err
 	}
@@ -26,12 +26,12 @@ func TestStartFailure(t *testing.T) {
 	mockSender := mocksender.NewMockSender("snmp-traps-listener")
 	mockSender.SetupAcceptAll()
 
-	sucessServer, err := NewTrapServer(config, &DummyFormatter{}, mockSender)
+	sucessServer, err := NewTrapServer(config, &DummyFormatter{}, nil, mockSender)
 	require.NoError(t, err)
 	require.NotNil(t, sucessServer)
 	defer sucessServer.Stop()
 
-	failedServer, err := NewTrapServer(config, &DummyFormatter{}, mockSender)
+	failedServer, err := NewTrapServer(config, &DummyFormatter{}, nil, mockSender)
 	require.Nil(t, failedServer)
 	require.Error(t, err)
 }
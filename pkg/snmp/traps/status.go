@@ -16,11 +16,13 @@ var (
 	trapsExpvars           = expvar.NewMap("snmp_traps")
 	trapsPackets           = expvar.Int{}
 	trapsPacketsAuthErrors = expvar.Int{}
+	trapsInforms           = expvar.Int{}
 )
 
 func init() {
 	trapsExpvars.Set("Packets", &trapsPackets)
 	trapsExpvars.Set("PacketsAuthErrors", &trapsPacketsAuthErrors)
+	trapsExpvars.Set("Informs", &trapsInforms)
 }
 
 func getDroppedPackets() int64 {
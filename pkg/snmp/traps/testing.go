@@ -205,6 +205,21 @@ func sendTestV3Trap(t *testing.T, trapConfig Config, securityParams *gosnmp.UsmS
 	return params
 }
 
+// sendTestInform sends an INFORM request with the given parameters, and
+// returns the acknowledgement of the listener
+func sendTestInform(t *testing.T, params *gosnmp.GoSNMP) (*gosnmp.SnmpPacket, error) {
+	params.Timeout = 500 * time.Millisecond // Must be non-zero when sending traps.
+	params.Retries = 1                      // Must be non-zero when sending traps.
+
+	err := params.Connect()
+	require.NoError(t, err)
+	defer params.Conn.Close()
+
+	trap := NetSNMPExampleHeartbeatNotification
+	trap.IsInform = true
+	return params.SendTrap(trap)
+}
+
 func assertIsValidV2Packet(t *testing.T, packet *SnmpPacket, trapConfig Config) {
 	require.Equal(t, gosnmp.Version2c, packet.Content.Version)
 	communityValid := false
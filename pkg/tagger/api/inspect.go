@@ -0,0 +1,201 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+
+	"github.com/DataDog/datadog-agent/pkg/api/util"
+)
+
+// StateAt rebuilds the tags reported by each source at the given point in
+// time from the history. Changes older than the history are not known, so
+// the result is only accurate for times after the oldest recorded event.
+func (r *TaggerInspectResponse) StateAt(t time.Time) map[string]TaggerSourceTags {
+	state := make(map[string]TaggerSourceTags)
+
+	for _, event := range r.History {
+		if event.Timestamp.After(t) {
+			break
+		}
+
+		if event.Deleted {
+			delete(state, event.Source)
+		} else {
+			state[event.Source] = event.Tags
+		}
+	}
+
+	return state
+}
+
+// Diff returns the tags that were added or removed between from and to,
+// attributed to their source and cardinality.
+func (r *TaggerInspectResponse) Diff(from, to time.Time) []TaggerTagChange {
+	before := r.StateAt(from)
+	after := r.StateAt(to)
+
+	sources := make(map[string]struct{})
+	for source := range before {
+		sources[source] = struct{}{}
+	}
+	for source := range after {
+		sources[source] = struct{}{}
+	}
+
+	changes := []TaggerTagChange{}
+	for source := range sources {
+		b, a := before[source], after[source]
+		changes = append(changes, diffTags(source, "low", b.LowCardinalityTags, a.LowCardinalityTags)...)
+		changes = append(changes, diffTags(source, "orchestrator", b.OrchestratorCardinalityTags, a.OrchestratorCardinalityTags)...)
+		changes = append(changes, diffTags(source, "high", b.HighCardinalityTags, a.HighCardinalityTags)...)
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Source != changes[j].Source {
+			return changes[i].Source < changes[j].Source
+		}
+		return changes[i].Tag < changes[j].Tag
+	})
+
+	return changes
+}
+
+func diffTags(source, cardinality string, before, after []string) []TaggerTagChange {
+	var changes []TaggerTagChange
+
+	beforeSet := make(map[string]struct{}, len(before))
+	for _, tag := range before {
+		beforeSet[tag] = struct{}{}
+	}
+
+	afterSet := make(map[string]struct{}, len(after))
+	for _, tag := range after {
+		afterSet[tag] = struct{}{}
+		if _, found := beforeSet[tag]; !found {
+			changes = append(changes, TaggerTagChange{Source: source, Cardinality: cardinality, Tag: tag, Added: true})
+		}
+	}
+
+	for _, tag := range before {
+		if _, found := afterSet[tag]; !found {
+			changes = append(changes, TaggerTagChange{Source: source, Cardinality: cardinality, Tag: tag, Added: false})
+		}
+	}
+
+	return changes
+}
+
+// GetTaggerInspect displays in a human readable format the tags of an entity
+// by source and cardinality, along with their history. If from is not zero,
+// the tags added and removed between from and to are displayed instead of
+// the full history.
+func GetTaggerInspect(w io.Writer, url string, from, to time.Time) error {
+	c := util.GetClient(false) // FIX: get certificates right then make this true
+
+	r, err := util.DoGet(c, url, util.LeaveConnectionOpen)
+	if err != nil {
+		if r != nil && string(r) != "" {
+			return fmt.Errorf("the agent ran into an error while inspecting the entity: %s", string(r))
+		}
+		return fmt.Errorf("failed to query the agent (running?): %s", err)
+	}
+
+	ir := TaggerInspectResponse{}
+	err = json.Unmarshal(r, &ir)
+	if err != nil {
+		return err
+	}
+
+	if from.IsZero() {
+		printTaggerInspect(w, &ir)
+	} else {
+		printTaggerDiff(w, &ir, from, to)
+	}
+
+	return nil
+}
+
+func printTaggerInspect(w io.Writer, ir *TaggerInspectResponse) {
+	fmt.Fprintf(w, "\n=== Entity %s ===\n", color.GreenString(ir.Entity))
+
+	sources := make([]string, 0, len(ir.Sources))
+	for source := range ir.Sources {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	for _, source := range sources {
+		fmt.Fprintf(w, "== Source %s ==\n", source)
+		printSourceTags(w, ir.Sources[source])
+	}
+
+	fmt.Fprintf(w, "\n=== %s ===\n", color.GreenString("History"))
+	for _, event := range ir.History {
+		if event.Deleted {
+			fmt.Fprintf(w, "%s %s %s\n", event.Timestamp.Format(time.RFC3339), event.Source, color.YellowString("deleted"))
+			continue
+		}
+		fmt.Fprintf(w, "%s %s\n", event.Timestamp.Format(time.RFC3339), event.Source)
+		printSourceTags(w, event.Tags)
+	}
+
+	fmt.Fprintln(w, "===")
+}
+
+func printSourceTags(w io.Writer, tags TaggerSourceTags) {
+	printTagList(w, "Low", tags.LowCardinalityTags)
+	printTagList(w, "Orchestrator", tags.OrchestratorCardinalityTags)
+	printTagList(w, "High", tags.HighCardinalityTags)
+	printTagList(w, "Standard", tags.StandardTags)
+}
+
+func printTagList(w io.Writer, name string, tags []string) {
+	if len(tags) == 0 {
+		return
+	}
+
+	sorted := append([]string(nil), tags...)
+	sort.Strings(sorted)
+
+	colored := make([]string, 0, len(sorted))
+	for _, tag := range sorted {
+		tagInfo := strings.Split(tag, ":")
+		colored = append(colored, fmt.Sprintf("%s:%s", color.BlueString(tagInfo[0]), color.CyanString(strings.Join(tagInfo[1:], ":"))))
+	}
+
+	fmt.Fprintf(w, "  %s: [%s]\n", name, strings.Join(colored, " "))
+}
+
+func printTaggerDiff(w io.Writer, ir *TaggerInspectResponse, from, to time.Time) {
+	fmt.Fprintf(w, "\n=== Entity %s from %s to %s ===\n", color.GreenString(ir.Entity), from.Format(time.RFC3339), to.Format(time.RFC3339))
+
+	if len(ir.History) > 0 && from.Before(ir.History[0].Timestamp) {
+		fmt.Fprintf(w, "%s: history starts at %s, older changes are unknown\n", color.YellowString("Warning"), ir.History[0].Timestamp.Format(time.RFC3339))
+	}
+
+	changes := ir.Diff(from, to)
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "No tag changes")
+	}
+
+	for _, change := range changes {
+		sign := color.GreenString("+")
+		if !change.Added {
+			sign = color.RedString("-")
+		}
+		fmt.Fprintf(w, "%s %s (source %s, %s cardinality)\n", sign, change.Tag, change.Source, change.Cardinality)
+	}
+
+	fmt.Fprintln(w, "===")
+}
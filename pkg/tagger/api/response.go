@@ -5,6 +5,8 @@
 
 package api
 
+import "time"
+
 // TaggerListResponse holds the tagger list response
 type TaggerListResponse struct {
 	Entities map[string]TaggerListEntity `json:"entities"`
@@ -14,3 +16,36 @@ type TaggerListResponse struct {
 type TaggerListEntity struct {
 	Tags map[string][]string `json:"tags"`
 }
+
+// TaggerInspectResponse holds the tags of an entity attributed to the source
+// that reported them, along with the history of their changes
+type TaggerInspectResponse struct {
+	Entity  string                      `json:"entity"`
+	Sources map[string]TaggerSourceTags `json:"sources"`
+	History []TaggerHistoryEvent        `json:"history"`
+}
+
+// TaggerSourceTags holds the tags reported by a single source, by cardinality
+type TaggerSourceTags struct {
+	LowCardinalityTags          []string `json:"low"`
+	OrchestratorCardinalityTags []string `json:"orchestrator"`
+	HighCardinalityTags         []string `json:"high"`
+	StandardTags                []string `json:"standard"`
+}
+
+// TaggerHistoryEvent records the tags reported by a source after a change.
+// Deleted is true when the source stopped reporting tags for the entity.
+type TaggerHistoryEvent struct {
+	Timestamp time.Time        `json:"timestamp"`
+	Source    string           `json:"source"`
+	Deleted   bool             `json:"deleted"`
+	Tags      TaggerSourceTags `json:"tags"`
+}
+
+// TaggerTagChange is a tag that was added or removed between two points in time
+type TaggerTagChange struct {
+	Source      string `json:"source"`
+	Cardinality string `json:"cardinality"`
+	Tag         string `json:"tag"`
+	Added       bool   `json:"added"`
+}
@@ -133,6 +133,10 @@ func (c *WorkloadMetaCollector) processEvents(evBundle workloadmeta.EventBundle)
 				tagInfos = append(tagInfos, c.handleKubePod(ev)...)
 			case workloadmeta.KindECSTask:
 				tagInfos = append(tagInfos, c.handleECSTask(ev)...)
+			case workloadmeta.KindSystemdUnit:
+				tagInfos = append(tagInfos, c.handleSystemdUnit(ev)...)
+			case workloadmeta.KindProcess:
+				tagInfos = append(tagInfos, c.handleProcess(ev)...)
 			default:
 				log.Errorf("cannot handle event for entity %q with kind %q", entityID.ID, entityID.Kind)
 			}
@@ -381,6 +385,50 @@ func (c *WorkloadMetaCollector) handleECSTask(ev workloadmeta.Event) []*TagInfo
 	return tagInfos
 }
 
+func (c *WorkloadMetaCollector) handleSystemdUnit(ev workloadmeta.Event) []*TagInfo {
+	unit := ev.Entity.(*workloadmeta.SystemdUnit)
+
+	tags := utils.NewTagList()
+	tags.AddLow("systemd_unit", unit.Name)
+
+	// standard tags from environment
+	c.extractFromMapWithFn(unit.EnvVars, standardEnvKeys, tags.AddStandard)
+
+	low, orch, high, standard := tags.Compute()
+	return []*TagInfo{
+		{
+			Source:               unitSource,
+			Entity:               buildTaggerEntityID(unit.EntityID),
+			HighCardTags:         high,
+			OrchestratorCardTags: orch,
+			LowCardTags:          low,
+			StandardTags:         standard,
+		},
+	}
+}
+
+func (c *WorkloadMetaCollector) handleProcess(ev workloadmeta.Event) []*TagInfo {
+	process := ev.Entity.(*workloadmeta.Process)
+
+	tags := utils.NewTagList()
+	tags.AddLow("process_name", process.Name)
+
+	// standard tags from environment
+	c.extractFromMapWithFn(process.EnvVars, standardEnvKeys, tags.AddStandard)
+
+	low, orch, high, standard := tags.Compute()
+	return []*TagInfo{
+		{
+			Source:               processSource,
+			Entity:               buildTaggerEntityID(process.EntityID),
+			HighCardTags:         high,
+			OrchestratorCardTags: orch,
+			LowCardTags:          low,
+			StandardTags:         standard,
+		},
+	}
+}
+
 func (c *WorkloadMetaCollector) handleGardenContainer(container *workloadmeta.Container) []*TagInfo {
 	return []*TagInfo{
 		{
@@ -594,6 +642,10 @@ func buildTaggerEntityID(entityID workloadmeta.EntityID) string {
 		return kubelet.PodUIDToTaggerEntityName(entityID.ID)
 	case workloadmeta.KindECSTask:
 		return fmt.Sprintf("ecs_task://%s", entityID.ID)
+	case workloadmeta.KindSystemdUnit:
+		return fmt.Sprintf("systemd_unit://%s", entityID.ID)
+	case workloadmeta.KindProcess:
+		return fmt.Sprintf("process://%s", entityID.ID)
 	default:
 		log.Errorf("can't recognize entity %q with kind %q; trying %s://%s as tagger entity",
 			entityID.ID, entityID.Kind, entityID.ID, entityID.Kind)
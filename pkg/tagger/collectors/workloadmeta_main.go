@@ -26,6 +26,8 @@ const (
 	podSource       = workloadmetaCollectorName + "-" + string(workloadmeta.KindKubernetesPod)
 	taskSource      = workloadmetaCollectorName + "-" + string(workloadmeta.KindECSTask)
 	containerSource = workloadmetaCollectorName + "-" + string(workloadmeta.KindContainer)
+	unitSource      = workloadmetaCollectorName + "-" + string(workloadmeta.KindSystemdUnit)
+	processSource   = workloadmetaCollectorName + "-" + string(workloadmeta.KindProcess)
 )
 
 // CollectorPriorities holds collector priorities
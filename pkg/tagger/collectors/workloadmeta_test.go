@@ -536,6 +536,98 @@ func TestHandleECSTask(t *testing.T) {
 	}
 }
 
+func TestHandleSystemdUnit(t *testing.T) {
+	unit := workloadmeta.SystemdUnit{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindSystemdUnit,
+			ID:   "nginx.service",
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name: "nginx.service",
+		},
+		EnvVars: map[string]string{
+			"DD_SERVICE": "web",
+			"DD_ENV":     "prod",
+			"DD_VERSION": "1.22",
+			"HOME":       "/var/www",
+		},
+		MainPID: 1234,
+	}
+
+	collector := &WorkloadMetaCollector{
+		children: make(map[string]map[string]struct{}),
+	}
+
+	actual := collector.handleSystemdUnit(workloadmeta.Event{
+		Type:   workloadmeta.EventTypeSet,
+		Entity: &unit,
+	})
+
+	assertTagInfoListEqual(t, []*TagInfo{
+		{
+			Source:               unitSource,
+			Entity:               "systemd_unit://nginx.service",
+			HighCardTags:         []string{},
+			OrchestratorCardTags: []string{},
+			LowCardTags: []string{
+				"env:prod",
+				"service:web",
+				"systemd_unit:nginx.service",
+				"version:1.22",
+			},
+			StandardTags: []string{
+				"env:prod",
+				"service:web",
+				"version:1.22",
+			},
+		},
+	}, actual)
+}
+
+func TestHandleProcess(t *testing.T) {
+	process := workloadmeta.Process{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindProcess,
+			ID:   "1234",
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name: "nginx",
+		},
+		EnvVars: map[string]string{
+			"DD_SERVICE": "web",
+			"DD_ENV":     "prod",
+		},
+		Exe: "/usr/sbin/nginx",
+	}
+
+	collector := &WorkloadMetaCollector{
+		children: make(map[string]map[string]struct{}),
+	}
+
+	actual := collector.handleProcess(workloadmeta.Event{
+		Type:   workloadmeta.EventTypeSet,
+		Entity: &process,
+	})
+
+	assertTagInfoListEqual(t, []*TagInfo{
+		{
+			Source:               processSource,
+			Entity:               "process://1234",
+			HighCardTags:         []string{},
+			OrchestratorCardTags: []string{},
+			LowCardTags: []string{
+				"env:prod",
+				"process_name:nginx",
+				"service:web",
+			},
+			StandardTags: []string{
+				"env:prod",
+				"service:web",
+			},
+		},
+	}, actual)
+}
+
 func TestHandleContainer(t *testing.T) {
 	const (
 		containerName = "foobar"
@@ -224,6 +224,11 @@ func List(cardinality collectors.TagCardinality) tagger_api.TaggerListResponse {
 	return defaultTagger.List(cardinality)
 }
 
+// Inspect returns the tags of an entity by source, along with their history
+func Inspect(entityID string) (tagger_api.TaggerInspectResponse, error) {
+	return defaultTagger.Inspect(entityID)
+}
+
 // SetDefaultTagger sets the global Tagger instance
 func SetDefaultTagger(tagger Tagger) {
 	// reset initOnce so that this new tagger's Init(..) will get called
@@ -24,6 +24,7 @@ type Tagger interface {
 	AccumulateTagsFor(entity string, cardinality collectors.TagCardinality, tb tagset.TagsAccumulator) error
 	Standard(entity string) ([]string, error)
 	List(cardinality collectors.TagCardinality) tagger_api.TaggerListResponse
+	Inspect(entityID string) (tagger_api.TaggerInspectResponse, error)
 	GetEntity(entityID string) (*types.Entity, error)
 
 	Subscribe(cardinality collectors.TagCardinality) chan []types.EntityEvent
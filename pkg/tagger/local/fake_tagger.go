@@ -112,6 +112,11 @@ func (f *FakeTagger) List(cardinality collectors.TagCardinality) tagger_api.Tagg
 	return f.store.List()
 }
 
+// Inspect fake implementation
+func (f *FakeTagger) Inspect(entityID string) (tagger_api.TaggerInspectResponse, error) {
+	return f.store.Inspect(entityID)
+}
+
 // Subscribe fake implementation
 func (f *FakeTagger) Subscribe(cardinality collectors.TagCardinality) chan []types.EntityEvent {
 	return f.store.Subscribe(cardinality)
@@ -118,6 +118,11 @@ func (t *Tagger) List(cardinality collectors.TagCardinality) tagger_api.TaggerLi
 	return t.tagStore.List()
 }
 
+// Inspect returns the tags of an entity by source, along with their history.
+func (t *Tagger) Inspect(entityID string) (tagger_api.TaggerInspectResponse, error) {
+	return t.tagStore.Inspect(entityID)
+}
+
 // Subscribe returns a channel that receives a slice of events whenever an entity is
 // added, modified or deleted. It can send an initial burst of events only to the new
 // subscriber, without notifying all of the others.
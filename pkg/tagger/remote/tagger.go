@@ -197,6 +197,18 @@ func (t *Tagger) List(cardinality collectors.TagCardinality) tagger_api.TaggerLi
 	return resp
 }
 
+// Inspect returns the tags of an entity, along with their history. The remote
+// tagger only receives the merged tags of each entity, so they are all
+// attributed to a single "remote" source.
+func (t *Tagger) Inspect(entityID string) (tagger_api.TaggerInspectResponse, error) {
+	resp, found := t.store.inspect(entityID)
+	if !found {
+		return resp, fmt.Errorf("Entity not found for entityID")
+	}
+
+	return resp, nil
+}
+
 // Subscribe returns a channel that receives a slice of events whenever an entity is
 // added, modified or deleted. It can send an initial burst of events only to the new
 // subscriber, without notifying all of the others.
@@ -7,7 +7,9 @@ package remote
 
 import (
 	"sync"
+	"time"
 
+	tagger_api "github.com/DataDog/datadog-agent/pkg/tagger/api"
 	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
 	"github.com/DataDog/datadog-agent/pkg/tagger/subscriber"
 	"github.com/DataDog/datadog-agent/pkg/tagger/telemetry"
@@ -15,11 +17,17 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/containers"
 )
 
-const remoteSource = "remote"
+const (
+	remoteSource = "remote"
+
+	// maxHistoryEvents is the number of tag changes kept for each entity
+	maxHistoryEvents = 20
+)
 
 type tagStore struct {
 	mutex     sync.RWMutex
 	store     map[string]*types.Entity
+	history   map[string][]tagger_api.TaggerHistoryEvent
 	telemetry map[string]float64
 
 	subscriber *subscriber.Subscriber
@@ -28,6 +36,7 @@ type tagStore struct {
 func newTagStore() *tagStore {
 	return &tagStore{
 		store:      make(map[string]*types.Entity),
+		history:    make(map[string][]tagger_api.TaggerHistoryEvent),
 		telemetry:  make(map[string]float64),
 		subscriber: subscriber.NewSubscriber(),
 	}
@@ -48,16 +57,23 @@ func (s *tagStore) processEvents(events []types.EntityEvent, replace bool) error
 		case types.EventTypeAdded:
 			telemetry.UpdatedEntities.Inc()
 			s.store[event.Entity.ID] = &entity
+			s.recordHistory(&entity)
 
 		case types.EventTypeModified:
 			telemetry.UpdatedEntities.Inc()
 			s.store[event.Entity.ID] = &entity
+			s.recordHistory(&entity)
 
 		case types.EventTypeDeleted:
 			delete(s.store, event.Entity.ID)
+			delete(s.history, event.Entity.ID)
 		}
 	}
 
+	if replace {
+		s.pruneHistory()
+	}
+
 	s.notifySubscribers(events)
 
 	return nil
@@ -70,6 +86,60 @@ func (s *tagStore) getEntity(entityID string) *types.Entity {
 	return s.store[entityID]
 }
 
+// recordHistory appends the current tags of the entity to its history,
+// dropping the oldest events once maxHistoryEvents is reached.
+// NOTE: caller must ensure that it holds s.mutex's lock.
+func (s *tagStore) recordHistory(entity *types.Entity) {
+	history := append(s.history[entity.ID], tagger_api.TaggerHistoryEvent{
+		Timestamp: time.Now(),
+		Source:    remoteSource,
+		Tags:      entityToSourceTags(entity),
+	})
+	if len(history) > maxHistoryEvents {
+		history = history[len(history)-maxHistoryEvents:]
+	}
+	s.history[entity.ID] = history
+}
+
+// pruneHistory forgets about the history of the entities which are not in the
+// store anymore, like the ones which disappeared across a resync, while the
+// entities which are still there keep their history.
+// NOTE: caller must ensure that it holds s.mutex's lock.
+func (s *tagStore) pruneHistory() {
+	for entityID := range s.history {
+		if _, found := s.store[entityID]; !found {
+			delete(s.history, entityID)
+		}
+	}
+}
+
+func (s *tagStore) inspect(entityID string) (tagger_api.TaggerInspectResponse, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	entity, found := s.store[entityID]
+	if !found {
+		return tagger_api.TaggerInspectResponse{}, false
+	}
+
+	return tagger_api.TaggerInspectResponse{
+		Entity: entityID,
+		Sources: map[string]tagger_api.TaggerSourceTags{
+			remoteSource: entityToSourceTags(entity),
+		},
+		History: append([]tagger_api.TaggerHistoryEvent(nil), s.history[entityID]...),
+	}, true
+}
+
+func entityToSourceTags(entity *types.Entity) tagger_api.TaggerSourceTags {
+	return tagger_api.TaggerSourceTags{
+		LowCardinalityTags:          entity.LowCardinalityTags,
+		OrchestratorCardinalityTags: entity.OrchestratorCardinalityTags,
+		HighCardinalityTags:         entity.HighCardinalityTags,
+		StandardTags:                entity.StandardTags,
+	}
+}
+
 func (s *tagStore) listEntities() []*types.Entity {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
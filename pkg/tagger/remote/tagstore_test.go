@@ -122,3 +122,68 @@ func TestProcessEvent_Replace(t *testing.T) {
 
 	assert.NotNil(t, entity)
 }
+
+func TestInspect(t *testing.T) {
+	events := []types.EntityEvent{
+		{
+			EventType: types.EventTypeAdded,
+			Entity: types.Entity{
+				ID:                 entityID,
+				LowCardinalityTags: []string{"foo"},
+			},
+		},
+		{
+			EventType: types.EventTypeModified,
+			Entity: types.Entity{
+				ID:                  entityID,
+				LowCardinalityTags:  []string{"foo"},
+				HighCardinalityTags: []string{"bar"},
+			},
+		},
+	}
+
+	store := newTagStore()
+	store.processEvents(events, false)
+
+	resp, found := store.inspect(entityID)
+	assert.True(t, found)
+	assert.Equal(t, []string{"bar"}, resp.Sources[remoteSource].HighCardinalityTags)
+	assert.Len(t, resp.History, 2)
+
+	store.processEvents([]types.EntityEvent{{
+		EventType: types.EventTypeDeleted,
+		Entity:    types.Entity{ID: entityID},
+	}}, false)
+
+	_, found = store.inspect(entityID)
+	assert.False(t, found)
+	assert.Empty(t, store.history)
+}
+
+func TestInspectAfterReplace(t *testing.T) {
+	store := newTagStore()
+	store.processEvents([]types.EntityEvent{
+		{
+			EventType: types.EventTypeAdded,
+			Entity:    types.Entity{ID: entityID, LowCardinalityTags: []string{"foo"}},
+		},
+		{
+			EventType: types.EventTypeAdded,
+			Entity:    types.Entity{ID: anotherEntityID, LowCardinalityTags: []string{"foo"}},
+		},
+	}, false)
+
+	// entityID disappears across the resync
+	store.processEvents([]types.EntityEvent{{
+		EventType: types.EventTypeAdded,
+		Entity:    types.Entity{ID: anotherEntityID, LowCardinalityTags: []string{"bar"}},
+	}}, true)
+
+	_, found := store.inspect(entityID)
+	assert.False(t, found)
+	assert.NotContains(t, store.history, entityID)
+
+	resp, found := store.inspect(anotherEntityID)
+	assert.True(t, found)
+	assert.Len(t, resp.History, 2)
+}
@@ -102,6 +102,11 @@ func (t *Tagger) List(cardinality collectors.TagCardinality) tagger_api.TaggerLi
 	return t.store.List()
 }
 
+// Inspect returns the tags of an entity by source, along with their history.
+func (t *Tagger) Inspect(entityID string) (tagger_api.TaggerInspectResponse, error) {
+	return t.store.Inspect(entityID)
+}
+
 // Subscribe does nothing in the replay tagger this tagger does not respond to events.
 func (t *Tagger) Subscribe(cardinality collectors.TagCardinality) chan []types.EntityEvent {
 	// NOP
@@ -8,7 +8,9 @@ package tagstore
 import (
 	"sort"
 	"strings"
+	"time"
 
+	tagger_api "github.com/DataDog/datadog-agent/pkg/tagger/api"
 	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
 	"github.com/DataDog/datadog-agent/pkg/tagger/types"
 	"github.com/DataDog/datadog-agent/pkg/tagset"
@@ -24,6 +26,7 @@ type EntityTags struct {
 	cachedAll          tagset.HashedTags // Low + orchestrator + high
 	cachedOrchestrator tagset.HashedTags // Low + orchestrator (subslice of cachedAll)
 	cachedLow          tagset.HashedTags // Sub-slice of cachedAll
+	history            []tagger_api.TaggerHistoryEvent
 }
 
 func newEntityTags(entityID string) *EntityTags {
@@ -138,6 +141,24 @@ func (e *EntityTags) computeCache() {
 	e.cachedOrchestrator = cached.Slice(0, lowCardTags+orchCardTags)
 }
 
+// recordHistory appends an event to the history of the entity, dropping the
+// oldest events once maxHistoryEvents is reached.
+func (e *EntityTags) recordHistory(now time.Time, source string, st *sourceTags) {
+	event := tagger_api.TaggerHistoryEvent{
+		Timestamp: now,
+		Source:    source,
+		Deleted:   st == nil,
+	}
+	if st != nil {
+		event.Tags = st.toAPI()
+	}
+
+	e.history = append(e.history, event)
+	if len(e.history) > maxHistoryEvents {
+		e.history = e.history[len(e.history)-maxHistoryEvents:]
+	}
+}
+
 func (e *EntityTags) shouldRemove() bool {
 	for _, tags := range e.sourceTags {
 		if !tags.expiryDate.IsZero() || !tags.isEmpty() {
@@ -5,7 +5,11 @@
 
 package tagstore
 
-import "time"
+import (
+	"time"
+
+	tagger_api "github.com/DataDog/datadog-agent/pkg/tagger/api"
+)
 
 // sourceTags holds the tags for a given entity collected from a single source,
 // grouped by their cardinality.
@@ -28,3 +32,12 @@ func (st *sourceTags) isExpired(t time.Time) bool {
 
 	return st.expiryDate.Before(t)
 }
+
+func (st *sourceTags) toAPI() tagger_api.TaggerSourceTags {
+	return tagger_api.TaggerSourceTags{
+		LowCardinalityTags:          st.lowCardTags,
+		OrchestratorCardinalityTags: st.orchestratorCardTags,
+		HighCardinalityTags:         st.highCardTags,
+		StandardTags:                st.standardTags,
+	}
+}
@@ -27,6 +27,9 @@ import (
 
 const (
 	deletedTTL = 5 * time.Minute
+
+	// maxHistoryEvents is the number of tag changes kept for each entity
+	maxHistoryEvents = 20
 )
 
 // ErrNotFound is returned when entity id is not found in the store.
@@ -142,6 +145,7 @@ func (s *TagStore) ProcessTagInfo(tagInfos []*collectors.TagInfo) {
 		telemetry.UpdatedEntities.Inc()
 		storedTags.cacheValid = false
 		storedTags.sourceTags[info.Source] = newSt
+		storedTags.recordHistory(s.clock.Now(), info.Source, &newSt)
 
 		events = append(events, types.EntityEvent{
 			EventType: eventType,
@@ -226,6 +230,7 @@ func (s *TagStore) Prune() {
 		for source, st := range storedTags.sourceTags {
 			if st.isExpired(now) {
 				delete(storedTags.sourceTags, source)
+				storedTags.recordHistory(now, source, nil)
 				changed = true
 			}
 		}
@@ -342,3 +347,27 @@ func (s *TagStore) GetEntity(entityID string) (*types.Entity, error) {
 	entity := tags.toEntity()
 	return &entity, nil
 }
+
+// Inspect returns the tags of an entity attributed to their source, along with
+// the history of their changes, in an API format.
+func (s *TagStore) Inspect(entityID string) (tagger_api.TaggerInspectResponse, error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	storedTags, present := s.store[entityID]
+	if !present {
+		return tagger_api.TaggerInspectResponse{}, ErrNotFound
+	}
+
+	r := tagger_api.TaggerInspectResponse{
+		Entity:  entityID,
+		Sources: make(map[string]tagger_api.TaggerSourceTags, len(storedTags.sourceTags)),
+		History: append([]tagger_api.TaggerHistoryEvent(nil), storedTags.history...),
+	}
+
+	for source, st := range storedTags.sourceTags {
+		r.Sources[source] = st.toAPI()
+	}
+
+	return r, nil
+}
@@ -12,8 +12,10 @@ import (
 
 	"github.com/benbjohnson/clock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 
+	tagger_api "github.com/DataDog/datadog-agent/pkg/tagger/api"
 	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
 	"github.com/DataDog/datadog-agent/pkg/tagger/types"
 )
@@ -243,6 +245,71 @@ func (s *StoreTestSuite) TestPrune__emptyEntries() {
 	assert.Len(s.T(), emptyTags2, 0)
 }
 
+func (s *StoreTestSuite) TestInspect() {
+	start := s.clock.Now()
+
+	s.store.ProcessTagInfo([]*collectors.TagInfo{
+		{
+			Source:       "source1",
+			Entity:       "test",
+			LowCardTags:  []string{"env:prod"},
+			HighCardTags: []string{"container_id:abcd"},
+		},
+		{
+			Source:      "source2",
+			Entity:      "test",
+			LowCardTags: []string{"service:web"},
+		},
+	})
+
+	s.clock.Add(time.Minute)
+	s.store.ProcessTagInfo([]*collectors.TagInfo{
+		{
+			Source:       "source1",
+			Entity:       "test",
+			LowCardTags:  []string{"env:staging"},
+			HighCardTags: []string{"container_id:abcd"},
+		},
+		{
+			// unchanged, must not be recorded in the history
+			Source:      "source2",
+			Entity:      "test",
+			LowCardTags: []string{"service:web"},
+		},
+		{
+			Source:       "source2",
+			Entity:       "test",
+			DeleteEntity: true,
+		},
+	})
+
+	s.clock.Add(10 * time.Minute)
+	s.store.Prune()
+
+	_, err := s.store.Inspect("unknown")
+	assert.Equal(s.T(), ErrNotFound, err)
+
+	resp, err := s.store.Inspect("test")
+	require.NoError(s.T(), err)
+
+	assert.Equal(s.T(), "test", resp.Entity)
+	assert.Len(s.T(), resp.Sources, 1)
+	assert.Equal(s.T(), []string{"env:staging"}, resp.Sources["source1"].LowCardinalityTags)
+	assert.Equal(s.T(), []string{"container_id:abcd"}, resp.Sources["source1"].HighCardinalityTags)
+
+	require.Len(s.T(), resp.History, 4)
+	assert.Equal(s.T(), start, resp.History[0].Timestamp)
+	assert.Equal(s.T(), "source2", resp.History[3].Source)
+	assert.True(s.T(), resp.History[3].Deleted)
+
+	changes := resp.Diff(start, s.clock.Now())
+	assert.Equal(s.T(), []tagger_api.TaggerTagChange{
+		{Source: "source1", Cardinality: "low", Tag: "env:prod", Added: false},
+		{Source: "source1", Cardinality: "low", Tag: "env:staging", Added: true},
+		{Source: "source2", Cardinality: "low", Tag: "service:web", Added: false},
+	}, changes)
+}
+
 func TestStoreSuite(t *testing.T) {
 	suite.Run(t, &StoreTestSuite{})
 }
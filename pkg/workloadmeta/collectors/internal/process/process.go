@@ -0,0 +1,208 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package process
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/errors"
+	"github.com/DataDog/datadog-agent/pkg/process/procutil"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+const (
+	collectorID   = "process"
+	componentName = "workloadmeta-process"
+
+	// scanInterval is the minimum interval between two scans of the
+	// processes, which is longer than the pull interval of the store as
+	// long-running processes are expected to change rarely
+	scanInterval = 30 * time.Second
+)
+
+// allowedEnvVars are the only environment variables read from the processes,
+// as they are used to compute their standard tags
+var allowedEnvVars = []string{"DD_SERVICE", "DD_ENV", "DD_VERSION"}
+
+type processProbe interface {
+	ProcessesByPID(now time.Time, collectStats bool) (map[int32]*procutil.Process, error)
+}
+
+type collector struct {
+	probe     processProbe
+	store     workloadmeta.Store
+	procRoot  string
+	include   []*regexp.Regexp
+	minUptime time.Duration
+	lastScan  time.Time
+	seen      map[workloadmeta.EntityID]*workloadmeta.Process
+}
+
+func init() {
+	workloadmeta.RegisterCollector(collectorID, func() workloadmeta.Collector {
+		return &collector{
+			seen: make(map[workloadmeta.EntityID]*workloadmeta.Process),
+		}
+	})
+}
+
+func (c *collector) Start(_ context.Context, store workloadmeta.Store) error {
+	if !config.Datadog.GetBool("host_processes.enabled") {
+		return errors.NewDisabled(componentName, "host processes collection is disabled")
+	}
+
+	patterns := config.Datadog.GetStringSlice("host_processes.include")
+	if len(patterns) == 0 {
+		return errors.NewDisabled(componentName, "host_processes.include is empty")
+	}
+
+	include := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return err
+		}
+		include = append(include, re)
+	}
+
+	c.probe = procutil.NewProcessProbe()
+	c.store = store
+	c.procRoot = util.HostProc()
+	c.include = include
+	c.minUptime = config.Datadog.GetDuration("host_processes.min_uptime")
+
+	return nil
+}
+
+func (c *collector) Pull(_ context.Context) error {
+	now := time.Now()
+	if now.Sub(c.lastScan) < scanInterval {
+		return nil
+	}
+	c.lastScan = now
+
+	procs, err := c.probe.ProcessesByPID(now, false)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[workloadmeta.EntityID]*workloadmeta.Process)
+	events := []workloadmeta.CollectorEvent{}
+
+	for pid, proc := range procs {
+		if proc.Stats == nil {
+			continue
+		}
+
+		startedAt := time.UnixMilli(proc.Stats.CreateTime)
+		if now.Sub(startedAt) < c.minUptime || !c.isIncluded(proc.Cmdline) {
+			continue
+		}
+
+		entityID := workloadmeta.EntityID{
+			Kind: workloadmeta.KindProcess,
+			ID:   strconv.Itoa(int(pid)),
+		}
+
+		// a PID can be reused by a new process, which is told apart by
+		// its start time
+		process, ok := c.seen[entityID]
+		if !ok || !process.StartedAt.Equal(startedAt) {
+			process = &workloadmeta.Process{
+				EntityID: entityID,
+				EntityMeta: workloadmeta.EntityMeta{
+					Name: proc.Name,
+				},
+				Exe:       proc.Exe,
+				Cmdline:   proc.Cmdline,
+				EnvVars:   c.readEnvVars(pid),
+				StartedAt: startedAt,
+			}
+
+			events = append(events, workloadmeta.CollectorEvent{
+				Type:   workloadmeta.EventTypeSet,
+				Source: workloadmeta.SourceHost,
+				Entity: process,
+			})
+		}
+
+		seen[entityID] = process
+	}
+
+	for seenID := range c.seen {
+		if _, ok := seen[seenID]; ok {
+			continue
+		}
+
+		events = append(events, workloadmeta.CollectorEvent{
+			Type:   workloadmeta.EventTypeUnset,
+			Source: workloadmeta.SourceHost,
+			Entity: &workloadmeta.Process{
+				EntityID: seenID,
+			},
+		})
+	}
+
+	c.seen = seen
+
+	c.store.Notify(events)
+
+	return nil
+}
+
+func (c *collector) isIncluded(cmdline []string) bool {
+	joined := strings.Join(cmdline, " ")
+	for _, re := range c.include {
+		if re.MatchString(joined) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// readEnvVars reads the allowed environment variables of a process
+func (c *collector) readEnvVars(pid int32) map[string]string {
+	environ, err := os.ReadFile(filepath.Join(c.procRoot, strconv.Itoa(int(pid)), "environ"))
+	if err != nil {
+		log.Debugf("cannot read environment of process %d: %s", pid, err)
+		return nil
+	}
+
+	return parseEnviron(environ)
+}
+
+// parseEnviron extracts the allowed environment variables from the content
+// of /proc/<pid>/environ, a list of NUL-separated `KEY=value` assignments.
+func parseEnviron(environ []byte) map[string]string {
+	envVars := make(map[string]string)
+	for _, assignment := range bytes.Split(environ, []byte{0}) {
+		key, value, found := strings.Cut(string(assignment), "=")
+		if !found {
+			continue
+		}
+		for _, allowed := range allowedEnvVars {
+			if key == allowed {
+				envVars[key] = value
+			}
+		}
+	}
+
+	return envVars
+}
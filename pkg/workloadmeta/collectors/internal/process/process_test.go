@@ -0,0 +1,128 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package process
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/process/procutil"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+type fakeWorkloadmetaStore struct {
+	workloadmeta.Store
+	notifiedEvents []workloadmeta.CollectorEvent
+}
+
+func (store *fakeWorkloadmetaStore) Notify(events []workloadmeta.CollectorEvent) {
+	store.notifiedEvents = append(store.notifiedEvents, events...)
+}
+
+type fakeProcessProbe struct {
+	procs map[int32]*procutil.Process
+}
+
+func (probe *fakeProcessProbe) ProcessesByPID(_ time.Time, _ bool) (map[int32]*procutil.Process, error) {
+	return probe.procs, nil
+}
+
+func newFakeProcess(pid int32, name string, startedAt time.Time, cmdline ...string) *procutil.Process {
+	return &procutil.Process{
+		Pid:     pid,
+		Name:    name,
+		Exe:     cmdline[0],
+		Cmdline: cmdline,
+		Stats: &procutil.Stats{
+			CreateTime: startedAt.UnixMilli(),
+		},
+	}
+}
+
+func TestPull(t *testing.T) {
+	procRoot := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(procRoot, "1234"), 0755))
+	require.NoError(t, os.WriteFile(
+		filepath.Join(procRoot, "1234", "environ"),
+		[]byte("DD_SERVICE=web\x00DD_ENV=prod\x00DB_PASSWORD=secret\x00"),
+		0644,
+	))
+
+	startedAt := time.Now().Add(-time.Hour).Truncate(time.Millisecond)
+	probe := &fakeProcessProbe{
+		procs: map[int32]*procutil.Process{
+			1234: newFakeProcess(1234, "nginx", startedAt, "/usr/sbin/nginx", "-g", "daemon off;"),
+			1235: newFakeProcess(1235, "nginx", time.Now(), "/usr/sbin/nginx", "-t"),
+			1236: newFakeProcess(1236, "bash", startedAt, "/bin/bash"),
+		},
+	}
+	store := &fakeWorkloadmetaStore{}
+	c := collector{
+		probe:     probe,
+		store:     store,
+		procRoot:  procRoot,
+		include:   []*regexp.Regexp{regexp.MustCompile("^/usr/sbin/nginx")},
+		minUptime: 5 * time.Minute,
+		seen:      make(map[workloadmeta.EntityID]*workloadmeta.Process),
+	}
+
+	// processes that are too recent or not included are not collected,
+	// and only the allowed environment variables are read
+	require.NoError(t, c.Pull(context.Background()))
+	require.Len(t, store.notifiedEvents, 1)
+	assert.Equal(t, workloadmeta.CollectorEvent{
+		Type:   workloadmeta.EventTypeSet,
+		Source: workloadmeta.SourceHost,
+		Entity: &workloadmeta.Process{
+			EntityID: workloadmeta.EntityID{
+				Kind: workloadmeta.KindProcess,
+				ID:   "1234",
+			},
+			EntityMeta: workloadmeta.EntityMeta{
+				Name: "nginx",
+			},
+			Exe:     "/usr/sbin/nginx",
+			Cmdline: []string{"/usr/sbin/nginx", "-g", "daemon off;"},
+			EnvVars: map[string]string{
+				"DD_SERVICE": "web",
+				"DD_ENV":     "prod",
+			},
+			StartedAt: startedAt,
+		},
+	}, store.notifiedEvents[0])
+
+	// processes are not scanned again before the scan interval
+	store.notifiedEvents = nil
+	delete(probe.procs, 1234)
+	require.NoError(t, c.Pull(context.Background()))
+	assert.Empty(t, store.notifiedEvents)
+
+	// processes that are gone are removed
+	c.lastScan = time.Time{}
+	require.NoError(t, c.Pull(context.Background()))
+	assert.Equal(t, []workloadmeta.CollectorEvent{
+		{
+			Type:   workloadmeta.EventTypeUnset,
+			Source: workloadmeta.SourceHost,
+			Entity: &workloadmeta.Process{
+				EntityID: workloadmeta.EntityID{
+					Kind: workloadmeta.KindProcess,
+					ID:   "1234",
+				},
+			},
+		},
+	}, store.notifiedEvents)
+}
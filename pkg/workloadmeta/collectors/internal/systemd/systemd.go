@@ -0,0 +1,186 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build systemd
+// +build systemd
+
+package systemd
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/coreos/go-systemd/dbus"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/errors"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+const (
+	collectorID   = "systemd"
+	componentName = "workloadmeta-systemd"
+
+	// systemdRunPath exists only on hosts booted with systemd, see
+	// sd_booted(3)
+	systemdRunPath = "/run/systemd/system"
+
+	unitActiveState = "active"
+	serviceUnitType = "Service"
+)
+
+type systemdClient interface {
+	ListUnitsByPatterns(states []string, patterns []string) ([]dbus.UnitStatus, error)
+	GetUnitTypeProperties(unit string, unitType string) (map[string]interface{}, error)
+}
+
+type collector struct {
+	client   systemdClient
+	store    workloadmeta.Store
+	patterns []string
+	seen     map[workloadmeta.EntityID]*workloadmeta.SystemdUnit
+}
+
+func init() {
+	workloadmeta.RegisterCollector(collectorID, func() workloadmeta.Collector {
+		return &collector{
+			seen: make(map[workloadmeta.EntityID]*workloadmeta.SystemdUnit),
+		}
+	})
+}
+
+func (c *collector) Start(_ context.Context, store workloadmeta.Store) error {
+	if !config.Datadog.GetBool("systemd_units.enabled") {
+		return errors.NewDisabled(componentName, "systemd units collection is disabled")
+	}
+
+	if _, err := os.Stat(systemdRunPath); err != nil {
+		return errors.NewDisabled(componentName, "host is not running systemd")
+	}
+
+	conn, err := dbus.NewSystemConnection()
+	if err != nil {
+		return err
+	}
+
+	c.client = conn
+	c.store = store
+	c.patterns = config.Datadog.GetStringSlice("systemd_units.include")
+
+	return nil
+}
+
+func (c *collector) Pull(_ context.Context) error {
+	units, err := c.client.ListUnitsByPatterns([]string{unitActiveState}, c.patterns)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[workloadmeta.EntityID]*workloadmeta.SystemdUnit)
+	events := make([]workloadmeta.CollectorEvent, 0, len(units))
+
+	for _, status := range units {
+		entityID := workloadmeta.EntityID{
+			Kind: workloadmeta.KindSystemdUnit,
+			ID:   status.Name,
+		}
+
+		// unit properties are queried on every pull, as a unit restarted
+		// by systemd (e.g. with Restart=always) can be back to the same
+		// state with a new main process and cgroup
+		unit := c.buildUnit(entityID, status)
+		if previous, ok := c.seen[entityID]; !ok || !reflect.DeepEqual(previous, unit) {
+			events = append(events, workloadmeta.CollectorEvent{
+				Type:   workloadmeta.EventTypeSet,
+				Source: workloadmeta.SourceHost,
+				Entity: unit,
+			})
+		}
+
+		seen[entityID] = unit
+	}
+
+	for seenID := range c.seen {
+		if _, ok := seen[seenID]; ok {
+			continue
+		}
+
+		events = append(events, workloadmeta.CollectorEvent{
+			Type:   workloadmeta.EventTypeUnset,
+			Source: workloadmeta.SourceHost,
+			Entity: &workloadmeta.SystemdUnit{
+				EntityID: seenID,
+			},
+		})
+	}
+
+	c.seen = seen
+
+	c.store.Notify(events)
+
+	return nil
+}
+
+func (c *collector) buildUnit(entityID workloadmeta.EntityID, status dbus.UnitStatus) *workloadmeta.SystemdUnit {
+	unit := &workloadmeta.SystemdUnit{
+		EntityID: entityID,
+		EntityMeta: workloadmeta.EntityMeta{
+			Name: status.Name,
+		},
+		Description: status.Description,
+		State: workloadmeta.SystemdUnitState{
+			Running:     status.ActiveState == unitActiveState && status.SubState == "running",
+			ActiveState: status.ActiveState,
+			SubState:    status.SubState,
+		},
+	}
+
+	if !strings.HasSuffix(status.Name, ".service") {
+		return unit
+	}
+
+	properties, err := c.client.GetUnitTypeProperties(status.Name, serviceUnitType)
+	if err != nil {
+		log.Debugf("cannot get properties of unit %s: %s", status.Name, err)
+		return unit
+	}
+
+	if pid, ok := properties["MainPID"].(uint32); ok {
+		unit.MainPID = int(pid)
+	}
+
+	if cgroup, ok := properties["ControlGroup"].(string); ok {
+		unit.CgroupPath = cgroup
+	}
+
+	if env, ok := properties["Environment"].([]string); ok {
+		unit.EnvVars = parseEnvironment(env)
+	}
+
+	if ts, ok := properties["ExecMainStartTimestamp"].(uint64); ok && ts > 0 {
+		unit.State.StartedAt = time.UnixMicro(int64(ts))
+	}
+
+	return unit
+}
+
+// parseEnvironment converts the `Environment` property of a unit, a list of
+// `KEY=value` assignments, into a map.
+func parseEnvironment(env []string) map[string]string {
+	envVars := make(map[string]string, len(env))
+	for _, assignment := range env {
+		key, value, found := strings.Cut(assignment, "=")
+		if !found || key == "" {
+			continue
+		}
+		envVars[key] = value
+	}
+
+	return envVars
+}
@@ -0,0 +1,144 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build systemd
+// +build systemd
+
+package systemd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/coreos/go-systemd/dbus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+type fakeWorkloadmetaStore struct {
+	workloadmeta.Store
+	notifiedEvents []workloadmeta.CollectorEvent
+}
+
+func (store *fakeWorkloadmetaStore) Notify(events []workloadmeta.CollectorEvent) {
+	store.notifiedEvents = append(store.notifiedEvents, events...)
+}
+
+type fakeSystemdClient struct {
+	units              []dbus.UnitStatus
+	properties         map[string]map[string]interface{}
+	propertiesRequests int
+}
+
+func (client *fakeSystemdClient) ListUnitsByPatterns(_ []string, _ []string) ([]dbus.UnitStatus, error) {
+	return client.units, nil
+}
+
+func (client *fakeSystemdClient) GetUnitTypeProperties(unit string, _ string) (map[string]interface{}, error) {
+	client.propertiesRequests++
+	return client.properties[unit], nil
+}
+
+func TestPull(t *testing.T) {
+	startedAt := time.Unix(1666000000, 0)
+
+	client := &fakeSystemdClient{
+		units: []dbus.UnitStatus{
+			{Name: "nginx.service", Description: "A high performance web server", ActiveState: "active", SubState: "running"},
+			{Name: "backup.service", Description: "Nightly backup", ActiveState: "active", SubState: "exited"},
+		},
+		properties: map[string]map[string]interface{}{
+			"nginx.service": {
+				"MainPID":                uint32(1234),
+				"ControlGroup":           "/system.slice/nginx.service",
+				"Environment":            []string{"DD_SERVICE=web", "DD_ENV=prod", "MALFORMED"},
+				"ExecMainStartTimestamp": uint64(startedAt.UnixMicro()),
+			},
+			"backup.service": {
+				"MainPID":      uint32(0),
+				"ControlGroup": "/system.slice/backup.service",
+			},
+		},
+	}
+	store := &fakeWorkloadmetaStore{}
+	c := collector{
+		client: client,
+		store:  store,
+		seen:   make(map[workloadmeta.EntityID]*workloadmeta.SystemdUnit),
+	}
+
+	require.NoError(t, c.Pull(context.Background()))
+	require.Len(t, store.notifiedEvents, 2)
+
+	nginx := store.notifiedEvents[0]
+	assert.Equal(t, workloadmeta.EventTypeSet, nginx.Type)
+	assert.Equal(t, workloadmeta.SourceHost, nginx.Source)
+	assert.Equal(t, &workloadmeta.SystemdUnit{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindSystemdUnit,
+			ID:   "nginx.service",
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name: "nginx.service",
+		},
+		Description: "A high performance web server",
+		EnvVars: map[string]string{
+			"DD_SERVICE": "web",
+			"DD_ENV":     "prod",
+		},
+		CgroupPath: "/system.slice/nginx.service",
+		MainPID:    1234,
+		State: workloadmeta.SystemdUnitState{
+			Running:     true,
+			ActiveState: "active",
+			SubState:    "running",
+			StartedAt:   startedAt,
+		},
+	}, nginx.Entity)
+
+	backup := store.notifiedEvents[1].Entity.(*workloadmeta.SystemdUnit)
+	assert.False(t, backup.State.Running)
+	assert.Equal(t, 0, backup.MainPID)
+
+	// unchanged units are not sent again, and units that are gone are
+	// removed
+	store.notifiedEvents = nil
+	client.units = client.units[:1]
+
+	require.NoError(t, c.Pull(context.Background()))
+	assert.Equal(t, 3, client.propertiesRequests)
+	assert.Equal(t, []workloadmeta.CollectorEvent{
+		{
+			Type:   workloadmeta.EventTypeUnset,
+			Source: workloadmeta.SourceHost,
+			Entity: &workloadmeta.SystemdUnit{
+				EntityID: workloadmeta.EntityID{
+					Kind: workloadmeta.KindSystemdUnit,
+					ID:   "backup.service",
+				},
+			},
+		},
+	}, store.notifiedEvents)
+
+	// a unit that changed state is collected again
+	store.notifiedEvents = nil
+	client.units[0].SubState = "reloading"
+
+	require.NoError(t, c.Pull(context.Background()))
+	require.Len(t, store.notifiedEvents, 1)
+	assert.Equal(t, "reloading", store.notifiedEvents[0].Entity.(*workloadmeta.SystemdUnit).State.SubState)
+
+	// a unit restarted by systemd is collected again, even when it is back
+	// to the same state
+	store.notifiedEvents = nil
+	client.properties["nginx.service"]["MainPID"] = uint32(5678)
+
+	require.NoError(t, c.Pull(context.Background()))
+	require.Len(t, store.notifiedEvents, 1)
+	assert.Equal(t, 5678, store.notifiedEvents[0].Entity.(*workloadmeta.SystemdUnit).MainPID)
+}
@@ -55,6 +55,10 @@ func (s *store) Dump(verbose bool) WorkloadDumpResponse {
 			info = e.String(verbose)
 		case *ECSTask:
 			info = e.String(verbose)
+		case *SystemdUnit:
+			info = e.String(verbose)
+		case *Process:
+			info = e.String(verbose)
 		default:
 			return "", fmt.Errorf("unsupported type %T", e)
 		}
@@ -49,3 +49,25 @@ func (ms *MockStore) UnsetEntity(e Entity) {
 		},
 	})
 }
+
+// Restore loads the content of a snapshot into the store, generating a Set
+// event for each source of each entity.
+func (ms *MockStore) Restore(snapshot *Snapshot) error {
+	events := make([]CollectorEvent, 0, len(snapshot.Entities))
+	for _, e := range snapshot.Entities {
+		entity, err := e.Decode()
+		if err != nil {
+			return err
+		}
+
+		events = append(events, CollectorEvent{
+			Type:   EventTypeSet,
+			Source: e.Source,
+			Entity: entity,
+		})
+	}
+
+	ms.Notify(events)
+
+	return nil
+}
@@ -0,0 +1,130 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package workloadmeta
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// Snapshot is a serializable copy of the content of a store. Each source of an
+// entity is kept separately, so that loading a snapshot in a MockStore
+// reproduces the same merged entities as the store it was taken from.
+type Snapshot struct {
+	Entities []SnapshotEntity `json:"entities"`
+}
+
+// SnapshotEntity is the data reported by a single source for an entity.
+type SnapshotEntity struct {
+	Kind   Kind            `json:"kind"`
+	ID     string          `json:"id"`
+	Source Source          `json:"source"`
+	Entity json.RawMessage `json:"entity"`
+}
+
+// NewSnapshotEntity encodes the data reported by source for an entity. Only
+// the allowed environment variables of the entity are kept, see RedactEntity.
+func NewSnapshotEntity(source Source, entity Entity) (SnapshotEntity, error) {
+	id := entity.GetID()
+
+	data, err := json.Marshal(RedactEntity(entity))
+	if err != nil {
+		return SnapshotEntity{}, fmt.Errorf("cannot encode entity %s: %w", id.ID, err)
+	}
+
+	return SnapshotEntity{
+		Kind:   id.Kind,
+		ID:     id.ID,
+		Source: source,
+		Entity: data,
+	}, nil
+}
+
+// Decode returns the entity contained in a SnapshotEntity, with its concrete
+// type determined by its kind.
+func (e SnapshotEntity) Decode() (Entity, error) {
+	var entity Entity
+	switch e.Kind {
+	case KindContainer:
+		entity = &Container{}
+	case KindKubernetesPod:
+		entity = &KubernetesPod{}
+	case KindECSTask:
+		entity = &ECSTask{}
+	case KindSystemdUnit:
+		entity = &SystemdUnit{}
+	case KindProcess:
+		entity = &Process{}
+	default:
+		return nil, fmt.Errorf("unsupported kind %q", e.Kind)
+	}
+
+	if err := json.Unmarshal(e.Entity, entity); err != nil {
+		return nil, fmt.Errorf("cannot decode entity %s: %w", e.ID, err)
+	}
+
+	return entity, nil
+}
+
+// ReadSnapshot reads a snapshot in the JSON format returned by the agent's
+// `workload-list --snapshot` command.
+func ReadSnapshot(r io.Reader) (*Snapshot, error) {
+	snapshot := &Snapshot{}
+	if err := json.NewDecoder(r).Decode(snapshot); err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+// ReadSnapshotFile reads a snapshot from a file, see ReadSnapshot.
+func ReadSnapshotFile(path string) (*Snapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ReadSnapshot(f)
+}
+
+// Snapshot implements Store#Snapshot
+func (s *store) Snapshot() (*Snapshot, error) {
+	s.storeMut.RLock()
+	defer s.storeMut.RUnlock()
+
+	snapshot := &Snapshot{}
+	for _, entitiesOfKind := range s.store {
+		for _, cachedEntity := range entitiesOfKind {
+			for source, entity := range cachedEntity.sources {
+				e, err := NewSnapshotEntity(source, entity)
+				if err != nil {
+					return nil, err
+				}
+
+				snapshot.Entities = append(snapshot.Entities, e)
+			}
+		}
+	}
+
+	// map iteration order is random, sort the entities to make snapshots
+	// of the same store identical
+	sort.Slice(snapshot.Entities, func(i, j int) bool {
+		a, b := snapshot.Entities[i], snapshot.Entities[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.ID != b.ID {
+			return a.ID < b.ID
+		}
+		return a.Source < b.Source
+	})
+
+	return snapshot, nil
+}
@@ -0,0 +1,141 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package workloadmeta
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	s := newTestStore()
+
+	container := &Container{
+		EntityID: EntityID{
+			Kind: KindContainer,
+			ID:   "ctr-id",
+		},
+		EntityMeta: EntityMeta{
+			Name: "ctr-name",
+		},
+		Image: ContainerImage{
+			Name: "ctr-image",
+		},
+		Runtime: ContainerRuntimeDocker,
+		State: ContainerState{
+			Running:   true,
+			StartedAt: time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	ctrToMerge := &Container{
+		EntityID: EntityID{
+			Kind: KindContainer,
+			ID:   "ctr-id",
+		},
+		EntityMeta: EntityMeta{
+			Labels: map[string]string{"foo": "bar"},
+		},
+		PID: 1,
+	}
+
+	pod := &KubernetesPod{
+		EntityID: EntityID{
+			Kind: KindKubernetesPod,
+			ID:   "pod-id",
+		},
+		EntityMeta: EntityMeta{
+			Name:      "pod-name",
+			Namespace: "default",
+		},
+		Containers: []OrchestratorContainer{
+			{ID: "ctr-id", Name: "ctr-name"},
+		},
+	}
+
+	s.handleEvents([]CollectorEvent{
+		{Type: EventTypeSet, Source: SourceRuntime, Entity: container},
+		{Type: EventTypeSet, Source: SourceNodeOrchestrator, Entity: ctrToMerge},
+		{Type: EventTypeSet, Source: SourceNodeOrchestrator, Entity: pod},
+	})
+
+	snapshot, err := s.Snapshot()
+	require.NoError(t, err)
+	require.Len(t, snapshot.Entities, 3)
+	assert.Equal(t, KindContainer, snapshot.Entities[0].Kind)
+	assert.Equal(t, SourceNodeOrchestrator, snapshot.Entities[0].Source)
+	assert.Equal(t, SourceRuntime, snapshot.Entities[1].Source)
+	assert.Equal(t, KindKubernetesPod, snapshot.Entities[2].Kind)
+
+	// snapshots are meant to be stored in files
+	data, err := json.Marshal(snapshot)
+	require.NoError(t, err)
+	snapshot, err = ReadSnapshot(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	ms := NewMockStore()
+	require.NoError(t, ms.Restore(snapshot))
+
+	expected, err := s.GetContainer("ctr-id")
+	require.NoError(t, err)
+	restored, err := ms.GetContainer("ctr-id")
+	require.NoError(t, err)
+	assert.Equal(t, expected, restored)
+
+	restoredPod, err := ms.GetKubernetesPod("pod-id")
+	require.NoError(t, err)
+	assert.Equal(t, pod, restoredPod)
+
+	// sources are kept when restoring
+	assert.Equal(t, container, ms.store.store[KindContainer]["ctr-id"].get(SourceRuntime))
+}
+
+func TestSnapshotEntityDecodeUnknownKind(t *testing.T) {
+	_, err := SnapshotEntity{Kind: "unknown", ID: "foo", Entity: []byte("{}")}.Decode()
+	assert.Error(t, err)
+}
+
+func TestNewSnapshotEntityRedactsEnvVars(t *testing.T) {
+	container := &Container{
+		EntityID: EntityID{
+			Kind: KindContainer,
+			ID:   "ctr-id",
+		},
+		EnvVars: map[string]string{
+			"DD_SERVICE":  "web",
+			"DB_PASSWORD": "secret",
+		},
+	}
+	unit := &SystemdUnit{
+		EntityID: EntityID{
+			Kind: KindSystemdUnit,
+			ID:   "nginx.service",
+		},
+		EnvVars: map[string]string{
+			"DD_ENV":  "prod",
+			"API_KEY": "secret",
+		},
+	}
+
+	for _, entity := range []Entity{container, unit} {
+		snapshotEntity, err := NewSnapshotEntity(SourceRuntime, entity)
+		require.NoError(t, err)
+		assert.NotContains(t, string(snapshotEntity.Entity), "secret")
+
+		decoded, err := snapshotEntity.Decode()
+		require.NoError(t, err)
+		assert.Equal(t, RedactEntity(entity), decoded)
+	}
+
+	// the entities in the store are left untouched
+	assert.Equal(t, "secret", container.EnvVars["DB_PASSWORD"])
+	assert.Equal(t, map[string]string{"DD_ENV": "prod"}, RedactEntity(unit).(*SystemdUnit).EnvVars)
+}
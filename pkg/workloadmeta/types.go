@@ -73,12 +73,33 @@ type Store interface {
 	// kind KindECSTask and the given ID.
 	GetECSTask(id string) (*ECSTask, error)
 
+	// GetSystemdUnit returns metadata about a systemd unit.  It fetches the
+	// entity with kind KindSystemdUnit and the given ID.
+	GetSystemdUnit(id string) (*SystemdUnit, error)
+
+	// ListSystemdUnits returns metadata about all known systemd units,
+	// equivalent to all entities with kind KindSystemdUnit.
+	ListSystemdUnits() []*SystemdUnit
+
+	// GetProcess returns metadata about a host process.  It fetches the
+	// entity with kind KindProcess and the given ID.
+	GetProcess(id string) (*Process, error)
+
+	// ListProcesses returns metadata about all known host processes,
+	// equivalent to all entities with kind KindProcess.
+	ListProcesses() []*Process
+
 	// Notify notifies the store with a slice of events.  It should only be
 	// used by workloadmeta collectors.
 	Notify(events []CollectorEvent)
 
 	// Dump lists the content of the store, for debugging purposes.
 	Dump(verbose bool) WorkloadDumpResponse
+
+	// Snapshot returns a serializable copy of the content of the store,
+	// keeping the data reported by each source separate.  It can be loaded
+	// in a MockStore with MockStore#Restore.
+	Snapshot() (*Snapshot, error)
 }
 
 // Kind is the kind of an entity.
@@ -89,6 +110,8 @@ const (
 	KindContainer     Kind = "container"
 	KindKubernetesPod Kind = "kubernetes_pod"
 	KindECSTask       Kind = "ecs_task"
+	KindSystemdUnit   Kind = "systemd_unit"
+	KindProcess       Kind = "process"
 )
 
 // Source is the source name of an entity.
@@ -114,6 +137,11 @@ const (
 	// the central component of an orchestrator, or the Datadog Cluster
 	// Agent.  `kube_metadata` and `cloudfoundry` use this.
 	SourceClusterOrchestrator Source = "cluster_orchestrator"
+
+	// SourceHost represents entities detected on the host itself, outside
+	// of any container runtime or orchestrator.  `systemd` and `process`
+	// use this.
+	SourceHost Source = "host"
 )
 
 // ContainerRuntime is the container runtime used by a container.
@@ -596,6 +624,141 @@ func (t ECSTask) String(verbose bool) string {
 
 var _ Entity = &ECSTask{}
 
+// SystemdUnitState is the state of a systemd unit.
+type SystemdUnitState struct {
+	Running     bool
+	ActiveState string
+	SubState    string
+	StartedAt   time.Time
+}
+
+// String returns a string representation of SystemdUnitState.
+func (s SystemdUnitState) String(verbose bool) string {
+	var sb strings.Builder
+	_, _ = fmt.Fprintln(&sb, "Running:", s.Running)
+
+	if verbose {
+		_, _ = fmt.Fprintln(&sb, "Active State:", s.ActiveState)
+		_, _ = fmt.Fprintln(&sb, "Sub State:", s.SubState)
+		_, _ = fmt.Fprintln(&sb, "Started At:", s.StartedAt)
+	}
+
+	return sb.String()
+}
+
+// SystemdUnit is an Entity representing a systemd unit, and the long-running
+// process it supervises, on a host where workloads are not containerized.
+// Its ID is the unit name (e.g. `nginx.service`).
+type SystemdUnit struct {
+	EntityID
+	EntityMeta
+	Description string
+	EnvVars     map[string]string
+	CgroupPath  string
+	MainPID     int
+	State       SystemdUnitState
+}
+
+// GetID implements Entity#GetID.
+func (u SystemdUnit) GetID() EntityID {
+	return u.EntityID
+}
+
+// Merge implements Entity#Merge.
+func (u *SystemdUnit) Merge(e Entity) error {
+	uu, ok := e.(*SystemdUnit)
+	if !ok {
+		return fmt.Errorf("cannot merge SystemdUnit with different kind %T", e)
+	}
+
+	return merge(u, uu)
+}
+
+// DeepCopy implements Entity#DeepCopy.
+func (u SystemdUnit) DeepCopy() Entity {
+	cp := deepcopy.Copy(u).(SystemdUnit)
+	return &cp
+}
+
+// String implements Entity#String.
+func (u SystemdUnit) String(verbose bool) string {
+	var sb strings.Builder
+	_, _ = fmt.Fprintln(&sb, "----------- Entity ID -----------")
+	_, _ = fmt.Fprint(&sb, u.EntityID.String(verbose))
+
+	_, _ = fmt.Fprintln(&sb, "----------- Entity Meta -----------")
+	_, _ = fmt.Fprint(&sb, u.EntityMeta.String(verbose))
+
+	_, _ = fmt.Fprintln(&sb, "----------- Unit Info -----------")
+	_, _ = fmt.Fprintln(&sb, "Description:", u.Description)
+	_, _ = fmt.Fprintln(&sb, "Main PID:", u.MainPID)
+	_, _ = fmt.Fprint(&sb, u.State.String(verbose))
+
+	if verbose {
+		_, _ = fmt.Fprintln(&sb, "Allowed env variables:", filterAndFormatEnvVars(u.EnvVars))
+		_, _ = fmt.Fprintln(&sb, "Cgroup Path:", u.CgroupPath)
+	}
+
+	return sb.String()
+}
+
+var _ Entity = &SystemdUnit{}
+
+// Process is an Entity representing a long-running process on a host where
+// workloads are not containerized.  Its ID is the PID of the process.
+type Process struct {
+	EntityID
+	EntityMeta
+	Exe       string
+	Cmdline   []string
+	EnvVars   map[string]string
+	StartedAt time.Time
+}
+
+// GetID implements Entity#GetID.
+func (p Process) GetID() EntityID {
+	return p.EntityID
+}
+
+// Merge implements Entity#Merge.
+func (p *Process) Merge(e Entity) error {
+	pp, ok := e.(*Process)
+	if !ok {
+		return fmt.Errorf("cannot merge Process with different kind %T", e)
+	}
+
+	return merge(p, pp)
+}
+
+// DeepCopy implements Entity#DeepCopy.
+func (p Process) DeepCopy() Entity {
+	cp := deepcopy.Copy(p).(Process)
+	return &cp
+}
+
+// String implements Entity#String.
+func (p Process) String(verbose bool) string {
+	var sb strings.Builder
+	_, _ = fmt.Fprintln(&sb, "----------- Entity ID -----------")
+	_, _ = fmt.Fprint(&sb, p.EntityID.String(verbose))
+
+	_, _ = fmt.Fprintln(&sb, "----------- Entity Meta -----------")
+	_, _ = fmt.Fprint(&sb, p.EntityMeta.String(verbose))
+
+	_, _ = fmt.Fprintln(&sb, "----------- Process Info -----------")
+	_, _ = fmt.Fprintln(&sb, "Exe:", p.Exe)
+	_, _ = fmt.Fprintln(&sb, "Started At:", p.StartedAt)
+
+	if verbose {
+		_, _ = fmt.Fprintln(&sb, "Cmdline:", strings.Join(p.Cmdline, " "))
+		_, _ = fmt.Fprintln(&sb, "Allowed env variables:", filterAndFormatEnvVars(p.EnvVars))
+	}
+
+	return sb.String()
+}
+
+var _ Entity = &Process{}
+
 // CollectorEvent is an event generated by a metadata collector, to be handled
 // by the metadata store.
 type CollectorEvent struct {
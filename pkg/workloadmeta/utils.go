@@ -23,9 +23,50 @@ func sliceToString(s []string) string {
 	return strings.Join(s, " ")
 }
 
+// allowedEnvVariables are the only environment variables exposed outside of
+// the store, as the others can contain secrets.
+var allowedEnvVariables = []string{"DD_SERVICE", "DD_ENV", "DD_VERSION"}
+
+// filterEnvVars returns the subset of allowed environment variables.
+func filterEnvVars(envs map[string]string) map[string]string {
+	if envs == nil {
+		return nil
+	}
+
+	filtered := make(map[string]string)
+	for _, allowed := range allowedEnvVariables {
+		if val, found := envs[allowed]; found {
+			filtered[allowed] = val
+		}
+	}
+
+	return filtered
+}
+
+// RedactEntity returns a copy of the entity that is safe to serialize out of
+// the agent, in snapshots or event streams, with only its allowed environment
+// variables.
+func RedactEntity(entity Entity) Entity {
+	switch e := entity.(type) {
+	case *Container:
+		redacted := e.DeepCopy().(*Container)
+		redacted.EnvVars = filterEnvVars(e.EnvVars)
+		return redacted
+	case *SystemdUnit:
+		redacted := e.DeepCopy().(*SystemdUnit)
+		redacted.EnvVars = filterEnvVars(e.EnvVars)
+		return redacted
+	case *Process:
+		redacted := e.DeepCopy().(*Process)
+		redacted.EnvVars = filterEnvVars(e.EnvVars)
+		return redacted
+	default:
+		return entity
+	}
+}
+
 // filterAndFormatEnvVars extracts and formats a subset of allowed environment variables.
 func filterAndFormatEnvVars(envs map[string]string) string {
-	allowedEnvVariables := []string{"DD_SERVICE", "DD_ENV", "DD_VERSION"}
 	var sb strings.Builder
 	for _, allowed := range allowedEnvVariables {
 		if val, found := envs[allowed]; found {
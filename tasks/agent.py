@@ -57,6 +57,7 @@ AGENT_CORECHECKS = [
     "ntp",
     "oom_kill",
     "systemd",
+    "tcp_health",
     "tcp_queue_length",
     "uptime",
     "winkmem",